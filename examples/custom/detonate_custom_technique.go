@@ -26,6 +26,7 @@ func buildCustomAttackTechnique() *stratus.AttackTechnique {
 		Description:                "A sample AWS attack technique that creates an IAM user as a prerequisite, and prints its ARN as a detonation",
 		Platform:                   stratus.AWS,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution},
+		MitreAttackTechniques:      []string{"T1059.009"},
 		PrerequisitesTerraformCode: myPrerequisitesTerraformCode,
 		Detonate:                   detonate,
 	}
@@ -20,6 +20,7 @@ func init() {
 		Description:                "A sample AWS attack technique that creates an IAM user as a prerequisite, and prints its ARN as a detonation",
 		Platform:                   stratus.AWS,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution},
+		MitreAttackTechniques:      []string{"T1059.009"},
 		PrerequisitesTerraformCode: tf,
 		Detonate:                   detonate,
 	})
@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
-	"os"
 
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
@@ -12,13 +11,14 @@ import (
 
 var flagForceCleanup bool
 var flagCleanupAll bool
+var flagCleanupTargetsFile string
 
 func buildCleanupCmd() *cobra.Command {
 	cleanupCmd := &cobra.Command{
 		Use:                   "cleanup [attack-technique-id]... | --all",
 		Aliases:               []string{"clean"},
 		Short:                 "Cleans up any leftover infrastructure or configuration from a TTP.",
-		Example:               "stratus cleanup aws.defense-evasion.cloudtrail-stop\nstratus cleanup --all",
+		Example:               "stratus cleanup aws.defense-evasion.cloudtrail-stop\nstratus cleanup --all\nstratus cleanup --all --targets targets.yaml",
 		DisableFlagsInUseLine: true,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 && flagCleanupAll {
@@ -52,33 +52,48 @@ func buildCleanupCmd() *cobra.Command {
 	}
 	cleanupCmd.Flags().BoolVarP(&flagForceCleanup, "force", "f", false, "Force cleanup even if the technique is already COLD")
 	cleanupCmd.Flags().BoolVarP(&flagCleanupAll, "all", "", false, "Clean up all techniques that are not in COLD state")
+	cleanupCmd.Flags().StringVarP(&flagCleanupTargetsFile, "targets", "", "", "YAML file of AWS accounts and regions, GCP projects and Azure subscriptions to clean up the techniques in, instead of the targets of the configuration file")
 	return cleanupCmd
 }
 
 func doCleanupCmd(techniques []*stratus.AttackTechnique) {
-	workerCount := len(techniques)
-	techniquesChan := make(chan *stratus.AttackTechnique, workerCount)
-	errorsChan := make(chan error, workerCount)
+	targets, err := resolveTargets(flagCleanupTargetsFile)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	jobs := targetTechniques(techniques, targets)
+	workerCount := len(jobs)
+	jobsChan := make(chan targetedTechnique, workerCount)
+	resultsChan := make(chan targetedResult, workerCount)
 	for i := 0; i < workerCount; i++ {
-		go cleanupCmdWorker(techniquesChan, errorsChan)
+		go cleanupCmdWorker(jobsChan, resultsChan)
 	}
-	for i := range techniques {
-		techniquesChan <- techniques[i]
+	for i := range jobs {
+		jobsChan <- jobs[i]
 	}
-	close(techniquesChan)
+	close(jobsChan)
 
-	hadError := handleErrorsChannel(errorsChan, workerCount)
-	doStatusCmd(techniques)
+	results, hadError := handleResultsChannel(resultsChan, workerCount)
+	if hasTargets(results) {
+		printTargetSummary(results)
+	} else {
+		doStatusCmd(techniques, nil)
+	}
 	if hadError {
-		os.Exit(1)
+		log.Exit(1)
 	}
 }
 
-func cleanupCmdWorker(techniques <-chan *stratus.AttackTechnique, errors chan<- error) {
-	for technique := range techniques {
-		stratusRunner := runner.NewRunner(technique, flagForceCleanup)
+func cleanupCmdWorker(jobs <-chan targetedTechnique, results chan<- targetedResult) {
+	for job := range jobs {
+		stratusRunner := runner.NewRunner(job.technique, flagForceCleanup, job.runnerOptions()...)
+		// --all only cleans up the techniques that are not COLD, in each target
+		if flagCleanupAll && !flagForceCleanup && stratusRunner.GetState() == stratus.AttackTechniqueStatusCold {
+			results <- targetedResult{targetedTechnique: job, state: stratus.AttackTechniqueStatusCold, skipped: true}
+			continue
+		}
 		err := stratusRunner.CleanUp()
-		errors <- err
+		results <- targetedResult{targetedTechnique: job, state: stratusRunner.GetState(), err: err}
 	}
 }
 
@@ -4,15 +4,24 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
 	"github.com/spf13/cobra"
 )
 
 var detonateForce bool
+var detonateParameterFlags []string
+var detonateParameters map[string]string
 var detonateCleanup bool
+var detonateTTL time.Duration
+var detonateIUnderstand bool
+var detonateTargetsFile string
+var detonateTargets []config.Target
 
 func buildDetonateCmd() *cobra.Command {
 	detonateCmd := &cobra.Command{
@@ -21,6 +30,10 @@ func buildDetonateCmd() *cobra.Command {
 		Example: strings.Join([]string{
 			"stratus detonate aws.defense-evasion.cloudtrail-stop",
 			"stratus detonate aws.defense-evasion.cloudtrail-stop --cleanup",
+			"stratus detonate aws.defense-evasion.cloudtrail-stop --ttl 2h",
+			"stratus detonate aws.execution.ec2-launch-unusual-instances --param instance_types=p3.2xlarge,g4dn.xlarge",
+			"stratus detonate aws.impact.s3-ransomware-batch-deletion --i-understand",
+			"stratus detonate aws.defense-evasion.cloudtrail-stop --cleanup --targets targets.yaml",
 		}, "\n"),
 		DisableFlagsInUseLine: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -34,7 +47,15 @@ func buildDetonateCmd() *cobra.Command {
 			if len(args) == 0 {
 				return errors.New("you must specify at least one attack technique")
 			}
-			_, err := resolveTechniques(args)
+			techniques, err := resolveTechniques(args)
+			if err != nil {
+				return err
+			}
+			detonateParameters, err = parseParameterFlags(detonateParameterFlags, techniques)
+			if err != nil {
+				return err
+			}
+			detonateTargets, err = resolveTargets(detonateTargetsFile)
 			return err
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -49,39 +70,54 @@ func buildDetonateCmd() *cobra.Command {
 	//detonateCmd.Flags().BoolVarP(&detonateNoWarmup, "no-warmup", "", false, "Do not spin up prerequisite infrastructure or configuration. Requires that 'warmup' was used before.")
 	detonateCmd.Flags().BoolVarP(&detonateForce, "force", "f", false, "Force detonation in cases where the technique is not idempotent and has already been detonated")
 
+	detonateCmd.Flags().StringArrayVarP(&detonateParameterFlags, "param", "", nil, "Set a parameter of the attack technique, as key=value. Can be repeated")
+	detonateCmd.Flags().DurationVarP(&detonateTTL, "ttl", "", 0, "Time after which 'stratus reap' cleans up the technique, e.g. 2h")
+	detonateCmd.Flags().BoolVarP(&detonateIUnderstand, "i-understand", "", false, "Confirm the detonation of techniques with a destructive impact, which may not be reverted")
+	detonateCmd.Flags().StringVarP(&detonateTargetsFile, "targets", "", "", "YAML file of AWS accounts and regions, GCP projects and Azure subscriptions to detonate the techniques in, instead of the targets of the configuration file")
 	return detonateCmd
 }
 func doDetonateCmd(techniques []*stratus.AttackTechnique, cleanup bool) {
-	VerifyPlatformRequirements(techniques)
-	workerCount := len(techniques)
-	techniquesChan := make(chan *stratus.AttackTechnique, workerCount)
-	errorsChan := make(chan error, workerCount)
+	jobs := targetTechniques(techniques, detonateTargets)
+	VerifyPlatformRequirements(untargetedTechniques(jobs))
+	workerCount := len(jobs)
+	jobsChan := make(chan targetedTechnique, workerCount)
+	resultsChan := make(chan targetedResult, workerCount)
 
 	// Create workers
 	for i := 0; i < workerCount; i++ {
-		go detonateCmdWorker(techniquesChan, errorsChan)
+		go detonateCmdWorker(jobsChan, resultsChan)
 	}
 
-	// Send attack techniques to detonate
-	for i := range techniques {
-		techniquesChan <- techniques[i]
+	// Send attack techniques to detonate, once per target
+	for i := range jobs {
+		jobsChan <- jobs[i]
 	}
-	close(techniquesChan)
+	close(jobsChan)
 
-	if hadError := handleErrorsChannel(errorsChan, workerCount); hadError {
-		os.Exit(1)
+	results, hadError := handleResultsChannel(resultsChan, workerCount)
+	if hasTargets(results) {
+		printTargetSummary(results)
+	}
+	if hadError {
+		log.Exit(1)
 	}
 }
 
-func detonateCmdWorker(techniques <-chan *stratus.AttackTechnique, errors chan<- error) {
-	for technique := range techniques {
-		stratusRunner := runner.NewRunner(technique, detonateForce)
-		detonateErr := stratusRunner.Detonate()
+func detonateCmdWorker(jobs <-chan targetedTechnique, results chan<- targetedResult) {
+	for job := range jobs {
+		opts := append([]runner.RunnerOption{
+			runner.WithParameters(parametersOf(job.technique, detonateParameters)),
+			runner.WithTTL(detonateTTL),
+		}, job.runnerOptions()...)
+		if detonateIUnderstand {
+			opts = append(opts, runner.WithDestructiveImpactAcknowledged())
+		}
+		stratusRunner := runner.NewRunner(job.technique, detonateForce, opts...)
+		err := stratusRunner.Detonate()
 		if detonateCleanup {
 			cleanupErr := stratusRunner.CleanUp()
-			errors <- utils.CoalesceErr(detonateErr, cleanupErr)
-		} else {
-			errors <- detonateErr
+			err = utils.CoalesceErr(err, cleanupErr)
 		}
+		results <- targetedResult{targetedTechnique: job, state: stratusRunner.GetState(), err: err}
 	}
 }
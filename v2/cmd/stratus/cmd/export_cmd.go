@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/navigator"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/sigma"
+	"github.com/spf13/cobra"
+)
+
+var exportPlatform string
+var exportLayerName string
+var exportDetonated bool
+var exportSigmaDirectory string
+
+func buildExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export attack techniques to other tools",
+	}
+	exportCmd.AddCommand(buildExportNavigatorLayerCmd())
+	exportCmd.AddCommand(buildExportSigmaCmd())
+	return exportCmd
+}
+
+func buildExportNavigatorLayerCmd() *cobra.Command {
+	navigatorLayerCmd := &cobra.Command{
+		Use:   "navigator-layer",
+		Short: "Export the MITRE ATT&CK coverage of attack techniques as an ATT&CK Navigator layer",
+		Long: "Export a MITRE ATT&CK Navigator JSON layer, scoring each ATT&CK technique by the number of attack " +
+			"techniques mapping to it. With --detonated, ATT&CK techniques are coloured by whether you detonated " +
+			"at least one of these attack techniques, according to the execution journal.",
+		Example: strings.Join([]string{
+			"stratus export navigator-layer > stratus-red-team.json",
+			"stratus export navigator-layer --platform aws --detonated > aws-detonations.json",
+		}, "\n"),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			doExportNavigatorLayerCmd(exportPlatform, exportLayerName, exportDetonated)
+		},
+	}
+	navigatorLayerCmd.Flags().StringVarP(&exportPlatform, "platform", "", "", "Only export the attack techniques of a specific platform")
+	navigatorLayerCmd.Flags().StringVarP(&exportLayerName, "name", "", "", "Name of the layer")
+	navigatorLayerCmd.Flags().BoolVarP(&exportDetonated, "detonated", "", false, "Colour ATT&CK techniques by whether their attack techniques were detonated")
+	return navigatorLayerCmd
+}
+
+func doExportNavigatorLayerCmd(platform string, name string, detonated bool) {
+	filter := stratus.AttackTechniqueFilter{}
+	if platform != "" {
+		platform, err := stratus.PlatformFromString(platform)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filter.Platform = platform
+	}
+	techniques := stratus.GetRegistry().GetAttackTechniques(&filter)
+	options := navigator.LayerOptions{Name: name}
+	if detonated {
+		journal, err := readJournal(techniques)
+		if err != nil {
+			log.Fatalf("unable to read the execution journal: %v", err)
+		}
+		options.Detonations = navigator.LastDetonations(journal)
+	}
+
+	layer, err := navigator.NewLayer(techniques, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(layer); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func buildExportSigmaCmd() *cobra.Command {
+	sigmaCmd := &cobra.Command{
+		Use:   "sigma [attack-technique-id]...",
+		Short: "Generate baseline Sigma rules from the detection rules of attack techniques",
+		Long: "Generate a baseline Sigma rule for each attack technique, or for all of them if none is specified, " +
+			"from its detection rule. Rules are written to stdout as a multi-document YAML stream, or to one file " +
+			"per technique with --directory.",
+		Example: strings.Join([]string{
+			"stratus export sigma aws.defense-evasion.cloudtrail-stop",
+			"stratus export sigma --directory ./rules",
+		}, "\n"),
+		Args: func(cmd *cobra.Command, args []string) error {
+			_, err := resolveTechniques(args)
+			return err
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return getTechniquesCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			techniques, _ := resolveTechniques(args)
+			if len(techniques) == 0 {
+				techniques = stratus.GetRegistry().ListAttackTechniques()
+			}
+			doExportSigmaCmd(techniques, exportSigmaDirectory)
+		},
+	}
+	sigmaCmd.Flags().StringVarP(&exportSigmaDirectory, "directory", "d", "", "Write each rule to <directory>/<attack-technique-id>.yml instead of stdout")
+	return sigmaCmd
+}
+
+func doExportSigmaCmd(techniques []*stratus.AttackTechnique, directory string) {
+	if directory != "" {
+		if err := os.MkdirAll(directory, 0755); err != nil {
+			log.Fatalf("unable to create %s: %v", directory, err)
+		}
+	}
+	now := time.Now()
+	for i, technique := range techniques {
+		rule, err := sigma.NewRule(technique, now)
+		if err != nil {
+			log.Fatal(err)
+		}
+		raw, err := rule.Marshal()
+		if err != nil {
+			log.Fatalf("unable to generate the Sigma rule of %s: %v", technique.ID, err)
+		}
+		if directory == "" {
+			if i > 0 {
+				os.Stdout.WriteString("---\n")
+			}
+			os.Stdout.Write(raw)
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(directory, technique.ID+".yml"), raw, 0644); err != nil {
+			log.Fatalf("unable to write the Sigma rule of %s: %v", technique.ID, err)
+		}
+	}
+	if directory != "" {
+		log.Printf("Wrote %d Sigma rules to %s", len(techniques), directory)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+func buildHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history [attack-technique-id]...",
+		Short: "Display the execution journal of TTPs.",
+		Long: "Display the execution journal, which records every warm up, detonation, revert and cleanup, " +
+			"along with who ran it, when, with which correlation ID and whether it failed.",
+		Example: "stratus history\nstratus history aws.defense-evasion.cloudtrail-stop --output json\nstratus history --correlation-id 5ae4f3d0-3f41-4bd1-a7cc-1c3b1ac1f7de",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil // no technique specified == all techniques
+			}
+			_, err := resolveTechniques(args)
+			return err
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			techniques, _ := resolveTechniques(args)
+			doHistoryCmd(techniques)
+		},
+	}
+	return historyCmd
+}
+
+// doHistoryCmd displays the execution journal of the techniques, restricted to the correlation ID passed
+// with --correlation-id, the environment or the configuration file, if any
+func doHistoryCmd(techniques []*stratus.AttackTechnique) {
+	journal, err := readJournal(techniques)
+	if err != nil {
+		log.Fatalf("unable to read the execution journal: %v", err)
+	}
+	var correlationID string
+	if id := sharedStateSettings().correlationID; id != uuid.Nil {
+		correlationID = id.String()
+	}
+	entries := filterHistory(journal, techniques, correlationID)
+
+	if isStructuredOutput() {
+		result := []journalEntryOutput{}
+		for _, entry := range entries {
+			result = append(result, newJournalEntryOutput(entry))
+		}
+		if err := printStructured(result); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(entries) == 0 {
+		log.Println("No operation recorded in the execution journal")
+		return
+	}
+	t := GetDisplayTable()
+	t.AppendHeader(table.Row{"Time", "Technique", "Operation", "Correlation ID", "Caller", "Duration", "State", "Error"})
+	for _, entry := range entries {
+		duration := (time.Duration(entry.Duration * float64(time.Second))).Round(time.Second)
+		transition := fmt.Sprintf("%s → %s", colorState(entry.StateBefore), colorState(entry.StateAfter))
+		t.AppendRow(table.Row{
+			entry.StartedAt.Local().Format(time.DateTime),
+			entry.TechniqueID,
+			entry.Operation,
+			entry.CorrelationID,
+			entry.Caller,
+			duration,
+			transition,
+			color.RedString(entry.Error),
+		})
+	}
+	t.Render()
+}
+
+// readJournal returns the execution journal of the techniques, or of all techniques if none, oldest first.
+// The local journal is a single file holding every technique, while remote backends are read one
+// technique at a time.
+func readJournal(techniques []*stratus.AttackTechnique) ([]stratus.JournalEntry, error) {
+	if sharedStateSettings().backend == nil {
+		return state.ReadLocalJournal()
+	}
+	if len(techniques) == 0 {
+		techniques = stratus.GetRegistry().ListAttackTechniques()
+	}
+	var entries []stratus.JournalEntry
+	for _, technique := range techniques {
+		techniqueEntries, err := newStateManager(technique, state.WithReadOnlyState()).GetJournalEntries()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the journal of %s: %w", technique.ID, err)
+		}
+		entries = append(entries, techniqueEntries...)
+	}
+	slices.SortStableFunc(entries, func(a, b stratus.JournalEntry) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return entries, nil
+}
+
+// filterHistory keeps the journal entries of the given techniques (all if none) and correlation ID (any if empty)
+func filterHistory(entries []stratus.JournalEntry, techniques []*stratus.AttackTechnique, correlationID string) []stratus.JournalEntry {
+	techniqueIDs := map[string]bool{}
+	for _, technique := range techniques {
+		techniqueIDs[technique.ID] = true
+	}
+	var result []stratus.JournalEntry
+	for _, entry := range entries {
+		if len(techniqueIDs) > 0 && !techniqueIDs[entry.TechniqueID] {
+			continue
+		}
+		if correlationID != "" && entry.CorrelationID != correlationID {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGCSBucket serves objects the way the GCS API does for the storage client, which is pointed at it
+// through STORAGE_EMULATOR_HOST
+type fakeGCSBucket struct {
+	name    string
+	objects map[string]string
+}
+
+func newFakeGCSBucket(t *testing.T, name string, objects map[string]string) {
+	server := httptest.NewServer(&fakeGCSBucket{name: name, objects: objects})
+	t.Cleanup(server.Close)
+	t.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+}
+
+func (m *fakeGCSBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	switch {
+	case r.Method == http.MethodGet && path == "/storage/v1/b/"+m.name+"/o":
+		var names []string
+		for name := range m.objects {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		items := []map[string]any{}
+		for _, name := range names {
+			items = append(items, map[string]any{"bucket": m.name, "name": name})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"kind": "storage#objects", "items": items})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/"+m.name+"/"):
+		name, _ := url.PathUnescape(strings.TrimPrefix(path, "/"+m.name+"/"))
+		object, found := m.objects[name]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, object)
+	default:
+		http.Error(w, "not implemented by the fake GCS bucket", http.StatusNotImplemented)
+	}
+}
+
+// runCommand runs the CLI with args, and returns what it printed on stdout
+func runCommand(t *testing.T, args ...string) string {
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	originalStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() {
+		os.Stdout = originalStdout
+		outputFormat = OutputFormatTable
+		setupLogging()
+	})
+
+	RootCmd.SetArgs(args)
+	require.NoError(t, RootCmd.Execute())
+
+	os.Stdout = originalStdout
+	output, err := os.ReadFile(stdout.Name())
+	require.NoError(t, err)
+	return string(output)
+}
+
+func TestHistoryReadsRemoteJournal(t *testing.T) {
+	useTestState(t, "", "")
+	newFakeGCSBucket(t, "stratus-state", map[string]string{
+		"team/journal/aws.defense-evasion.cloudtrail-stop/20250102T100000.000000000Z-a.json":          `{"technique_id":"aws.defense-evasion.cloudtrail-stop","operation":"detonate","correlation_id":"22222222-2222-2222-2222-222222222222","caller":"bob@ci","started_at":"2025-01-02T10:00:00Z"}`,
+		"team/journal/aws.defense-evasion.cloudtrail-stop/20250101T100000.000000000Z-b.json":          `{"technique_id":"aws.defense-evasion.cloudtrail-stop","operation":"warmup","correlation_id":"11111111-1111-1111-1111-111111111111","caller":"alice@laptop","started_at":"2025-01-01T10:00:00Z"}`,
+		"team/journal/gcp.persistence.create-admin-service-account/20250101T120000.000000000Z-c.json": `{"technique_id":"gcp.persistence.create-admin-service-account","operation":"warmup","correlation_id":"33333333-3333-3333-3333-333333333333","caller":"alice@laptop","started_at":"2025-01-01T12:00:00Z"}`,
+	})
+
+	output := runCommand(t, "history", "aws.defense-evasion.cloudtrail-stop", "gcp.persistence.create-admin-service-account", "--state-backend", "gs://stratus-state/team", "--output", "json")
+
+	var entries []journalEntryOutput
+	require.NoError(t, json.Unmarshal([]byte(output), &entries))
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"warmup", "warmup", "detonate"}, []string{entries[0].Operation, entries[1].Operation, entries[2].Operation})
+	assert.Equal(t, "alice@laptop", entries[0].Caller)
+	assert.Equal(t, "gcp.persistence.create-admin-service-account", entries[1].TechniqueID)
+	assert.Equal(t, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), entries[2].StartedAt.UTC())
+}
+
+// remoteJournal holds two executions of aws.defense-evasion.cloudtrail-stop, with different correlation IDs
+var remoteJournal = map[string]string{
+	"team/journal/aws.defense-evasion.cloudtrail-stop/20250101T100000.000000000Z-a.json": `{"technique_id":"aws.defense-evasion.cloudtrail-stop","operation":"detonate","correlation_id":"11111111-1111-1111-1111-111111111111","caller":"alice@laptop","started_at":"2025-01-01T10:00:00Z","finished_at":"2025-01-01T10:01:00Z"}`,
+	"team/journal/aws.defense-evasion.cloudtrail-stop/20250102T100000.000000000Z-b.json": `{"technique_id":"aws.defense-evasion.cloudtrail-stop","operation":"detonate","correlation_id":"22222222-2222-2222-2222-222222222222","caller":"bob@ci","started_at":"2025-01-02T10:00:00Z","finished_at":"2025-01-02T10:01:00Z"}`,
+}
+
+func TestHistoryUsesStateSettingsOfConfig(t *testing.T) {
+	useTestState(t, "", "")
+	newFakeGCSBucket(t, "stratus-state", remoteJournal)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("state_backend: gs://stratus-state/team\ncorrelation_id: 22222222-2222-2222-2222-222222222222\n"), 0600))
+	t.Setenv(config.ConfigEnvVar, configPath)
+
+	output := runCommand(t, "history", "aws.defense-evasion.cloudtrail-stop", "--output", "json")
+
+	var entries []journalEntryOutput
+	require.NoError(t, json.Unmarshal([]byte(output), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "bob@ci", entries[0].Caller)
+}
+
+func TestHistoryUsesCorrelationIDOfEnvironment(t *testing.T) {
+	useTestState(t, "", "")
+	newFakeGCSBucket(t, "stratus-state", remoteJournal)
+	t.Setenv(runner.EnvVarStratusRedTeamCorrelationId, "11111111-1111-1111-1111-111111111111")
+
+	output := runCommand(t, "history", "aws.defense-evasion.cloudtrail-stop", "--state-backend", "gs://stratus-state/team", "--output", "json")
+
+	var entries []journalEntryOutput
+	require.NoError(t, json.Unmarshal([]byte(output), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alice@laptop", entries[0].Caller)
+}
+
+func TestExportNavigatorLayerReadsRemoteJournal(t *testing.T) {
+	useTestState(t, "", "")
+	newFakeGCSBucket(t, "stratus-state", remoteJournal)
+
+	output := runCommand(t, "export", "navigator-layer", "--platform", "aws", "--detonated", "--state-backend", "gs://stratus-state/team")
+
+	assert.Contains(t, output, "aws.defense-evasion.cloudtrail-stop (last detonated 2025-01-02T10:01:00Z)")
+}
@@ -14,6 +14,7 @@ import (
 
 var listPlatform string
 var listMitreAttackTactic string
+var listMitreAttackTechnique string
 
 func buildListCmd() *cobra.Command {
 	listCmd := &cobra.Command{
@@ -22,17 +23,19 @@ func buildListCmd() *cobra.Command {
 		Example: strings.Join([]string{
 			"stratus list",
 			"stratus list --platform aws --mitre-attack-tactic persistence",
+			"stratus list --mitre-attack-technique T1098",
 		}, "\n"),
 		Run: func(cmd *cobra.Command, args []string) {
-			doListCmd(listMitreAttackTactic, listPlatform)
+			doListCmd(listMitreAttackTactic, listMitreAttackTechnique, listPlatform)
 		},
 	}
 	listCmd.Flags().StringVarP(&listPlatform, "platform", "", "", "Filter on specific platform")
 	listCmd.Flags().StringVarP(&listMitreAttackTactic, "mitre-attack-tactic", "", "", "Filter on a specific MITRE ATT&CK tactic.")
+	listCmd.Flags().StringVarP(&listMitreAttackTechnique, "mitre-attack-technique", "", "", "Filter on a specific MITRE ATT&CK technique, including its sub-techniques, or sub-technique (e.g. T1098 or T1098.001).")
 	return listCmd
 }
 
-func doListCmd(mitreAttackTactic string, platform string) {
+func doListCmd(mitreAttackTactic string, mitreAttackTechnique string, platform string) {
 	filter := stratus.AttackTechniqueFilter{}
 	if platform != "" {
 		platform, err := stratus.PlatformFromString(platform)
@@ -48,9 +51,27 @@ func doListCmd(mitreAttackTactic string, platform string) {
 		}
 		filter.Tactic = tactic
 	}
+	if mitreAttackTechnique != "" {
+		technique, err := mitreattack.AttackTechniqueFromID(mitreAttackTechnique)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filter.MitreAttackTechnique = technique.ID
+	}
 	techniques := stratus.GetRegistry().GetAttackTechniques(&filter)
+	if isStructuredOutput() {
+		result := []techniqueOutput{}
+		for i := range techniques {
+			result = append(result, newTechniqueOutput(techniques[i]))
+		}
+		if err := printStructured(result); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	t := GetDisplayTable()
-	t.AppendHeader(table.Row{"Technique ID", "Technique name", "Platform", "MITRE ATT&CK Tactic"})
+	t.AppendHeader(table.Row{"Technique ID", "Technique name", "Platform", "MITRE ATT&CK Tactic", "MITRE ATT&CK Technique"})
 
 	for i := range techniques {
 		displayName := techniques[i].ID
@@ -62,6 +83,7 @@ func doListCmd(mitreAttackTactic string, platform string) {
 			displayName,
 			techniques[i].Platform,
 			getTacticsString(techniques[i].MitreAttackTactics),
+			strings.Join(techniques[i].MitreAttackTechniques, "\n"),
 		})
 	}
 
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputFormatTable = "table"
+	OutputFormatJSON  = "json"
+	OutputFormatYAML  = "yaml"
+)
+
+var outputFormat string
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case OutputFormatTable, OutputFormatJSON, OutputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q, expected one of: %s, %s, %s", format, OutputFormatTable, OutputFormatJSON, OutputFormatYAML)
+	}
+}
+
+// isStructuredOutput reports whether the user asked for machine-readable output rather than tables
+func isStructuredOutput() bool {
+	return outputFormat == OutputFormatJSON || outputFormat == OutputFormatYAML
+}
+
+// printStructured writes value to stdout in the requested machine-readable format
+func printStructured(value any) error {
+	return writeStructured(os.Stdout, outputFormat, value)
+}
+
+func writeStructured(w io.Writer, format string, value any) error {
+	switch format {
+	case OutputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(value)
+	case OutputFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("output format %q is not machine-readable", format)
+	}
+}
+
+// techniqueOutput is the machine-readable representation of an attack technique
+type techniqueOutput struct {
+	ID                    string                      `json:"id" yaml:"id"`
+	Name                  string                      `json:"name" yaml:"name"`
+	Platform              string                      `json:"platform" yaml:"platform"`
+	MitreAttackTactics    []string                    `json:"mitreAttackTactics" yaml:"mitreAttackTactics"`
+	MitreAttackTechniques []string                    `json:"mitreAttackTechniques" yaml:"mitreAttackTechniques"`
+	FrameworkMappings     []stratus.FrameworkMappings `json:"frameworkMappings,omitempty" yaml:"frameworkMappings,omitempty"`
+	IsSlow                bool                        `json:"isSlow" yaml:"isSlow"`
+	IsIdempotent          bool                        `json:"isIdempotent" yaml:"isIdempotent"`
+	IsDestructive         bool                        `json:"isDestructive" yaml:"isDestructive"`
+}
+
+func newTechniqueOutput(technique *stratus.AttackTechnique) techniqueOutput {
+	tactics := []string{}
+	for _, tactic := range technique.MitreAttackTactics {
+		tactics = append(tactics, mitreattack.AttackTacticToString(tactic))
+	}
+	return techniqueOutput{
+		ID:                    technique.ID,
+		Name:                  technique.FriendlyName,
+		Platform:              string(technique.Platform),
+		MitreAttackTactics:    tactics,
+		MitreAttackTechniques: technique.MitreAttackTechniques,
+		FrameworkMappings:     technique.FrameworkMappings,
+		IsSlow:                technique.IsSlow,
+		IsIdempotent:          technique.IsIdempotent,
+		IsDestructive:         technique.IsDestructive(),
+	}
+}
+
+// techniqueDetailsOutput adds the documentation of a technique, as printed by 'stratus show'
+type techniqueDetailsOutput struct {
+	techniqueOutput `yaml:",inline"`
+	Description     string                       `json:"description" yaml:"description"`
+	Detection       string                       `json:"detection" yaml:"detection"`
+	Parameters      []stratus.TechniqueParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// techniqueStatusOutput is the machine-readable status of a technique, as printed by 'stratus status'
+type techniqueStatusOutput struct {
+	ID               string            `json:"id" yaml:"id"`
+	Target           string            `json:"target,omitempty" yaml:"target,omitempty"`
+	Name             string            `json:"name" yaml:"name"`
+	State            string            `json:"state" yaml:"state"`
+	CorrelationID    string            `json:"correlationId" yaml:"correlationId"`
+	TerraformOutputs map[string]string `json:"terraformOutputs" yaml:"terraformOutputs"`
+	WarmedUpAt       *time.Time        `json:"warmedUpAt,omitempty" yaml:"warmedUpAt,omitempty"`
+	ExpiresAt        *time.Time        `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+}
+
+// targetedResultOutput is the outcome of running a technique in a target, as printed by 'stratus detonate' and
+// 'stratus cleanup' when they run techniques in targets
+type targetedResultOutput struct {
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+	ID     string `json:"id" yaml:"id"`
+	State  string `json:"state" yaml:"state"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// reapedExecutionOutput is an expired execution of a technique, as printed by 'stratus reap'
+type reapedExecutionOutput struct {
+	ID            string    `json:"id" yaml:"id"`
+	Target        string    `json:"target,omitempty" yaml:"target,omitempty"`
+	CorrelationID string    `json:"correlationId" yaml:"correlationId"`
+	State         string    `json:"state" yaml:"state"`
+	WarmedUpAt    time.Time `json:"warmedUpAt" yaml:"warmedUpAt"`
+	ExpiresAt     time.Time `json:"expiresAt" yaml:"expiresAt"`
+	Reaped        bool      `json:"reaped" yaml:"reaped"`
+	Error         string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// journalEntryOutput is the machine-readable representation of an execution journal entry, as printed by 'stratus history'
+type journalEntryOutput struct {
+	TechniqueID            string    `json:"techniqueId" yaml:"techniqueId"`
+	Operation              string    `json:"operation" yaml:"operation"`
+	CorrelationID          string    `json:"correlationId" yaml:"correlationId"`
+	Caller                 string    `json:"caller" yaml:"caller"`
+	StartedAt              time.Time `json:"startedAt" yaml:"startedAt"`
+	FinishedAt             time.Time `json:"finishedAt" yaml:"finishedAt"`
+	DurationSeconds        float64   `json:"durationSeconds" yaml:"durationSeconds"`
+	StateBefore            string    `json:"stateBefore" yaml:"stateBefore"`
+	StateAfter             string    `json:"stateAfter" yaml:"stateAfter"`
+	TerraformVariablesHash string    `json:"terraformVariablesHash,omitempty" yaml:"terraformVariablesHash,omitempty"`
+	Error                  string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func newJournalEntryOutput(entry stratus.JournalEntry) journalEntryOutput {
+	return journalEntryOutput{
+		TechniqueID:            entry.TechniqueID,
+		Operation:              string(entry.Operation),
+		CorrelationID:          entry.CorrelationID,
+		Caller:                 entry.Caller,
+		StartedAt:              entry.StartedAt,
+		FinishedAt:             entry.FinishedAt,
+		DurationSeconds:        entry.Duration,
+		StateBefore:            string(entry.StateBefore),
+		StateAfter:             string(entry.StateAfter),
+		TerraformVariablesHash: entry.TerraformVariablesHash,
+		Error:                  entry.Error,
+	}
+}
+
+// techniquePlanOutput is what warming up and detonating a technique would do, as printed by 'stratus warmup --dry-run'
+type techniquePlanOutput struct {
+	ID         string                    `json:"id" yaml:"id"`
+	Target     string                    `json:"target,omitempty" yaml:"target,omitempty"`
+	Name       string                    `json:"name" yaml:"name"`
+	Resources  []stratus.PlannedResource `json:"resources" yaml:"resources"`
+	Detonation detonationPlanOutput      `json:"detonation" yaml:"detonation"`
+}
+
+type detonationPlanOutput struct {
+	Description    string                  `json:"description" yaml:"description"`
+	ExpectedEvents []stratus.ExpectedEvent `json:"expectedEvents,omitempty" yaml:"expectedEvents,omitempty"`
+}
+
+func newTechniquePlanOutput(technique *stratus.AttackTechnique, resources []stratus.PlannedResource) techniquePlanOutput {
+	return techniquePlanOutput{
+		ID:        technique.ID,
+		Name:      technique.FriendlyName,
+		Resources: resources,
+		Detonation: detonationPlanOutput{
+			Description:    strings.TrimSpace(technique.Description),
+			ExpectedEvents: technique.ExpectedEvents,
+		},
+	}
+}
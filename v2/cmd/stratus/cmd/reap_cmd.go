@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var reapDryRun bool
+var reapForce bool
+var reapTargetsFile string
+
+func buildReapCmd() *cobra.Command {
+	reapCmd := &cobra.Command{
+		Use:   "reap [attack-technique-id]...",
+		Short: "Clean up every execution of TTPs that has outlived its TTL.",
+		Long: "Clean up every execution that was warmed up or detonated with --ttl and has expired, including " +
+			"those isolated by a correlation ID. Exits with a non-zero status if any cleanup fails, " +
+			"which makes it suitable for a cron job.",
+		Example: "stratus reap\nstratus reap --dry-run\nstratus reap aws.defense-evasion.cloudtrail-stop\nstratus reap --targets targets.yaml",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return nil // no technique specified == all techniques
+			}
+			_, err := resolveTechniques(args)
+			return err
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return getTechniquesCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			techniques, _ := resolveTechniques(args)
+			if len(techniques) == 0 {
+				techniques = stratus.GetRegistry().ListAttackTechniques()
+			}
+			doReapCmd(techniques)
+		},
+	}
+	reapCmd.Flags().BoolVarP(&reapDryRun, "dry-run", "", false, "Only display the expired executions, without cleaning them up")
+	reapCmd.Flags().BoolVarP(&reapForce, "force", "f", false, "Clean up expired executions even if reverting their detonation fails")
+	reapCmd.Flags().StringVarP(&reapTargetsFile, "targets", "", "", "YAML file of the targets that the executions ran in, instead of the targets of the configuration file")
+	return reapCmd
+}
+
+// expiredExecution is an execution of a technique that has outlived its TTL.
+type expiredExecution struct {
+	Technique *stratus.AttackTechnique
+	// Sub-directory of the execution, "" for the flat layout
+	ExecutionSubdirectory string
+	CorrelationID         string
+	// Target the execution ran in, nil if it used the default credentials
+	Target   *config.Target
+	State    stratus.AttackTechniqueState
+	Lifetime stratus.ExecutionLifetime
+}
+
+func doReapCmd(techniques []*stratus.AttackTechnique) {
+	targets, err := resolveTargets(reapTargetsFile)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	executions, hadError := findExpiredExecutions(techniques, targets, time.Now())
+	if len(executions) == 0 && !isStructuredOutput() {
+		log.Println("No expired execution to reap")
+	}
+
+	errs := make([]error, len(executions))
+	if !reapDryRun && len(executions) > 0 {
+		var expiredTechniques []*stratus.AttackTechnique
+		for _, execution := range executions {
+			expiredTechniques = append(expiredTechniques, execution.Technique)
+		}
+		VerifyPlatformRequirements(expiredTechniques)
+
+		// One at a time, as a cron job has no reason to hurry
+		for i, execution := range executions {
+			log.Printf("Reaping %s (correlation ID %s), expired since %s", execution.Technique.ID, execution.CorrelationID, execution.Lifetime.ExpiresAt.Local().Format(time.DateTime))
+			if errs[i] = reapExecution(execution); errs[i] != nil {
+				log.Println(errs[i])
+				hadError = true
+			}
+		}
+	}
+	printReapResults(executions, errs)
+	if hadError {
+		log.Exit(1)
+	}
+}
+
+// findExpiredExecutions looks for expired executions across all sub-directories of the techniques.
+// It reports whether some of them could not be inspected, or ran in a target that is not among targets,
+// but carries on with the others.
+func findExpiredExecutions(techniques []*stratus.AttackTechnique, targets []config.Target, now time.Time) ([]expiredExecution, bool) {
+	var result []expiredExecution
+	hadError := false
+	for _, technique := range techniques {
+		subdirectories, err := newStateManager(technique, state.WithReadOnlyState()).ListExecutions()
+		if err != nil {
+			log.Warnf("unable to list the executions of %s: %v", technique.ID, err)
+			hadError = true
+			continue
+		}
+		for _, subdirectory := range subdirectories {
+			stateManager := newStateManager(technique, state.WithReadOnlyState(), state.WithExecutionSubdirectory(subdirectory))
+			lifetime, err := stateManager.GetExecutionLifetime()
+			if err != nil {
+				log.Warnf("unable to read the lifetime of %s: %v", stateManager.GetWorkingDirectory(), err)
+				hadError = true
+				continue
+			}
+			techniqueState := stateManager.GetTechniqueState()
+			if lifetime == nil || !lifetime.IsExpired(now) || techniqueState == stratus.AttackTechniqueStatusCold {
+				continue
+			}
+			correlationID, target, err := parseExecutionSubdirectory(subdirectory, technique, targets)
+			if err != nil {
+				log.Warnf("refusing to reap %s: %v", stateManager.GetWorkingDirectory(), err)
+				hadError = true
+				continue
+			}
+			if subdirectory == "" || (correlationID == "" && target != nil) {
+				// The flat layout and target subdirectories are not named after their correlation ID, but remember it
+				variables, _ := stateManager.GetTerraformVariables()
+				correlationID = state.CorrelationIDFromVariables(variables)
+			}
+			result = append(result, expiredExecution{
+				Technique:             technique,
+				ExecutionSubdirectory: subdirectory,
+				CorrelationID:         correlationID,
+				Target:                target,
+				State:                 techniqueState,
+				Lifetime:              *lifetime,
+			})
+		}
+	}
+	return result, hadError
+}
+
+func reapExecution(execution expiredExecution) error {
+	opts := []runner.RunnerOption{
+		runner.WithStateManager(newStateManager(execution.Technique, state.WithExecutionSubdirectory(execution.ExecutionSubdirectory))),
+	}
+	if correlationID, err := uuid.Parse(execution.CorrelationID); err == nil {
+		opts = append(opts, runner.WithCorrelationID(correlationID))
+	}
+	if execution.Target != nil {
+		opts = append(opts, runner.WithTarget(*execution.Target))
+	}
+	return runner.NewRunner(execution.Technique, reapForce, opts...).CleanUp()
+}
+
+// parseExecutionSubdirectory returns the correlation ID and the target that named the subdirectory of an
+// execution, as named by runner.ExecutionSubdirectory. The target must be one of targets, since cleaning up an
+// execution with other credentials than those it ran with would leave its resources behind.
+func parseExecutionSubdirectory(subdirectory string, technique *stratus.AttackTechnique, targets []config.Target) (string, *config.Target, error) {
+	if subdirectory == "" {
+		return "", nil, nil
+	}
+	if correlationID, err := uuid.Parse(subdirectory); err == nil {
+		return correlationID.String(), nil, nil
+	}
+	correlationID := ""
+	targetName := subdirectory
+	if prefix, name, found := strings.Cut(subdirectory, "_"); found {
+		if id, err := uuid.Parse(prefix); err == nil {
+			correlationID, targetName = id.String(), name
+		}
+	}
+	for i := range targets {
+		if targets[i].Name == targetName {
+			if !isTargetOf(targets[i], technique.Platform) {
+				return "", nil, fmt.Errorf("it ran in the target %s, which is not a target of %s techniques", targetName, technique.Platform)
+			}
+			return correlationID, &targets[i], nil
+		}
+	}
+	return "", nil, fmt.Errorf("it ran in the target %s, which is not configured, pass its targets file with --targets", targetName)
+}
+
+func printReapResults(executions []expiredExecution, errs []error) {
+	if isStructuredOutput() {
+		result := []reapedExecutionOutput{}
+		for i, execution := range executions {
+			output := reapedExecutionOutput{
+				ID:            execution.Technique.ID,
+				Target:        targetName(execution.Target),
+				CorrelationID: execution.CorrelationID,
+				State:         string(execution.State),
+				WarmedUpAt:    execution.Lifetime.WarmedUpAt,
+				ExpiresAt:     *execution.Lifetime.ExpiresAt,
+				Reaped:        !reapDryRun && errs[i] == nil,
+			}
+			if errs[i] != nil {
+				output.Error = errs[i].Error()
+			}
+			result = append(result, output)
+		}
+		if err := printStructured(result); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(executions) == 0 {
+		return
+	}
+
+	t := GetDisplayTable()
+	t.AppendHeader(table.Row{"ID", "Target", "Correlation ID", "Status", "Expired", "Result"})
+	for i, execution := range executions {
+		result := color.GreenString("reaped")
+		switch {
+		case reapDryRun:
+			result = "would be reaped"
+		case errs[i] != nil:
+			result = color.RedString("failed")
+		}
+		t.AppendRow(table.Row{
+			execution.Technique.ID,
+			targetName(execution.Target),
+			execution.CorrelationID,
+			colorState(execution.State),
+			execution.Lifetime.ExpiresAt.Local().Format(time.DateTime),
+			result,
+		})
+	}
+	t.Render()
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// warmUpExpiredExecution leaves the state of a WARM execution of technique that expired an hour ago
+func warmUpExpiredExecution(t *testing.T, technique *stratus.AttackTechnique, subdirectory string) {
+	stateManager := state.NewFileSystemStateManager(technique, state.WithExecutionSubdirectory(subdirectory))
+	require.NoError(t, stateManager.ExtractTechnique())
+	require.NoError(t, stateManager.SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+	require.NoError(t, stateManager.WriteExecutionLifetime(stratus.NewExecutionLifetime(time.Now().Add(-2*time.Hour), time.Hour)))
+}
+
+func TestFindExpiredExecutionsInTargets(t *testing.T) {
+	useTestState(t, "", "")
+	technique := stratus.GetRegistry().GetAttackTechniqueByName("aws.defense-evasion.cloudtrail-stop")
+	targets := []config.Target{
+		{Name: "sandbox", GCP: &config.GCPTarget{ProjectID: "stratus-sandbox"}},
+		{Name: "prod-eu-west-1", AWS: &config.AWSTarget{RoleARN: "arn:aws:iam::123456789012:role/stratus", Region: "eu-west-1"}},
+	}
+	correlationID := uuid.MustParse("5ae4f3d0-3f41-4bd1-a7cc-1c3b1ac1f7de")
+	warmUpExpiredExecution(t, technique, runner.ExecutionSubdirectory(correlationID, &targets[1]))
+
+	executions, hadError := findExpiredExecutions([]*stratus.AttackTechnique{technique}, targets, time.Now())
+
+	assert.False(t, hadError)
+	require.Len(t, executions, 1)
+	assert.Equal(t, correlationID.String(), executions[0].CorrelationID)
+	require.NotNil(t, executions[0].Target)
+	assert.Equal(t, "prod-eu-west-1", executions[0].Target.Name)
+}
+
+func TestFindExpiredExecutionsRefusesUnknownTargets(t *testing.T) {
+	useTestState(t, "", "")
+	technique := stratus.GetRegistry().GetAttackTechniqueByName("aws.defense-evasion.cloudtrail-stop")
+	warmUpExpiredExecution(t, technique, "prod-eu-west-1")
+	warmUpExpiredExecution(t, technique, "sandbox")
+	targets := []config.Target{{Name: "sandbox", GCP: &config.GCPTarget{ProjectID: "stratus-sandbox"}}}
+
+	executions, hadError := findExpiredExecutions([]*stratus.AttackTechnique{technique}, targets, time.Now())
+
+	// Neither is reaped: one ran in a target that is not configured, and the other cannot be an AWS target
+	assert.True(t, hadError)
+	assert.Empty(t, executions)
+}
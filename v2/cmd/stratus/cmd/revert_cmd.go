@@ -6,17 +6,22 @@ import (
 	"os"
 
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
 	"github.com/spf13/cobra"
 )
 
 var revertForce bool
+var revertParameterFlags []string
+var revertParameters map[string]string
+var revertTargetsFile string
+var revertTargets []config.Target
 
 func buildRevertCmd() *cobra.Command {
 	detonateCmd := &cobra.Command{
 		Use:                   "revert attack-technique-id [attack-technique-id]...",
 		Short:                 "Revert the detonation of an attack technique",
-		Example:               "stratus revert aws.defense-evasion.cloudtrail-stop",
+		Example:               "stratus revert aws.defense-evasion.cloudtrail-stop\nstratus revert aws.defense-evasion.cloudtrail-stop --targets targets.yaml",
 		DisableFlagsInUseLine: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -29,7 +34,15 @@ func buildRevertCmd() *cobra.Command {
 			if len(args) == 0 {
 				return errors.New("you must specify at least one attack technique")
 			}
-			_, err := resolveTechniques(args)
+			techniques, err := resolveTechniques(args)
+			if err != nil {
+				return err
+			}
+			revertParameters, err = parseParameterFlags(revertParameterFlags, techniques)
+			if err != nil {
+				return err
+			}
+			revertTargets, err = resolveTargets(revertTargetsFile)
 			return err
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -41,42 +54,49 @@ func buildRevertCmd() *cobra.Command {
 		},
 	}
 	detonateCmd.Flags().BoolVarP(&revertForce, "force", "f", false, "Force attempt to reverting even if the technique is not in the DETONATED state")
+	detonateCmd.Flags().StringArrayVarP(&revertParameterFlags, "param", "", nil, "Set a parameter of the attack technique, as key=value. Can be repeated")
+	detonateCmd.Flags().StringVarP(&revertTargetsFile, "targets", "", "", "YAML file of AWS accounts and regions, GCP projects and Azure subscriptions to revert the techniques in, instead of the targets of the configuration file")
 	return detonateCmd
 }
 
 func doRevertCmd(techniques []*stratus.AttackTechnique) {
-	VerifyPlatformRequirements(techniques)
-	workerCount := len(techniques)
-	techniquesChan := make(chan *stratus.AttackTechnique, workerCount)
-	errorsChan := make(chan error, workerCount)
+	jobs := targetTechniques(techniques, revertTargets)
+	VerifyPlatformRequirements(untargetedTechniques(jobs))
+	workerCount := len(jobs)
+	jobsChan := make(chan targetedTechnique, workerCount)
+	resultsChan := make(chan targetedResult, workerCount)
 
 	// Create workers
 	for i := 0; i < workerCount; i++ {
-		go revertCmdWorker(techniquesChan, errorsChan)
+		go revertCmdWorker(jobsChan, resultsChan)
 	}
 
-	// Send attack techniques to revert
-	for i := range techniques {
-		techniquesChan <- techniques[i]
+	// Send attack techniques to revert, once per target
+	for i := range jobs {
+		jobsChan <- jobs[i]
 	}
-	close(techniquesChan)
+	close(jobsChan)
 
-	hadError := handleErrorsChannel(errorsChan, workerCount)
-	doStatusCmd(techniques)
+	results, hadError := handleResultsChannel(resultsChan, workerCount)
+	if hasTargets(results) {
+		printTargetSummary(results)
+	} else {
+		doStatusCmd(techniques, nil)
+	}
 	if hadError {
-		os.Exit(1)
+		log.Exit(1)
 	}
 }
 
-func revertCmdWorker(techniques <-chan *stratus.AttackTechnique, errors chan<- error) {
-	for technique := range techniques {
-		if technique.Revert == nil {
-			log.Warnf("%s has no revert function and cannot be reverted.", technique.ID)
-			errors <- nil
+func revertCmdWorker(jobs <-chan targetedTechnique, results chan<- targetedResult) {
+	for job := range jobs {
+		if !job.technique.IsRevertible() {
+			log.Warnf("%s has no revert function and cannot be reverted.", job.technique.ID)
+			results <- targetedResult{targetedTechnique: job, skipped: true}
 			continue
 		}
-		stratusRunner := runner.NewRunner(technique, revertForce)
+		stratusRunner := runner.NewRunner(job.technique, revertForce, append(job.runnerOptions(), runner.WithParameters(parametersOf(job.technique, revertParameters)))...)
 		err := stratusRunner.Revert()
-		errors <- err
+		results <- targetedResult{targetedTechnique: job, state: stratusRunner.GetState(), err: err}
 	}
 }
@@ -1,21 +1,54 @@
 package cmd
 
 import (
+	"context"
 	stdlog "log"
 	"log/slog"
 	"os"
 
 	_ "github.com/datadog/stratus-red-team/v2/internal/attacktechniques"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/packs"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/tracing"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
+// tracerProvider exports the traces of the command, if OTEL_TRACES_EXPORTER is set
+var tracerProvider *sdktrace.TracerProvider
+
+// techniquePacks are directories of technique packs to load, in addition to the default ones
+var techniquePacks []string
+
 var RootCmd = &cobra.Command{
 	Use: "stratus",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return err
+		}
+		setupLogging()
+		if err := validateStateFlags(); err != nil {
+			return err
+		}
+		return setupTracing()
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		shutdownTracing()
+	},
 }
 
 func init() {
 	setupLogging()
+	// Initializers run before arguments are validated, which requires the techniques of packs to be registered
+	cobra.OnInitialize(loadTechniquePacks)
+
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", OutputFormatTable, "Output format: table, json or yaml")
+	RootCmd.PersistentFlags().StringSliceVar(&techniquePacks, "technique-pack", []string{}, "Directory of a technique pack to load, in addition to "+packs.EnvVarTechniquePacks+" and ~/.stratus-red-team/packs")
+	RootCmd.PersistentFlags().StringVar(&stateBackendFlag, "state-backend", "", "Bucket or container to share state in, e.g. s3://bucket/prefix?region=us-east-1, gs://bucket/prefix or azblob://account/container/prefix")
+	RootCmd.PersistentFlags().StringVar(&correlationIDFlag, "correlation-id", "", "Correlation ID of the execution, isolating its state from others. Defaults to "+runner.EnvVarStratusRedTeamCorrelationId)
 
 	listCmd := buildListCmd()
 	showCmd := buildShowCmd()
@@ -24,6 +57,13 @@ func init() {
 	revertCmd := buildRevertCmd()
 	statusCmd := buildStatusCmd()
 	cleanupCmd := buildCleanupCmd()
+	scenarioCmd := buildScenarioCmd()
+	unlockCmd := buildUnlockCmd()
+	verifyCmd := buildVerifyCmd()
+	serveCmd := buildServeCmd()
+	historyCmd := buildHistoryCmd()
+	reapCmd := buildReapCmd()
+	exportCmd := buildExportCmd()
 	versionCmd := buildVersionCmd()
 
 	RootCmd.AddCommand(listCmd)
@@ -33,12 +73,52 @@ func init() {
 	RootCmd.AddCommand(revertCmd)
 	RootCmd.AddCommand(statusCmd)
 	RootCmd.AddCommand(cleanupCmd)
+	RootCmd.AddCommand(scenarioCmd)
+	RootCmd.AddCommand(unlockCmd)
+	RootCmd.AddCommand(verifyCmd)
+	RootCmd.AddCommand(serveCmd)
+	RootCmd.AddCommand(historyCmd)
+	RootCmd.AddCommand(reapCmd)
+	RootCmd.AddCommand(exportCmd)
 	RootCmd.AddCommand(versionCmd)
 }
 
 func setupLogging() {
-	// Keep the historical CLI format on stdout.
-	log.SetLogger(slog.New(log.NewLegacyHandler(os.Stdout)))
-	// Keep stdlib-log output (e.g. custom CLI extensions) on stdout too.
-	stdlog.SetOutput(os.Stdout)
+	// Keep the historical CLI format on stdout, unless it holds a JSON or YAML document that logs would corrupt.
+	logOutput := os.Stdout
+	if isStructuredOutput() {
+		logOutput = os.Stderr
+	}
+	log.SetLogger(slog.New(log.NewLegacyHandler(logOutput)))
+	// Keep stdlib-log output (e.g. custom CLI extensions) alongside.
+	stdlog.SetOutput(logOutput)
+}
+
+// loadTechniquePacks registers the attack techniques of technique packs, so that commands see them like built-in ones
+func loadTechniquePacks() {
+	err := packs.LoadAndRegister(stratus.GetRegistry(), append(packs.DefaultDirectories(), techniquePacks...)...)
+	if err != nil {
+		log.Fatalf("error loading technique packs: %s", err)
+	}
+}
+
+// setupTracing makes the tracer provider selected by OTEL_TRACES_EXPORTER the global one, which runners
+// use by default.
+func setupTracing() error {
+	provider, err := tracing.NewTracerProviderFromEnv(context.Background())
+	if err != nil || provider == nil {
+		return err
+	}
+	tracerProvider = provider
+	otel.SetTracerProvider(provider)
+	// Commands that fail exit without returning, and their traces are the ones that matter most
+	log.RegisterExitHandler(shutdownTracing)
+	return nil
+}
+
+// shutdownTracing flushes the spans that were not exported yet
+func shutdownTracing() {
+	if tracerProvider != nil {
+		_ = tracerProvider.Shutdown(context.Background())
+	}
 }
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredOutputLogsToStderr(t *testing.T) {
+	useTestState(t, "", "")
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.NoError(t, err)
+	originalStderr := os.Stderr
+	os.Stderr = stderr
+	t.Cleanup(func() { os.Stderr = originalStderr })
+
+	assert.Contains(t, runCommand(t, "unlock", "aws.defense-evasion.cloudtrail-stop"), "aws.defense-evasion.cloudtrail-stop is not locked")
+	assert.Empty(t, runCommand(t, "unlock", "aws.defense-evasion.cloudtrail-stop", "--output", "json"))
+
+	logs, err := os.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(logs), "aws.defense-evasion.cloudtrail-stop is not locked")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/scenario"
+	"github.com/spf13/cobra"
+)
+
+func buildScenarioCmd() *cobra.Command {
+	scenarioCmd := &cobra.Command{
+		Use:   "scenario",
+		Short: "Chain multiple attack techniques into a single attack campaign",
+	}
+	scenarioCmd.AddCommand(buildScenarioRunCmd())
+	return scenarioCmd
+}
+
+var scenarioIUnderstand bool
+
+func buildScenarioRunCmd() *cobra.Command {
+	scenarioRunCmd := &cobra.Command{
+		Use:   "run scenario-file",
+		Short: "Run the steps of a scenario file, with a shared correlation ID",
+		Example: strings.Join([]string{
+			"stratus scenario run campaign.yaml",
+		}, "\n"),
+		DisableFlagsInUseLine: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must specify exactly one scenario file")
+			}
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			doScenarioRunCmd(args[0])
+		},
+	}
+	scenarioRunCmd.Flags().BoolVarP(&scenarioIUnderstand, "i-understand", "", false, "Confirm the detonation of techniques with a destructive impact, which may not be reverted")
+	return scenarioRunCmd
+}
+
+func doScenarioRunCmd(path string) {
+	campaign, err := scenario.Load(path)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if err := campaign.Validate(stratus.GetRegistry()); err != nil {
+		log.Fatal("invalid scenario: " + err.Error())
+	}
+	techniques, _ := campaign.Techniques(stratus.GetRegistry())
+	VerifyPlatformRequirements(techniques)
+
+	// Stop the steps in flight on Ctrl+C, while still cleaning up the steps that asked for it. A second
+	// Ctrl+C exits right away, since cleaning up ignores the first one.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	// Steps keep the correlation ID of the scenario, but share state in the backend of --state-backend
+	opts := []scenario.ExecutorOption{scenario.WithRunnerOptions(stateBackendOptions()...)}
+	if scenarioIUnderstand {
+		opts = append(opts, scenario.WithRunnerOptions(runner.WithDestructiveImpactAcknowledged()))
+	}
+	executor := scenario.NewExecutor(campaign, opts...)
+	result, err := executor.Run(ctx)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	t := GetDisplayTable()
+	t.AppendHeader([]interface{}{"Step", "Technique", "Status", "Duration", "Cleaned up"})
+	for _, step := range result.Steps {
+		t.AppendRow([]interface{}{step.StepID, step.Technique, step.Status, formatStepDuration(step), formatStepCleanup(campaign, step)})
+	}
+	t.Render()
+	log.Infof("All steps used the correlation ID %s", result.CorrelationID)
+
+	if result.Failed() {
+		log.Println(result.Errors())
+		log.Exit(1)
+	}
+}
+
+func formatStepDuration(step scenario.StepResult) string {
+	if step.Status == scenario.StepStatusSkipped {
+		return "-"
+	}
+	return step.Duration.Round(time.Second).String()
+}
+
+func formatStepCleanup(campaign *scenario.Scenario, result scenario.StepResult) string {
+	for _, step := range campaign.Steps {
+		if step.ID != result.StepID {
+			continue
+		}
+		switch {
+		case step.Cleanup == scenario.CleanupNever || result.Status == scenario.StepStatusSkipped:
+			return "-"
+		case result.CleanupError != nil:
+			return "FAILED"
+		default:
+			return "yes"
+		}
+	}
+	return "-"
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/server"
+	"github.com/spf13/cobra"
+)
+
+// EnvVarServeToken holds the bearer token required by 'stratus serve', to keep it out of the process list
+const EnvVarServeToken = "STRATUS_RED_TEAM_SERVE_TOKEN"
+
+var serveListenAddress string
+var serveIUnderstand bool
+var serveJobRetention time.Duration
+
+func buildServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Expose the lifecycle of attack techniques over an HTTP API",
+		Example: strings.Join([]string{
+			"stratus serve",
+			"STRATUS_RED_TEAM_SERVE_TOKEN=my-token stratus serve --listen 0.0.0.0:8080",
+		}, "\n"),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			doServeCmd(serveListenAddress, os.Getenv(EnvVarServeToken))
+		},
+	}
+	serveCmd.Flags().StringVarP(&serveListenAddress, "listen", "", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().DurationVarP(&serveJobRetention, "job-retention", "", server.DefaultJobRetention, "How long finished jobs and their logs remain available")
+	serveCmd.Flags().BoolVarP(&serveIUnderstand, "i-understand", "", false, "Allow jobs to detonate techniques with a destructive impact, which may not be reverted")
+	return serveCmd
+}
+
+func doServeCmd(listenAddress string, token string) {
+	// Load the configuration once, rather than in every job
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("error loading config: %s", err)
+	}
+
+	var opts []server.ServerOption
+	opts = append(opts, server.WithRunnerOptions(runner.WithConfig(cfg)))
+	if backend := sharedStateSettings().backend; backend != nil {
+		opts = append(opts, server.WithStateBackend(*backend))
+	}
+	opts = append(opts, server.WithJobRetention(serveJobRetention))
+	if serveIUnderstand {
+		opts = append(opts, server.WithRunnerOptions(runner.WithDestructiveImpactAcknowledged()))
+	}
+	if token != "" {
+		opts = append(opts, server.WithBearerToken(token))
+	} else {
+		log.Warnf("%s is not set, the API will not require authentication", EnvVarServeToken)
+	}
+	stratusServer := server.NewServer(opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	httpServer := &http.Server{
+		Addr:              listenAddress,
+		Handler:           stratusServer.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		log.Println("Shutting down, cancelling the jobs in flight")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Infof("Listening on http://%s", listenAddress)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	stratusServer.Close()
+}
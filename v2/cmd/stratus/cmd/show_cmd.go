@@ -4,7 +4,12 @@ import (
 	"errors"
 	"fmt"
 
+	"strings"
+
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
 
@@ -31,7 +36,39 @@ func buildShowCmd() *cobra.Command {
 }
 
 func doShowCmd(techniques []*stratus.AttackTechnique) {
+	if isStructuredOutput() {
+		result := []techniqueDetailsOutput{}
+		for i := range techniques {
+			result = append(result, techniqueDetailsOutput{
+				techniqueOutput: newTechniqueOutput(techniques[i]),
+				Description:     strings.TrimSpace(techniques[i].Description),
+				Detection:       strings.TrimSpace(techniques[i].Detection),
+				Parameters:      techniques[i].Parameters,
+			})
+		}
+		if err := printStructured(result); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	for i := range techniques {
 		fmt.Println(techniques[i].Description)
+		if detection := strings.TrimSpace(techniques[i].Detection); detection != "" {
+			fmt.Println(color.CyanString("Detection"))
+			fmt.Println()
+			fmt.Println(detection)
+		}
+		if len(techniques[i].Parameters) > 0 {
+			fmt.Println()
+			fmt.Println(color.CyanString("Parameters"))
+			fmt.Println()
+			t := GetDisplayTable()
+			t.AppendHeader(table.Row{"Name", "Type", "Default", "Description"})
+			for _, parameter := range techniques[i].Parameters {
+				t.AppendRow(table.Row{parameter.Name, parameter.Type, parameter.Default, parameter.Description})
+			}
+			t.Render()
+		}
 	}
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/google/uuid"
+)
+
+// stateBackendFlag and correlationIDFlag are the global --state-backend and --correlation-id flags
+var stateBackendFlag string
+var correlationIDFlag string
+
+// stateSettings are the state backend and correlation ID shared by all commands, resolved on first use
+// since they require loading the configuration file
+type stateSettings struct {
+	// backend is nil when state is stored on the local filesystem
+	backend *runner.StateBackend
+	// correlationID is uuid.Nil when none was provided
+	correlationID uuid.UUID
+}
+
+var sharedStateSettings = newSharedStateSettings()
+
+func newSharedStateSettings() func() stateSettings {
+	return sync.OnceValue(func() stateSettings {
+		settings, err := resolveStateSettings()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return settings
+	})
+}
+
+// validateStateFlags fails early on malformed flags, before any command runs
+func validateStateFlags() error {
+	if correlationIDFlag != "" {
+		if _, err := uuid.Parse(correlationIDFlag); err != nil {
+			return fmt.Errorf("invalid correlation ID %s: %w", correlationIDFlag, err)
+		}
+	}
+	return nil
+}
+
+// resolveStateSettings reads the flags, and else the environment and the configuration file
+func resolveStateSettings() (stateSettings, error) {
+	var settings stateSettings
+	backendURL := stateBackendFlag
+	rawCorrelationID := correlationIDFlag
+	if rawCorrelationID == "" {
+		rawCorrelationID = runner.ExecutionSubdirectoryFromEnv()
+	}
+	if backendURL == "" || rawCorrelationID == "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return settings, fmt.Errorf("error loading config: %w", err)
+		}
+		if backendURL == "" {
+			backendURL = cfg.GetStateBackend()
+		}
+		if rawCorrelationID == "" {
+			rawCorrelationID = cfg.GetCorrelationID()
+		}
+	}
+
+	if backendURL != "" {
+		backend, err := runner.ParseStateBackend(context.Background(), backendURL)
+		if err != nil {
+			return settings, err
+		}
+		settings.backend = backend
+	}
+	if rawCorrelationID != "" {
+		correlationID, err := uuid.Parse(rawCorrelationID)
+		if err != nil {
+			return settings, fmt.Errorf("invalid correlation ID %s: %w", rawCorrelationID, err)
+		}
+		settings.correlationID = correlationID
+	}
+	return settings, nil
+}
+
+// stateBackendOptions returns the runner options storing state in the shared backend, if any
+func stateBackendOptions() []runner.RunnerOption {
+	if backend := sharedStateSettings().backend; backend != nil {
+		return []runner.RunnerOption{runner.WithStateBackend(*backend)}
+	}
+	return nil
+}
+
+// globalRunnerOptions returns the runner options of the global --state-backend and --correlation-id flags
+func globalRunnerOptions() []runner.RunnerOption {
+	opts := stateBackendOptions()
+	if correlationID := sharedStateSettings().correlationID; correlationID != uuid.Nil {
+		opts = append(opts, runner.WithCorrelationID(correlationID))
+	}
+	return opts
+}
+
+// newStateManager returns the state manager of a technique in the shared backend, or else on the local filesystem
+func newStateManager(technique *stratus.AttackTechnique, opts ...state.ManagerOption) state.StateManager {
+	backend := sharedStateSettings().backend
+	if backend == nil {
+		return state.NewFileSystemStateManager(technique, opts...)
+	}
+	stateManager, err := backend.NewStateManager(technique, opts...)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return stateManager
+}
@@ -1,18 +1,24 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/datadog/stratus-red-team/v2/internal/state"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
-	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
 
+var statusTargetsFile string
+
 func buildStatusCmd() *cobra.Command {
 	statusCmd := &cobra.Command{
-		Use:   "status",
-		Short: "Display the status of TTPs.",
+		Use:     "status",
+		Short:   "Display the status of TTPs.",
+		Example: "stratus status\nstratus status aws.defense-evasion.cloudtrail-stop --targets targets.yaml",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return nil // no technique specified == all techniques
@@ -21,37 +27,108 @@ func buildStatusCmd() *cobra.Command {
 			return err
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) > 0 {
-				techniques, _ := resolveTechniques(args)
-				doStatusCmd(techniques)
-			} else {
-				doStatusCmd(stratus.GetRegistry().ListAttackTechniques())
+			techniques, _ := resolveTechniques(args)
+			if len(techniques) == 0 {
+				techniques = stratus.GetRegistry().ListAttackTechniques()
 			}
+			targets, err := resolveTargets(statusTargetsFile)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			doStatusCmd(techniques, targets)
 		},
 	}
+	statusCmd.Flags().StringVarP(&statusTargetsFile, "targets", "", "", "YAML file of AWS accounts and regions, GCP projects and Azure subscriptions to display the status of the techniques in, instead of the targets of the configuration file")
 	return statusCmd
 }
 
-func doStatusCmd(techniques []*stratus.AttackTechnique) {
+// doStatusCmd displays the status of the techniques, in each of the targets of their platform
+func doStatusCmd(techniques []*stratus.AttackTechnique, targets []config.Target) {
+	jobs := targetTechniques(techniques, targets)
+	if isStructuredOutput() {
+		doStructuredStatusCmd(jobs)
+		return
+	}
+
 	t := GetDisplayTable()
-	t.AppendHeader(table.Row{"ID", "Name", "Status"})
-	// Resolve the same state layout the lifecycle commands use, so that a correlation ID in the
-	// environment reports on that execution rather than on the flat layout.
-	stateOpts := []state.ManagerOption{state.WithReadOnlyState()}
-	if subdirectory := runner.ExecutionSubdirectoryFromEnv(); subdirectory != "" {
-		stateOpts = append(stateOpts, state.WithExecutionSubdirectory(subdirectory))
+	header := table.Row{"ID", "Name", "Status", "Expires"}
+	if len(targets) > 0 {
+		header = append(table.Row{"Target"}, header...)
 	}
-	for i := range techniques {
-		stateManager := state.NewFileSystemStateManager(techniques[i], stateOpts...)
+	t.AppendHeader(header)
+	now := time.Now()
+	for _, job := range jobs {
+		stateManager := newStateManager(job.technique, readOnlyStateOptions(job.target)...)
 		techniqueState := stateManager.GetTechniqueState()
 		if techniqueState == "" {
 			techniqueState = stratus.AttackTechniqueStatusCold
 		}
-		t.AppendRow(table.Row{techniques[i].ID, techniques[i].FriendlyName, colorState(techniqueState)})
+		row := table.Row{job.technique.ID, job.technique.FriendlyName, colorState(techniqueState), formatExpiry(readLifetime(stateManager), now)}
+		if len(targets) > 0 {
+			row = append(table.Row{targetName(job.target)}, row...)
+		}
+		t.AppendRow(row)
 	}
 	t.Render()
 }
 
+func doStructuredStatusCmd(jobs []targetedTechnique) {
+	result := []techniqueStatusOutput{}
+	for _, job := range jobs {
+		stateManager := newStateManager(job.technique, readOnlyStateOptions(job.target)...)
+		techniqueState := stateManager.GetTechniqueState()
+		if techniqueState == "" {
+			techniqueState = stratus.AttackTechniqueStatusCold
+		}
+		outputs, err := stateManager.GetTerraformOutputs()
+		if err != nil {
+			log.Fatalf("unable to read the Terraform outputs of %s: %v", job.technique.ID, err)
+		}
+		variables, err := stateManager.GetTerraformVariables()
+		if err != nil {
+			log.Fatalf("unable to read the Terraform variables of %s: %v", job.technique.ID, err)
+		}
+		status := techniqueStatusOutput{
+			ID:               job.technique.ID,
+			Target:           targetName(job.target),
+			Name:             job.technique.FriendlyName,
+			State:            string(techniqueState),
+			CorrelationID:    state.CorrelationIDFromVariables(variables),
+			TerraformOutputs: outputs,
+		}
+		if lifetime := readLifetime(stateManager); lifetime != nil {
+			status.WarmedUpAt = &lifetime.WarmedUpAt
+			status.ExpiresAt = lifetime.ExpiresAt
+		}
+		result = append(result, status)
+	}
+	if err := printStructured(result); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readLifetime returns the lifetime of an execution, or nil if it has none or it is unreadable.
+func readLifetime(stateManager state.StateManager) *stratus.ExecutionLifetime {
+	lifetime, err := stateManager.GetExecutionLifetime()
+	if err != nil {
+		log.Warnf("unable to read the lifetime of %s: %v", stateManager.GetWorkingDirectory(), err)
+		return nil
+	}
+	return lifetime
+}
+
+// formatExpiry displays when an execution expires, highlighting those that are due for 'stratus reap'.
+func formatExpiry(lifetime *stratus.ExecutionLifetime, now time.Time) string {
+	if lifetime == nil || lifetime.ExpiresAt == nil {
+		return ""
+	}
+	expiry := lifetime.ExpiresAt.Local().Format(time.DateTime)
+	if lifetime.IsExpired(now) {
+		return color.RedString(expiry + " (expired)")
+	}
+	return expiry
+}
+
 func colorState(state stratus.AttackTechniqueState) string {
 	stateString := string(state)
 	switch state {
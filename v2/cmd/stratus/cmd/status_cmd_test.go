@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusReadsStateOfTargets(t *testing.T) {
+	useTestState(t, "", "")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  - name: prod\n    aws:\n      regions: [eu-west-1]\n"), 0600))
+	t.Setenv(config.ConfigEnvVar, configPath)
+	technique := stratus.GetRegistry().GetAttackTechniqueByName("aws.defense-evasion.cloudtrail-stop")
+	warmUpExpiredExecution(t, technique, "prod")
+
+	output := runCommand(t, "status", technique.ID, "--output", "json")
+
+	var statuses []techniqueStatusOutput
+	require.NoError(t, json.Unmarshal([]byte(output), &statuses))
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "prod", statuses[0].Target)
+	assert.Equal(t, string(stratus.AttackTechniqueStatusWarm), statuses[0].State)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// targetedTechnique is a technique to run in a target, or with the default credentials if target is nil
+type targetedTechnique struct {
+	// index orders the results like the jobs, although they run concurrently
+	index     int
+	technique *stratus.AttackTechnique
+	target    *config.Target
+}
+
+// targetedResult is the outcome of running a technique in a target
+type targetedResult struct {
+	targetedTechnique
+	state stratus.AttackTechniqueState
+	err   error
+	// skipped is set when there was nothing to do, e.g. cleaning up a COLD technique with --all
+	skipped bool
+}
+
+// resolveTargets returns the targets of the file passed with --targets, or else of the configuration file
+func resolveTargets(targetsFile string) ([]config.Target, error) {
+	if targetsFile != "" {
+		return config.LoadTargets(targetsFile)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	return cfg.GetTargets(), nil
+}
+
+// targetTechniques returns each technique once per target of its platform. Techniques of platforms without
+// any target run once, with the default credentials.
+func targetTechniques(techniques []*stratus.AttackTechnique, targets []config.Target) []targetedTechnique {
+	var result []targetedTechnique
+	for _, technique := range techniques {
+		targeted := false
+		for i := range targets {
+			if isTargetOf(targets[i], technique.Platform) {
+				result = append(result, targetedTechnique{index: len(result), technique: technique, target: &targets[i]})
+				targeted = true
+			}
+		}
+		if !targeted {
+			result = append(result, targetedTechnique{index: len(result), technique: technique})
+		}
+	}
+	return result
+}
+
+func isTargetOf(target config.Target, platform stratus.Platform) bool {
+	switch platform {
+	case stratus.AWS:
+		return target.AWS != nil
+	case stratus.GCP:
+		return target.GCP != nil
+	case stratus.Azure:
+		return target.Azure != nil
+	default:
+		return false
+	}
+}
+
+// targetName returns the name of a target, or "" for the default credentials
+func targetName(target *config.Target) string {
+	if target == nil {
+		return ""
+	}
+	return target.Name
+}
+
+// untargetedTechniques returns the techniques that run with the default credentials, which must be authenticated
+func untargetedTechniques(jobs []targetedTechnique) []*stratus.AttackTechnique {
+	var techniques []*stratus.AttackTechnique
+	for _, job := range jobs {
+		if job.target == nil {
+			techniques = append(techniques, job.technique)
+		}
+	}
+	return techniques
+}
+
+// runnerOptions returns the options of the global flags, along with the target if any
+func (m targetedTechnique) runnerOptions() []runner.RunnerOption {
+	opts := globalRunnerOptions()
+	if m.target != nil {
+		opts = append(opts, runner.WithTarget(*m.target))
+	}
+	return opts
+}
+
+// handleResultsChannel logs the errors of jobsCount results, and returns the results in the order of the jobs
+// along with whether any failed
+func handleResultsChannel(results <-chan targetedResult, jobsCount int) ([]targetedResult, bool) {
+	all := make([]targetedResult, jobsCount)
+	hasError := false
+	for i := 0; i < jobsCount; i++ {
+		result := <-results
+		if result.err != nil {
+			if result.target != nil {
+				log.Println(result.target.Name + ": " + result.err.Error())
+			} else {
+				log.Println(result.err)
+			}
+			hasError = true
+		}
+		all[result.index] = result
+	}
+	return all, hasError
+}
+
+// hasTargets reports whether any of the jobs ran in a target, in which case a summary is printed
+func hasTargets(results []targetedResult) bool {
+	for _, result := range results {
+		if result.target != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// printTargetSummary prints the outcome of running each technique in each target
+func printTargetSummary(results []targetedResult) {
+	outputs := []targetedResultOutput{}
+	for _, result := range results {
+		if result.skipped {
+			continue
+		}
+		output := targetedResultOutput{ID: result.technique.ID, State: string(result.state)}
+		if result.target != nil {
+			output.Target = result.target.Name
+		}
+		if result.err != nil {
+			output.Error = result.err.Error()
+		}
+		outputs = append(outputs, output)
+	}
+
+	if isStructuredOutput() {
+		if err := printStructured(outputs); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	t := GetDisplayTable()
+	t.AppendHeader(table.Row{"Target", "ID", "Status", "Result"})
+	for _, output := range outputs {
+		result := color.GreenString("OK")
+		if output.Error != "" {
+			result = color.RedString("FAILED")
+		}
+		t.AppendRow(table.Row{output.Target, output.ID, colorState(stratus.AttackTechniqueState(output.State)), result})
+	}
+	t.Render()
+}
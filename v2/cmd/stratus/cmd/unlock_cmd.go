@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/spf13/cobra"
+)
+
+var unlockTargetsFile string
+
+func buildUnlockCmd() *cobra.Command {
+	unlockCmd := &cobra.Command{
+		Use:                   "unlock attack-technique-id [attack-technique-id]...",
+		Short:                 "Break the state lock of an attack technique, left behind by a command that crashed",
+		Example:               "stratus unlock aws.defense-evasion.cloudtrail-stop\nstratus unlock aws.defense-evasion.cloudtrail-stop --targets targets.yaml",
+		DisableFlagsInUseLine: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("you must specify at least one attack technique")
+			}
+			_, err := resolveTechniques(args)
+			return err
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return getTechniquesCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			techniques, _ := resolveTechniques(args)
+			targets, err := resolveTargets(unlockTargetsFile)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			doUnlockCmd(techniques, targets)
+		},
+	}
+	unlockCmd.Flags().StringVarP(&unlockTargetsFile, "targets", "", "", "YAML file of AWS accounts and regions, GCP projects and Azure subscriptions to unlock the techniques in, instead of the targets of the configuration file")
+	return unlockCmd
+}
+
+func doUnlockCmd(techniques []*stratus.AttackTechnique, targets []config.Target) {
+	hadError := false
+	for _, job := range targetTechniques(techniques, targets) {
+		if err := unlockTechnique(job); err != nil {
+			log.Error(err.Error())
+			hadError = true
+		}
+	}
+	if hadError {
+		log.Exit(1)
+	}
+}
+
+func unlockTechnique(job targetedTechnique) error {
+	stateManager := newStateManager(job.technique, readOnlyStateOptions(job.target)...)
+	name := job.technique.ID
+	if job.target != nil {
+		name += " in " + job.target.Name
+	}
+	lock, err := stateManager.GetLock()
+	if err != nil {
+		// An unreadable lock can still be broken
+		log.Warnf("unable to read the state lock of %s: %s", name, err.Error())
+	} else if lock == nil {
+		log.Println(name + " is not locked")
+		return nil
+	} else {
+		log.Printf("Breaking the lock held by %s on %s since %s", lock.Owner, name, lock.AcquiredAt.Local().Format(time.RFC1123))
+	}
+	return stateManager.BreakLock()
+}
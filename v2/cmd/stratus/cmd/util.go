@@ -4,9 +4,13 @@ import (
 	"errors"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/datadog/stratus-red-team/v2/internal/state"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
@@ -17,6 +21,17 @@ func GetDisplayTable() table.Writer {
 	return t
 }
 
+// readOnlyStateOptions resolves the same state layout the lifecycle commands use, so that a
+// correlation ID from --correlation-id, the environment or the config, and a target if not nil,
+// report on that execution rather than on the flat layout.
+func readOnlyStateOptions(target *config.Target) []state.ManagerOption {
+	stateOpts := []state.ManagerOption{state.WithReadOnlyState()}
+	if subdirectory := runner.ExecutionSubdirectory(sharedStateSettings().correlationID, target); subdirectory != "" {
+		stateOpts = append(stateOpts, state.WithExecutionSubdirectory(subdirectory))
+	}
+	return stateOpts
+}
+
 func resolveTechniques(names []string) ([]*stratus.AttackTechnique, error) {
 	var result []*stratus.AttackTechnique
 	for i := range names {
@@ -29,19 +44,6 @@ func resolveTechniques(names []string) ([]*stratus.AttackTechnique, error) {
 	return result, nil
 }
 
-func handleErrorsChannel(errors <-chan error, jobsCount int) bool {
-	hasError := false
-	for i := 0; i < jobsCount; i++ {
-		err := <-errors
-		if err != nil {
-			log.Println(err)
-			hasError = true
-		}
-	}
-
-	return hasError
-}
-
 // VerifyPlatformRequirements ensures that the user is properly authenticated against all platforms
 // of a list of attack techniques
 func VerifyPlatformRequirements(attackTechniques []*stratus.AttackTechnique) {
@@ -69,3 +71,32 @@ func getTechniquesCompletion(completionPrefix string) []string {
 	}
 	return matchingTechniques
 }
+
+// parseParameterFlags parses --param key=value flags, and ensures that each parameter is taken by
+// at least one of the techniques.
+func parseParameterFlags(flags []string, techniques []*stratus.AttackTechnique) (map[string]string, error) {
+	parameters := map[string]string{}
+	for _, flag := range flags {
+		name, value, found := strings.Cut(flag, "=")
+		if name = strings.TrimSpace(name); !found || name == "" {
+			return nil, errors.New("invalid parameter " + flag + ", expected key=value")
+		}
+		if !slices.ContainsFunc(techniques, func(technique *stratus.AttackTechnique) bool { return technique.HasParameter(name) }) {
+			return nil, errors.New("none of the attack techniques takes a parameter " + name + ", use 'stratus show' to see the parameters of a technique")
+		}
+		parameters[name] = value
+	}
+	return parameters, nil
+}
+
+// parametersOf returns the parameters of a technique among those set with --param, which may be
+// meant for other techniques run by the same command.
+func parametersOf(technique *stratus.AttackTechnique, parameters map[string]string) map[string]string {
+	result := map[string]string{}
+	for name, value := range parameters {
+		if technique.HasParameter(name) {
+			result[name] = value
+		}
+	}
+	return result
+}
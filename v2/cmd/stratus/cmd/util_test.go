@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+)
+
+// useTestState points the commands at an empty home directory, and sets the global --state-backend and
+// --correlation-id flags
+func useTestState(t *testing.T, stateBackend string, correlationID string) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.ConfigEnvVar, "")
+	t.Setenv(runner.EnvVarStratusRedTeamCorrelationId, "")
+	t.Setenv(runner.EnvVarStratusRedTeamDetonationId, "")
+	stateBackendFlag, correlationIDFlag = stateBackend, correlationID
+	sharedStateSettings = newSharedStateSettings()
+	t.Cleanup(func() {
+		stateBackendFlag, correlationIDFlag = "", ""
+		sharedStateSettings = newSharedStateSettings()
+	})
+}
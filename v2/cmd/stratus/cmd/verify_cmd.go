@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/verify"
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var verifyLogsFile string
+
+func buildVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify attack-technique-id --logs file.jsonl",
+		Short: "Verify that exported audit logs contain the events expected from the detonation of an attack technique",
+		Example: strings.Join([]string{
+			"stratus verify aws.defense-evasion.cloudtrail-stop --logs cloudtrail.jsonl",
+			"stratus verify k8s.credential-access.dump-secrets --logs audit.jsonl --correlation-id 5ae4f3d0-3f41-4bd1-a7cc-1c3b1ac1f7de",
+		}, "\n"),
+		DisableFlagsInUseLine: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must specify exactly one attack technique")
+			}
+			techniques, err := resolveTechniques(args)
+			if err != nil {
+				return err
+			}
+			if err := verify.CanVerify(techniques[0]); err != nil {
+				return fmt.Errorf("%w. The techniques that can be verified are: %s", err, strings.Join(verifiableTechniques(), ", "))
+			}
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var completions []string
+			for _, id := range verifiableTechniques() {
+				if strings.HasPrefix(id, toComplete) {
+					completions = append(completions, id)
+				}
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			techniques, _ := resolveTechniques(args)
+			doVerifyCmd(techniques[0], verifyLogsFile)
+		},
+	}
+	verifyCmd.Flags().StringVarP(&verifyLogsFile, "logs", "", "", "Newline-delimited JSON export of the audit logs (CloudTrail, GCP Cloud Audit Logs or Kubernetes audit logs)")
+	_ = verifyCmd.MarkFlagRequired("logs")
+	return verifyCmd
+}
+
+// verifiableTechniques returns the IDs of the techniques whose logs can be verified
+func verifiableTechniques() []string {
+	var ids []string
+	for _, technique := range stratus.GetRegistry().ListAttackTechniques() {
+		if verify.CanVerify(technique) == nil {
+			ids = append(ids, technique.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// verifyEventOutput is the machine-readable outcome of looking for an expected event, as printed by 'stratus verify'
+type verifyEventOutput struct {
+	stratus.ExpectedEvent `yaml:",inline"`
+	Occurrences           int  `json:"occurrences" yaml:"occurrences"`
+	Found                 bool `json:"found" yaml:"found"`
+}
+
+type verifyOutput struct {
+	ID            string              `json:"id" yaml:"id"`
+	CorrelationID string              `json:"correlationId" yaml:"correlationId"`
+	ScannedEvents int                 `json:"scannedEvents" yaml:"scannedEvents"`
+	StratusEvents int                 `json:"stratusEvents" yaml:"stratusEvents"`
+	Events        []verifyEventOutput `json:"events" yaml:"events"`
+}
+
+func doVerifyCmd(technique *stratus.AttackTechnique, logsFile string) {
+	correlationID, err := detonationCorrelationID(technique)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	logs, err := os.Open(logsFile)
+	if err != nil {
+		log.Fatalf("unable to open the logs: %v", err)
+	}
+	defer logs.Close()
+	report, err := verify.Verify(logs, technique, correlationID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if isStructuredOutput() {
+		result := verifyOutput{
+			ID:            report.TechniqueID,
+			CorrelationID: report.CorrelationID,
+			ScannedEvents: report.ScannedEvents,
+			StratusEvents: report.StratusEvents,
+			Events:        []verifyEventOutput{},
+		}
+		for _, event := range report.Events {
+			result.Events = append(result.Events, verifyEventOutput{ExpectedEvent: event.Expected, Occurrences: event.Occurrences, Found: event.Found()})
+		}
+		if err := printStructured(result); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		printVerifyReport(report)
+	}
+
+	if len(report.Missing()) > 0 {
+		log.Exit(1)
+	}
+}
+
+func printVerifyReport(report *verify.Report) {
+	t := GetDisplayTable()
+	t.AppendHeader(table.Row{"Event source", "Event name", "Fields", "Occurrences"})
+	for _, event := range report.Events {
+		occurrences := color.GreenString("%d", event.Occurrences)
+		if !event.Found() {
+			occurrences = color.RedString("missing")
+		}
+		t.AppendRow(table.Row{event.Expected.EventSource, event.Expected.EventName, formatFields(event.Expected.Fields), occurrences})
+	}
+	t.Render()
+
+	log.Printf("Scanned %d events, of which %d were emitted by Stratus Red Team", report.ScannedEvents, report.StratusEvents)
+	if missing := len(report.Missing()); missing > 0 {
+		log.Errorf("%d of the %d expected events of %s are missing from the logs", missing, len(report.Events), report.TechniqueID)
+	}
+}
+
+func formatFields(fields map[string]string) string {
+	var matchers []string
+	for path, value := range fields {
+		matchers = append(matchers, fmt.Sprintf("%s: %s", path, value))
+	}
+	sort.Strings(matchers)
+	return strings.Join(matchers, "\n")
+}
+
+// detonationCorrelationID returns the correlation ID of the detonation to verify: the one passed with
+// --correlation-id, the environment or the configuration file, or else the one the technique was last
+// successfully detonated with according to the execution journal. The correlation ID persisted at warm-up does not
+// do, since detonating without a correlation ID uses a new one.
+func detonationCorrelationID(technique *stratus.AttackTechnique) (string, error) {
+	if correlationID := sharedStateSettings().correlationID; correlationID != uuid.Nil {
+		return correlationID.String(), nil
+	}
+	entries, err := newStateManager(technique, readOnlyStateOptions(nil)...).GetJournalEntries()
+	if err != nil {
+		return "", fmt.Errorf("unable to read the execution journal of %s, pass the correlation ID of its detonation with --correlation-id: %w", technique.ID, err)
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Operation == stratus.JournalOperationDetonate && entries[i].Succeeded() {
+			return entries[i].CorrelationID, nil
+		}
+	}
+	return "", fmt.Errorf("no successful detonation of %s found in the execution journal, pass the correlation ID of its detonation with --correlation-id", technique.ID)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetonationCorrelationID(t *testing.T) {
+	technique := stratus.GetRegistry().GetAttackTechniqueByName("aws.defense-evasion.cloudtrail-stop")
+	const warmUpID = "11111111-1111-1111-1111-111111111111"
+	const detonationID = "22222222-2222-2222-2222-222222222222"
+	const failedDetonationID = "33333333-3333-3333-3333-333333333333"
+
+	t.Run("uses the correlation ID of the last successful detonation rather than of the warm-up", func(t *testing.T) {
+		useTestState(t, "", "")
+		journal := state.NewFileSystemStateManager(technique)
+		for _, entry := range []stratus.JournalEntry{
+			{TechniqueID: technique.ID, Operation: stratus.JournalOperationWarmUp, CorrelationID: warmUpID},
+			{TechniqueID: technique.ID, Operation: stratus.JournalOperationDetonate, CorrelationID: detonationID},
+			{TechniqueID: "aws.other", Operation: stratus.JournalOperationDetonate, CorrelationID: warmUpID},
+			{TechniqueID: technique.ID, Operation: stratus.JournalOperationDetonate, CorrelationID: failedDetonationID, Error: "access denied"},
+		} {
+			require.NoError(t, journal.AppendJournalEntry(entry))
+		}
+
+		correlationID, err := detonationCorrelationID(technique)
+
+		require.NoError(t, err)
+		assert.Equal(t, detonationID, correlationID)
+	})
+
+	t.Run("prefers the correlation ID passed explicitly", func(t *testing.T) {
+		useTestState(t, "", "")
+		t.Setenv(runner.EnvVarStratusRedTeamCorrelationId, warmUpID)
+
+		correlationID, err := detonationCorrelationID(technique)
+
+		require.NoError(t, err)
+		assert.Equal(t, warmUpID, correlationID)
+	})
+
+	t.Run("fails when the technique was never detonated successfully", func(t *testing.T) {
+		useTestState(t, "", "")
+		journal := state.NewFileSystemStateManager(technique)
+		for _, entry := range []stratus.JournalEntry{
+			{TechniqueID: technique.ID, Operation: stratus.JournalOperationWarmUp, CorrelationID: warmUpID},
+			{TechniqueID: technique.ID, Operation: stratus.JournalOperationDetonate, CorrelationID: failedDetonationID, Error: "access denied"},
+		} {
+			require.NoError(t, journal.AppendJournalEntry(entry))
+		}
+
+		_, err := detonationCorrelationID(technique)
+
+		assert.ErrorContains(t, err, "--correlation-id")
+	})
+}
+
+func TestVerifyRefusesTechniquesWithoutExpectedEvents(t *testing.T) {
+	verifyCmd := buildVerifyCmd()
+
+	assert.NoError(t, verifyCmd.Args(verifyCmd, []string{"aws.defense-evasion.cloudtrail-stop"}))
+	err := verifyCmd.Args(verifyCmd, []string{"aws.persistence.iam-create-admin-user"})
+	assert.ErrorContains(t, err, "does not declare the events")
+	assert.ErrorContains(t, err, "aws.defense-evasion.cloudtrail-stop")
+	assert.ErrorContains(t, verifyCmd.Args(verifyCmd, []string{"azure.execution.vm-run-command"}), "not supported for the azure platform")
+	assert.Contains(t, verifiableTechniques(), "k8s.credential-access.dump-secrets")
+	assert.NotContains(t, verifiableTechniques(), "aws.persistence.iam-create-admin-user")
+}
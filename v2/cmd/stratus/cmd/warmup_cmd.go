@@ -2,20 +2,30 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
 
 var forceWarmup bool
+var dryRunWarmup bool
+var warmupTTL time.Duration
+var warmupTargetsFile string
+var warmupTargets []config.Target
 
 func buildWarmupCmd() *cobra.Command {
 	warmupCmd := &cobra.Command{
 		Use:                   "warmup attack-technique-id [attack-technique-id]...",
 		Short:                 "\"Warm up\" an attack technique by spinning up the prerequisite infrastructure or configuration, without detonating it",
-		Example:               "stratus warmup aws.defense-evasion.cloudtrail-stop",
+		Example:               "stratus warmup aws.defense-evasion.cloudtrail-stop\nstratus warmup --dry-run aws.defense-evasion.cloudtrail-stop\nstratus warmup --ttl 2h aws.defense-evasion.cloudtrail-stop\nstratus warmup aws.defense-evasion.cloudtrail-stop --targets targets.yaml",
 		DisableFlagsInUseLine: true,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -28,7 +38,11 @@ func buildWarmupCmd() *cobra.Command {
 			if len(args) == 0 {
 				return errors.New("you must specify at least one attack technique")
 			}
-			_, err := resolveTechniques(args)
+			if _, err := resolveTechniques(args); err != nil {
+				return err
+			}
+			var err error
+			warmupTargets, err = resolveTargets(warmupTargetsFile)
 			return err
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -36,35 +50,119 @@ func buildWarmupCmd() *cobra.Command {
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			techniques, _ := resolveTechniques(args)
+			if dryRunWarmup {
+				doWarmupDryRunCmd(techniques)
+				return
+			}
 			doWarmupCmd(techniques)
 		},
 	}
 	warmupCmd.Flags().BoolVarP(&forceWarmup, "force", "f", false, "Force re-ensuring the prerequisite infrastructure or configuration is up to date")
+	warmupCmd.Flags().BoolVarP(&dryRunWarmup, "dry-run", "", false, "Only display the prerequisite infrastructure that would be created and what the detonation does, without changing anything")
+	warmupCmd.Flags().DurationVarP(&warmupTTL, "ttl", "", 0, "Time after which 'stratus reap' cleans up the prerequisite infrastructure or configuration, e.g. 2h")
+	warmupCmd.Flags().StringVarP(&warmupTargetsFile, "targets", "", "", "YAML file of AWS accounts and regions, GCP projects and Azure subscriptions to warm up the techniques in, instead of the targets of the configuration file")
 	return warmupCmd
 }
 
 func doWarmupCmd(techniques []*stratus.AttackTechnique) {
-	VerifyPlatformRequirements(techniques)
-	workerCount := len(techniques)
-	techniquesChan := make(chan *stratus.AttackTechnique, workerCount)
-	errorsChan := make(chan error, workerCount)
+	jobs := targetTechniques(techniques, warmupTargets)
+	VerifyPlatformRequirements(untargetedTechniques(jobs))
+	workerCount := len(jobs)
+	jobsChan := make(chan targetedTechnique, workerCount)
+	resultsChan := make(chan targetedResult, workerCount)
 	for i := 0; i < workerCount; i++ {
-		go warmupCmdWorker(techniquesChan, errorsChan)
+		go warmupCmdWorker(jobsChan, resultsChan)
 	}
-	for i := range techniques {
-		techniquesChan <- techniques[i]
+	// Send attack techniques to warm up, once per target
+	for i := range jobs {
+		jobsChan <- jobs[i]
 	}
-	close(techniquesChan)
+	close(jobsChan)
 
-	if hadError := handleErrorsChannel(errorsChan, len(techniques)); hadError {
-		os.Exit(1)
+	results, hadError := handleResultsChannel(resultsChan, workerCount)
+	if hasTargets(results) {
+		printTargetSummary(results)
+	}
+	if hadError {
+		log.Exit(1)
 	}
 }
 
-func warmupCmdWorker(techniques <-chan *stratus.AttackTechnique, errors chan<- error) {
-	for technique := range techniques {
-		stratusRunner := runner.NewRunner(technique, forceWarmup)
+func warmupCmdWorker(jobs <-chan targetedTechnique, results chan<- targetedResult) {
+	for job := range jobs {
+		stratusRunner := runner.NewRunner(job.technique, forceWarmup, append(job.runnerOptions(), runner.WithTTL(warmupTTL))...)
 		_, err := stratusRunner.WarmUp()
-		errors <- err
+		results <- targetedResult{targetedTechnique: job, state: stratusRunner.GetState(), err: err}
+	}
+}
+
+func doWarmupDryRunCmd(techniques []*stratus.AttackTechnique) {
+	jobs := targetTechniques(techniques, warmupTargets)
+	VerifyPlatformRequirements(untargetedTechniques(jobs))
+	// Plans run one after the other, to keep their output readable
+	result := []techniquePlanOutput{}
+	hadError := false
+	for _, job := range jobs {
+		resources, err := runner.NewRunner(job.technique, forceWarmup, job.runnerOptions()...).Plan()
+		if err != nil {
+			log.Println(err)
+			hadError = true
+			continue
+		}
+		plan := newTechniquePlanOutput(job.technique, resources)
+		plan.Target = targetName(job.target)
+		result = append(result, plan)
+	}
+
+	if isStructuredOutput() {
+		if err := printStructured(result); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, plan := range result {
+			printTechniquePlan(plan)
+		}
+	}
+	if hadError {
+		log.Exit(1)
+	}
+}
+
+func printTechniquePlan(plan techniquePlanOutput) {
+	if plan.Target != "" {
+		fmt.Println(color.CyanString("Warming up %s in %s", plan.ID, plan.Target))
+	} else {
+		fmt.Println(color.CyanString("Warming up %s", plan.ID))
+	}
+	fmt.Println()
+	if len(plan.Resources) == 0 {
+		fmt.Println("No prerequisite resource would be created or changed.")
+	} else {
+		t := GetDisplayTable()
+		t.AppendHeader(table.Row{"Action", "Type", "Address"})
+		for _, resource := range plan.Resources {
+			t.AppendRow(table.Row{resource.Action, resource.Type, resource.Address})
+		}
+		t.Render()
+	}
+	fmt.Println()
+
+	fmt.Println(color.CyanString("Detonating %s", plan.ID))
+	fmt.Println()
+	if len(plan.Detonation.ExpectedEvents) > 0 {
+		fmt.Println("Detonation would make the following API calls:")
+		for _, event := range plan.Detonation.ExpectedEvents {
+			if event.EventSource != "" {
+				fmt.Printf("  - %s (%s)\n", event.EventName, event.EventSource)
+			} else {
+				fmt.Printf("  - %s\n", event.EventName)
+			}
+		}
+		fmt.Println()
+	} else {
+		fmt.Println("The API calls made by the detonation of this technique are not documented, see its description:")
+		fmt.Println()
 	}
+	fmt.Println(plan.Detonation.Description)
+	fmt.Println()
 }
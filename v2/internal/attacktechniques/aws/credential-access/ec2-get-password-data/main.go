@@ -34,22 +34,30 @@ Detonation:
 - Assume the role
 - Run a number of ec2:GetPasswordData calls (which will be denied) using fictitious instance IDs
 `,
-		Detection:                  "Identify principals making a large number of ec2:GetPasswordData calls, using CloudTrail's GetPasswordData event",
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess},
+		Detection:             "Identify principals making a large number of ec2:GetPasswordData calls, using CloudTrail's GetPasswordData event",
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1552"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"GetPasswordData"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators retrieving the initial password of their Windows instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
 const numCalls = 30
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	roleArn := params["role_arn"]
 
 	awsConnection := providers.AWS().GetConnection()
-	if err := utils.WaitForAndAssumeAWSRole(&awsConnection, roleArn); err != nil {
+	if err := utils.WaitForAndAssumeAWSRole(ctx, &awsConnection, roleArn); err != nil {
 		return err
 	}
 	ec2Client := ec2.NewFromConfig(awsConnection)
@@ -60,7 +68,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		// Since we don't have the permission, we don't care if the instance actually exists
 		instanceId := "i-" + utils.RandomString(16)
 
-		_, err := ec2Client.GetPasswordData(context.Background(), &ec2.GetPasswordDataInput{
+		_, err := ec2Client.GetPasswordData(ctx, &ec2.GetPasswordDataInput{
 			InstanceId: &instanceId,
 		})
 
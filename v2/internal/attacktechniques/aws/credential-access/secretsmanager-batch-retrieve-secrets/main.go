@@ -101,15 +101,22 @@ The following may be used to tune the detection, or validate findings:
 - Principals who do not usually call GetBatchSecretValue
 - Attempts to call GetBatchSecretValue resulting in access denied errors
 - Principals calling GetBatchSecretValue in several regions in a short period of time`,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1555.006"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"secretsmanager.amazonaws.com"}, "eventName": {"BatchGetSecretValue"}},
+			},
+			FalsePositives: []string{"Applications retrieving their secrets in bulk at startup"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	secretsManagerClient := secretsmanager.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Retrieving secrets by batch of " + strconv.Itoa(BatchSize) + " using BatchGetSecretValue...")
@@ -123,7 +130,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	})
 
 	for paginator.HasMorePages() {
-		batchSecretsResponse, err := paginator.NextPage(context.Background())
+		batchSecretsResponse, err := paginator.NextPage(ctx)
 		if err != nil {
 			return errors.New("unable to call BatchGetSecretValue: " + err.Error())
 		}
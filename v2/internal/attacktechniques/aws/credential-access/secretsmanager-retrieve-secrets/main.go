@@ -29,7 +29,7 @@ Warm-up:
 Detonation:
 
 - Enumerate the secrets through secretsmanager:ListSecrets
-- Retrieve each secret value, one by one through secretsmanager:GetSecretValue
+- Retrieve each secret value, one by one through secretsmanager:GetSecretValue, up to the number of secrets set by the <code>max_secrets</code> parameter
 
 References:
 
@@ -43,18 +43,33 @@ The following may be use to tune the detection, or validate findings:
 
 - Principals who do not usually call secretsmanager:GetSecretValue
 - Attempts to call GetSecretValue resulting in access denied errors`,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess},
+		Parameters: []stratus.TechniqueParameter{
+			{Name: "max_secrets", Description: "Maximum number of secrets to retrieve", Type: stratus.ParameterTypeInteger, Default: "20"},
+		},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1555.006"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"secretsmanager.amazonaws.com"}, "eventName": {"GetSecretValue"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications retrieving their own secrets"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
+	maxSecrets := stratus.ParametersFromContext(ctx).GetInt("max_secrets")
+	if maxSecrets < 1 {
+		return errors.New("max_secrets must be at least 1")
+	}
 	secretsManagerClient := secretsmanager.NewFromConfig(providers.AWS().GetConnection())
 
-	secretsResponse, err := secretsManagerClient.ListSecrets(context.Background(), &secretsmanager.ListSecretsInput{
+	secretsResponse, err := secretsManagerClient.ListSecrets(ctx, &secretsmanager.ListSecretsInput{
 		Filters: []types.Filter{
 			{Key: types.FilterNameStringTypeTagKey, Values: []string{"StratusRedTeam"}},
 		},
@@ -65,10 +80,14 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 		return errors.New("unable to list SecretsManager secrets: " + err.Error())
 	}
 
-	for i := range secretsResponse.SecretList {
-		secret := secretsResponse.SecretList[i]
+	secrets := secretsResponse.SecretList
+	if len(secrets) > maxSecrets {
+		secrets = secrets[:maxSecrets]
+	}
+	for i := range secrets {
+		secret := secrets[i]
 		log.Println("Retrieving value of secret " + *secret.ARN)
-		_, err := secretsManagerClient.GetSecretValue(context.Background(), &secretsmanager.GetSecretValueInput{
+		_, err := secretsManagerClient.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
 			SecretId: secret.ARN,
 		})
 
@@ -44,15 +44,23 @@ The following may be used to tune the detection, or validate findings:
 - Principals who do not usually call ssm:GetParameter(s)
 - Attempts to call ssm:GetParameter(s) resulting in access denied errors
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1555.006"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ssm.amazonaws.com"}, "eventName": {"GetParameter", "GetParameters"}, "requestParameters.withDecryption": {"true"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications retrieving their own configuration"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ssmParameterPath := params["ssm_parameter_path"]
 	if ssmParameterPath == "" {
 		return errors.New("missing required Terraform output: ssm_parameter_path")
@@ -65,7 +73,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		options.Limit = 10
 	})
 	for paginator.HasMorePages() {
-		result, err := paginator.NextPage(context.Background())
+		result, err := paginator.NextPage(ctx)
 		if err != nil {
 			return errors.New("unable to retrieve SSM parameters: " + err.Error())
 		}
@@ -77,7 +85,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 			continue
 		}
 
-		response, err := ssmClient.GetParameters(context.Background(), &ssm.GetParametersInput{
+		response, err := ssmClient.GetParameters(ctx, &ssm.GetParametersInput{
 			Names:          names,
 			WithDecryption: aws.Bool(true),
 		})
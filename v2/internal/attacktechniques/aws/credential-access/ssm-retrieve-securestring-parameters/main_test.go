@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"testing"
 
 	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
@@ -40,7 +41,7 @@ func TestParameterNamesWithPrefix(t *testing.T) {
 }
 
 func TestDetonateRequiresSSMParameterPath(t *testing.T) {
-	err := detonate(nil, nil)
+	err := detonate(context.Background(), nil, nil)
 
 	assert.EqualError(t, err, "missing required Terraform output: ssm_parameter_path")
 }
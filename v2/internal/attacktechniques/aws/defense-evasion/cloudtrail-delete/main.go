@@ -15,10 +15,18 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.cloudtrail-delete",
-		FriendlyName:       "Delete CloudTrail Trail",
-		Platform:           stratus.AWS,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.cloudtrail-delete",
+		FriendlyName:          "Delete CloudTrail Trail",
+		Platform:              stratus.AWS,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"cloudtrail.amazonaws.com"}, "eventName": {"DeleteTrail"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Decommissioning of trails by administrators"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -47,19 +55,22 @@ Identify when a CloudTrail trail is deleted, through CloudTrail's <code>DeleteTr
 
 GuardDuty also provides a dedicated finding type, [Stealth:IAMUser/CloudTrailLoggingDisabled](https://docs.aws.amazon.com/guardduty/latest/ug/guardduty_finding-types-iam.html#stealth-iam-cloudtrailloggingdisabled).
 `,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventSource: "cloudtrail.amazonaws.com", EventName: "DeleteTrail"},
+		},
 		IsIdempotent:               false, // can't delete a CloudTrail twice
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	cloudtrailClient := cloudtrail.NewFromConfig(providers.AWS().GetConnection())
 	trailName := params["cloudtrail_trail_name"]
 
 	log.Println("Deleting CloudTrail trail " + trailName)
 
-	_, err := cloudtrailClient.DeleteTrail(context.Background(), &cloudtrail.DeleteTrailInput{
+	_, err := cloudtrailClient.DeleteTrail(ctx, &cloudtrail.DeleteTrailInput{
 		Name: &trailName,
 	})
 
@@ -17,10 +17,17 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.cloudtrail-event-selectors",
-		FriendlyName:       "Disable CloudTrail Logging Through Event Selectors",
-		Platform:           stratus.AWS,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.cloudtrail-event-selectors",
+		FriendlyName:          "Disable CloudTrail Logging Through Event Selectors",
+		Platform:              stratus.AWS,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"cloudtrail.amazonaws.com"}, "eventName": {"PutEventSelectors"}},
+			},
+			FalsePositives: []string{"Administrators tuning the events recorded by a trail"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -51,18 +58,18 @@ Identify when event selectors of a CloudTrail trail are updated, through CloudTr
 `,
 		IsIdempotent:               true, // cloudtrail:PutEventSelectors is idempotent
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	cloudtrailClient := cloudtrail.NewFromConfig(providers.AWS().GetConnection())
 	trailName := params["cloudtrail_trail_name"]
 
 	log.Println("Applying event selector on CloudTrail trail " + trailName + " to disable logging management and data events")
 
-	_, err := cloudtrailClient.PutEventSelectors(context.Background(), &cloudtrail.PutEventSelectorsInput{
+	_, err := cloudtrailClient.PutEventSelectors(ctx, &cloudtrail.PutEventSelectorsInput{
 		TrailName: &trailName,
 		EventSelectors: []types.EventSelector{
 			{
@@ -83,12 +90,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	cloudtrailClient := cloudtrail.NewFromConfig(providers.AWS().GetConnection())
 	trailName := params["cloudtrail_trail_name"]
 
 	log.Println("Reverting event selector on CloudTrail trail " + trailName)
-	_, err := cloudtrailClient.PutEventSelectors(context.Background(), &cloudtrail.PutEventSelectorsInput{
+	_, err := cloudtrailClient.PutEventSelectors(ctx, &cloudtrail.PutEventSelectorsInput{
 		TrailName:      &trailName,
 		EventSelectors: []types.EventSelector{{IncludeManagementEvents: aws.Bool(true)}},
 	})
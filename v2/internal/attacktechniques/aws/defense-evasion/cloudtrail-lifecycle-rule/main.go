@@ -18,10 +18,17 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.cloudtrail-lifecycle-rule",
-		FriendlyName:       "CloudTrail Logs Impairment Through S3 Lifecycle Rule",
-		Platform:           stratus.AWS,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.cloudtrail-lifecycle-rule",
+		FriendlyName:          "CloudTrail Logs Impairment Through S3 Lifecycle Rule",
+		Platform:              stratus.AWS,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"s3.amazonaws.com"}, "eventName": {"PutBucketLifecycle"}},
+			},
+			FalsePositives: []string{"Lifecycle rules applied to buckets that do not hold CloudTrail logs"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -55,17 +62,17 @@ The CloudTrail event <code>PutBucketLifecycle</code> and its attribute
 `,
 		IsIdempotent:               false, // can't create twice a lifecycle rule with the same name
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 	bucketName := params["s3_bucket_name"]
 
 	log.Println("Setting a short retention policy on CloudTrail S3 bucket " + bucketName)
-	_, err := s3Client.PutBucketLifecycleConfiguration(context.Background(), &s3.PutBucketLifecycleConfigurationInput{
+	_, err := s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
 		Bucket: &bucketName,
 		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
 			Rules: []types.LifecycleRule{
@@ -87,12 +94,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 	bucketName := params["s3_bucket_name"]
 
 	log.Println("Reverting S3 Lifecycle Rules on CloudTrail S3 bucket " + bucketName)
-	_, err := s3Client.DeleteBucketLifecycle(context.Background(), &s3.DeleteBucketLifecycleInput{
+	_, err := s3Client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
 		Bucket: &bucketName,
 	})
 
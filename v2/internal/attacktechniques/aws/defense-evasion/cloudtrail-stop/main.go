@@ -15,10 +15,18 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.cloudtrail-stop",
-		FriendlyName:       "Stop CloudTrail Trail",
-		Platform:           stratus.AWS,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.cloudtrail-stop",
+		FriendlyName:          "Stop CloudTrail Trail",
+		Platform:              stratus.AWS,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"cloudtrail.amazonaws.com"}, "eventName": {"StopLogging"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators temporarily stopping a trail"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -47,20 +55,23 @@ Identify when a CloudTrail trail is disabled, through CloudTrail's <code>StopLog
 
 GuardDuty also provides a dedicated finding type, [Stealth:IAMUser/CloudTrailLoggingDisabled](https://docs.aws.amazon.com/guardduty/latest/ug/guardduty_finding-types-iam.html#stealth-iam-cloudtrailloggingdisabled).
 `,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventSource: "cloudtrail.amazonaws.com", EventName: "StopLogging"},
+		},
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               true, // cloudtrail:StopLogging is idempotent
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	cloudtrailClient := cloudtrail.NewFromConfig(providers.AWS().GetConnection())
 	trailName := params["cloudtrail_trail_name"]
 
 	log.Println("Stopping CloudTrail trail " + trailName)
 
-	_, err := cloudtrailClient.StopLogging(context.Background(), &cloudtrail.StopLoggingInput{
+	_, err := cloudtrailClient.StopLogging(ctx, &cloudtrail.StopLoggingInput{
 		Name: &trailName,
 	})
 
@@ -71,12 +82,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	cloudtrailClient := cloudtrail.NewFromConfig(providers.AWS().GetConnection())
 	trailName := params["cloudtrail_trail_name"]
 
 	log.Println("Restarting CloudTrail trail " + trailName)
-	_, err := cloudtrailClient.StartLogging(context.Background(), &cloudtrail.StartLoggingInput{
+	_, err := cloudtrailClient.StartLogging(ctx, &cloudtrail.StartLoggingInput{
 		Name: &trailName,
 	})
 
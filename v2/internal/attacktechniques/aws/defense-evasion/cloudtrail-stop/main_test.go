@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/stratustest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetonateStopsTrail(t *testing.T) {
+	providers := stratustest.New(t)
+
+	err := detonate(context.Background(), map[string]string{"cloudtrail_trail_name": "my-trail"}, providers)
+
+	require.NoError(t, err)
+	providers.Server.AssertCalled(t, "cloudtrail:StopLogging", stratustest.WithParam("Name", "my-trail"))
+	providers.Server.AssertNotCalled(t, "cloudtrail:StartLogging")
+}
+
+func TestDetonatePropagatesAWSError(t *testing.T) {
+	providers := stratustest.New(t)
+	providers.Server.RespondWithAWSError("cloudtrail:StopLogging", http.StatusBadRequest, "TrailNotFoundException", "unknown trail")
+
+	err := detonate(context.Background(), map[string]string{"cloudtrail_trail_name": "my-trail"}, providers)
+
+	assert.ErrorContains(t, err, "unable to stop CloudTrail logging")
+	assert.ErrorContains(t, err, "TrailNotFoundException")
+}
+
+func TestRevertRestartsTrail(t *testing.T) {
+	providers := stratustest.New(t)
+
+	require.NoError(t, revert(context.Background(), map[string]string{"cloudtrail_trail_name": "my-trail"}, providers))
+	providers.Server.AssertCalled(t, "cloudtrail:StartLogging", stratustest.WithParam("Name", "my-trail"))
+}
@@ -16,10 +16,16 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.dns-delete-logs",
-		FriendlyName:       "Delete DNS query logs",
-		Platform:           stratus.AWS,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.dns-delete-logs",
+		FriendlyName:          "Delete DNS query logs",
+		Platform:              stratus.AWS,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"route53resolver.amazonaws.com"}, "eventName": {"DeleteResolverQueryLogConfig"}},
+			},
+		},
 		Description: `
 Deletes a Route53 DNS Resolver query logging configuration. Simulates an attacker disrupting DNS logging.
 
@@ -35,17 +41,17 @@ Identify when a DNS logging configuration is deleted, through CloudTrail's <code
 `,
 		IsIdempotent:               false, // can't delete a DNS logging configuration twice
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	resolverClient := route53resolver.NewFromConfig(providers.AWS().GetConnection())
 	queryLoggingConfigId := params["route53_logger_id"]
 
 	log.Println("Deleting DNS logging configuration " + queryLoggingConfigId)
 
-	_, err := resolverClient.DeleteResolverQueryLogConfig(context.Background(), &route53resolver.DeleteResolverQueryLogConfigInput{
+	_, err := resolverClient.DeleteResolverQueryLogConfig(ctx, &route53resolver.DeleteResolverQueryLogConfigInput{
 		ResolverQueryLogConfigId: &queryLoggingConfigId,
 	})
 
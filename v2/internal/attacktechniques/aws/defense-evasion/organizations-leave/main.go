@@ -17,11 +17,19 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.organizations-leave",
-		FriendlyName:       "Attempt to Leave the AWS Organization",
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.organizations-leave",
+		FriendlyName:          "Attempt to Leave the AWS Organization",
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1666"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"organizations.amazonaws.com"}, "eventName": {"LeaveOrganization"}},
+			},
+			Level: stratus.DetectionLevelHigh,
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -53,22 +61,22 @@ Any attempts from a child account to leave its AWS Organization should be consid
 
 Use the CloudTrail event <code>LeaveOrganization</code>.`,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	roleArn := params["role_arn"]
 
 	awsConnection := providers.AWS().GetConnection()
-	if err := utils.WaitForAndAssumeAWSRole(&awsConnection, roleArn); err != nil {
+	if err := utils.WaitForAndAssumeAWSRole(ctx, &awsConnection, roleArn); err != nil {
 		return err
 	}
 	organizationsClient := organizations.NewFromConfig(awsConnection)
 
 	log.Println("Attempting to leave the AWS organization (will trigger an Access Denied error)")
 
-	_, err := organizationsClient.LeaveOrganization(context.Background(), &organizations.LeaveOrganizationInput{})
+	_, err := organizationsClient.LeaveOrganization(ctx, &organizations.LeaveOrganizationInput{})
 
 	if err == nil {
 		// We expected an error
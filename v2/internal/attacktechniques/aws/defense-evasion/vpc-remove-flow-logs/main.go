@@ -15,11 +15,18 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.defense-evasion.vpc-remove-flow-logs",
-		FriendlyName:       "Remove VPC Flow Logs",
-		Platform:           stratus.AWS,
-		IsIdempotent:       false, // can't remove VPC flow logs once they have already been removed
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		ID:                    "aws.defense-evasion.vpc-remove-flow-logs",
+		FriendlyName:          "Remove VPC Flow Logs",
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // can't remove VPC flow logs once they have already been removed
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"DeleteFlowLogs"}},
+			},
+			FalsePositives: []string{"Deletion of VPCs in development environments"},
+		},
 		Description: `
 Removes a VPC Flow Logs configuration from a VPC.
 
@@ -38,11 +45,11 @@ To reduce the risk of false positives related to VPC deletion in development env
 only when <code>DeleteFlowLogs</code> is not closely followed by <code>DeleteVpc</code>.
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 
 	vpcId := params["vpc_id"]
@@ -50,7 +57,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Removing VPC Flow Logs " + flowLogsId + " in VPC " + vpcId)
 
-	_, err := ec2Client.DeleteFlowLogs(context.Background(), &ec2.DeleteFlowLogsInput{
+	_, err := ec2Client.DeleteFlowLogs(ctx, &ec2.DeleteFlowLogsInput{
 		FlowLogIds: []string{flowLogsId},
 	})
 	if err != nil {
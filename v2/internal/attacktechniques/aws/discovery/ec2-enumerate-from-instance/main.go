@@ -58,20 +58,28 @@ An action can be determined to have been performed by an EC2 instance under its
 arn:aws:sts::012345678901:assumed-role/my-instance-role/i-0adc17a5acb70d9ae
 </code>
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Discovery},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Discovery},
+		MitreAttackTechniques: []string{"T1580", "T1087.004"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"userIdentity.type": {"AssumedRole"}, "userIdentity.principalId|contains": {":i-"}, "eventName|startswith": {"Describe", "List", "Get"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Workloads legitimately enumerating resources from EC2 instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	awsProvider := providers.AWS()
 	ssmClient := ssm.NewFromConfig(awsProvider.GetConnection())
 	instanceId := params["instance_id"]
 
-	if err := utils.WaitForInstancesToRegisterInSSM(ssmClient, []string{instanceId}); err != nil {
+	if err := utils.WaitForInstancesToRegisterInSSM(ctx, ssmClient, []string{instanceId}); err != nil {
 		return fmt.Errorf("failed to wait for instances to register in SSM: %v", err)
 	}
 
@@ -90,7 +98,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Running commands through SSM on " + instanceId + ":\n  - " + strings.Join(commands, "\n  - "))
 
-	result, err := ssmClient.SendCommand(context.Background(), &ssm.SendCommandInput{
+	result, err := ssmClient.SendCommand(ctx, &ssm.SendCommandInput{
 		DocumentName: aws.String("AWS-RunShellScript"),
 		InstanceIds:  []string{instanceId},
 		Parameters: map[string][]string{
@@ -100,7 +108,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	if err != nil {
 		return errors.New("unable to send SSM command to instance: " + err.Error())
 	}
-	_, err = ssm.NewCommandExecutedWaiter(ssmClient).WaitForOutput(context.Background(), &ssm.GetCommandInvocationInput{
+	_, err = ssm.NewCommandExecutedWaiter(ssmClient).WaitForOutput(ctx, &ssm.GetCommandInvocationInput{
 		CommandId:  result.Command.CommandId,
 		InstanceId: &instanceId,
 	}, 2*time.Minute)
@@ -44,20 +44,28 @@ Through CloudTrail's <code>DescribeInstanceAttribute</code> event.
 See:
 
 * [Associated Sigma rule](https://github.com/SigmaHQ/sigma/blob/master/rules/cloud/aws/aws_ec2_download_userdata.yml)`,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Discovery},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Discovery},
+		MitreAttackTechniques: []string{"T1580"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"DescribeInstanceAttribute"}, "requestParameters.attribute": {"userData"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators troubleshooting the user data of their instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
 const numCalls = 15
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	roleArn := params["role_arn"]
 	awsConnection := providers.AWS().GetConnection()
-	if err := utils.WaitForAndAssumeAWSRole(&awsConnection, roleArn); err != nil {
+	if err := utils.WaitForAndAssumeAWSRole(ctx, &awsConnection, roleArn); err != nil {
 		return err
 	}
 	ec2Client := ec2.NewFromConfig(awsConnection)
@@ -68,7 +76,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 		// Call DescribeInstanceAttribute to retrieve the userData attribute
 		// Expected Client.UnauthorizedOperation
-		ec2Client.DescribeInstanceAttribute(context.Background(), &ec2.DescribeInstanceAttributeInput{
+		ec2Client.DescribeInstanceAttribute(ctx, &ec2.DescribeInstanceAttributeInput{
 			Attribute:  types.InstanceAttributeNameUserData,
 			InstanceId: &instanceId,
 		})
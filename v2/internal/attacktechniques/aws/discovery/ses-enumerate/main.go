@@ -39,19 +39,27 @@ References:
 Through CloudTrail's <code>GetAccountSendingEnabled</code>, <code>GetSendQuota</code> and <code>ListIdentities</code> events.
 These can be considered suspicious especially when performed by a long-lived access key, or when the calls span across multiple regions.
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Discovery},
-		Detonate:           detonate,
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Discovery},
+		MitreAttackTechniques: []string{"T1526"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ses.amazonaws.com"}, "eventName": {"GetAccountSendingEnabled", "GetSendQuota", "ListIdentities"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications and administrators checking their SES configuration"},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	awsConnection := providers.AWS().GetConnection()
 	sesClient := ses.NewFromConfig(awsConnection)
 
 	log.Println("Checking if SES email sending is enabled in the current region")
-	result, err := sesClient.GetAccountSendingEnabled(context.Background(), &ses.GetAccountSendingEnabledInput{})
+	result, err := sesClient.GetAccountSendingEnabled(ctx, &ses.GetAccountSendingEnabledInput{})
 	if err != nil {
 		return fmt.Errorf("unable to check if SES sending is enabled: %w", err)
 	}
@@ -62,7 +70,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Enumerating verified SES identities using ses:ListIdentities")
-	identities, err := sesClient.ListIdentities(context.Background(), &ses.ListIdentitiesInput{})
+	identities, err := sesClient.ListIdentities(ctx, &ses.ListIdentitiesInput{})
 	if err != nil {
 		return fmt.Errorf("unable to list SES identities: %w", err)
 	}
@@ -71,7 +79,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 		log.Println("No verified SES identities found")
 	} else {
 		log.Printf("Found %d verified SES identities", len(identities.Identities))
-		verificationAttributes, err := sesClient.GetIdentityVerificationAttributes(context.Background(), &ses.GetIdentityVerificationAttributesInput{
+		verificationAttributes, err := sesClient.GetIdentityVerificationAttributes(ctx, &ses.GetIdentityVerificationAttributesInput{
 			Identities: identities.Identities,
 		})
 		if err != nil {
@@ -83,7 +91,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Enumerating SES quotas")
-	quotas, err := sesClient.GetSendQuota(context.Background(), &ses.GetSendQuotaInput{})
+	quotas, err := sesClient.GetSendQuota(ctx, &ses.GetSendQuotaInput{})
 	if err != nil {
 		return fmt.Errorf("unable to get SES quotas: %w", err)
 	}
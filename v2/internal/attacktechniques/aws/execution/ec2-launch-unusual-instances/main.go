@@ -17,20 +17,19 @@ import (
 //go:embed main.tf
 var tf []byte
 
-const instanceType = types.InstanceTypeP2Xlarge
-const numInstances = 10
+const defaultInstanceType = types.InstanceTypeP2Xlarge
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
 		ID:           "aws.execution.ec2-launch-unusual-instances",
 		FriendlyName: "Launch Unusual EC2 instances",
 		Description: `
-Attempts to launch several unusual EC2 instances (` + string(instanceType) + `).
+Attempts to launch several unusual EC2 instances (by default, ` + string(defaultInstanceType) + `).
 
 Warm-up: Creates an IAM role that doesn't have permissions to launch EC2 instances.
 This ensures the attempts is not successful, and the attack technique is fast to detonate.
 
-Detonation: Attempts to launch several unusual EC2 instances. The calls will fail as the IAM role does not have sufficient permissions.
+Detonation: Attempts to launch several unusual EC2 instances of each type set by the <code>instance_types</code> parameter. The calls will fail as the IAM role does not have sufficient permissions.
 `,
 		Detection: `
 Through CloudTrail events with the event name <code>RunInstances</code> and error
@@ -40,9 +39,15 @@ field will contain the instance type that was attempted to be launched.
 
 Depending on your account limits you might also see <code>VcpuLimitExceeded</code> error codes.
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Execution},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Execution},
+		MitreAttackTechniques: []string{"T1578.002"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"RunInstances"}, "errorCode": {"Client.UnauthorizedOperation"}},
+			},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -60,45 +65,57 @@ Depending on your account limits you might also see <code>VcpuLimitExceeded</cod
 				},
 			},
 		},
+		Parameters: []stratus.TechniqueParameter{
+			{Name: "instance_types", Description: "Comma-separated EC2 instance types to attempt to launch", Type: stratus.ParameterTypeStringList, Default: string(defaultInstanceType)},
+			{Name: "instance_count", Description: "Maximum number of instances to attempt to launch, for each instance type", Type: stratus.ParameterTypeInteger, Default: "10"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	awsConnection := providers.AWS().GetConnection()
 
 	amiId := params["ami_id"]
 	roleArn := params["role_arn"]
 	subnetId := params["subnet_id"]
 
-	if err := utils.WaitForAndAssumeAWSRole(&awsConnection, roleArn); err != nil {
+	if err := utils.WaitForAndAssumeAWSRole(ctx, &awsConnection, roleArn); err != nil {
 		return err
 	}
 	ec2Client := ec2.NewFromConfig(awsConnection)
 
-	log.Printf("Attempting to run up to %d instances of type %s\n", numInstances, string(instanceType))
-	_, err := ec2Client.RunInstances(ctx, &ec2.RunInstancesInput{
-		ImageId:  aws.String(amiId),
-		SubnetId: aws.String(subnetId),
-		// Note: These parameters will attempt to launch the maximum between 1 and `numInstances` instances
-		MinCount:     aws.Int32(1),
-		MaxCount:     aws.Int32(numInstances),
-		InstanceType: instanceType,
-	})
-
-	if err == nil {
-		// We expected an error
-		return errors.New("expected ec2:RunInstances to return an error")
+	parameters := stratus.ParametersFromContext(ctx)
+	instanceTypes := parameters.GetStringList("instance_types")
+	numInstances := parameters.GetInt("instance_count")
+	if len(instanceTypes) == 0 || numInstances < 1 {
+		return errors.New("instance_types must not be empty and instance_count must be at least 1")
 	}
 
-	if !isExpectedError(err) {
-		return errors.New("expected ec2:RunInstances to return an access denied error, got instead: " + err.Error())
+	for _, instanceType := range instanceTypes {
+		log.Printf("Attempting to run up to %d instances of type %s\n", numInstances, instanceType)
+		_, err := ec2Client.RunInstances(ctx, &ec2.RunInstancesInput{
+			ImageId:  aws.String(amiId),
+			SubnetId: aws.String(subnetId),
+			// Note: These parameters will attempt to launch the maximum between 1 and `numInstances` instances
+			MinCount:     aws.Int32(1),
+			MaxCount:     aws.Int32(int32(numInstances)),
+			InstanceType: types.InstanceType(instanceType),
+		})
+
+		if err == nil {
+			// We expected an error
+			return errors.New("expected ec2:RunInstances to return an error")
+		}
+
+		if !isExpectedError(err) {
+			return errors.New("expected ec2:RunInstances to return an access denied error, got instead: " + err.Error())
+		}
+
+		log.Println("Got an access denied error as expected")
 	}
 
-	log.Println("Got an access denied error as expected")
-
 	return nil
 }
 
@@ -53,25 +53,32 @@ When not possible to perform such correlation, alerting on the second event only
 expected that the user data of an EC2 instance changes often, especially with the popularity of immutable machine images,
 provisioned before instantiation.
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Execution, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1059"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"ModifyInstanceAttribute"}, "requestParameters.userData": {"*"}},
+			},
+			FalsePositives: []string{"Administrators updating the user data of their instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	instanceId := params["instance_id"]
 
-	err := stopInstance(instanceId, ec2Client)
+	err := stopInstance(ctx, instanceId, ec2Client)
 	if err != nil {
 		return err
 	}
 
 	log.Println("Injecting malicious user data")
-	_, err = ec2Client.ModifyInstanceAttribute(context.Background(), &ec2.ModifyInstanceAttributeInput{
+	_, err = ec2Client.ModifyInstanceAttribute(ctx, &ec2.ModifyInstanceAttributeInput{
 		InstanceId: &instanceId,
 		UserData:   &types.BlobAttributeValue{Value: maliciousUserData},
 	})
@@ -79,7 +86,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to update user data: " + err.Error())
 	}
 
-	err = startInstance(instanceId, ec2Client)
+	err = startInstance(ctx, instanceId, ec2Client)
 	if err != nil {
 		return err
 	}
@@ -92,9 +99,9 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 const maxWaitDuration = 2 * time.Minute
 
 // Stops an EC2 instance, and synchronously returns only when it is stopped
-func stopInstance(instanceId string, ec2Client *ec2.Client) error {
+func stopInstance(ctx context.Context, instanceId string, ec2Client *ec2.Client) error {
 	log.Println("Stopping instance " + instanceId)
-	_, err := ec2Client.StopInstances(context.Background(), &ec2.StopInstancesInput{
+	_, err := ec2Client.StopInstances(ctx, &ec2.StopInstancesInput{
 		InstanceIds: []string{instanceId},
 		Force:       aws.Bool(true),
 	})
@@ -108,7 +115,7 @@ func stopInstance(instanceId string, ec2Client *ec2.Client) error {
 		options.MinDelay = 1 * time.Second
 	}
 	err = ec2.NewInstanceStoppedWaiter(ec2Client, stopOptions).Wait(
-		context.Background(),
+		ctx,
 		&ec2.DescribeInstancesInput{InstanceIds: []string{instanceId}},
 		maxWaitDuration,
 	)
@@ -119,9 +126,9 @@ func stopInstance(instanceId string, ec2Client *ec2.Client) error {
 }
 
 // Starts an EC2 instance, and synchronously returns only when it is running
-func startInstance(instanceId string, ec2Client *ec2.Client) error {
+func startInstance(ctx context.Context, instanceId string, ec2Client *ec2.Client) error {
 	log.Println("Starting instance")
-	_, err := ec2Client.StartInstances(context.Background(), &ec2.StartInstancesInput{
+	_, err := ec2Client.StartInstances(ctx, &ec2.StartInstancesInput{
 		InstanceIds: []string{instanceId},
 	})
 	if err != nil {
@@ -133,7 +140,7 @@ func startInstance(instanceId string, ec2Client *ec2.Client) error {
 		options.MinDelay = 1 * time.Second
 	}
 	err = ec2.NewInstanceRunningWaiter(ec2Client, startOptions).Wait(
-		context.Background(),
+		ctx,
 		&ec2.DescribeInstancesInput{InstanceIds: []string{instanceId}},
 		maxWaitDuration,
 	)
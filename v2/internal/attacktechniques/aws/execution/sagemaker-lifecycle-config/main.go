@@ -55,27 +55,34 @@ References:
 Through CloudTrail's <code>UpdateNotebookInstance</code> events.
 You can also watch for suspicious sequences of <code>StopNotebookInstance</code> and <code>StopNotebookInstance</code> events correlated with <code>UpdateNotebookInstance</code> events.
 `,
-		Platform:                   stratus.AWS,
-		IsSlow:                     true,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.AWS,
+		IsSlow:                true,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Execution, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1059"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"sagemaker.amazonaws.com"}, "eventName": {"UpdateNotebookInstance"}, "requestParameters.lifecycleConfigName": {"*"}},
+			},
+			FalsePositives: []string{"Data scientists updating the lifecycle configuration of their notebook instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	client := sagemaker.NewFromConfig(providers.AWS().GetConnection())
 
 	notebookName = params["target_notebook_name"]
 
-	err := CreateNotebookLifecycleConfig(client, configName, scriptToExecute)
+	err := CreateNotebookLifecycleConfig(ctx, client, configName, scriptToExecute)
 	if err != nil {
 		log.Fatalf("Lifecycle config creation failed: %v", err)
 	}
 
-	err = UpdateAndRestartNotebook(client, notebookName, configName)
+	err = UpdateAndRestartNotebook(ctx, client, notebookName, configName)
 	if err != nil {
 		log.Fatalf("Lifecycle config creation failed: %v", err)
 	}
@@ -83,13 +90,13 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 
 	client := sagemaker.NewFromConfig(providers.AWS().GetConnection())
 
 	notebookName = params["target_notebook_name"]
 
-	err := DetachAndDeleteLifecycleConfig(client, notebookName, configName)
+	err := DetachAndDeleteLifecycleConfig(ctx, client, notebookName, configName)
 	if err != nil {
 		log.Fatalf("Cleanup failed: %v", err)
 	}
@@ -97,7 +104,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 }
 
 // CreateNotebookLifecycleConfig defines and creates the lifecycle configuration.
-func CreateNotebookLifecycleConfig(
+func CreateNotebookLifecycleConfig(ctx context.Context,
 	client *sagemaker.Client,
 	configName string,
 	onStartScript string) error {
@@ -123,7 +130,7 @@ func CreateNotebookLifecycleConfig(
 	// 3. Execute the API call
 	log.Printf("Attempting to create lifecycle configuration: %s", configName)
 
-	_, err := client.CreateNotebookInstanceLifecycleConfig(context.Background(), input)
+	_, err := client.CreateNotebookInstanceLifecycleConfig(ctx, input)
 
 	if err != nil {
 		return fmt.Errorf("failed to create lifecycle config %s: %w", configName, err)
@@ -133,13 +140,11 @@ func CreateNotebookLifecycleConfig(
 	return nil
 }
 
-func UpdateAndRestartNotebook(
+func UpdateAndRestartNotebook(ctx context.Context,
 	client *sagemaker.Client,
 	notebookName string,
 	lifecycleConfigName string) error {
 
-	ctx := context.Background()
-
 	log.Printf("Starting workflow for Notebook: %s", notebookName)
 
 	// --- 1. Stop the Notebook Instance (and wait for it to stop) ---
@@ -216,12 +221,11 @@ func UpdateAndRestartNotebook(
 
 // DetachAndDeleteLifecycleConfig performs the cleanup steps:
 // 1. Stops the Notebook. 2. Detaches the config. 3. Deletes the config.
-func DetachAndDeleteLifecycleConfig(
+func DetachAndDeleteLifecycleConfig(ctx context.Context,
 	client *sagemaker.Client,
 	notebookName string,
 	configName string) error {
 
-	ctx := context.Background()
 	log.Printf("Starting cleanup workflow for Notebook: %s and Config: %s", notebookName, configName)
 
 	// --- 1. Stop the Notebook Instance (Prerequisite for Update) ---
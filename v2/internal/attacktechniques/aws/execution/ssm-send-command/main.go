@@ -69,22 +69,30 @@ While this technique uses a single call to <code>ssm:SendCommand</code> on sever
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               true,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution},
-		Detonate:                   detonate,
+		MitreAttackTechniques:      []string{"T1651"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ssm.amazonaws.com"}, "eventName": {"SendCommand"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators and automation running commands on instances"},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ssmClient := ssm.NewFromConfig(providers.AWS().GetConnection())
 	instanceIDs := getInstanceIds(params)
 
-	if err := utils.WaitForInstancesToRegisterInSSM(ssmClient, instanceIDs); err != nil {
+	if err := utils.WaitForInstancesToRegisterInSSM(ctx, ssmClient, instanceIDs); err != nil {
 		return fmt.Errorf("failed to wait for instances to register in SSM: %v", err)
 	}
 
 	log.Println("Instances are ready and registered in SSM!")
 	log.Println("Executing command '" + commandToExecute + "' through ssm:SendCommand on all instances...")
 
-	result, err := ssmClient.SendCommand(context.Background(), &ssm.SendCommandInput{
+	result, err := ssmClient.SendCommand(ctx, &ssm.SendCommandInput{
 		InstanceIds:  instanceIDs,
 		DocumentName: aws.String("AWS-RunShellScript"),
 		Parameters: map[string][]string{
@@ -100,7 +108,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	log.Println("Waiting for command outputs")
 
 	for _, instanceID := range instanceIDs {
-		result, err := ssm.NewCommandExecutedWaiter(ssmClient).WaitForOutput(context.Background(), &ssm.GetCommandInvocationInput{
+		result, err := ssm.NewCommandExecutedWaiter(ssmClient).WaitForOutput(ctx, &ssm.GetCommandInvocationInput{
 			InstanceId: &instanceID,
 			CommandId:  commandId,
 		}, 2*time.Minute)
@@ -60,15 +60,23 @@ Identify, through CloudTrail's <code>StartSession</code> event, when a user is s
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               true,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution},
-		Detonate:                   detonate,
+		MitreAttackTechniques:      []string{"T1651"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ssm.amazonaws.com"}, "eventName": {"StartSession"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators opening sessions on instances"},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ssmClient := ssm.NewFromConfig(providers.AWS().GetConnection())
 	instanceIDs := getInstanceIds(params)
 
-	if err := utils.WaitForInstancesToRegisterInSSM(ssmClient, instanceIDs); err != nil {
+	if err := utils.WaitForInstancesToRegisterInSSM(ctx, ssmClient, instanceIDs); err != nil {
 		return fmt.Errorf("failed to wait for instances to register in SSM: %v", err)
 	}
 
@@ -76,7 +84,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	log.Println("Starting SSM sessions on each instance...")
 
 	for _, instanceID := range instanceIDs {
-		session, err := ssmClient.StartSession(context.Background(), &ssm.StartSessionInput{
+		session, err := ssmClient.StartSession(ctx, &ssm.StartSessionInput{
 			Target: &instanceID,
 		})
 		if err != nil {
@@ -85,7 +93,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		fmt.Printf("\tSession started on instance %s\n", instanceID)
 
 		// Attempt to terminate the session to not leave it hanging
-		_, err = ssmClient.TerminateSession(context.Background(), &ssm.TerminateSessionInput{
+		_, err = ssmClient.TerminateSession(ctx, &ssm.TerminateSessionInput{
 			SessionId: session.SessionId,
 		})
 		if err != nil {
@@ -16,11 +16,19 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.exfiltration.ec2-security-group-open-port-22-ingress",
-		FriendlyName:       "Open Ingress Port 22 on a Security Group",
-		Platform:           stratus.AWS,
-		IsIdempotent:       false, // cannot call ec2:AuthorizeSecurityGroupIngress multiple times with the same parameters
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Exfiltration},
+		ID:                    "aws.exfiltration.ec2-security-group-open-port-22-ingress",
+		FriendlyName:          "Open Ingress Port 22 on a Security Group",
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // cannot call ec2:AuthorizeSecurityGroupIngress multiple times with the same parameters
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1562.007"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"AuthorizeSecurityGroupIngress"}, "requestParameters.cidrIp": {"0.0.0.0/0"}, "requestParameters.fromPort": {"22"}},
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"AuthorizeSecurityGroupIngress"}, "requestParameters.ipPermissions.items.ipRanges.items.cidrIp": {"0.0.0.0/0"}, "requestParameters.ipPermissions.items.fromPort": {"22"}},
+			},
+			FalsePositives: []string{"Bastion hosts intentionally exposed to the Internet"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -51,12 +59,12 @@ You can use the CloudTrail event <code>AuthorizeSecurityGroupIngress</code> when
 - and <code>requestParameters.fromPort</code>/<code>requestParameters.toPort</code> is not a commonly exposed port or corresponds to a known administrative protocol such as SSH or RDP
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 
 	// Find the snapshot to exfiltrate
@@ -65,7 +73,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	// Open port 22 to the world
 	log.Println("Opening port 22 from the Internet on " + securityGroupId)
 
-	_, err := ec2Client.AuthorizeSecurityGroupIngress(context.Background(), &ec2.AuthorizeSecurityGroupIngressInput{
+	_, err := ec2Client.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
 		GroupId:    &securityGroupId,
 		CidrIp:     aws.String("0.0.0.0/0"),
 		FromPort:   aws.Int32(22),
@@ -80,7 +88,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 
 	// Find the snapshot to exfiltrate
@@ -89,7 +97,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	// Open port 22 to the world
 	log.Println("Closing port 22 from the Internet on " + securityGroupId)
 
-	_, err := ec2Client.RevokeSecurityGroupIngress(context.Background(), &ec2.RevokeSecurityGroupIngressInput{
+	_, err := ec2Client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{
 		GroupId:    &securityGroupId,
 		CidrIp:     aws.String("0.0.0.0/0"),
 		FromPort:   aws.Int32(22),
@@ -48,12 +48,19 @@ that the AMI was shared with a new or unknown AWS account, such as:
 An attacker can also make an AMI completely public. In this case, the <code>item</code> entry
 will look like <code>{"groups":"all"}</code>.
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"ModifyImageAttribute"}, "requestParameters.attributeType": {"launchPermission"}},
+			},
+			FalsePositives: []string{"AMIs shared with known accounts of the organization"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
@@ -61,12 +68,12 @@ var amiPermissions = []types.LaunchPermission{
 	{UserId: aws.String("012345678901")},
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	amiId := params["ami_id"]
 
 	log.Println("Exfiltrating AMI " + amiId + " by sharing it with an external AWS account")
-	_, err := ec2Client.ModifyImageAttribute(context.Background(), &ec2.ModifyImageAttributeInput{
+	_, err := ec2Client.ModifyImageAttribute(ctx, &ec2.ModifyImageAttributeInput{
 		ImageId: &amiId,
 		LaunchPermission: &types.LaunchPermissionModifications{
 			Add: amiPermissions,
@@ -87,12 +94,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	amiId := params["ami_id"]
 
 	log.Println("Reverting exfiltration of AMI " + amiId + " by removing cross-account sharing")
-	_, err := ec2Client.ModifyImageAttribute(context.Background(), &ec2.ModifyImageAttributeInput{
+	_, err := ec2Client.ModifyImageAttribute(ctx, &ec2.ModifyImageAttributeInput{
 		ImageId: &amiId,
 		LaunchPermission: &types.LaunchPermissionModifications{
 			Remove: amiPermissions,
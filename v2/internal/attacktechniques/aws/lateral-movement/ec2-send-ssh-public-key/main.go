@@ -60,17 +60,25 @@ Identify, through CloudTrail's <code>SendSSHPublicKey</code> event, when a user
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               true,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.LateralMovement},
-		Detonate:                   detonate,
+		MitreAttackTechniques:      []string{"T1021.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"ec2-instance-connect.amazonaws.com"}, "eventName": {"SendSSHPublicKey"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators connecting to instances through EC2 Instance Connect"},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	ec2instanceconnectClient := ec2instanceconnect.NewFromConfig(providers.AWS().GetConnection())
 	instanceIDs := strings.Split(params["instance_ids"], ",")
 
 	for _, instanceID := range instanceIDs {
 		cleanInstanceID := strings.Trim(instanceID, " \"\n\r")
-		err := sendSSHPublicKey(ec2instanceconnectClient, cleanInstanceID, "ec2-user", publicSSHKey)
+		err := sendSSHPublicKey(ctx, ec2instanceconnectClient, cleanInstanceID, "ec2-user", publicSSHKey)
 		if err != nil {
 			return fmt.Errorf("failed to send SSH public key to instance %s: %v", cleanInstanceID, err)
 		}
@@ -81,8 +89,8 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func sendSSHPublicKey(ec2instanceconnectClient *ec2instanceconnect.Client, instanceId, instanceOSUser, sshPublicKey string) error {
-	_, err := ec2instanceconnectClient.SendSSHPublicKey(context.Background(), &ec2instanceconnect.SendSSHPublicKeyInput{
+func sendSSHPublicKey(ctx context.Context, ec2instanceconnectClient *ec2instanceconnect.Client, instanceId, instanceOSUser, sshPublicKey string) error {
+	_, err := ec2instanceconnectClient.SendSSHPublicKey(ctx, &ec2instanceconnect.SendSSHPublicKeyInput{
 		InstanceId:     &instanceId,
 		InstanceOSUser: &instanceOSUser,
 		SSHPublicKey:   &sshPublicKey,
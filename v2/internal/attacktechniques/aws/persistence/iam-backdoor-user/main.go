@@ -38,8 +38,16 @@ correlated with other indicators.
 '`,
 		Platform: stratus.AWS,
 
-		IsIdempotent:       false, // iam:CreateAccessKey can only be called twice (limit of 2 access keys per user)
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		IsIdempotent:          false, // iam:CreateAccessKey can only be called twice (limit of 2 access keys per user)
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"iam.amazonaws.com"}, "eventName": {"CreateAccessKey"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators creating access keys for users"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -53,17 +61,17 @@ correlated with other indicators.
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 	userName := params["user_name"]
 
 	log.Println("Creating access key on legit IAM user to simulate backdoor")
-	result, err := iamClient.CreateAccessKey(context.Background(), &iam.CreateAccessKeyInput{
+	result, err := iamClient.CreateAccessKey(ctx, &iam.CreateAccessKeyInput{
 		UserName: &userName,
 	})
 	if err != nil {
@@ -74,12 +82,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 	userName := params["user_name"]
 
 	log.Println("Removing access key from IAM user " + userName)
-	result, err := iamClient.ListAccessKeys(context.Background(), &iam.ListAccessKeysInput{
+	result, err := iamClient.ListAccessKeys(ctx, &iam.ListAccessKeysInput{
 		UserName: &userName,
 	})
 	if err != nil {
@@ -89,7 +97,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	for i := range result.AccessKeyMetadata {
 		accessKeyId := result.AccessKeyMetadata[i].AccessKeyId
 		log.Println("Removing access key " + *accessKeyId)
-		_, err := iamClient.DeleteAccessKey(context.Background(), &iam.DeleteAccessKeyInput{
+		_, err := iamClient.DeleteAccessKey(ctx, &iam.DeleteAccessKeyInput{
 			AccessKeyId: accessKeyId,
 			UserName:    &userName,
 		})
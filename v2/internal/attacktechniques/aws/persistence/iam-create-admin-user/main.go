@@ -48,9 +48,17 @@ can help to craft more precise detections:
 
 - Identify a call to <code>CreateUser</code> resulting in an access denied error
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       false, // cannot create twice an IAM user with the same name
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // cannot create twice an IAM user with the same name
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1136.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"iam.amazonaws.com"}, "eventName": {"AttachUserPolicy"}, "requestParameters.policyArn": {"arn:aws:iam::aws:policy/AdministratorAccess"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators granting administrator privileges to a new user"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -63,16 +71,16 @@ can help to craft more precise detections:
 				},
 			},
 		},
-		Detonate: detonate,
-		Revert:   revert,
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Creating a malicious IAM user")
-	_, err := iamClient.CreateUser(context.Background(), &iam.CreateUserInput{
+	_, err := iamClient.CreateUser(ctx, &iam.CreateUserInput{
 		UserName: userName,
 		Tags: []types.Tag{
 			{Key: aws.String("StratusRedTeam"), Value: aws.String("true")},
@@ -83,7 +91,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Attaching an administrative IAM policy to the malicious IAM user")
-	_, err = iamClient.AttachUserPolicy(context.Background(), &iam.AttachUserPolicyInput{
+	_, err = iamClient.AttachUserPolicy(ctx, &iam.AttachUserPolicyInput{
 		UserName:  userName,
 		PolicyArn: adminPolicyArn,
 	})
@@ -92,7 +100,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Creating an access key for the IAM user")
-	result, err := iamClient.CreateAccessKey(context.Background(), &iam.CreateAccessKeyInput{
+	result, err := iamClient.CreateAccessKey(ctx, &iam.CreateAccessKeyInput{
 		UserName: userName,
 	})
 	if err != nil {
@@ -104,10 +112,10 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 
-	result, err := iamClient.ListAccessKeys(context.Background(), &iam.ListAccessKeysInput{
+	result, err := iamClient.ListAccessKeys(ctx, &iam.ListAccessKeysInput{
 		UserName: userName,
 	})
 	if err != nil {
@@ -116,7 +124,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 
 	for i := range result.AccessKeyMetadata {
 		accessKeyId := result.AccessKeyMetadata[i].AccessKeyId
-		_, err := iamClient.DeleteAccessKey(context.Background(), &iam.DeleteAccessKeyInput{
+		_, err := iamClient.DeleteAccessKey(ctx, &iam.DeleteAccessKeyInput{
 			UserName:    userName,
 			AccessKeyId: accessKeyId,
 		})
@@ -127,7 +135,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Detaching administrative policy")
-	_, err = iamClient.DetachUserPolicy(context.Background(), &iam.DetachUserPolicyInput{
+	_, err = iamClient.DetachUserPolicy(ctx, &iam.DetachUserPolicyInput{
 		UserName:  userName,
 		PolicyArn: adminPolicyArn,
 	})
@@ -136,6 +144,6 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Removing IAM user")
-	_, err = iamClient.DeleteUser(context.Background(), &iam.DeleteUserInput{UserName: userName})
+	_, err = iamClient.DeleteUser(ctx, &iam.DeleteUserInput{UserName: userName})
 	return err
 }
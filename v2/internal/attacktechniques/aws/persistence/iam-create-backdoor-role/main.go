@@ -64,9 +64,16 @@ which generates a finding when a role can be assumed from a new AWS account or p
 }
 ` + codeBlock + `
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       false, // cannot create twice a role with the same name
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // cannot create twice a role with the same name
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"iam.amazonaws.com"}, "eventName": {"AttachRolePolicy"}, "requestParameters.policyArn": {"arn:aws:iam::aws:policy/AdministratorAccess"}},
+			},
+			FalsePositives: []string{"Administrators creating administrator roles"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -79,12 +86,12 @@ which generates a finding when a role can be assumed from a new AWS account or p
 				},
 			},
 		},
-		Detonate: detonate,
-		Revert:   revert,
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 
 	log.Println("Creating a malicious IAM role")
@@ -94,7 +101,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 		PermissionsBoundary:      aws.String("arn:aws:iam::aws:policy/AWSDenyAll"),
 	}
 
-	_, err := iamClient.CreateRole(context.Background(), input)
+	_, err := iamClient.CreateRole(ctx, input)
 	if err != nil {
 		return errors.New("Unable to create IAM role: " + err.Error())
 	}
@@ -106,7 +113,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 		PolicyArn: &adminPolicyArn,
 	}
 
-	_, err = iamClient.AttachRolePolicy(context.Background(), attachPolicyInput)
+	_, err = iamClient.AttachRolePolicy(ctx, attachPolicyInput)
 	if err != nil {
 		log.Fatalf("Unable to attach AdministratorAccess policy to IAM role: %v", err)
 	}
@@ -115,13 +122,13 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 	detachPolicyInput := &iam.DetachRolePolicyInput{
 		RoleName:  &roleName,
 		PolicyArn: &adminPolicyArn,
 	}
-	_, err := iamClient.DetachRolePolicy(context.Background(), detachPolicyInput)
+	_, err := iamClient.DetachRolePolicy(ctx, detachPolicyInput)
 	if err != nil {
 		return errors.New("Unable to detach policy from IAM role: " + err.Error())
 	}
@@ -130,7 +137,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 	input := &iam.DeleteRoleInput{
 		RoleName: &roleName,
 	}
-	_, err = iamClient.DeleteRole(context.Background(), input)
+	_, err = iamClient.DeleteRole(ctx, input)
 	if err != nil {
 		return errors.New("Unable to delete IAM role: " + err.Error())
 	}
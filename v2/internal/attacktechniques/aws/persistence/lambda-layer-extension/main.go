@@ -45,13 +45,20 @@ While matching this event may be impractical and prone to false positives in mos
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               true,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		MitreAttackTechniques:      []string{"T1546"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"lambda.amazonaws.com"}, "eventName": {"UpdateFunctionConfiguration20150331v2"}, "requestParameters.layers": {"*"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Deployment pipelines updating the layers of functions"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	lambdaClient := lambda.NewFromConfig(providers.AWS().GetConnection())
 	lambdaExtensionLayerArn := params["lambda_extension_layer_arn"]
 	lambdaArn := params["lambda_arn"]
@@ -70,8 +77,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	lambdaArn := params["lambda_arn"]
 	lambdaClient := lambda.NewFromConfig(providers.AWS().GetConnection())
 
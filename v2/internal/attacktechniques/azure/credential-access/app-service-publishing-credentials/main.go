@@ -68,15 +68,22 @@ Sample event (redacted for clarity):
 
 Note that this operation is logged even when the App Service has only basic (publishing) authentication disabled.
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess},
+		Platform:              stratus.Azure,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1552"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Web/sites/publishxml/action"}},
+			},
+			FalsePositives: []string{"Developers downloading the publishing profile of their web apps"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	appServiceName := params["app_service_name"]
 	resourceGroup := params["resource_group_name"]
 
@@ -87,7 +94,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Retrieving publishing credentials for App Service " + appServiceName)
 	response, err := webAppsClient.ListPublishingProfileXMLWithSecrets(
-		context.Background(),
+		ctx,
 		resourceGroup,
 		appServiceName,
 		armappservice.CsmPublishingProfileOptions{},
@@ -62,23 +62,29 @@ Identify Azure events of type <code>Microsoft.Compute/virtualMachines/extensions
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsSlow:                     true,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution},
+		Platform:              stratus.Azure,
+		IsSlow:                true,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Execution},
+		MitreAttackTechniques: []string{"T1651"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Compute/virtualMachines/extensions/write"}},
+			},
+			FalsePositives: []string{"Administrators and automation configuring virtual machines"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
 const ExtensionName = "CustomScriptExtension-StratusRedTeam-Example"
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	vmName := params["vm_name"]
 	resourceGroup := params["resource_group_name"]
 
-	ctx := context.Background()
 	cred := providers.Azure().GetCredentials()
 	subscriptionID := providers.Azure().SubscriptionID
 	clientOptions := providers.Azure().ClientOptions
@@ -118,7 +124,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to create virtual machine extension: " + err.Error())
 	}
 
-	ctxWithTimeout, done := context.WithTimeout(context.Background(), 60*3*time.Second)
+	ctxWithTimeout, done := context.WithTimeout(ctx, 60*3*time.Second)
 	defer done()
 	_, err = poller.PollUntilDone(ctxWithTimeout, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
 	if err != nil {
@@ -131,11 +137,10 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	vmName := params["vm_name"]
 	resourceGroup := params["resource_group_name"]
 
-	ctx := context.Background()
 	cred := providers.Azure().GetCredentials()
 	subscriptionID := providers.Azure().SubscriptionID
 	clientOptions := providers.Azure().ClientOptions
@@ -157,7 +162,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 		return errors.New("unable to remove custom script extension: " + err.Error())
 	}
 
-	ctxWithTimeout, done := context.WithTimeout(context.Background(), 60*3*time.Second)
+	ctxWithTimeout, done := context.WithTimeout(ctx, 60*3*time.Second)
 	defer done()
 
 	_, err = poller.PollUntilDone(ctxWithTimeout, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
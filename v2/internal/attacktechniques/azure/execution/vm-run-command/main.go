@@ -83,16 +83,23 @@ Sample event (redacted for clarity):
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsSlow:                     true,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution},
+		Platform:              stratus.Azure,
+		IsSlow:                true,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Execution},
+		MitreAttackTechniques: []string{"T1651"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Compute/virtualMachines/runCommand/action", "Microsoft.Compute/virtualMachines/runCommands/write"}},
+			},
+			FalsePositives: []string{"Administrators running commands on virtual machines"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	vmObjectId := params["vm_instance_object_id"]
 	vmName := params["vm_name"]
 	resourceGroup := params["resource_group_name"]
@@ -111,13 +118,13 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return errors.New("unable to instantiate Azure virtual machine client: " + err.Error())
 	}
 
-	commandCreation, err := vmClient.BeginRunCommand(context.Background(), resourceGroup, vmName, runCommandInput, nil)
+	commandCreation, err := vmClient.BeginRunCommand(ctx, resourceGroup, vmName, runCommandInput, nil)
 	if err != nil {
 		return errors.New("unable to run a command on the virtual machine: " + err.Error())
 	}
 
 	log.Println("Waiting for command to be run on the VM")
-	ctxWithTimeout, done := context.WithTimeout(context.Background(), 60*3*time.Second) // This can sometimes be quite slow
+	ctxWithTimeout, done := context.WithTimeout(ctx, 60*3*time.Second) // This can sometimes be quite slow
 	defer done()
 	commandResult, err := commandCreation.PollUntilDone(ctxWithTimeout, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
 	if err != nil {
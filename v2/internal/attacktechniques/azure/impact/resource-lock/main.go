@@ -60,18 +60,24 @@ Sample Azure Activity Log event to monitor:
     }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               true,
-		IsSlow:                     true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Platform:              stratus.Azure,
+		IsIdempotent:          true,
+		IsSlow:                true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1562"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Authorization/locks/delete"}},
+			},
+			FalsePositives: []string{"Administrators removing resource locks"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Starting technique execution")
 
@@ -96,8 +102,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Starting cleanup")
 
@@ -22,6 +22,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 )
@@ -110,17 +111,23 @@ Sample Azure Activity Log event to monitor:
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.Azure,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials/write"}},
+			},
+			FalsePositives: []string{"Workload identity federation set up for CI/CD pipelines"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 
 	managedIdentityName := params["managed_identity_name"]
 	managedIdentityClientId := params["managed_identity_client_id"]
@@ -149,7 +156,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	// Upload OIDC discovery document and JWKS to the storage account
 	log.Printf("Uploading OIDC metadata to %s...", issuerURL)
-	if err := uploadOIDCDocuments(azureProvider, blobServiceURL, issuerURL, privateKey, keyID); err != nil {
+	if err := uploadOIDCDocuments(ctx, azureProvider, blobServiceURL, issuerURL, privateKey, keyID); err != nil {
 		return fmt.Errorf("could not upload OIDC documents: %w", err)
 	}
 
@@ -182,7 +189,9 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	// Wait for FIC and OIDC metadata to propagate
 	log.Printf("Waiting %s for FIC and OIDC metadata to propagate...", ficWaitTime)
-	time.Sleep(ficWaitTime)
+	if err := utils.SleepWithContext(ctx, ficWaitTime); err != nil {
+		return err
+	}
 
 	// Issue token signed by the attacker's OIDC private key
 	log.Println("Issuing token as attacker OIDC provider for exchange...")
@@ -209,8 +218,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 
 	managedIdentityName := params["managed_identity_name"]
 	resourceGroupName := params["resource_group_name"]
@@ -257,7 +265,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	// Delete the OIDC container (storage account is managed by Terraform)
 	log.Printf("Deleting OIDC container from storage account...")
-	if err := deleteOIDCContainer(azureProvider, blobServiceURL); err != nil {
+	if err := deleteOIDCContainer(ctx, azureProvider, blobServiceURL); err != nil {
 		log.Printf("Warning: could not delete OIDC container: %v", err)
 	} else {
 		log.Println("Deleted OIDC container")
@@ -271,8 +279,7 @@ func strPtr(s string) *string {
 }
 
 // deleteOIDCContainer deletes the OIDC container from the storage account.
-func deleteOIDCContainer(azureProvider *providers.AzureProvider, blobServiceURL string) error {
-	ctx := context.Background()
+func deleteOIDCContainer(ctx context.Context, azureProvider *providers.AzureProvider, blobServiceURL string) error {
 	blobClient, err := azblob.NewClient(blobServiceURL, azureProvider.GetCredentials(), nil)
 	if err != nil {
 		return fmt.Errorf("could not create blob client: %w", err)
@@ -282,8 +289,7 @@ func deleteOIDCContainer(azureProvider *providers.AzureProvider, blobServiceURL
 }
 
 // uploadOIDCDocuments creates the OIDC container and uploads the discovery document and JWKS.
-func uploadOIDCDocuments(azureProvider *providers.AzureProvider, blobServiceURL, issuerURL string, privateKey *rsa.PrivateKey, keyID string) error {
-	ctx := context.Background()
+func uploadOIDCDocuments(ctx context.Context, azureProvider *providers.AzureProvider, blobServiceURL, issuerURL string, privateKey *rsa.PrivateKey, keyID string) error {
 	blobClient, err := azblob.NewClient(blobServiceURL, azureProvider.GetCredentials(), nil)
 	if err != nil {
 		return fmt.Errorf("could not create blob client: %w", err)
@@ -81,17 +81,24 @@ Identify Azure events of type <code>Microsoft.Network/bastionHosts/createshareab
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsSlow:                     true,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.Azure,
+		IsSlow:                true,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1021.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Network/bastionHosts/createshareablelinks/action"}},
+			},
+			FalsePositives: []string{"Administrators sharing access to virtual machines"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bastionName := params["bastion_name"]
 	resourceGroup := params["resource_group_name"]
 	vmId := params["vm_id"]
@@ -100,7 +107,6 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	// String requires extra quotations for unmarshaling, see below for more on this
 	adminPassword := fmt.Sprintf(`"%s"`, params["admin_password"])
 
-	ctx := context.Background()
 	cred := providers.Azure().GetCredentials()
 	subscriptionID := providers.Azure().SubscriptionID
 	clientOptions := providers.Azure().ClientOptions
@@ -163,14 +169,13 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// Reference method: https://learn.microsoft.com/en-us/rest/api/virtualnetwork/delete-bastion-shareable-link/delete-bastion-shareable-link?view=rest-virtualnetwork-2024-03-01&tabs=Go
 	bastionName := params["bastion_name"]
 	resourceGroup := params["resource_group_name"]
 	vmId := params["vm_id"]
 	vmName := params["vm_name"]
 
-	ctx := context.Background()
 	cred := providers.Azure().GetCredentials()
 	subscriptionID := providers.Azure().SubscriptionID
 	clientOptions := providers.Azure().ClientOptions
@@ -96,16 +96,24 @@ Sample events (redacted for clarity):
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.Azure,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.CredentialAccess},
+		Platform:              stratus.Azure,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1098.001", "T1552"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.CognitiveServices/accounts/listKeys/action"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications using key authentication"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	accountName := params["cognitive_account_name"]
 	resourceGroup := params["resource_group_name"]
 
@@ -115,7 +123,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	}
 
 	log.Println("Attempting to list keys while local authentication is disabled on account " + accountName)
-	_, err = client.ListKeys(context.Background(), resourceGroup, accountName, nil)
+	_, err = client.ListKeys(ctx, resourceGroup, accountName, nil)
 	if err != nil {
 		log.Println("ListKeys failed as expected (local auth is disabled): " + err.Error())
 	} else {
@@ -124,7 +132,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Re-enabling local authentication on Cognitive Services account " + accountName)
 	disableLocalAuth := false
-	poller, err := client.BeginUpdate(context.Background(), resourceGroup, accountName, armcognitiveservices.Account{
+	poller, err := client.BeginUpdate(ctx, resourceGroup, accountName, armcognitiveservices.Account{
 		Properties: &armcognitiveservices.AccountProperties{
 			DisableLocalAuth: &disableLocalAuth,
 		},
@@ -132,14 +140,14 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	if err != nil {
 		return fmt.Errorf("failed to update Cognitive Services account: %w", err)
 	}
-	_, err = poller.PollUntilDone(context.Background(), nil)
+	_, err = poller.PollUntilDone(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to poll update of Cognitive Services account: %w", err)
 	}
 	log.Println("Successfully re-enabled local authentication on account " + accountName)
 
 	log.Println("Listing keys for Cognitive Services account " + accountName)
-	keysResult, err := client.ListKeys(context.Background(), resourceGroup, accountName, nil)
+	keysResult, err := client.ListKeys(ctx, resourceGroup, accountName, nil)
 	if err != nil {
 		return fmt.Errorf("failed to list keys for Cognitive Services account: %w", err)
 	}
@@ -150,7 +158,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	accountName := params["cognitive_account_name"]
 	resourceGroup := params["resource_group_name"]
 
@@ -161,7 +169,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Disabling local authentication on Cognitive Services account " + accountName)
 	disableLocalAuth := true
-	poller, err := client.BeginUpdate(context.Background(), resourceGroup, accountName, armcognitiveservices.Account{
+	poller, err := client.BeginUpdate(ctx, resourceGroup, accountName, armcognitiveservices.Account{
 		Properties: &armcognitiveservices.AccountProperties{
 			DisableLocalAuth: &disableLocalAuth,
 		},
@@ -169,7 +177,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	if err != nil {
 		return fmt.Errorf("failed to update Cognitive Services account: %w", err)
 	}
-	_, err = poller.PollUntilDone(context.Background(), nil)
+	_, err = poller.PollUntilDone(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to poll update of Cognitive Services account: %w", err)
 	}
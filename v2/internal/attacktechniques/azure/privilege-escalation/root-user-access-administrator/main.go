@@ -29,6 +29,14 @@ func init() {
 		MitreAttackTactics: []mitreattack.Tactic{
 			mitreattack.PrivilegeEscalation,
 		},
+		MitreAttackTechniques: []string{"T1098.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"operationName": {"Microsoft.Authorization/elevateAccess/action"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Global administrators regaining access to subscriptions"},
+		},
 		Description: `
 Elevates the current principal to the User Access Administrator role at root scope (/),
 by abusing the "Access management for Azure resources" capability available to Global Administrators in Entra ID.
@@ -105,14 +113,13 @@ Sample Azure Activity Log entry:
 }
 ` + codeBlock + `
 `,
-		IsIdempotent: false,
-		Detonate:     detonate,
-		Revert:       revert,
+		IsIdempotent:        false,
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	cred := providers.Azure().GetCredentials()
 	clientOptions := providers.Azure().ClientOptions
 
@@ -131,8 +138,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	cred := providers.Azure().GetCredentials()
 	clientOptions := providers.Azure().ClientOptions
 
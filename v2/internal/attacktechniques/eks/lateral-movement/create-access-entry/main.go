@@ -87,19 +87,26 @@ You can use the following CloudTrail events to identify when someone grants acce
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               false,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.LateralMovement},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		MitreAttackTechniques:      []string{"T1098.006"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"eventSource": {"eks.amazonaws.com"}, "eventName": {"CreateAccessEntry", "AssociateAccessPolicy"}},
+			},
+			FalsePositives: []string{"Administrators granting access to clusters"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	eksProvider := providers.EKS()
 	eksClient := eks.NewFromConfig(eksProvider.GetAWSConnection())
 	roleArn := params["role_arn"]
 
 	log.Println("Using EKS cluster management API to assign administrator privileges to " + roleArn)
 
-	_, err := eksClient.CreateAccessEntry(context.Background(), &eks.CreateAccessEntryInput{
+	_, err := eksClient.CreateAccessEntry(ctx, &eks.CreateAccessEntryInput{
 		ClusterName:  aws.String(eksProvider.GetEKSClusterName()),
 		PrincipalArn: &roleArn,
 	})
@@ -109,7 +116,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	log.Println("Successfully created EKS access entry for role", roleArn)
 	log.Println("This role is now full EKS cluster admin")
 
-	_, err = eksClient.AssociateAccessPolicy(context.Background(), &eks.AssociateAccessPolicyInput{
+	_, err = eksClient.AssociateAccessPolicy(ctx, &eks.AssociateAccessPolicyInput{
 		AccessScope:  &types.AccessScope{Type: types.AccessScopeTypeCluster},
 		ClusterName:  aws.String(eksProvider.GetEKSClusterName()),
 		PolicyArn:    aws.String(ClusterAccessPolicyARN),
@@ -122,12 +129,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	eksProvider := providers.EKS()
 	eksClient := eks.NewFromConfig(eksProvider.GetAWSConnection())
 	roleArn := params["role_arn"]
 
-	_, err := eksClient.DeleteAccessEntry(context.Background(), &eks.DeleteAccessEntryInput{
+	_, err := eksClient.DeleteAccessEntry(ctx, &eks.DeleteAccessEntryInput{
 		ClusterName:  aws.String(eksProvider.GetEKSClusterName()),
 		PrincipalArn: &roleArn,
 	})
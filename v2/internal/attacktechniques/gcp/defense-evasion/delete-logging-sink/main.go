@@ -42,26 +42,34 @@ References:
 Identify when a log sink is deleted using the GCP Admin Activity audit log event
 <code>google.logging.v2.ConfigServiceV2.DeleteSink</code>.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.logging.v2.ConfigServiceV2.DeleteSink"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators decommissioning log sinks"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	sinkName := params["sink_name"]
 	projectId := providers.GCP().GetProjectId()
 	sinkPath := fmt.Sprintf("projects/%s/sinks/%s", projectId, sinkName)
 
-	loggingService, err := logging.NewService(context.Background(), providers.GCP().Options())
+	loggingService, err := logging.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Cloud Logging client: %w", err)
 	}
 
 	log.Println("Deleting log sink " + sinkPath)
-	_, err = loggingService.Projects.Sinks.Delete(sinkPath).Do()
+	_, err = loggingService.Projects.Sinks.Delete(sinkPath).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete log sink %s: %w", sinkPath, err)
 	}
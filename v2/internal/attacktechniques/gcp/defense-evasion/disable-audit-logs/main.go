@@ -59,12 +59,20 @@ Identify when Data Access audit log configuration is removed from the project IA
 by monitoring for <code>SetIamPolicy</code> events in GCP Admin Activity audit logs where
 the request removes or reduces <code>auditConfigs</code> entries.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"SetIamPolicy"}, "protoPayload.serviceData.policyDelta.auditConfigDeltas.action": {"REMOVE"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators tuning Data Access audit logs"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
@@ -110,10 +118,10 @@ func setAuditConfigs(svc *cloudresourcemanager.Service, resource string, policy
 	return err
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	resource := "projects/" + providers.GCP().GetProjectId()
 
-	svc, err := cloudresourcemanager.NewService(context.Background(), providers.GCP().Options())
+	svc, err := cloudresourcemanager.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return fmt.Errorf("failed to create Cloud Resource Manager client: %w", err)
 	}
@@ -167,7 +175,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	resource := "projects/" + providers.GCP().GetProjectId()
 
 	policyJSON, err := base64.StdEncoding.DecodeString(params["original_policy_b64"])
@@ -200,7 +208,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 		break
 	}
 
-	svc, err := cloudresourcemanager.NewService(context.Background(), providers.GCP().Options())
+	svc, err := cloudresourcemanager.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return fmt.Errorf("failed to create Cloud Resource Manager client: %w", err)
 	}
@@ -48,31 +48,39 @@ Identify when a log sink is updated using the GCP Admin Activity audit log event
 <code>google.logging.v2.ConfigServiceV2.UpdateSink</code>. Inspect the request to check
 whether the <code>disabled</code> field was set to <code>true</code>.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.logging.v2.ConfigServiceV2.UpdateSink"}, "protoPayload.request.sink.disabled": {"true"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators temporarily disabling log sinks"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func setSinkDisabled(providers stratus.CloudProviders, sinkName string, disabled bool) error {
+func setSinkDisabled(ctx context.Context, providers stratus.CloudProviders, sinkName string, disabled bool) error {
 	projectId := providers.GCP().GetProjectId()
 	sinkPath := fmt.Sprintf("projects/%s/sinks/%s", projectId, sinkName)
 
-	loggingService, err := logging.NewService(context.Background(), providers.GCP().Options())
+	loggingService, err := logging.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Cloud Logging client: %w", err)
 	}
 
-	sink, err := loggingService.Projects.Sinks.Get(sinkPath).Do()
+	sink, err := loggingService.Projects.Sinks.Get(sinkPath).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to get log sink %s: %w", sinkPath, err)
 	}
 
 	sink.Disabled = disabled
-	_, err = loggingService.Projects.Sinks.Update(sinkPath, sink).UniqueWriterIdentity(true).UpdateMask("disabled").Do()
+	_, err = loggingService.Projects.Sinks.Update(sinkPath, sink).UniqueWriterIdentity(true).UpdateMask("disabled").Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to update log sink %s: %w", sinkPath, err)
 	}
@@ -80,20 +88,20 @@ func setSinkDisabled(providers stratus.CloudProviders, sinkName string, disabled
 	return nil
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	sinkName := params["sink_name"]
 	log.Println("Disabling log sink " + sinkName)
-	if err := setSinkDisabled(providers, sinkName, true); err != nil {
+	if err := setSinkDisabled(ctx, providers, sinkName, true); err != nil {
 		return err
 	}
 	log.Println("Successfully disabled log sink " + sinkName)
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	sinkName := params["sink_name"]
 	log.Println("Re-enabling log sink " + sinkName)
-	if err := setSinkDisabled(providers, sinkName, false); err != nil {
+	if err := setSinkDisabled(ctx, providers, sinkName, false); err != nil {
 		return err
 	}
 	log.Println("Successfully re-enabled log sink " + sinkName)
@@ -52,27 +52,34 @@ for Cloud Logging export. Monitor for <code>storage.buckets.update</code> events
 GCP Data Access audit logs where the request sets a lifecycle rule with a short
 expiration on a bucket associated with a logging sink.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"storage.buckets.update"}, "protoPayload.request.lifecycle.rule.action.type": {"Delete"}},
+			},
+			FalsePositives: []string{"Lifecycle rules applied to buckets that do not hold exported logs"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func newStorageService(providers stratus.CloudProviders) (*storagev1.Service, error) {
-	svc, err := storagev1.NewService(context.Background(), providers.GCP().Options())
+func newStorageService(ctx context.Context, providers stratus.CloudProviders) (*storagev1.Service, error) {
+	svc, err := storagev1.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Storage client: %w", err)
 	}
 	return svc, nil
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
 
-	svc, err := newStorageService(providers)
+	svc, err := newStorageService(ctx, providers)
 	if err != nil {
 		return err
 	}
@@ -88,7 +95,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 				},
 			},
 		},
-	}).Do()
+	}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to set lifecycle rule on bucket %s: %w", bucketName, err)
 	}
@@ -97,10 +104,10 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
 
-	svc, err := newStorageService(providers)
+	svc, err := newStorageService(ctx, providers)
 	if err != nil {
 		return err
 	}
@@ -108,7 +115,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	log.Printf("Removing lifecycle rule from log sink bucket %s\n", bucketName)
 	_, err = svc.Buckets.Patch(bucketName, &storagev1.Bucket{
 		NullFields: []string{"Lifecycle"},
-	}).Do()
+	}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to remove lifecycle rule from bucket %s: %w", bucketName, err)
 	}
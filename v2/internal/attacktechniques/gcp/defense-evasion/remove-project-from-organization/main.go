@@ -47,17 +47,25 @@ GCP Admin Activity audit logs, especially where the request attempts to change t
 project parent to a different organization. Unexpected move attempts on the project
 resource should be treated as high-severity events.
 `,
-		Platform:           stratus.GCP,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.DefenseEvasion},
-		Detonate:           detonate,
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1666"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.cloud.resourcemanager.v3.Projects.MoveProject", "MoveProject"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Migrations of projects between organizations"},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	projectId := gcp.GetProjectId()
-	ctx := context.Background()
 
 	svc, err := cloudresourcemanager.NewService(ctx, gcp.Options())
 	if err != nil {
@@ -65,7 +73,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Printf("Reading current project metadata for %s\n", projectId)
-	project, err := svc.Projects.Get("projects/" + projectId).Context(ctx).Do()
+	project, err := svc.Projects.Get("projects/" + projectId).Context(ctx).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to get project %s: %w", projectId, err)
 	}
@@ -81,7 +89,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	log.Printf("Attempting to move project %s to %s\n", projectId, attackerOrgId)
 	_, err = svc.Projects.Move("projects/"+projectId, &cloudresourcemanager.MoveProjectRequest{
 		DestinationParent: attackerOrgId,
-	}).Context(ctx).Do()
+	}).Context(ctx).Context(ctx).Do()
 	if err != nil {
 		var apiErr *googleapi.Error
 		if errors.As(err, &apiErr) && (apiErr.Code == 403 || apiErr.Code == 404) {
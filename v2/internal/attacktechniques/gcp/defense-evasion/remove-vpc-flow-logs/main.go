@@ -49,18 +49,24 @@ Identify when VPC flow logging is disabled on a subnet by monitoring for
 <code>v1.compute.subnetworks.patch</code> events in GCP Admin Activity audit logs
 where the request sets <code>logConfig.enable</code> to <code>false</code>.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+		MitreAttackTechniques: []string{"T1562.008"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.subnetworks.patch"}, "protoPayload.request.logConfig.enable": {"false"}},
+			},
+			FalsePositives: []string{"Administrators disabling flow logs to reduce costs"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func setFlowLogsEnabled(providers stratus.CloudProviders, subnetName string, region string, enabled bool) error {
+func setFlowLogsEnabled(ctx context.Context, providers stratus.CloudProviders, subnetName string, region string, enabled bool) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 	projectId := gcp.GetProjectId()
 
 	subnetsClient, err := compute.NewSubnetworksRESTClient(ctx, gcp.Options())
@@ -100,24 +106,24 @@ func setFlowLogsEnabled(providers stratus.CloudProviders, subnetName string, reg
 	return nil
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	subnetName := params["subnet_name"]
 	region := params["region"]
 
 	log.Printf("Disabling VPC flow logs on subnet %s in region %s\n", subnetName, region)
-	if err := setFlowLogsEnabled(providers, subnetName, region, false); err != nil {
+	if err := setFlowLogsEnabled(ctx, providers, subnetName, region, false); err != nil {
 		return err
 	}
 	log.Printf("Successfully disabled VPC flow logs on subnet %s\n", subnetName)
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	subnetName := params["subnet_name"]
 	region := params["region"]
 
 	log.Printf("Re-enabling VPC flow logs on subnet %s in region %s\n", subnetName, region)
-	if err := setFlowLogsEnabled(providers, subnetName, region, true); err != nil {
+	if err := setFlowLogsEnabled(ctx, providers, subnetName, region, true); err != nil {
 		return err
 	}
 	log.Printf("Successfully re-enabled VPC flow logs on subnet %s\n", subnetName)
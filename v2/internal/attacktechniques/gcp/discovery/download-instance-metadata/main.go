@@ -54,20 +54,27 @@ Identify unexpected reads of instance metadata via the Compute API by monitoring
 Data Access audit logs originating from identities that do not normally perform Compute
 Engine management operations.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Discovery},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Discovery},
+		MitreAttackTechniques: []string{"T1580"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.instances.get", "v1.compute.instances.list"}},
+			},
+			Level:          stratus.DetectionLevelInformational,
+			FalsePositives: []string{"Administrators and tooling managing Compute Engine instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	projectId := gcp.GetProjectId()
 	instanceName := params["instance_name"]
 	zone := params["zone"]
-	ctx := context.Background()
 
 	instancesClient, err := compute.NewInstancesRESTClient(ctx, gcp.Options())
 	if err != nil {
@@ -63,15 +63,23 @@ References:
     <a href="https://cloud.google.com/logging/docs/audit#data-access">Data Access audit logs</a>,
     which are disabled by default.
     Enable Data Access logging for Resource Manager to capture this behavior.`,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Discovery},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Discovery},
+		MitreAttackTechniques: []string{"T1069.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name|endswith": {"TestIamPermissions"}},
+			},
+			Level:          stratus.DetectionLevelInformational,
+			FalsePositives: []string{"Consoles and tooling checking the permissions of their users"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	saKeyBase64 := params["sa_key"]
 	projectID := params["project_id"]
 	saEmail := params["sa_email"]
@@ -81,8 +89,6 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		return fmt.Errorf("failed to decode service account key: %w", err)
 	}
 
-	ctx := context.Background()
-
 	testablePermissions := params["testable_permissions"]
 	if testablePermissions == "" {
 		return fmt.Errorf("testable permissions list is empty")
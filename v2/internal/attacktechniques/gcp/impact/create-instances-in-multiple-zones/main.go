@@ -69,18 +69,25 @@ Detection criteria:
   <li>Exclude legitimate automation such as Managed Instance Groups (user agent containing <code>GCE Managed Instance Group</code>)</li>
 </ul>
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1496.001", "T1578.002"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.instances.insert", "beta.compute.instances.insert"}},
+			},
+			Level:          stratus.DetectionLevelInformational,
+			FalsePositives: []string{"Administrators and autoscalers creating instances"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 	projectId := gcp.GetProjectId()
 	suffix := params["suffix"]
 
@@ -160,9 +167,8 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 	projectId := gcp.GetProjectId()
 
 	instancesClient, err := compute.NewInstancesRESTClient(ctx, gcp.Options())
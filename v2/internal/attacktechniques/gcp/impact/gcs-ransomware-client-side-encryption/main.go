@@ -84,18 +84,26 @@ Sample audit log event for a rewrite-in-place, shortened for readability:
 }
 ` + CodeBlock + `
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1486"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"storage.objects.create"}, "protoPayload.authorizationInfo.permission": {"storage.objects.delete"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Applications overwriting objects"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert, // We need to decrypt files before cleaning up, otherwise Terraform can't delete them properly
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert, // We need to decrypt files before cleaning up, otherwise Terraform can't delete them properly
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
-	ctx := context.Background()
 
 	client, err := storage.NewClient(ctx, providers.GCP().Options())
 	if err != nil {
@@ -154,9 +162,8 @@ func uploadObject(ctx context.Context, client *storage.Client, bucketName, objec
 	return writer.Close()
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
-	ctx := context.Background()
 
 	client, err := storage.NewClient(ctx, providers.GCP().Options())
 	if err != nil {
@@ -79,17 +79,25 @@ Sample audit log event for <code>storage.objects.delete</code>, shortened for re
 }
 ` + CodeBlock + `
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Impact},
+		Impact:                stratus.ImpactDestructive,
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Impact},
+		MitreAttackTechniques: []string{"T1485"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"storage.objects.delete"}},
+			},
+			Level:          stratus.DetectionLevelInformational,
+			FalsePositives: []string{"Applications deleting objects"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	bucketName := params["bucket_name"]
-	ctx := context.Background()
 
 	client, err := storage.NewClient(ctx, providers.GCP().Options())
 	if err != nil {
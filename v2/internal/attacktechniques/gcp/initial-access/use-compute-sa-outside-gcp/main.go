@@ -7,6 +7,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
@@ -70,12 +71,20 @@ Detection criteria:
   <li>Exclude calls with user agents containing <code>GCE</code> or <code>gcloud</code> (which indicate legitimate in-cloud usage)</li>
 </ul>
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.CredentialAccess, mitreattack.InitialAccess},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess, mitreattack.InitialAccess},
+		MitreAttackTechniques: []string{"T1552.005", "T1078.004"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"protoPayload.authenticationInfo.principalEmail|endswith": {"-compute@developer.gserviceaccount.com"}, "protoPayload.requestMetadata.callerSuppliedUserAgent|contains": {"google-api-go-client", "gcloud", "python", "curl"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators using the default service account from their workstations"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
@@ -86,8 +95,7 @@ type tokenResponse struct {
 	TokenType   string `json:"token_type"`
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
-	ctx := context.Background()
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	projectId := gcp.GetProjectId()
 	saEmail := params["sa_email"]
@@ -177,7 +185,9 @@ func readTokenFromSerialPort(ctx context.Context, client *compute.InstancesClien
 		if i == 0 {
 			log.Println("Waiting for instance startup script to write OAuth2 token to serial port...")
 		}
-		time.Sleep(5 * time.Second)
+		if err := utils.SleepWithContext(ctx, 5*time.Second); err != nil {
+			return "", err
+		}
 	}
 
 	return "", fmt.Errorf("timed out waiting for OAuth2 token in serial port output (instance startup script may have failed)")
@@ -197,9 +207,8 @@ func extractToken(serialOutput string) string {
 	return remainder[:endIdx]
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 	projectId := gcp.GetProjectId()
 	instanceName := params["instance_name"]
 	zone := params["zone"]
@@ -82,15 +82,21 @@ Sample event (shortened for readability):
 		MitreAttackTactics: []mitreattack.Tactic{
 			mitreattack.LateralMovement,
 			mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.004"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"v1.compute.instances.setMetadata"}, "protoPayload.metadata.instanceMetadataDelta.addedMetadataKeys": {"ssh-keys"}},
+			},
+			FalsePositives: []string{"Administrators managing SSH access through instance metadata"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 
 	projectId := gcp.GetProjectId()
 	zone := params["zone"]
@@ -114,7 +120,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	log.Println("Registering public key to instance's metadata")
 
 	// get instance information
-	instance, err := service.Instances.Get(projectId, zone, instanceName).Do()
+	instance, err := service.Instances.Get(projectId, zone, instanceName).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to get instance information: %v", err)
 	}
@@ -123,7 +129,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	entry := fmt.Sprintf("%s:%s", AttackerUsername, string(key.PublicKey))
 
 	gcp_utils.InsertToMetadata(md, "ssh-keys", entry)
-	if _, err := service.Instances.SetMetadata(projectId, zone, instanceName, md).Do(); err != nil {
+	if _, err := service.Instances.SetMetadata(projectId, zone, instanceName, md).Context(ctx).Do(); err != nil {
 		return fmt.Errorf("failed to update instance metadata: %v", err)
 	}
 
@@ -139,9 +145,8 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 // may have already synced the key to ~/.ssh/authorized_keys on the VM's filesystem,
 // and removing the key from metadata does not guarantee it is removed from disk.
 // The attacker's user account also persists.
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
-	ctx := context.Background()
 
 	projectId := gcp.GetProjectId()
 	zone := params["zone"]
@@ -152,7 +157,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 		return fmt.Errorf("failed to create compute service: %v", err)
 	}
 
-	instance, err := service.Instances.Get(projectId, zone, instanceName).Do()
+	instance, err := service.Instances.Get(projectId, zone, instanceName).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to get instance information: %v", err)
 	}
@@ -174,7 +179,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Removing attacker SSH key from instance metadata")
-	if _, err := service.Instances.SetMetadata(projectId, zone, instanceName, md).Do(); err != nil {
+	if _, err := service.Instances.SetMetadata(projectId, zone, instanceName, md).Context(ctx).Do(); err != nil {
 		return fmt.Errorf("failed to update instance metadata: %v", err)
 	}
 
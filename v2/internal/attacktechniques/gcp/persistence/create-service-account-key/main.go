@@ -39,18 +39,24 @@ References:
 		Detection: `
 Using GCP Admin Activity audit logs event <code>google.iam.admin.v1.CreateServiceAccountKey</code>.
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               false,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		Platform:              stratus.GCP,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"google.iam.admin.v1.CreateServiceAccountKey"}},
+			},
+			FalsePositives: []string{"Administrators creating keys for workloads running outside of Google Cloud"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	saEmail := params["sa_email"]
-	ctx := context.Background()
 	service, err := iam.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return errors.New("Error instantiating GCP SDK Client: " + err.Error())
@@ -59,7 +65,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	log.Println("Creating service account key on service account " + saEmail)
 	resource := "projects/-/serviceAccounts/" + saEmail
 	request := &iam.CreateServiceAccountKeyRequest{}
-	key, err := service.Projects.ServiceAccounts.Keys.Create(resource, request).Do()
+	key, err := service.Projects.ServiceAccounts.Keys.Create(resource, request).Context(ctx).Do()
 	if err != nil {
 		return errors.New("Unable to create service account key: " + err.Error())
 	}
@@ -71,17 +77,16 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	saEmail := params["sa_email"]
 	resource := "projects/-/serviceAccounts/" + saEmail
 
-	ctx := context.Background()
 	service, err := iam.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return errors.New("")
 	}
 
-	keys, err := service.Projects.ServiceAccounts.Keys.List(resource).Do()
+	keys, err := service.Projects.ServiceAccounts.Keys.List(resource).Context(ctx).Do()
 	if err != nil {
 		return errors.New("Failed to list Service Account Keys: " + err.Error())
 	}
@@ -92,7 +97,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 			continue
 		}
 		log.Println("Deleting service account key " + key.Name)
-		_, err := service.Projects.ServiceAccounts.Keys.Delete(key.Name).Do()
+		_, err := service.Projects.ServiceAccounts.Keys.Delete(key.Name).Context(ctx).Do()
 		if err != nil {
 			return errors.New("Failed to delete service account key: " + err.Error())
 		}
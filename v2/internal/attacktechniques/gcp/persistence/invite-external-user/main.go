@@ -1,6 +1,7 @@
 package gcp
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"github.com/datadog/stratus-red-team/v2/internal/utils"
@@ -92,28 +93,35 @@ which cannot be done through the SetIamPolicy API call. In that case, an <code>I
 ` + codeBlock + `
 
 `,
-		Platform:           stratus.GCP,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
-		Detonate:           detonate,
-		Revert:             revert,
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.003"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"SetIamPolicy"}, "protoPayload.serviceData.policyDelta.bindingDeltas.action": {"ADD"}, "protoPayload.serviceData.policyDelta.bindingDeltas.member|endswith": {"@gmail.com"}},
+			},
+			FalsePositives: []string{"Collaborators using personal Google accounts"},
+		},
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
 const RoleToGrant = "roles/editor"
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	attackerPrincipal := gcp_utils.GetAttackerPrincipal()
-	err := gcp_utils.GCPAssignProjectRole(providers.GCP(), attackerPrincipal, RoleToGrant)
+	err := gcp_utils.GCPAssignProjectRole(ctx, providers.GCP(), attackerPrincipal, RoleToGrant)
 	if err != nil {
 		return fmt.Errorf("unable to assign %s to %s: %w", attackerPrincipal, RoleToGrant, err)
 	}
 	return nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	attackerPrincipal := gcp_utils.GetAttackerPrincipal()
-	err := gcp_utils.GCPUnassignProjectRole(providers.GCP(), attackerPrincipal, RoleToGrant)
+	err := gcp_utils.GCPUnassignProjectRole(ctx, providers.GCP(), attackerPrincipal, RoleToGrant)
 	if err != nil {
 		return fmt.Errorf("unable to assign %s to %s: %w", attackerPrincipal, RoleToGrant, err)
 	}
@@ -162,19 +162,27 @@ Some detection strategies may include:
 * Alerting when the same IP address / user-agent attempts to impersonate several service accounts in a
 short amount of time (successfully or not)
 `,
-		Platform:                   stratus.GCP,
-		IsIdempotent:               true,
-		MitreAttackTactics:         []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		Platform:              stratus.GCP,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1548.005"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"gcp.audit.method_name": {"GenerateAccessToken"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Workloads and administrators impersonating service accounts"},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	serviceAccountEmails := strings.Split(params["service_account_emails"], ",")
 	numServiceAccounts := len(serviceAccountEmails)
 
-	iamCredentialsClient, err := iamcredentials.NewService(context.Background(), providers.GCP().Options())
+	iamCredentialsClient, err := iamcredentials.NewService(ctx, providers.GCP().Options())
 	if err != nil {
 		return fmt.Errorf("unable to instantiate GCP IAM client: %v", err)
 	}
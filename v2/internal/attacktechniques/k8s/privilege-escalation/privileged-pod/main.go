@@ -21,11 +21,19 @@ const techniqueID = "k8s.privilege-escalation.privileged-pod"
 
 func init() {
 	stratus.GetRegistry().RegisterAttackTechnique(&stratus.AttackTechnique{
-		ID:                 techniqueID,
-		FriendlyName:       "Run a Privileged Pod",
-		Platform:           stratus.Kubernetes,
-		IsIdempotent:       false,
-		MitreAttackTactics: []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		ID:                    techniqueID,
+		FriendlyName:          "Run a Privileged Pod",
+		Platform:              stratus.Kubernetes,
+		IsIdempotent:          false,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1610", "T1611"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"create"}, "objectRef.resource": {"pods"}, "requestObject.spec.containers.securityContext.privileged": {"true"}},
+			},
+			Level:          stratus.DetectionLevelHigh,
+			FalsePositives: []string{"Node agents such as CNI plugins or security tools"},
+		},
 		Description: `
 Runs a privileged pod. Privileged pods are equivalent to running as root on the worker node, and can be used for privilege escalation.
 
@@ -81,13 +89,16 @@ Sample event (shortened):
 	}
 }
 ` + codeBlock,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventName: "create", Fields: map[string]string{"objectRef.resource": "pods"}},
+		},
 		PrerequisitesTerraformCode: tf,
-		Detonate:                   detonate,
-		Revert:                     revert,
+		DetonateWithContext:        detonate,
+		RevertWithContext:          revert,
 	})
 }
 
-func detonate(params map[string]string, providers stratus.CloudProviders) error {
+func detonate(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 	namespace := params["namespace"]
 	correlationID := providers.K8s().UniqueCorrelationId.String()
@@ -97,7 +108,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	providers.K8s().ApplyPodConfig(techniqueID, podSpec)
 
 	log.Println("Creating privileged pod " + podSpec.ObjectMeta.Name)
-	_, err := client.CoreV1().Pods(namespace).Create(context.Background(), podSpec, metav1.CreateOptions{})
+	_, err := client.CoreV1().Pods(namespace).Create(ctx, podSpec, metav1.CreateOptions{})
 	if err != nil {
 		return errors.New("unable to create pod: " + err.Error())
 	}
@@ -106,7 +117,7 @@ func detonate(params map[string]string, providers stratus.CloudProviders) error
 	return nil
 }
 
-func revert(params map[string]string, providers stratus.CloudProviders) error {
+func revert(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
 	client := providers.K8s().GetClient()
 	// Param retrieved from the Terraform output. If a namespace was defined in the config, it will
 	// be passed through terraform, otherwise it's the created namespace.
@@ -116,7 +127,7 @@ func revert(params map[string]string, providers stratus.CloudProviders) error {
 
 	log.Println("Removing privileged pod " + podSpec.ObjectMeta.Name)
 	deleteOptions := metav1.DeleteOptions{GracePeriodSeconds: ptr.Int64(0)}
-	err := client.CoreV1().Pods(namespace).Delete(context.Background(), podSpec.ObjectMeta.Name, deleteOptions)
+	err := client.CoreV1().Pods(namespace).Delete(ctx, podSpec.ObjectMeta.Name, deleteOptions)
 	if err != nil {
 		return errors.New("unable to remove pod: " + err.Error())
 	}
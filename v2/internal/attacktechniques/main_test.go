@@ -0,0 +1,114 @@
+package attacktechniques
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/sigma"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/stratustest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttackTechniquesMapToMitreAttackTechniques(t *testing.T) {
+	for _, technique := range stratus.GetRegistry().ListAttackTechniques() {
+		assert.NotEmpty(t, technique.MitreAttackTechniques, "%s does not map to any MITRE ATT&CK technique", technique.ID)
+		_, err := technique.GetMitreAttackTechniques()
+		assert.NoError(t, err)
+	}
+}
+
+func TestAttackTechniquesCanReadTheirParameters(t *testing.T) {
+	for _, technique := range stratus.GetRegistry().ListAttackTechniques() {
+		_, err := technique.ResolveParameters()
+		assert.NoError(t, err, "the default parameters of %s are invalid", technique.ID)
+	}
+}
+
+func TestAttackTechniquesHaveValidSigmaRules(t *testing.T) {
+	for _, technique := range stratus.GetRegistry().ListAttackTechniques() {
+		rule, err := sigma.NewRule(technique, time.Now())
+		if !assert.NoError(t, err, "%s has no valid detection rule", technique.ID) {
+			continue
+		}
+		raw, err := rule.Marshal()
+		if assert.NoError(t, err) {
+			assert.NoError(t, sigma.Validate(raw), "Sigma rule of %s is invalid", technique.ID)
+		}
+	}
+}
+
+// sigmaEventFields are the names, in the Sigma taxonomy of each type of logs, of the fields holding the
+// EventSource and EventName of expected events. Only in CloudTrail and Kubernetes audit logs are the
+// Sigma fields named after the path of the fields in the events, as Fields of expected events are.
+var sigmaEventFields = map[stratus.LogSource]struct {
+	eventSource string
+	eventName   string
+	pathFields  bool
+}{
+	stratus.LogSourceCloudTrail:          {eventSource: "eventSource", eventName: "eventName", pathFields: true},
+	stratus.LogSourceGCPAuditLogs:        {eventName: "gcp.audit.method_name"},
+	stratus.LogSourceKubernetesAuditLogs: {eventName: "verb", pathFields: true},
+}
+
+// TestAttackTechniquesDetectionRulesMatchTheirExpectedEvents makes sure that the detection rule of a technique
+// triggers on at least one of the events it is expected to produce, so that the two cannot drift apart
+func TestAttackTechniquesDetectionRulesMatchTheirExpectedEvents(t *testing.T) {
+	for _, technique := range stratus.GetRegistry().ListAttackTechniques() {
+		if technique.DetectionRule == nil || len(technique.ExpectedEvents) == 0 {
+			continue
+		}
+		logSource := technique.DetectionRule.GetLogSource(technique.Platform)
+		fields, ok := sigmaEventFields[logSource]
+		if !assert.True(t, ok, "%s declares expected events in %s logs, which cannot be compared to its detection rule", technique.ID, logSource) {
+			continue
+		}
+		for _, selection := range technique.DetectionRule.Selections {
+			matched := false
+			for _, event := range technique.ExpectedEvents {
+				eventFields := map[string]string{fields.eventName: event.EventName}
+				if fields.eventSource != "" && event.EventSource != "" {
+					eventFields[fields.eventSource] = event.EventSource
+				}
+				if fields.pathFields {
+					for path, value := range event.Fields {
+						eventFields[path] = value
+					}
+				}
+				if selectionMatches(selection, fields.eventName, eventFields) {
+					matched = true
+					break
+				}
+			}
+			assert.True(t, matched, "the detection rule of %s has a selection %v matching none of its expected events", technique.ID, selection)
+		}
+	}
+}
+
+// selectionMatches returns whether a selection matches the name of an event, and every other field of the
+// event that it has a value for. Fields with Sigma modifiers are not compared.
+func selectionMatches(selection stratus.DetectionSelection, eventNameField string, eventFields map[string]string) bool {
+	if !slices.Contains(selection[eventNameField], eventFields[eventNameField]) {
+		return false
+	}
+	for field, values := range selection {
+		value, ok := eventFields[field]
+		if ok && !slices.Contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestAttackTechniquesMatchTheirCassettes replays the calls to cloud APIs recorded with
+// STRATUS_RECORD=internal/attacktechniques/testdata/cassettes
+func TestAttackTechniquesMatchTheirCassettes(t *testing.T) {
+	for _, technique := range stratus.GetRegistry().ListAttackTechniques() {
+		t.Run(technique.ID, func(t *testing.T) {
+			if !stratustest.ReplayCassettes(t, technique, "testdata/cassettes") {
+				t.Skip("no cassette recorded")
+			}
+		})
+	}
+}
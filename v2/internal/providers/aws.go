@@ -4,15 +4,20 @@ import (
 	"context"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/datadog/stratus-red-team/v2/internal/utils"
+	stratusconfig "github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/google/uuid"
 	"os"
+	"slices"
 )
 
 type AWSProvider struct {
 	awsConfig           *aws.Config
+	loadOptions         []func(*config.LoadOptions) error
 	UniqueCorrelationId uuid.UUID // unique value injected in the user-agent, to differentiate Stratus Red Team executions
 }
 
@@ -26,6 +31,12 @@ func WithAWSConfig(cfg aws.Config) AWSProviderOption {
 	return func(p *AWSProvider) { p.awsConfig = &cfg }
 }
 
+// WithAWSLoadOptions adds options to the loading of the default configuration, e.g. config.WithHTTPClient.
+// It has no effect along with WithAWSConfig.
+func WithAWSLoadOptions(opts ...func(*config.LoadOptions) error) AWSProviderOption {
+	return func(p *AWSProvider) { p.loadOptions = append(p.loadOptions, opts...) }
+}
+
 func NewAWSProvider(correlationId uuid.UUID, opts ...AWSProviderOption) *AWSProvider {
 	p := &AWSProvider{UniqueCorrelationId: correlationId}
 	for _, opt := range opts {
@@ -33,18 +44,65 @@ func NewAWSProvider(correlationId uuid.UUID, opts ...AWSProviderOption) *AWSProv
 	}
 	// Load default config only if no explicit config was injected
 	if p.awsConfig == nil {
-		cfg, err := config.LoadDefaultConfig(context.Background(), utils.CustomUserAgentApiOptions(correlationId))
+		loadOptions := append([]func(*config.LoadOptions) error{
+			utils.CustomUserAgentApiOptions(correlationId),
+			config.WithBaseEndpoint(getAWSEndpointURL()),
+		}, p.loadOptions...)
+		cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
 		if err != nil {
 			log.Fatalf("unable to load AWS configuration, %v", err)
 		}
 		p.awsConfig = &cfg
 	}
+	// Trace the calls made under the span of a runner operation, without changing the config of the caller
+	tracedConfig := p.awsConfig.Copy()
+	tracedConfig.APIOptions = append(slices.Clip(tracedConfig.APIOptions), utils.AddTracingMiddleware)
+	p.awsConfig = &tracedConfig
 	return p
 }
+
+// getAWSEndpointURL returns the endpoint set with aws.endpoint_url in the configuration file, if any.
+// The AWS SDK reads AWS_ENDPOINT_URL on its own.
+func getAWSEndpointURL() string {
+	cfg, err := stratusconfig.LoadConfig()
+	if err != nil {
+		log.Println("Warning: unable to load config, aws.endpoint_url will not be applied: " + err.Error())
+		return ""
+	}
+	return cfg.GetAWSEndpointURL()
+}
+
 func (m *AWSProvider) GetConnection() aws.Config {
 	return *m.awsConfig
 }
 
+// InRegion returns a provider with the credentials of m, calling the APIs of another region
+func (m *AWSProvider) InRegion(region string) *AWSProvider {
+	cfg := m.GetConnection()
+	cfg.Region = region
+	// The config is already traced, so it must not go through NewAWSProvider again
+	return &AWSProvider{awsConfig: &cfg, UniqueCorrelationId: m.UniqueCorrelationId}
+}
+
+// AssumeRoles returns a provider authenticated as the last of a chain of IAM roles, each assumed with the
+// credentials of the previous one, the first one with the credentials of m. externalID is passed when
+// assuming the last role, if set.
+func (m *AWSProvider) AssumeRoles(roleARNs []string, externalID string, sessionName string) *AWSProvider {
+	cfg := m.GetConnection()
+	for i, roleARN := range roleARNs {
+		isLast := i == len(roleARNs)-1
+		assumeRole := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = sessionName
+			if isLast && externalID != "" {
+				o.ExternalID = aws.String(externalID)
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(assumeRole)
+	}
+	// The config is already traced, so it must not go through NewAWSProvider again
+	return &AWSProvider{awsConfig: &cfg, UniqueCorrelationId: m.UniqueCorrelationId}
+}
+
 func (m *AWSProvider) IsAuthenticatedAgainstAWS() bool {
 	// We make a sample API call to AWS to ensure the user is authenticated
 	// Note: We use ec2:DescribeAccountAttributes as an arbitrary API call
@@ -63,3 +121,8 @@ func (m *AWSProvider) IsAuthenticatedAgainstAWS() bool {
 
 	return true
 }
+
+// GetAccountID returns the ID of the AWS account the current credentials belong to
+func (m *AWSProvider) GetAccountID(ctx context.Context) (string, error) {
+	return utils.GetCurrentAccountId(ctx, m.GetConnection())
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"net/http"
 	"os"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -10,6 +11,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const azureSubscriptionIdEnvVarKey = "AZURE_SUBSCRIPTION_ID"
@@ -19,6 +21,7 @@ type AzureProvider struct {
 	ClientOptions       *arm.ClientOptions
 	SubscriptionID      string
 	UniqueCorrelationId uuid.UUID // unique value injected in the user-agent, to differentiate Stratus Red Team executions
+	transport           policy.Transporter
 }
 
 // AzureProviderOption configures optional overrides on an AzureProvider.
@@ -36,6 +39,11 @@ func WithAzureSubscriptionID(subscriptionID string) AzureProviderOption {
 	return func(p *AzureProvider) { p.SubscriptionID = subscriptionID }
 }
 
+// WithAzureTransport sends the requests of Azure clients through an explicit transport
+func WithAzureTransport(transport policy.Transporter) AzureProviderOption {
+	return func(p *AzureProvider) { p.transport = transport }
+}
+
 func NewAzureProvider(correlationId uuid.UUID, opts ...AzureProviderOption) *AzureProvider {
 	p := &AzureProvider{UniqueCorrelationId: correlationId}
 	for _, opt := range opts {
@@ -57,9 +65,14 @@ func NewAzureProvider(correlationId uuid.UUID, opts ...AzureProviderOption) *Azu
 		p.Credentials = creds
 	}
 
+	if p.transport == nil {
+		// Trace the calls as children of the span of the runner operation, if any
+		p.transport = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	}
 	p.ClientOptions = &arm.ClientOptions{
 		ClientOptions: azcore.ClientOptions{
 			Telemetry: policy.TelemetryOptions{ApplicationID: correlationId.String(), Disabled: false},
+			Transport: p.transport,
 		},
 	}
 	return p
@@ -69,6 +82,28 @@ func (m *AzureProvider) GetCredentials() azcore.TokenCredential {
 	return m.Credentials
 }
 
+// InSubscription returns a provider with the credentials of m, for another subscription
+func (m *AzureProvider) InSubscription(subscriptionID string) *AzureProvider {
+	provider := *m
+	provider.SubscriptionID = subscriptionID
+	return &provider
+}
+
+// AsServicePrincipal returns a provider for the same subscription, authenticated as a service principal
+func (m *AzureProvider) AsServicePrincipal(tenantID string, clientID string, clientSecret string) (*AzureProvider, error) {
+	creds, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Transport: m.transport},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewAzureProvider(m.UniqueCorrelationId,
+		WithAzureCredentials(creds),
+		WithAzureSubscriptionID(m.SubscriptionID),
+		WithAzureTransport(m.transport),
+	), nil
+}
+
 func (m *AzureProvider) IsAuthenticatedAgainstAzure() bool {
 	_, err := armresources.NewClient(m.SubscriptionID, m.Credentials, nil)
 
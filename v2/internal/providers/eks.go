@@ -49,7 +49,12 @@ func (m *EKSProvider) GetAWSConnection() aws.Config {
 	return m.awsProvider.GetConnection()
 }
 
-func (m *EKSProvider) GetK8sClient() *kubernetes.Clientset {
+// GetAWSProvider returns the provider of the AWS account the EKS cluster lives in
+func (m *EKSProvider) GetAWSProvider() *AWSProvider {
+	return m.awsProvider
+}
+
+func (m *EKSProvider) GetK8sClient() kubernetes.Interface {
 	return m.k8sProvider.GetClient()
 }
 
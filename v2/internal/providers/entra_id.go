@@ -8,13 +8,19 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
 	"github.com/google/uuid"
+	azureauth "github.com/microsoft/kiota-authentication-azure-go"
+	khttp "github.com/microsoft/kiota-http-go"
 	graph "github.com/microsoftgraph/msgraph-sdk-go"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"net/http"
 )
 
 type EntraIdProvider struct {
-	Credentials   azcore.TokenCredential
-	ClientOptions *arm.ClientOptions
-	GraphClient   *graph.GraphServiceClient
+	Credentials         azcore.TokenCredential
+	ClientOptions       *arm.ClientOptions
+	GraphClient         *graph.GraphServiceClient
+	UniqueCorrelationId uuid.UUID // unique value injected in the user-agent, to differentiate Stratus Red Team executions
+	transport           http.RoundTripper
 }
 
 // EntraIdProviderOption configures optional overrides on an EntraIdProvider.
@@ -26,8 +32,13 @@ func WithEntraIdCredentials(cred azcore.TokenCredential) EntraIdProviderOption {
 	return func(p *EntraIdProvider) { p.Credentials = cred }
 }
 
+// WithEntraIdTransport sends the requests of the Graph client through an explicit transport
+func WithEntraIdTransport(transport http.RoundTripper) EntraIdProviderOption {
+	return func(p *EntraIdProvider) { p.transport = transport }
+}
+
 func NewEntraIdProvider(correlationId uuid.UUID, opts ...EntraIdProviderOption) *EntraIdProvider {
-	p := &EntraIdProvider{}
+	p := &EntraIdProvider{UniqueCorrelationId: correlationId}
 	for _, opt := range opts {
 		opt(p)
 	}
@@ -40,13 +51,18 @@ func NewEntraIdProvider(correlationId uuid.UUID, opts ...EntraIdProviderOption)
 		p.Credentials = creds
 	}
 
+	if p.transport == nil {
+		// Trace the calls as children of the span of the runner operation, if any
+		p.transport = otelhttp.NewTransport(http.DefaultTransport)
+	}
 	p.ClientOptions = &arm.ClientOptions{
 		ClientOptions: azcore.ClientOptions{
 			Telemetry: policy.TelemetryOptions{ApplicationID: correlationId.String(), Disabled: false},
+			Transport: &http.Client{Transport: p.transport},
 		},
 	}
 
-	graphClient, err := graph.NewGraphServiceClientWithCredentials(p.Credentials, nil)
+	graphClient, err := p.newGraphClient()
 	if err != nil {
 		log.Fatalf("could initialize Entra ID Graph client: %v", err)
 	}
@@ -55,6 +71,30 @@ func NewEntraIdProvider(correlationId uuid.UUID, opts ...EntraIdProviderOption)
 	return p
 }
 
+func (m *EntraIdProvider) newGraphClient() (*graph.GraphServiceClient, error) {
+	auth, err := azureauth.NewAzureIdentityAuthenticationProviderWithScopes(m.Credentials, []string{"https://graph.microsoft.com/.default"})
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: khttp.NewCustomTransportWithParentTransport(m.transport, khttp.GetDefaultMiddlewares()...)}
+	adapter, err := graph.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(auth, nil, nil, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return graph.NewGraphServiceClient(adapter), nil
+}
+
+// AsServicePrincipal returns a provider for the same tenant, authenticated as a service principal
+func (m *EntraIdProvider) AsServicePrincipal(tenantID string, clientID string, clientSecret string) (*EntraIdProvider, error) {
+	options := &azidentity.ClientSecretCredentialOptions{}
+	options.ClientOptions.Transport = &http.Client{Transport: m.transport}
+	creds, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewEntraIdProvider(m.UniqueCorrelationId, WithEntraIdCredentials(creds), WithEntraIdTransport(m.transport)), nil
+}
+
 func (m *EntraIdProvider) GetGraphClient() *graph.GraphServiceClient {
 	return m.GraphClient
 }
@@ -3,11 +3,16 @@ package providers
 import (
 	"context"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/useragent"
+	"net/http"
 	"os"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/iam/v1"
+	"google.golang.org/api/impersonate"
 	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
 )
 
 // TF and GCP defines multiple environment variables for this
@@ -31,6 +36,9 @@ func getProjectId() string {
 type GCPProvider struct {
 	UniqueCorrelationId uuid.UUID
 	ProjectId           string
+	httpClient          *http.Client
+	// baseTransport is the transport of httpClient beneath its credentials, when NewGCPProvider built it
+	baseTransport http.RoundTripper
 }
 
 // GCPProviderOption configures optional overrides on a GCPProvider.
@@ -42,6 +50,12 @@ func WithGCPProjectID(projectId string) GCPProviderOption {
 	return func(p *GCPProvider) { p.ProjectId = projectId }
 }
 
+// WithGCPHTTPClient sends the requests of GCP clients through an explicit HTTP client, which is then
+// responsible for authenticating them
+func WithGCPHTTPClient(client *http.Client) GCPProviderOption {
+	return func(p *GCPProvider) { p.httpClient = client }
+}
+
 func NewGCPProvider(correlationId uuid.UUID, opts ...GCPProviderOption) *GCPProvider {
 	p := &GCPProvider{
 		UniqueCorrelationId: correlationId,
@@ -50,13 +64,80 @@ func NewGCPProvider(correlationId uuid.UUID, opts ...GCPProviderOption) *GCPProv
 	for _, opt := range opts {
 		opt(p)
 	}
+
+	if p.httpClient == nil {
+		// Trace the calls as children of the span of the runner operation, if any. Without credentials,
+		// GCP clients are left to report the error when they are created.
+		base := otelhttp.NewTransport(http.DefaultTransport)
+		userAgent := useragent.GetStratusUserAgentForUUID(correlationId)
+		transport, err := htransport.NewTransport(context.Background(), userAgentTransport{userAgent: userAgent, base: base},
+			option.WithScopes(iam.CloudPlatformScope), option.WithTelemetryDisabled())
+		if err == nil {
+			p.httpClient = &http.Client{Transport: transport}
+			p.baseTransport = base
+		}
+	}
 	return p
 }
 
 func (m *GCPProvider) Options() option.ClientOption {
+	if m.httpClient != nil {
+		return option.WithHTTPClient(m.httpClient)
+	}
 	return option.WithUserAgent(useragent.GetStratusUserAgentForUUID(m.UniqueCorrelationId))
 }
 
+// InProject returns a provider with the credentials of m, for another project
+func (m *GCPProvider) InProject(projectID string) *GCPProvider {
+	provider := *m
+	provider.ProjectId = projectID
+	return &provider
+}
+
+// Impersonate returns a provider for the same project, authenticated as a service account impersonated with
+// the credentials of m. Each of the delegates, if any, must be allowed to impersonate the next one, and the
+// last one the service account.
+func (m *GCPProvider) Impersonate(ctx context.Context, serviceAccount string, delegates []string) (*GCPProvider, error) {
+	tokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: serviceAccount,
+		Delegates:       delegates,
+		Scopes:          []string{iam.CloudPlatformScope},
+	}, m.Options())
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport
+	if m.baseTransport != nil {
+		transport = m.baseTransport
+	} else if m.httpClient != nil && m.httpClient.Transport != nil {
+		transport = m.httpClient.Transport
+	}
+	// Requests must only carry the token of the service account, not the one of m
+	if authenticated, isAuthenticated := transport.(*oauth2.Transport); isAuthenticated {
+		transport = authenticated.Base
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+	}
+	userAgent := useragent.GetStratusUserAgentForUUID(m.UniqueCorrelationId)
+	return NewGCPProvider(m.UniqueCorrelationId, WithGCPProjectID(m.ProjectId), WithGCPHTTPClient(&http.Client{
+		Transport: &oauth2.Transport{Source: tokenSource, Base: userAgentTransport{userAgent: userAgent, base: transport}},
+	})), nil
+}
+
+// userAgentTransport sets the user-agent of requests, which GCP clients do not when given an HTTP client
+type userAgentTransport struct {
+	userAgent string
+	base      http.RoundTripper
+}
+
+func (m userAgentTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.Header.Set("User-Agent", m.userAgent)
+	return m.base.RoundTrip(request)
+}
+
 func (m *GCPProvider) IsAuthenticated() bool {
 	_, err := iam.NewService(context.Background())
 	return err == nil && m.ProjectId != ""
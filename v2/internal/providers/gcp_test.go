@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/useragent"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// writeServiceAccountKey writes the key of a service account whose tokens are issued by tokenURL
+func writeServiceAccountKey(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	encodedKey, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	serviceAccountKey, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "my-project",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encodedKey})),
+		"client_email":   "stratus@my-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, serviceAccountKey, 0o600))
+	return path
+}
+
+// recordSpans records the spans of the global tracer provider, which instrumented HTTP clients use
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousTracerProvider) })
+	return recorder
+}
+
+func TestGCPProviderTracesAuthenticatedRequests(t *testing.T) {
+	recorder := recordSpans(t)
+
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"access_token":"my-token","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		headers <- r.Header.Clone()
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", writeServiceAccountKey(t, server.URL+"/token"))
+
+	correlationID := uuid.New()
+	client, _, err := htransport.NewClient(context.Background(), NewGCPProvider(correlationID, WithGCPProjectID("my-project")).Options())
+	require.NoError(t, err)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/projects/my-project", nil)
+	require.NoError(t, err)
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	parent.End()
+
+	sent := <-headers
+	assert.Equal(t, useragent.GetStratusUserAgentForUUID(correlationID), sent.Get("User-Agent"))
+	assert.Equal(t, "Bearer my-token", sent.Get("Authorization"))
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, "HTTP GET", spans[0].Name())
+}
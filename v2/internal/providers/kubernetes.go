@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"net/http"
 	"os"
 	"path/filepath"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/useragent"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	authv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,7 +26,7 @@ const (
 )
 
 type K8sProvider struct {
-	k8sClient           *kubernetes.Clientset
+	k8sClient           kubernetes.Interface
 	RestConfig          *rest.Config
 	UniqueCorrelationId uuid.UUID // unique value injected in the user-agent, to differentiate Stratus Red Team executions
 }
@@ -43,6 +45,11 @@ func WithK8sRestConfig(cfg *rest.Config) K8sProviderOption {
 	return func(p *K8sProvider) { p.RestConfig = cfg }
 }
 
+// WithK8sClient overrides the client built from the REST configuration, e.g. with a fake clientset
+func WithK8sClient(client kubernetes.Interface) K8sProviderOption {
+	return func(p *K8sProvider) { p.k8sClient = client }
+}
+
 func NewK8sProvider(correlationId uuid.UUID, opts ...K8sProviderOption) *K8sProvider {
 	p := &K8sProvider{UniqueCorrelationId: correlationId}
 	for _, opt := range opts {
@@ -58,11 +65,18 @@ func NewK8sProvider(correlationId uuid.UUID, opts ...K8sProviderOption) *K8sProv
 	}
 
 	p.RestConfig.UserAgent = useragent.GetStratusUserAgentForUUID(correlationId)
-	k8sClient, err := kubernetes.NewForConfig(p.RestConfig)
-	if err != nil {
-		log.Fatalf("unable to create kube client: %v", err)
+	if p.k8sClient == nil {
+		// Trace the calls as children of the span of the runner operation, if any
+		clientConfig := rest.CopyConfig(p.RestConfig)
+		clientConfig.Wrap(func(transport http.RoundTripper) http.RoundTripper {
+			return otelhttp.NewTransport(transport)
+		})
+		k8sClient, err := kubernetes.NewForConfig(clientConfig)
+		if err != nil {
+			log.Fatalf("unable to create kube client: %v", err)
+		}
+		p.k8sClient = k8sClient
 	}
-	p.k8sClient = k8sClient
 
 	return p
 }
@@ -110,8 +124,22 @@ func buildKubeRestConfig() (*rest.Config, error) {
 	).ClientConfig()
 }
 
+// GetKubeCurrentContext returns the name of the current context of the kubeconfig, resolved the same
+// way as buildKubeRestConfig does. It is empty when relying on in-cluster credentials.
+func GetKubeCurrentContext() (string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err != nil {
+		return "", err
+	}
+	return rawConfig.CurrentContext, nil
+}
+
 // GetClient is used to authenticate with Kubernetes and build the client from a kubeconfig
-func (m *K8sProvider) GetClient() *kubernetes.Clientset {
+func (m *K8sProvider) GetClient() kubernetes.Interface {
 	return m.k8sClient
 }
 
@@ -1,12 +1,20 @@
 package providers
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 )
 
 func writeKubeconfig(t *testing.T, dir, name, contents string) string {
@@ -96,3 +104,24 @@ users:
 	require.NoError(t, err)
 	assert.Equal(t, "https://server-b:6443", restConfig.Host)
 }
+
+func TestK8sProviderTracesRequests(t *testing.T) {
+	recorder := recordSpans(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"kind":"NamespaceList","apiVersion":"v1","items":[]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewK8sProvider(uuid.New(), WithK8sRestConfig(&rest.Config{Host: server.URL}))
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := provider.GetClient().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, "HTTP GET", spans[0].Name())
+	assert.Empty(t, provider.GetRestConfig().WrapTransport, "only the client is traced")
+}
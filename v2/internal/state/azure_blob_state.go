@@ -0,0 +1,435 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+)
+
+// AzureBlobBackendConfig holds the configuration for storing state in an Azure Blob Storage container.
+type AzureBlobBackendConfig struct {
+	AccountName   string
+	ContainerName string
+	// KeyPrefix is prepended to all blob names. Defaults to "stratus/" if empty.
+	KeyPrefix string
+	// ServiceURL is the blob endpoint of the storage account. Defaults to
+	// https://{AccountName}.blob.core.windows.net/, set it to e.g.
+	// https://{AccountName}.blob.core.chinacloudapi.cn/ to use another Azure cloud, or to
+	// http://127.0.0.1:10000/devstoreaccount1 to use Azurite. Terraform can only store its own
+	// state in the Azure clouds, see terraformEnvironments.
+	ServiceURL string
+	// AccountKey authenticates with the storage account key. When empty, Credential is used
+	// instead, and Terraform authenticates with Entra ID (use_azuread_auth).
+	AccountKey string
+	// Credential authenticates with Entra ID. Defaults to azidentity.DefaultAzureCredential.
+	Credential azcore.TokenCredential
+}
+
+// AzureBlobStateManager stores technique state (lifecycle, outputs, variables) in Azure Blob
+// Storage while keeping Terraform source files on the local filesystem. It also injects a
+// backend.tf that points Terraform's own state at the same container.
+//
+// Unlike S3StateManager, it has no flat layout to migrate from, since it was introduced after
+// executions were isolated.
+type AzureBlobStateManager struct {
+	config                AzureBlobBackendConfig
+	client                *azblob.Client
+	technique             *stratus.AttackTechnique
+	rootDirectory         string
+	fileSystem            FileSystem
+	executionSubdirectory string
+	readOnly              bool
+}
+
+func NewAzureBlobStateManager(technique *stratus.AttackTechnique, cfg AzureBlobBackendConfig, opts ...ManagerOption) (*AzureBlobStateManager, error) {
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "stratus/"
+	}
+	if cfg.ServiceURL == "" {
+		cfg.ServiceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", cfg.AccountName)
+	}
+
+	client, err := newAzureBlobClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Azure Blob Storage client: %w", err)
+	}
+
+	homeDirectory, _ := os.UserHomeDir()
+	settings := buildManagerSettings(opts)
+	sm := &AzureBlobStateManager{
+		config:                cfg,
+		client:                client,
+		technique:             technique,
+		rootDirectory:         filepath.Join(homeDirectory, config.StratusBaseDirectoryName),
+		fileSystem:            &LocalFileSystem{},
+		executionSubdirectory: settings.executionSubdirectory,
+		readOnly:              settings.readOnly,
+	}
+	sm.Initialize()
+	return sm, nil
+}
+
+func newAzureBlobClient(cfg AzureBlobBackendConfig) (*azblob.Client, error) {
+	if cfg.AccountKey != "" {
+		credential, err := azblob.NewSharedKeyCredential(cfg.AccountName, cfg.AccountKey)
+		if err != nil {
+			return nil, err
+		}
+		return azblob.NewClientWithSharedKeyCredential(cfg.ServiceURL, credential, nil)
+	}
+	credential := cfg.Credential
+	if credential == nil {
+		defaultCredential, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		credential = defaultCredential
+	}
+	return azblob.NewClient(cfg.ServiceURL, credential, nil)
+}
+
+func (m *AzureBlobStateManager) Initialize() {
+	if m.readOnly {
+		return
+	}
+
+	if !m.fileSystem.FileExists(m.rootDirectory) {
+		log.Println("Creating " + m.rootDirectory + " as it doesn't exist yet")
+		err := m.fileSystem.CreateDirectory(m.rootDirectory, 0744)
+		if err != nil {
+			panic("Unable to create persistent directory: " + err.Error())
+		}
+	}
+}
+
+// ensureWorkingDirectory creates this execution's local Terraform working directory.
+func (m *AzureBlobStateManager) ensureWorkingDirectory() {
+	if m.fileSystem.FileExists(m.workingDirectory()) {
+		return
+	}
+	if err := m.fileSystem.CreateDirectory(m.workingDirectory(), 0744); err != nil {
+		panic("Unable to create persistent directory: " + err.Error())
+	}
+}
+
+func (m *AzureBlobStateManager) GetRootDirectory() string {
+	return m.rootDirectory
+}
+
+func (m *AzureBlobStateManager) GetWorkingDirectory() string {
+	return m.workingDirectory()
+}
+
+func (m *AzureBlobStateManager) ExtractTechnique() error {
+	m.ensureWorkingDirectory()
+	dir := m.workingDirectory()
+
+	if err := writeSharedTerraformFiles(m.fileSystem, dir, m.technique); err != nil {
+		return err
+	}
+
+	if len(m.technique.PrerequisitesTerraformCode) == 0 {
+		return nil // Terraform does not run, so has no state to store
+	}
+	environment, err := m.terraformEnvironment()
+	if err != nil {
+		return err
+	}
+
+	// Write backend.tf pointing Terraform state at the container. Credentials are NOT written here,
+	// they are passed via -backend-config flags during terraform init.
+	backendTf := fmt.Sprintf(`terraform {
+  backend "azurerm" {
+    environment          = %q
+    storage_account_name = %q
+    container_name       = %q
+    key                  = %q
+  }
+}
+`, environment, m.config.AccountName, m.config.ContainerName, m.blobName(terraformStateArtifact.S3Key))
+
+	return m.fileSystem.WriteFile(filepath.Join(dir, "backend.tf"), []byte(backendTf), 0644)
+}
+
+func (m *AzureBlobStateManager) CleanupTechnique() error {
+	// Delete this execution's blobs.
+	for _, artifact := range stateArtifacts {
+		name := m.blobName(artifact.S3Key)
+		_, err := m.client.DeleteBlob(context.Background(), m.config.ContainerName, name, nil)
+		if err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+			log.Warnf("failed to delete %s/%s/%s: %v", m.config.ServiceURL, m.config.ContainerName, name, err)
+		}
+	}
+
+	// Remove the local working directory
+	return removeWorkingDirectory(m.fileSystem, m.techniqueDirectory(), m.executionSubdirectory)
+}
+
+func (m *AzureBlobStateManager) GetTechniqueState() stratus.AttackTechniqueState {
+	data, err := m.blobGet(m.blobName(techniqueStateArtifact.S3Key))
+	if err != nil {
+		return ""
+	}
+	return stratus.AttackTechniqueState(data)
+}
+
+func (m *AzureBlobStateManager) SetTechniqueState(state stratus.AttackTechniqueState) error {
+	return m.blobPut(m.blobName(techniqueStateArtifact.S3Key), []byte(state))
+}
+
+func (m *AzureBlobStateManager) GetTerraformOutputs() (map[string]string, error) {
+	return m.getJSONMap(m.blobName(terraformOutputsArtifact.S3Key))
+}
+
+func (m *AzureBlobStateManager) WriteTerraformOutputs(outputs map[string]string) error {
+	return m.putJSONMap(m.blobName(terraformOutputsArtifact.S3Key), outputs)
+}
+
+func (m *AzureBlobStateManager) GetTerraformVariables() (map[string]string, error) {
+	return m.getJSONMap(m.blobName(terraformVariablesArtifact.S3Key))
+}
+
+func (m *AzureBlobStateManager) WriteTerraformVariables(variables map[string]string) error {
+	return m.putJSONMap(m.blobName(terraformVariablesArtifact.S3Key), variables)
+}
+
+// BackendConfigs returns the -backend-config key=value pairs that the TerraformManager should
+// pass during terraform init, containing the storage account credentials.
+func (m *AzureBlobStateManager) BackendConfigs() map[string]string {
+	if m.config.AccountKey != "" {
+		return map[string]string{"access_key": m.config.AccountKey}
+	}
+	return map[string]string{"use_azuread_auth": "true"}
+}
+
+// terraformEnvironments maps the blob endpoint suffix of each Azure cloud to the environment of the
+// Terraform azurerm backend, which builds the blob endpoint from the account name and the environment
+// rather than taking an endpoint.
+var terraformEnvironments = map[string]string{
+	".blob.core.windows.net":       "public",
+	".blob.core.chinacloudapi.cn":  "china",
+	".blob.core.usgovcloudapi.net": "usgovernment",
+	".blob.core.cloudapi.de":       "german",
+}
+
+// terraformEnvironment returns the environment in which the Terraform azurerm backend reaches the
+// service URL, or an error if it cannot, for instance with Azurite.
+func (m *AzureBlobStateManager) terraformEnvironment() (string, error) {
+	serviceURL, err := url.Parse(m.config.ServiceURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid Azure Blob Storage service URL %s: %w", m.config.ServiceURL, err)
+	}
+	for suffix, environment := range terraformEnvironments {
+		if serviceURL.Hostname() == m.config.AccountName+suffix {
+			return environment, nil
+		}
+	}
+	return "", fmt.Errorf("the Terraform azurerm backend cannot store the state of %s at %s, as it only reaches the blob endpoint of %s in an Azure cloud, e.g. https://%s.blob.core.windows.net/", m.technique.ID, m.config.ServiceURL, m.config.AccountName, m.config.AccountName)
+}
+
+// blobName builds the full name of a technique artifact blob.
+// Mirrors the local filesystem layout: {prefix}{technique-id}[/{execution}]/{artifact}
+func (m *AzureBlobStateManager) blobName(artifact string) string {
+	prefix := m.config.KeyPrefix + m.technique.ID + "/"
+	if m.executionSubdirectory != "" {
+		prefix += m.executionSubdirectory + "/"
+	}
+	return prefix + artifact
+}
+
+// techniqueDirectory is the local directory shared by every execution of the technique.
+func (m *AzureBlobStateManager) techniqueDirectory() string {
+	return filepath.Join(m.rootDirectory, m.technique.ID)
+}
+
+func (m *AzureBlobStateManager) workingDirectory() string {
+	return filepath.Join(m.techniqueDirectory(), m.executionSubdirectory)
+}
+
+func (m *AzureBlobStateManager) blobGet(name string) ([]byte, error) {
+	response, err := m.client.DownloadStream(context.Background(), m.config.ContainerName, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	return io.ReadAll(response.Body)
+}
+
+func (m *AzureBlobStateManager) blobPut(name string, data []byte) error {
+	_, err := m.client.UploadBuffer(context.Background(), m.config.ContainerName, name, data, nil)
+	return err
+}
+
+func (m *AzureBlobStateManager) getJSONMap(name string) (map[string]string, error) {
+	data, err := m.blobGet(name)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		// Same behavior as FileSystemStateManager when the file doesn't exist
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *AzureBlobStateManager) putJSONMap(name string, data map[string]string) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return m.blobPut(name, encoded)
+}
+
+func (m *AzureBlobStateManager) AppendJournalEntry(entry stratus.JournalEntry) error {
+	line, err := encodeJournalEntry(entry)
+	if err != nil {
+		return err
+	}
+	return m.blobPut(journalObjectName(m.config.KeyPrefix, entry), line)
+}
+
+func (m *AzureBlobStateManager) GetJournalEntries() ([]stratus.JournalEntry, error) {
+	var entries []stratus.JournalEntry
+	prefix := journalTechniquePrefix(m.config.KeyPrefix, m.technique.ID)
+	pager := m.client.NewListBlobsFlatPager(m.config.ContainerName, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+	for pager.More() {
+		page, err := pager.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Segment.BlobItems {
+			raw, err := m.blobGet(*item.Name)
+			if err != nil {
+				return nil, err
+			}
+			entry, err := decodeJournal(raw)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read blob %s: %w", *item.Name, err)
+			}
+			entries = append(entries, entry...)
+		}
+	}
+	sortJournal(entries)
+	return entries, nil
+}
+
+func (m *AzureBlobStateManager) GetExecutionLifetime() (*stratus.ExecutionLifetime, error) {
+	data, err := m.blobGet(m.blobName(executionLifetimeArtifact.S3Key))
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseExecutionLifetime(data)
+}
+
+func (m *AzureBlobStateManager) WriteExecutionLifetime(lifetime stratus.ExecutionLifetime) error {
+	encoded, err := json.Marshal(lifetime)
+	if err != nil {
+		return err
+	}
+	return m.blobPut(m.blobName(executionLifetimeArtifact.S3Key), encoded)
+}
+
+func (m *AzureBlobStateManager) ListExecutions() ([]string, error) {
+	techniquePrefix := m.config.KeyPrefix + m.technique.ID + "/"
+	var names []string
+	pager := m.client.NewListBlobsFlatPager(m.config.ContainerName, &azblob.ListBlobsFlatOptions{Prefix: &techniquePrefix})
+	for pager.More() {
+		page, err := pager.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Segment.BlobItems {
+			names = append(names, *item.Name)
+		}
+	}
+	return executionsFromObjectNames(techniquePrefix, names), nil
+}
+
+func (m *AzureBlobStateManager) AcquireLock(lock stratus.StateLock) error {
+	return acquireLock(m, m.technique.ID, lock)
+}
+
+func (m *AzureBlobStateManager) ReleaseLock(lockID string) error {
+	return releaseLock(m, lockID)
+}
+
+func (m *AzureBlobStateManager) GetLock() (*stratus.StateLock, error) {
+	return getLock(m)
+}
+
+func (m *AzureBlobStateManager) BreakLock() error {
+	return breakLock(m)
+}
+
+// The Azure lock is a blob written with access conditions, versioned by its ETag.
+
+func (m *AzureBlobStateManager) readLock() ([]byte, string, error) {
+	response, err := m.client.DownloadStream(context.Background(), m.config.ContainerName, m.blobName(lockArtifact.S3Key), nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+	raw, err := io.ReadAll(response.Body)
+	var version string
+	if response.ETag != nil {
+		version = string(*response.ETag)
+	}
+	return raw, version, err
+}
+
+func (m *AzureBlobStateManager) createLock(raw []byte) (bool, error) {
+	return m.putLock(raw, &blob.ModifiedAccessConditions{IfNoneMatch: to.Ptr(azcore.ETagAny)})
+}
+
+func (m *AzureBlobStateManager) replaceLock(raw []byte, version string) (bool, error) {
+	return m.putLock(raw, &blob.ModifiedAccessConditions{IfMatch: to.Ptr(azcore.ETag(version))})
+}
+
+func (m *AzureBlobStateManager) putLock(raw []byte, conditions *blob.ModifiedAccessConditions) (bool, error) {
+	_, err := m.client.UploadBuffer(context.Background(), m.config.ContainerName, m.blobName(lockArtifact.S3Key), raw, &azblob.UploadBufferOptions{
+		AccessConditions: &blob.AccessConditions{ModifiedAccessConditions: conditions},
+	})
+	if bloberror.HasCode(err, bloberror.BlobAlreadyExists, bloberror.ConditionNotMet) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *AzureBlobStateManager) deleteLock(version string) error {
+	var options *azblob.DeleteBlobOptions
+	if version != "" {
+		options = &azblob.DeleteBlobOptions{AccessConditions: &blob.AccessConditions{
+			ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: to.Ptr(azcore.ETag(version))},
+		}}
+	}
+	_, err := m.client.DeleteBlob(context.Background(), m.config.ContainerName, m.blobName(lockArtifact.S3Key), options)
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ConditionNotMet) {
+		// Already released, or taken over since
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Well-known credentials of the Azurite emulator.
+const (
+	azuriteAccountName = "devstoreaccount1"
+	azuriteAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// envVarAzuriteBlobEndpoint runs these tests against Azurite rather than an in-process fake,
+// e.g. AZURITE_BLOB_ENDPOINT=http://127.0.0.1:10000/devstoreaccount1
+const envVarAzuriteBlobEndpoint = "AZURITE_BLOB_ENDPOINT"
+
+// fakeBlobServer implements the subset of the Blob Storage REST API used by AzureBlobStateManager,
+// with Azurite's path-style URLs: /{account}/{container}/{blob}
+type fakeBlobServer struct {
+	lock  sync.Mutex
+	blobs map[string][]byte // keyed by container/blob
+	etags map[string]string
+	etag  int
+}
+
+func (m *fakeBlobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+azuriteAccountName+"/")
+	_, exists := m.blobs[key]
+	switch r.Method {
+	case http.MethodPut:
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			m.fail(w, http.StatusConflict, bloberror.BlobAlreadyExists)
+			return
+		}
+		if !m.etagMatches(r, key) {
+			m.fail(w, http.StatusPreconditionFailed, bloberror.ConditionNotMet)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.etag++
+		m.blobs[key] = data
+		m.etags[key] = fmt.Sprintf(`"0x%d"`, m.etag)
+		w.Header().Set("ETag", m.etags[key])
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		if r.URL.Query().Get("comp") == "list" {
+			m.list(w, key, r.URL.Query().Get("prefix"))
+			return
+		}
+		if !exists {
+			m.fail(w, http.StatusNotFound, bloberror.BlobNotFound)
+			return
+		}
+		w.Header().Set("ETag", m.etags[key])
+		_, _ = w.Write(m.blobs[key])
+	case http.MethodDelete:
+		if !exists {
+			m.fail(w, http.StatusNotFound, bloberror.BlobNotFound)
+			return
+		}
+		if !m.etagMatches(r, key) {
+			m.fail(w, http.StatusPreconditionFailed, bloberror.ConditionNotMet)
+			return
+		}
+		delete(m.blobs, key)
+		delete(m.etags, key)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "not implemented by the fake Blob Storage server", http.StatusNotImplemented)
+	}
+}
+
+// list answers a List Blobs request on a container, in a single page.
+func (m *fakeBlobServer) list(w http.ResponseWriter, container string, prefix string) {
+	var names []string
+	for key := range m.blobs {
+		if name, found := strings.CutPrefix(key, container+"/"); found && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var blobs strings.Builder
+	for _, name := range names {
+		blobs.WriteString("<Blob><Name>" + html.EscapeString(name) + "</Name><Properties></Properties></Blob>")
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults ContainerName="%s"><Prefix>%s</Prefix><Blobs>%s</Blobs><NextMarker/></EnumerationResults>`,
+		html.EscapeString(container), html.EscapeString(prefix), blobs.String())
+}
+
+func (m *fakeBlobServer) etagMatches(r *http.Request, key string) bool {
+	expected := r.Header.Get("If-Match")
+	return expected == "" || expected == m.etags[key]
+}
+
+func (m *fakeBlobServer) fail(w http.ResponseWriter, status int, code bloberror.Code) {
+	w.Header().Set("x-ms-error-code", string(code))
+	w.WriteHeader(status)
+}
+
+// newTestAzureBlobStateManager returns a state manager backed by Azurite when
+// AZURITE_BLOB_ENDPOINT is set, and by an in-process fake otherwise.
+func newTestAzureBlobStateManager(t *testing.T, opts ...ManagerOption) *AzureBlobStateManager {
+	serviceURL := os.Getenv(envVarAzuriteBlobEndpoint)
+	if serviceURL == "" {
+		server := httptest.NewServer(&fakeBlobServer{blobs: map[string][]byte{}, etags: map[string]string{}})
+		t.Cleanup(server.Close)
+		serviceURL = server.URL + "/" + azuriteAccountName
+	}
+	technique := &stratus.AttackTechnique{
+		ID:                         "azure.test.technique",
+		PrerequisitesTerraformCode: []byte("resource {}"),
+	}
+	sm, err := NewAzureBlobStateManager(technique, AzureBlobBackendConfig{
+		AccountName:   azuriteAccountName,
+		ContainerName: "stratus-state",
+		ServiceURL:    serviceURL,
+		AccountKey:    azuriteAccountKey,
+	}, opts...)
+	require.Nil(t, err)
+
+	if os.Getenv(envVarAzuriteBlobEndpoint) != "" {
+		_, err := sm.client.CreateContainer(context.Background(), sm.config.ContainerName, nil)
+		if err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+			require.Nil(t, err)
+		}
+	}
+	return sm
+}
+
+func (m *AzureBlobStateManager) blobExists(name string) bool {
+	_, err := m.blobGet(name)
+	return err == nil
+}
+
+func TestAzureBlobStateManagerExtractTechniqueWritesBackendTf(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t)
+	// Terraform only reaches the blob endpoints of the Azure clouds
+	sm.config.ServiceURL = "https://devstoreaccount1.blob.core.windows.net/"
+
+	err := sm.ExtractTechnique()
+	assert.Nil(t, err)
+
+	backendTf, err := sm.fileSystem.ReadFile(sm.workingDirectory() + "/backend.tf")
+	assert.Nil(t, err)
+	assert.Contains(t, string(backendTf), `backend "azurerm"`)
+	assert.Contains(t, string(backendTf), `environment          = "public"`)
+	assert.Contains(t, string(backendTf), `storage_account_name = "devstoreaccount1"`)
+	assert.Contains(t, string(backendTf), `container_name       = "stratus-state"`)
+	assert.Contains(t, string(backendTf), `key                  = "stratus/azure.test.technique/terraform.tfstate"`)
+	assert.NotContains(t, string(backendTf), azuriteAccountKey, "credentials must not be written to disk")
+
+	mainTf, err := sm.fileSystem.ReadFile(sm.workingDirectory() + "/main.tf")
+	assert.Nil(t, err)
+	assert.Equal(t, "resource {}", string(mainTf))
+}
+
+func TestAzureBlobStateManagerExtractTechniqueInSovereignCloud(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t)
+	sm.config.ServiceURL = "https://devstoreaccount1.blob.core.chinacloudapi.cn/"
+
+	require.Nil(t, sm.ExtractTechnique())
+
+	backendTf, err := sm.fileSystem.ReadFile(sm.workingDirectory() + "/backend.tf")
+	require.Nil(t, err)
+	assert.Contains(t, string(backendTf), `environment          = "china"`)
+}
+
+func TestAzureBlobStateManagerExtractTechniqueRefusesEndpointsUnreachableByTerraform(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t)
+
+	err := sm.ExtractTechnique()
+	assert.ErrorContains(t, err, "the Terraform azurerm backend cannot store the state of azure.test.technique at "+sm.config.ServiceURL)
+	assert.False(t, sm.fileSystem.FileExists(sm.workingDirectory()+"/backend.tf"))
+
+	// Stratus itself still stores state at the endpoint, which is enough for techniques without prerequisites
+	sm.technique = &stratus.AttackTechnique{ID: "azure.test.technique"}
+	require.Nil(t, sm.ExtractTechnique())
+	require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+	assert.Equal(t, stratus.AttackTechniqueState(stratus.AttackTechniqueStatusWarm), sm.GetTechniqueState())
+}
+
+func TestAzureBlobStateManagerRoundTrip(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t, WithExecutionSubdirectory(t.Name()))
+
+	// Nothing stored yet
+	assert.Equal(t, stratus.AttackTechniqueState(""), sm.GetTechniqueState())
+	outputs, err := sm.GetTerraformOutputs()
+	assert.Nil(t, err)
+	assert.Empty(t, outputs)
+
+	require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusDetonated))
+	require.Nil(t, sm.WriteTerraformOutputs(map[string]string{"storage_account_name": "foo"}))
+	require.Nil(t, sm.WriteTerraformVariables(map[string]string{"correlation": "{}"}))
+
+	assert.Equal(t, stratus.AttackTechniqueState(stratus.AttackTechniqueStatusDetonated), sm.GetTechniqueState())
+	outputs, err = sm.GetTerraformOutputs()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"storage_account_name": "foo"}, outputs)
+	variables, err := sm.GetTerraformVariables()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"correlation": "{}"}, variables)
+	assert.True(t, sm.blobExists("stratus/azure.test.technique/"+t.Name()+"/state"))
+
+	require.Nil(t, sm.CleanupTechnique())
+	assert.False(t, sm.blobExists("stratus/azure.test.technique/"+t.Name()+"/state"))
+	assert.False(t, sm.blobExists("stratus/azure.test.technique/"+t.Name()+"/outputs.json"))
+	assert.Equal(t, stratus.AttackTechniqueState(""), sm.GetTechniqueState())
+}
+
+func TestAzureBlobStateManagerBackendConfigs(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t)
+
+	assert.Equal(t, map[string]string{"access_key": azuriteAccountKey}, sm.BackendConfigs())
+
+	sm.config.AccountKey = ""
+	assert.Equal(t, map[string]string{"use_azuread_auth": "true"}, sm.BackendConfigs())
+}
+
+func TestAzureBlobStateManagerDefaultServiceURL(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "azure.test.technique"}
+	sm, err := NewAzureBlobStateManager(technique, AzureBlobBackendConfig{
+		AccountName:   "mystorageaccount",
+		ContainerName: "stratus-state",
+		AccountKey:    azuriteAccountKey,
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, "https://mystorageaccount.blob.core.windows.net/", sm.config.ServiceURL)
+	assert.Equal(t, "stratus/", sm.config.KeyPrefix)
+}
+
+func TestAzureBlobStateManagerLock(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t, WithExecutionSubdirectory(t.Name()))
+	// Another operator, sharing the same container
+	other := &AzureBlobStateManager{config: sm.config, client: sm.client, technique: sm.technique, executionSubdirectory: t.Name()}
+
+	testStateManagerLock(t, sm, other)
+}
+
+func TestAzureBlobStateManagerJournal(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t, WithExecutionSubdirectory(t.Name()))
+
+	testStateManagerJournal(t, sm, "azure.test.technique")
+}
+
+func TestAzureBlobStateManagerLifetime(t *testing.T) {
+	isolateHome(t)
+	sm := newTestAzureBlobStateManager(t)
+
+	testStateManagerLifetime(t, func(executionSubdirectory string) StateManager {
+		return &AzureBlobStateManager{config: sm.config, client: sm.client, technique: sm.technique, rootDirectory: sm.rootDirectory, fileSystem: sm.fileSystem, executionSubdirectory: executionSubdirectory}
+	})
+}
@@ -0,0 +1,406 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"cloud.google.com/go/storage"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// GCSBackendConfig holds the configuration for storing state in a Google Cloud Storage bucket.
+type GCSBackendConfig struct {
+	BucketName string
+	// KeyPrefix is prepended to all object names. Defaults to "stratus/" if empty.
+	KeyPrefix string
+	// CredentialsFile is the path to a service account key file. When empty, both Stratus and
+	// Terraform use Application Default Credentials.
+	CredentialsFile string
+	// ClientOptions are passed to the storage client, for instance option.WithEndpoint to use
+	// a local fake GCS server. The client also honors STORAGE_EMULATOR_HOST.
+	ClientOptions []option.ClientOption
+}
+
+// gcsTerraformStateObject is where the Terraform gcs backend writes its state, under the
+// configured prefix, when using the default workspace.
+const gcsTerraformStateObject = "terraform/default.tfstate"
+
+// GCSStateManager stores technique state (lifecycle, outputs, variables) in GCS while keeping
+// Terraform source files on the local filesystem. It also injects a backend.tf that points
+// Terraform's own state at the same bucket.
+//
+// Unlike S3StateManager, it has no flat layout to migrate from, since it was introduced after
+// executions were isolated.
+type GCSStateManager struct {
+	config                GCSBackendConfig
+	bucket                *storage.BucketHandle
+	technique             *stratus.AttackTechnique
+	rootDirectory         string
+	fileSystem            FileSystem
+	executionSubdirectory string
+	readOnly              bool
+}
+
+func NewGCSStateManager(technique *stratus.AttackTechnique, cfg GCSBackendConfig, opts ...ManagerOption) (*GCSStateManager, error) {
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "stratus/"
+	}
+
+	clientOptions := cfg.ClientOptions
+	if cfg.CredentialsFile != "" {
+		clientOptions = append(clientOptions, option.WithAuthCredentialsFile(option.ServiceAccount, cfg.CredentialsFile))
+	}
+	client, err := storage.NewClient(context.Background(), clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCS client: %w", err)
+	}
+
+	homeDirectory, _ := os.UserHomeDir()
+	settings := buildManagerSettings(opts)
+	sm := &GCSStateManager{
+		config:                cfg,
+		bucket:                client.Bucket(cfg.BucketName),
+		technique:             technique,
+		rootDirectory:         filepath.Join(homeDirectory, config.StratusBaseDirectoryName),
+		fileSystem:            &LocalFileSystem{},
+		executionSubdirectory: settings.executionSubdirectory,
+		readOnly:              settings.readOnly,
+	}
+	sm.Initialize()
+	return sm, nil
+}
+
+func (m *GCSStateManager) Initialize() {
+	if m.readOnly {
+		return
+	}
+
+	if !m.fileSystem.FileExists(m.rootDirectory) {
+		log.Println("Creating " + m.rootDirectory + " as it doesn't exist yet")
+		err := m.fileSystem.CreateDirectory(m.rootDirectory, 0744)
+		if err != nil {
+			panic("Unable to create persistent directory: " + err.Error())
+		}
+	}
+}
+
+// ensureWorkingDirectory creates this execution's local Terraform working directory.
+func (m *GCSStateManager) ensureWorkingDirectory() {
+	if m.fileSystem.FileExists(m.workingDirectory()) {
+		return
+	}
+	if err := m.fileSystem.CreateDirectory(m.workingDirectory(), 0744); err != nil {
+		panic("Unable to create persistent directory: " + err.Error())
+	}
+}
+
+func (m *GCSStateManager) GetRootDirectory() string {
+	return m.rootDirectory
+}
+
+func (m *GCSStateManager) GetWorkingDirectory() string {
+	return m.workingDirectory()
+}
+
+func (m *GCSStateManager) ExtractTechnique() error {
+	m.ensureWorkingDirectory()
+	dir := m.workingDirectory()
+
+	if err := writeSharedTerraformFiles(m.fileSystem, dir, m.technique); err != nil {
+		return err
+	}
+
+	// Write backend.tf pointing Terraform state at the GCS bucket. Credentials are NOT written here,
+	// they are passed via -backend-config flags during terraform init.
+	backendTf := fmt.Sprintf(`terraform {
+  backend "gcs" {
+    bucket = %q
+    prefix = %q
+  }
+}
+`, m.config.BucketName, m.objectPrefix()+"terraform")
+
+	return m.fileSystem.WriteFile(filepath.Join(dir, "backend.tf"), []byte(backendTf), 0644)
+}
+
+func (m *GCSStateManager) CleanupTechnique() error {
+	// Delete this execution's objects.
+	for _, artifact := range stateArtifacts {
+		name := m.objectName(artifact)
+		err := m.bucket.Object(name).Delete(context.Background())
+		if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			log.Warnf("failed to delete gs://%s/%s: %v", m.config.BucketName, name, err)
+		}
+	}
+
+	// Remove the local working directory
+	return removeWorkingDirectory(m.fileSystem, m.techniqueDirectory(), m.executionSubdirectory)
+}
+
+func (m *GCSStateManager) GetTechniqueState() stratus.AttackTechniqueState {
+	data, err := m.gcsGet(m.objectName(techniqueStateArtifact))
+	if err != nil {
+		return ""
+	}
+	return stratus.AttackTechniqueState(data)
+}
+
+func (m *GCSStateManager) SetTechniqueState(state stratus.AttackTechniqueState) error {
+	return m.gcsPut(m.objectName(techniqueStateArtifact), []byte(state))
+}
+
+func (m *GCSStateManager) GetTerraformOutputs() (map[string]string, error) {
+	return m.getJSONMap(m.objectName(terraformOutputsArtifact))
+}
+
+func (m *GCSStateManager) WriteTerraformOutputs(outputs map[string]string) error {
+	return m.putJSONMap(m.objectName(terraformOutputsArtifact), outputs)
+}
+
+func (m *GCSStateManager) GetTerraformVariables() (map[string]string, error) {
+	return m.getJSONMap(m.objectName(terraformVariablesArtifact))
+}
+
+func (m *GCSStateManager) WriteTerraformVariables(variables map[string]string) error {
+	return m.putJSONMap(m.objectName(terraformVariablesArtifact), variables)
+}
+
+// BackendConfigs returns the -backend-config key=value pairs that the TerraformManager should
+// pass during terraform init. Without a credentials file, Terraform falls back to Application
+// Default Credentials, just like the storage client.
+func (m *GCSStateManager) BackendConfigs() map[string]string {
+	if m.config.CredentialsFile == "" {
+		return nil
+	}
+	return map[string]string{"credentials": m.config.CredentialsFile}
+}
+
+// objectName builds the full object name of a technique artifact.
+// Mirrors the local filesystem layout: {prefix}{technique-id}[/{execution}]/{artifact}
+func (m *GCSStateManager) objectName(artifact stateArtifact) string {
+	if artifact == terraformStateArtifact {
+		// Named by the Terraform gcs backend, which only lets us pick its prefix
+		return m.objectPrefix() + gcsTerraformStateObject
+	}
+	return m.objectPrefix() + artifact.S3Key
+}
+
+// objectPrefix is the prefix of this execution's objects, trailing slash included.
+func (m *GCSStateManager) objectPrefix() string {
+	prefix := m.config.KeyPrefix + m.technique.ID + "/"
+	if m.executionSubdirectory != "" {
+		prefix += m.executionSubdirectory + "/"
+	}
+	return prefix
+}
+
+// techniqueDirectory is the local directory shared by every execution of the technique.
+func (m *GCSStateManager) techniqueDirectory() string {
+	return filepath.Join(m.rootDirectory, m.technique.ID)
+}
+
+func (m *GCSStateManager) workingDirectory() string {
+	return filepath.Join(m.techniqueDirectory(), m.executionSubdirectory)
+}
+
+func (m *GCSStateManager) gcsGet(name string) ([]byte, error) {
+	reader, err := m.bucket.Object(name).NewReader(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func (m *GCSStateManager) gcsPut(name string, data []byte) error {
+	writer := m.bucket.Object(name).NewWriter(context.Background())
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (m *GCSStateManager) getJSONMap(name string) (map[string]string, error) {
+	data, err := m.gcsGet(name)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		// Same behavior as FileSystemStateManager when the file doesn't exist
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string)
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *GCSStateManager) putJSONMap(name string, data map[string]string) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return m.gcsPut(name, encoded)
+}
+
+func (m *GCSStateManager) AppendJournalEntry(entry stratus.JournalEntry) error {
+	line, err := encodeJournalEntry(entry)
+	if err != nil {
+		return err
+	}
+	return m.gcsPut(journalObjectName(m.config.KeyPrefix, entry), line)
+}
+
+func (m *GCSStateManager) GetJournalEntries() ([]stratus.JournalEntry, error) {
+	var entries []stratus.JournalEntry
+	objects := m.bucket.Objects(context.Background(), &storage.Query{Prefix: journalTechniquePrefix(m.config.KeyPrefix, m.technique.ID)})
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, err := m.gcsGet(attrs.Name)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := decodeJournal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read gs://%s/%s: %w", m.config.BucketName, attrs.Name, err)
+		}
+		entries = append(entries, entry...)
+	}
+	sortJournal(entries)
+	return entries, nil
+}
+
+func (m *GCSStateManager) GetExecutionLifetime() (*stratus.ExecutionLifetime, error) {
+	data, err := m.gcsGet(m.objectName(executionLifetimeArtifact))
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseExecutionLifetime(data)
+}
+
+func (m *GCSStateManager) WriteExecutionLifetime(lifetime stratus.ExecutionLifetime) error {
+	encoded, err := json.Marshal(lifetime)
+	if err != nil {
+		return err
+	}
+	return m.gcsPut(m.objectName(executionLifetimeArtifact), encoded)
+}
+
+func (m *GCSStateManager) ListExecutions() ([]string, error) {
+	techniquePrefix := m.config.KeyPrefix + m.technique.ID + "/"
+	var names []string
+	objects := m.bucket.Objects(context.Background(), &storage.Query{Prefix: techniquePrefix})
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+	return executionsFromObjectNames(techniquePrefix, names), nil
+}
+
+func (m *GCSStateManager) AcquireLock(lock stratus.StateLock) error {
+	return acquireLock(m, m.technique.ID, lock)
+}
+
+func (m *GCSStateManager) ReleaseLock(lockID string) error {
+	return releaseLock(m, lockID)
+}
+
+func (m *GCSStateManager) GetLock() (*stratus.StateLock, error) {
+	return getLock(m)
+}
+
+func (m *GCSStateManager) BreakLock() error {
+	return breakLock(m)
+}
+
+// The GCS lock is an object written with preconditions, versioned by its generation.
+
+func (m *GCSStateManager) lockObject() *storage.ObjectHandle {
+	return m.bucket.Object(m.objectPrefix() + lockArtifact.S3Key)
+}
+
+func (m *GCSStateManager) readLock() ([]byte, string, error) {
+	reader, err := m.lockObject().NewReader(context.Background())
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer reader.Close()
+	raw, err := io.ReadAll(reader)
+	return raw, strconv.FormatInt(reader.Attrs.Generation, 10), err
+}
+
+func (m *GCSStateManager) createLock(raw []byte) (bool, error) {
+	return m.putLock(raw, storage.Conditions{DoesNotExist: true})
+}
+
+func (m *GCSStateManager) replaceLock(raw []byte, version string) (bool, error) {
+	generation, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return m.putLock(raw, storage.Conditions{GenerationMatch: generation})
+}
+
+func (m *GCSStateManager) putLock(raw []byte, conditions storage.Conditions) (bool, error) {
+	writer := m.lockObject().If(conditions).NewWriter(context.Background())
+	_, err := writer.Write(raw)
+	err = errors.Join(err, writer.Close())
+	if isPreconditionFailed(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *GCSStateManager) deleteLock(version string) error {
+	object := m.lockObject()
+	if version != "" {
+		generation, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return err
+		}
+		object = object.If(storage.Conditions{GenerationMatch: generation})
+	}
+	err := object.Delete(context.Background())
+	if errors.Is(err, storage.ErrObjectNotExist) || isPreconditionFailed(err) {
+		// Already released, or taken over since
+		return nil
+	}
+	return err
+}
+
+func isPreconditionFailed(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed
+}
@@ -0,0 +1,261 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGCSServer implements the subset of the GCS JSON and XML APIs used by GCSStateManager,
+// the same way a fake GCS server such as fsouza/fake-gcs-server does.
+type fakeGCSServer struct {
+	lock        sync.Mutex
+	objects     map[string][]byte // keyed by bucket/object
+	generations map[string]int64
+	generation  int64
+}
+
+// newFakeGCSServer starts a fake GCS server and points the storage client at it.
+func newFakeGCSServer(t *testing.T) *fakeGCSServer {
+	fake := &fakeGCSServer{objects: map[string][]byte{}, generations: map[string]int64{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	t.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+	return fake
+}
+
+func (m *fakeGCSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	path := r.URL.EscapedPath()
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/upload/storage/v1/b/"):
+		bucket, _, _ := strings.Cut(strings.TrimPrefix(path, "/upload/storage/v1/b/"), "/")
+		name, data, err := readMultipartUpload(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key := bucket + "/" + name
+		if !m.generationMatches(r, key) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		m.generation++
+		m.objects[key] = data
+		m.generations[key] = m.generation
+		_ = json.NewEncoder(w).Encode(map[string]any{"bucket": bucket, "name": name, "size": strconv.Itoa(len(data)), "generation": strconv.FormatInt(m.generation, 10)})
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/storage/v1/b/"):
+		key := m.objectKey(strings.TrimPrefix(path, "/storage/v1/b/"))
+		if _, found := m.objects[key]; !found {
+			http.NotFound(w, r)
+			return
+		}
+		if !m.generationMatches(r, key) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(m.objects, key)
+		delete(m.generations, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/storage/v1/b/") && strings.HasSuffix(path, "/o"):
+		bucket := strings.TrimSuffix(strings.TrimPrefix(path, "/storage/v1/b/"), "/o")
+		prefix := bucket + "/" + r.URL.Query().Get("prefix")
+		var names []string
+		for key := range m.objects {
+			if strings.HasPrefix(key, prefix) {
+				names = append(names, strings.TrimPrefix(key, bucket+"/"))
+			}
+		}
+		sort.Strings(names)
+		items := []map[string]any{}
+		for _, name := range names {
+			key := bucket + "/" + name
+			items = append(items, map[string]any{"bucket": bucket, "name": name, "size": strconv.Itoa(len(m.objects[key])), "generation": strconv.FormatInt(m.generations[key], 10)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"kind": "storage#objects", "items": items})
+	case r.Method == http.MethodGet:
+		// Media downloads go through the XML API: /{bucket}/{object}
+		bucket, object, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		name, _ := url.PathUnescape(object)
+		data, found := m.objects[bucket+"/"+name]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("x-goog-generation", strconv.FormatInt(m.generations[bucket+"/"+name], 10))
+		_, _ = w.Write(data)
+	default:
+		http.Error(w, "not implemented by the fake GCS server", http.StatusNotImplemented)
+	}
+}
+
+// generationMatches evaluates the ifGenerationMatch precondition, where 0 means that the object
+// must not exist.
+func (m *fakeGCSServer) generationMatches(r *http.Request, key string) bool {
+	raw := r.URL.Query().Get("ifGenerationMatch")
+	if raw == "" {
+		return true
+	}
+	expected, err := strconv.ParseInt(raw, 10, 64)
+	return err == nil && expected == m.generations[key]
+}
+
+// objectKey turns "{bucket}/o/{escaped object}" into "{bucket}/{object}".
+func (m *fakeGCSServer) objectKey(path string) string {
+	bucket, object, _ := strings.Cut(path, "/o/")
+	name, _ := url.PathUnescape(object)
+	return bucket + "/" + name
+}
+
+func (m *fakeGCSServer) has(bucket string, name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, found := m.objects[bucket+"/"+name]
+	return found
+}
+
+func readMultipartUpload(r *http.Request) (string, []byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", nil, err
+	}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	metadataPart, err := reader.NextPart()
+	if err != nil {
+		return "", nil, err
+	}
+	var metadata struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(metadataPart).Decode(&metadata); err != nil {
+		return "", nil, err
+	}
+	mediaPart, err := reader.NextPart()
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := io.ReadAll(mediaPart)
+	return metadata.Name, data, err
+}
+
+func newTestGCSStateManager(t *testing.T, opts ...ManagerOption) *GCSStateManager {
+	technique := &stratus.AttackTechnique{
+		ID:                         "gcp.test.technique",
+		PrerequisitesTerraformCode: []byte("resource {}"),
+	}
+	sm, err := NewGCSStateManager(technique, GCSBackendConfig{BucketName: "my-stratus-bucket"}, opts...)
+	require.Nil(t, err)
+	return sm
+}
+
+func TestGCSStateManagerExtractTechniqueWritesBackendTf(t *testing.T) {
+	isolateHome(t)
+	newFakeGCSServer(t)
+	sm := newTestGCSStateManager(t)
+
+	err := sm.ExtractTechnique()
+	assert.Nil(t, err)
+
+	backendTf, err := sm.fileSystem.ReadFile(sm.workingDirectory() + "/backend.tf")
+	assert.Nil(t, err)
+	assert.Contains(t, string(backendTf), `backend "gcs"`)
+	assert.Contains(t, string(backendTf), `bucket = "my-stratus-bucket"`)
+	assert.Contains(t, string(backendTf), `prefix = "stratus/gcp.test.technique/terraform"`)
+
+	mainTf, err := sm.fileSystem.ReadFile(sm.workingDirectory() + "/main.tf")
+	assert.Nil(t, err)
+	assert.Equal(t, "resource {}", string(mainTf))
+}
+
+func TestGCSStateManagerRoundTrip(t *testing.T) {
+	isolateHome(t)
+	fake := newFakeGCSServer(t)
+	sm := newTestGCSStateManager(t)
+
+	// Nothing stored yet
+	assert.Equal(t, stratus.AttackTechniqueState(""), sm.GetTechniqueState())
+	outputs, err := sm.GetTerraformOutputs()
+	assert.Nil(t, err)
+	assert.Empty(t, outputs)
+
+	require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+	require.Nil(t, sm.WriteTerraformOutputs(map[string]string{"bucket_name": "foo"}))
+	require.Nil(t, sm.WriteTerraformVariables(map[string]string{"correlation": "{}"}))
+
+	assert.Equal(t, stratus.AttackTechniqueState(stratus.AttackTechniqueStatusWarm), sm.GetTechniqueState())
+	outputs, err = sm.GetTerraformOutputs()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"bucket_name": "foo"}, outputs)
+	variables, err := sm.GetTerraformVariables()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"correlation": "{}"}, variables)
+	assert.True(t, fake.has("my-stratus-bucket", "stratus/gcp.test.technique/state"))
+
+	require.Nil(t, sm.CleanupTechnique())
+	assert.False(t, fake.has("my-stratus-bucket", "stratus/gcp.test.technique/state"))
+	assert.False(t, fake.has("my-stratus-bucket", "stratus/gcp.test.technique/outputs.json"))
+	assert.Equal(t, stratus.AttackTechniqueState(""), sm.GetTechniqueState())
+}
+
+func TestGCSStateManagerExecutionSubdirectory(t *testing.T) {
+	isolateHome(t)
+	fake := newFakeGCSServer(t)
+	sm := newTestGCSStateManager(t, WithExecutionSubdirectory("c0ffee"))
+
+	require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusDetonated))
+
+	assert.True(t, fake.has("my-stratus-bucket", "stratus/gcp.test.technique/c0ffee/state"))
+	assert.Equal(t, "stratus/gcp.test.technique/c0ffee/terraform/default.tfstate", sm.objectName(terraformStateArtifact))
+}
+
+func TestGCSStateManagerBackendConfigs(t *testing.T) {
+	isolateHome(t)
+	newFakeGCSServer(t)
+
+	sm := newTestGCSStateManager(t)
+	assert.Empty(t, sm.BackendConfigs(), "Terraform uses Application Default Credentials by default")
+
+	sm.config.CredentialsFile = "/path/to/key.json"
+	assert.Equal(t, map[string]string{"credentials": "/path/to/key.json"}, sm.BackendConfigs())
+}
+
+func TestGCSStateManagerLock(t *testing.T) {
+	isolateHome(t)
+	newFakeGCSServer(t)
+	sm := newTestGCSStateManager(t)
+	other := newTestGCSStateManager(t)
+
+	testStateManagerLock(t, sm, other)
+}
+
+func TestGCSStateManagerJournal(t *testing.T) {
+	isolateHome(t)
+	newFakeGCSServer(t)
+	sm := newTestGCSStateManager(t)
+
+	testStateManagerJournal(t, sm, "gcp.test.technique")
+}
+
+func TestGCSStateManagerLifetime(t *testing.T) {
+	isolateHome(t)
+	newFakeGCSServer(t)
+
+	testStateManagerLifetime(t, func(executionSubdirectory string) StateManager {
+		return newTestGCSStateManager(t, WithExecutionSubdirectory(executionSubdirectory))
+	})
+}
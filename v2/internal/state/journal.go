@@ -0,0 +1,109 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/google/uuid"
+)
+
+// The execution journal is kept apart from the state of techniques, so that cleaning them up
+// never removes it. Locally, it is a single JSONL file at the root of the state directory.
+// Object stores cannot append to an object, so remote backends write one object per entry
+// under {prefix}journal/{technique-id}/.
+const (
+	journalFileName     = "journal.jsonl"
+	journalObjectPrefix = "journal/"
+)
+
+// encodeJournalEntry encodes an entry as a single JSONL line.
+func encodeJournalEntry(entry stratus.JournalEntry) ([]byte, error) {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, '\n'), nil
+}
+
+// decodeJournal parses JSONL journal entries.
+func decodeJournal(raw []byte) ([]stratus.JournalEntry, error) {
+	var entries []stratus.JournalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(nil, 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry stratus.JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("unable to parse line %d of the journal: %w", lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// journalObjectName names the object holding a single entry, so that listing a technique's
+// objects returns its entries in chronological order.
+func journalObjectName(keyPrefix string, entry stratus.JournalEntry) string {
+	timestamp := entry.StartedAt.UTC().Format("20060102T150405.000000000Z")
+	return journalTechniquePrefix(keyPrefix, entry.TechniqueID) + timestamp + "-" + uuid.New().String() + ".json"
+}
+
+// journalTechniquePrefix is the prefix of the journal objects of a technique.
+func journalTechniquePrefix(keyPrefix string, techniqueID string) string {
+	return keyPrefix + journalObjectPrefix + techniqueID + "/"
+}
+
+// sortJournal orders entries chronologically.
+func sortJournal(entries []stratus.JournalEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedAt.Before(entries[j].StartedAt)
+	})
+}
+
+// ReadLocalJournal returns the entries of the local execution journal, for every technique,
+// oldest first.
+func ReadLocalJournal() ([]stratus.JournalEntry, error) {
+	homeDirectory, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return readFileSystemJournal(&LocalFileSystem{}, filepath.Join(homeDirectory, config.StratusBaseDirectoryName))
+}
+
+func readFileSystemJournal(fileSystem FileSystem, rootDirectory string) ([]stratus.JournalEntry, error) {
+	journalFile := filepath.Join(rootDirectory, journalFileName)
+	if !fileSystem.FileExists(journalFile) {
+		return nil, nil
+	}
+	raw, err := fileSystem.ReadFile(journalFile)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decodeJournal(raw)
+	if err != nil {
+		return nil, err
+	}
+	sortJournal(entries)
+	return entries, nil
+}
+
+// filterJournal keeps the entries of a technique.
+func filterJournal(entries []stratus.JournalEntry, techniqueID string) []stratus.JournalEntry {
+	var result []stratus.JournalEntry
+	for _, entry := range entries {
+		if entry.TechniqueID == techniqueID {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStateManagerJournal runs the same journaling scenario against any backend.
+func testStateManagerJournal(t *testing.T, sm StateManager, techniqueID string) {
+	entries, err := sm.GetJournalEntries()
+	require.Nil(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Now().UTC()
+	detonation := stratus.JournalEntry{
+		TechniqueID:            techniqueID,
+		Operation:              stratus.JournalOperationDetonate,
+		CorrelationID:          "c0ffee",
+		Caller:                 "alice@laptop",
+		StartedAt:              now.Add(time.Minute),
+		FinishedAt:             now.Add(2 * time.Minute),
+		Duration:               60,
+		StateBefore:            stratus.AttackTechniqueStatusWarm,
+		StateAfter:             stratus.AttackTechniqueStatusDetonated,
+		TerraformVariablesHash: stratus.HashTerraformVariables(map[string]string{"foo": "bar"}),
+	}
+	warmUp := stratus.JournalEntry{TechniqueID: techniqueID, Operation: stratus.JournalOperationWarmUp, StartedAt: now, Error: "boom"}
+	require.Nil(t, sm.AppendJournalEntry(detonation))
+	require.Nil(t, sm.AppendJournalEntry(warmUp))
+
+	// Cleaning up a technique keeps its history
+	require.Nil(t, sm.CleanupTechnique())
+
+	entries, err = sm.GetJournalEntries()
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, stratus.JournalOperationWarmUp, entries[0].Operation, "entries should be sorted chronologically")
+	assert.Equal(t, "boom", entries[0].Error)
+	assert.True(t, detonation.StartedAt.Equal(entries[1].StartedAt))
+	entries[1].StartedAt, entries[1].FinishedAt = detonation.StartedAt, detonation.FinishedAt
+	assert.Equal(t, detonation, entries[1])
+}
+
+func TestFileSystemStateManagerJournal(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+	sm := NewFileSystemStateManager(technique, WithExecutionSubdirectory("c0ffee"))
+
+	testStateManagerJournal(t, sm, technique.ID)
+}
+
+func TestFileSystemStateManagerJournalIsShared(t *testing.T) {
+	isolateHome(t)
+	sm := NewFileSystemStateManager(&stratus.AttackTechnique{ID: "aws.test.technique"})
+	other := NewFileSystemStateManager(&stratus.AttackTechnique{ID: "aws.test.other-technique"})
+
+	require.Nil(t, sm.AppendJournalEntry(stratus.JournalEntry{TechniqueID: "aws.test.technique", StartedAt: time.Now()}))
+	require.Nil(t, other.AppendJournalEntry(stratus.JournalEntry{TechniqueID: "aws.test.other-technique", StartedAt: time.Now()}))
+
+	entries, err := sm.GetJournalEntries()
+	require.Nil(t, err)
+	assert.Len(t, entries, 1, "a technique should only see its own entries")
+
+	all, err := ReadLocalJournal()
+	require.Nil(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestDecodeJournalRejectsInvalidLines(t *testing.T) {
+	entries, err := decodeJournal([]byte("{\"technique_id\": \"foo\"}\n\n"))
+	require.Nil(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = decodeJournal([]byte("{}\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+)
+
+func parseExecutionLifetime(raw []byte) (*stratus.ExecutionLifetime, error) {
+	var lifetime stratus.ExecutionLifetime
+	if err := json.Unmarshal(raw, &lifetime); err != nil {
+		return nil, err
+	}
+	return &lifetime, nil
+}
+
+// executionsFromObjectNames returns the executions of a technique, given the names of the objects
+// under its prefix (trailing slash included). An execution is identified by its sub-directory, or
+// "" for the flat layout, and exists as long as its state object does.
+func executionsFromObjectNames(techniquePrefix string, names []string) []string {
+	executions := []string{}
+	for _, name := range names {
+		relative, found := strings.CutPrefix(name, techniquePrefix)
+		if !found {
+			continue
+		}
+		if relative == techniqueStateArtifact.S3Key {
+			executions = append(executions, "")
+			continue
+		}
+		subdirectory, artifact, nested := strings.Cut(relative, "/")
+		if nested && artifact == techniqueStateArtifact.S3Key {
+			executions = append(executions, subdirectory)
+		}
+	}
+	sort.Strings(executions)
+	return executions
+}
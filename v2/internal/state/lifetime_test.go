@@ -0,0 +1,97 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStateManagerLifetime runs the same lifetime scenario against any backend. newManager returns
+// a state manager of the same technique, for the execution in the given sub-directory.
+func testStateManagerLifetime(t *testing.T, newManager func(executionSubdirectory string) StateManager) {
+	expiring, forever, stateless := t.Name()+"-expiring", t.Name()+"-forever", t.Name()+"-stateless"
+	sm := newManager(expiring)
+
+	lifetime, err := sm.GetExecutionLifetime()
+	require.Nil(t, err)
+	assert.Nil(t, lifetime, "no lifetime should be recorded yet")
+
+	warmedUpAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expected := stratus.NewExecutionLifetime(warmedUpAt, 2*time.Hour)
+	require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+	require.Nil(t, sm.WriteExecutionLifetime(expected))
+	lifetime, err = sm.GetExecutionLifetime()
+	require.Nil(t, err)
+	require.NotNil(t, lifetime)
+	assert.True(t, expected.WarmedUpAt.Equal(lifetime.WarmedUpAt))
+	require.NotNil(t, lifetime.ExpiresAt)
+	assert.True(t, expected.ExpiresAt.Equal(*lifetime.ExpiresAt))
+
+	require.Nil(t, newManager(forever).SetTechniqueState(stratus.AttackTechniqueStatusDetonated))
+	// An execution only exists as long as it has a state
+	require.Nil(t, newManager(stateless).WriteExecutionLifetime(expected))
+
+	executions, err := sm.ListExecutions()
+	require.Nil(t, err)
+	assert.Contains(t, executions, expiring)
+	assert.Contains(t, executions, forever)
+	assert.NotContains(t, executions, stateless)
+
+	// Cleaning up an execution removes its lifetime
+	require.Nil(t, sm.CleanupTechnique())
+	lifetime, err = sm.GetExecutionLifetime()
+	require.Nil(t, err)
+	assert.Nil(t, lifetime)
+	executions, err = sm.ListExecutions()
+	require.Nil(t, err)
+	assert.NotContains(t, executions, expiring)
+	assert.Contains(t, executions, forever)
+
+	require.Nil(t, newManager(forever).CleanupTechnique())
+	require.Nil(t, newManager(stateless).CleanupTechnique())
+}
+
+func TestFileSystemStateManagerLifetime(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+
+	testStateManagerLifetime(t, func(executionSubdirectory string) StateManager {
+		return NewFileSystemStateManager(technique, WithExecutionSubdirectory(executionSubdirectory))
+	})
+}
+
+func TestFileSystemStateManagerListsFlatExecution(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+	sm := NewFileSystemStateManager(technique)
+
+	executions, err := sm.ListExecutions()
+	require.Nil(t, err)
+	assert.Empty(t, executions)
+
+	require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+	require.Nil(t, sm.ExtractTechnique()) // .terraform is not an execution
+	require.Nil(t, sm.FileSystem.CreateDirectory(sm.GetWorkingDirectory()+"/"+terraformCacheDirectoryName, 0744))
+	require.Nil(t, NewFileSystemStateManager(technique, WithExecutionSubdirectory("c0ffee")).SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+
+	executions, err = sm.ListExecutions()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"", "c0ffee"}, executions)
+}
+
+func TestExecutionsFromObjectNames(t *testing.T) {
+	executions := executionsFromObjectNames("stratus/aws.foo/", []string{
+		"stratus/aws.foo/state",
+		"stratus/aws.foo/outputs.json",
+		"stratus/aws.foo/c0ffee/state",
+		"stratus/aws.foo/c0ffee/lifetime.json",
+		"stratus/aws.foo/b4dc0de/outputs.json",
+		"stratus/aws.foo/terraform/default.tfstate",
+		"stratus/aws.foo.bar/state",
+	})
+
+	assert.Equal(t, []string{"", "c0ffee"}, executions)
+}
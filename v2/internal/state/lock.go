@@ -0,0 +1,106 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+)
+
+// lockArtifact holds the lock. It is not a state artifact: cleaning up a technique must not
+// release the lock of the command doing so.
+var lockArtifact = stateArtifact{FileName: ".lock", S3Key: "lock"}
+
+// maxLockAttempts bounds how many times acquireLock looks at a lock that keeps changing under it.
+const maxLockAttempts = 3
+
+// lockStore holds the lock of a state backend. Writes are conditional on what was read, so that
+// two commands racing for the same lock cannot both win.
+type lockStore interface {
+	// readLock returns the raw lock and an opaque version of it, or nil when there is no lock.
+	readLock() ([]byte, string, error)
+	// createLock writes the lock only if there is none, and reports whether it did.
+	createLock(raw []byte) (bool, error)
+	// replaceLock overwrites the lock only if it is still at version, and reports whether it did.
+	replaceLock(raw []byte, version string) (bool, error)
+	// deleteLock removes the lock. An empty version removes it unconditionally.
+	deleteLock(version string) error
+}
+
+// acquireLock takes the lock, breaking it if it has expired.
+func acquireLock(store lockStore, techniqueID string, lock stratus.StateLock) error {
+	encoded, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; attempt < maxLockAttempts; attempt++ {
+		raw, version, err := store.readLock()
+		if err != nil {
+			return fmt.Errorf("unable to read the state lock of %s: %w", techniqueID, err)
+		}
+		acquired := false
+		if raw == nil {
+			acquired, err = store.createLock(encoded)
+		} else {
+			current, parseErr := parseLock(raw)
+			if parseErr != nil {
+				return fmt.Errorf("the state of %s is locked, but the lock is unreadable (%w). Break it with 'stratus unlock %s'", techniqueID, parseErr, techniqueID)
+			}
+			if !current.IsExpired(time.Now()) {
+				return &stratus.StateLockedError{TechniqueID: techniqueID, Lock: *current}
+			}
+			log.Warnf("Breaking the expired lock held by %s on the state of %s since %s", current.Owner, techniqueID, current.AcquiredAt.Format(time.RFC3339))
+			acquired, err = store.replaceLock(encoded, version)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to acquire the state lock of %s: %w", techniqueID, err)
+		}
+		if acquired {
+			return nil
+		}
+		// Another command changed the lock in the meantime, look at it again
+	}
+	return fmt.Errorf("unable to acquire the state lock of %s, as it keeps changing", techniqueID)
+}
+
+// releaseLock releases the lock, unless it is no longer the one with this ID because it was
+// broken or taken over in the meantime.
+func releaseLock(store lockStore, lockID string) error {
+	raw, version, err := store.readLock()
+	if err != nil || raw == nil {
+		return err
+	}
+	current, err := parseLock(raw)
+	if err != nil || current.ID != lockID {
+		return nil
+	}
+	return store.deleteLock(version)
+}
+
+// getLock returns the current lock, or nil if there is none.
+func getLock(store lockStore) (*stratus.StateLock, error) {
+	raw, _, err := store.readLock()
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	return parseLock(raw)
+}
+
+// breakLock releases the lock, whoever holds it.
+func breakLock(store lockStore) error {
+	raw, _, err := store.readLock()
+	if err != nil || raw == nil {
+		return err
+	}
+	return store.deleteLock("")
+}
+
+func parseLock(raw []byte) (*stratus.StateLock, error) {
+	var lock stratus.StateLock
+	if err := json.Unmarshal(raw, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStateManagerLock runs the same locking scenario against any backend. sm and other must be
+// two state managers on the same execution, as used by two different operators.
+func testStateManagerLock(t *testing.T, sm StateManager, other StateManager) {
+	current, err := sm.GetLock()
+	require.Nil(t, err)
+	assert.Nil(t, current, "the state should not be locked initially")
+
+	alice := stratus.NewStateLock("alice", time.Hour)
+	require.Nil(t, sm.AcquireLock(alice))
+	current, err = other.GetLock()
+	require.Nil(t, err)
+	require.NotNil(t, current)
+	assert.Equal(t, "alice", current.Owner)
+
+	// Another operator can neither acquire nor release the lock
+	bob := stratus.NewStateLock("bob", time.Hour)
+	err = other.AcquireLock(bob)
+	var lockedErr *stratus.StateLockedError
+	require.True(t, errors.As(err, &lockedErr), "expected a StateLockedError, got %v", err)
+	assert.Equal(t, alice.ID, lockedErr.Lock.ID)
+	require.Nil(t, other.ReleaseLock(bob.ID))
+	current, _ = sm.GetLock()
+	require.NotNil(t, current)
+	assert.Equal(t, alice.ID, current.ID)
+
+	require.Nil(t, sm.ReleaseLock(alice.ID))
+	current, err = sm.GetLock()
+	require.Nil(t, err)
+	assert.Nil(t, current)
+
+	// An expired lock is taken over, and its previous owner no longer releases it
+	expired := stratus.NewStateLock("alice", -time.Minute)
+	require.Nil(t, sm.AcquireLock(expired))
+	require.Nil(t, other.AcquireLock(bob))
+	require.Nil(t, sm.ReleaseLock(expired.ID))
+	current, _ = sm.GetLock()
+	require.NotNil(t, current)
+	assert.Equal(t, bob.ID, current.ID)
+
+	// Anyone can break a lock
+	require.Nil(t, sm.BreakLock())
+	current, err = other.GetLock()
+	require.Nil(t, err)
+	assert.Nil(t, current)
+	assert.Nil(t, sm.BreakLock(), "breaking a missing lock is a no-op")
+}
+
+func TestFileSystemStateManagerLock(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+
+	testStateManagerLock(t, NewFileSystemStateManager(technique), NewFileSystemStateManager(technique))
+}
+
+func TestFileSystemStateManagerLockRemovesItsDirectory(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+	sm := NewFileSystemStateManager(technique, WithExecutionSubdirectory("c0ffee"))
+
+	lock := stratus.NewStateLock("alice", time.Hour)
+	require.Nil(t, sm.AcquireLock(lock))
+	assert.True(t, sm.FileSystem.FileExists(sm.getLockFile()))
+
+	require.Nil(t, sm.ReleaseLock(lock.ID))
+	assert.False(t, sm.FileSystem.FileExists(sm.getTechniqueDirectory()), "a command with nothing to do should not leave an empty directory behind")
+}
+
+func TestFileSystemStateManagerCleanupKeepsTheLock(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+	for _, subdirectory := range []string{"", "c0ffee"} {
+		sm := NewFileSystemStateManager(technique, WithExecutionSubdirectory(subdirectory))
+		lock := stratus.NewStateLock("alice", time.Hour)
+		require.Nil(t, sm.AcquireLock(lock))
+		require.Nil(t, sm.SetTechniqueState(stratus.AttackTechniqueStatusWarm))
+
+		require.Nil(t, sm.CleanupTechnique())
+		assert.Equal(t, stratus.AttackTechniqueState(""), sm.GetTechniqueState())
+		current, err := sm.GetLock()
+		require.Nil(t, err)
+		require.NotNil(t, current, "the command cleaning up still holds the lock")
+		assert.Equal(t, lock.ID, current.ID)
+
+		require.Nil(t, sm.ReleaseLock(lock.ID))
+		assert.False(t, sm.FileSystem.FileExists(sm.getTechniqueDirectory()))
+	}
+}
+
+func TestFileSystemStateManagerUnreadableLock(t *testing.T) {
+	isolateHome(t)
+	technique := &stratus.AttackTechnique{ID: "aws.test.technique"}
+	sm := NewFileSystemStateManager(technique)
+	require.Nil(t, sm.ensureWorkingDirectory())
+	require.Nil(t, sm.FileSystem.WriteFile(sm.getLockFile(), []byte("not json"), 0644))
+
+	err := sm.AcquireLock(stratus.NewStateLock("alice", time.Hour))
+	assert.ErrorContains(t, err, "the lock is unreadable")
+
+	require.Nil(t, sm.BreakLock())
+	assert.Nil(t, sm.AcquireLock(stratus.NewStateLock("alice", time.Hour)))
+}
@@ -13,6 +13,24 @@ type FileSystemMock struct {
 	mock.Mock
 }
 
+// AppendFile provides a mock function with given fields: _a0, _a1, _a2
+func (_m *FileSystemMock) AppendFile(_a0 string, _a1 []byte, _a2 fs.FileMode) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendFile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []byte, fs.FileMode) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateDirectory provides a mock function with given fields: _a0, _a1
 func (_m *FileSystemMock) CreateDirectory(_a0 string, _a1 fs.FileMode) error {
 	ret := _m.Called(_a0, _a1)
@@ -31,6 +49,24 @@ func (_m *FileSystemMock) CreateDirectory(_a0 string, _a1 fs.FileMode) error {
 	return r0
 }
 
+// CreateFile provides a mock function with given fields: _a0, _a1, _a2
+func (_m *FileSystemMock) CreateFile(_a0 string, _a1 []byte, _a2 fs.FileMode) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateFile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []byte, fs.FileMode) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FileExists provides a mock function with given fields: _a0
 func (_m *FileSystemMock) FileExists(_a0 string) bool {
 	ret := _m.Called(_a0)
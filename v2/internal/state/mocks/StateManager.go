@@ -13,6 +13,60 @@ type StateManager struct {
 	mock.Mock
 }
 
+// AcquireLock provides a mock function with given fields: lock
+func (_m *StateManager) AcquireLock(lock stratus.StateLock) error {
+	ret := _m.Called(lock)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireLock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(stratus.StateLock) error); ok {
+		r0 = rf(lock)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AppendJournalEntry provides a mock function with given fields: entry
+func (_m *StateManager) AppendJournalEntry(entry stratus.JournalEntry) error {
+	ret := _m.Called(entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendJournalEntry")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(stratus.JournalEntry) error); ok {
+		r0 = rf(entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BreakLock provides a mock function with no fields
+func (_m *StateManager) BreakLock() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for BreakLock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CleanupTechnique provides a mock function with no fields
 func (_m *StateManager) CleanupTechnique() error {
 	ret := _m.Called()
@@ -49,6 +103,96 @@ func (_m *StateManager) ExtractTechnique() error {
 	return r0
 }
 
+// GetExecutionLifetime provides a mock function with no fields
+func (_m *StateManager) GetExecutionLifetime() (*stratus.ExecutionLifetime, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExecutionLifetime")
+	}
+
+	var r0 *stratus.ExecutionLifetime
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*stratus.ExecutionLifetime, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *stratus.ExecutionLifetime); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*stratus.ExecutionLifetime)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJournalEntries provides a mock function with no fields
+func (_m *StateManager) GetJournalEntries() ([]stratus.JournalEntry, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJournalEntries")
+	}
+
+	var r0 []stratus.JournalEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]stratus.JournalEntry, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []stratus.JournalEntry); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]stratus.JournalEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLock provides a mock function with no fields
+func (_m *StateManager) GetLock() (*stratus.StateLock, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLock")
+	}
+
+	var r0 *stratus.StateLock
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*stratus.StateLock, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *stratus.StateLock); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*stratus.StateLock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRootDirectory provides a mock function with no fields
 func (_m *StateManager) GetRootDirectory() string {
 	ret := _m.Called()
@@ -168,6 +312,54 @@ func (_m *StateManager) Initialize() {
 	_m.Called()
 }
 
+// ListExecutions provides a mock function with no fields
+func (_m *StateManager) ListExecutions() ([]string, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListExecutions")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseLock provides a mock function with given fields: lockID
+func (_m *StateManager) ReleaseLock(lockID string) error {
+	ret := _m.Called(lockID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseLock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(lockID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetTechniqueState provides a mock function with given fields: _a0
 func (_m *StateManager) SetTechniqueState(_a0 stratus.AttackTechniqueState) error {
 	ret := _m.Called(_a0)
@@ -186,6 +378,24 @@ func (_m *StateManager) SetTechniqueState(_a0 stratus.AttackTechniqueState) erro
 	return r0
 }
 
+// WriteExecutionLifetime provides a mock function with given fields: lifetime
+func (_m *StateManager) WriteExecutionLifetime(lifetime stratus.ExecutionLifetime) error {
+	ret := _m.Called(lifetime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteExecutionLifetime")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(stratus.ExecutionLifetime) error); ok {
+		r0 = rf(lifetime)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WriteTerraformOutputs provides a mock function with given fields: outputs
 func (_m *StateManager) WriteTerraformOutputs(outputs map[string]string) error {
 	ret := _m.Called(outputs)
@@ -15,6 +15,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 )
@@ -339,3 +340,147 @@ func (m *S3StateManager) putJSONMap(key string, data map[string]string) error {
 	}
 	return m.s3Put(key, encoded)
 }
+
+func (m *S3StateManager) AppendJournalEntry(entry stratus.JournalEntry) error {
+	line, err := encodeJournalEntry(entry)
+	if err != nil {
+		return err
+	}
+	return m.s3Put(journalObjectName(m.config.KeyPrefix, entry), line)
+}
+
+func (m *S3StateManager) GetJournalEntries() ([]stratus.JournalEntry, error) {
+	var entries []stratus.JournalEntry
+	paginator := s3.NewListObjectsV2Paginator(m.s3Client, &s3.ListObjectsV2Input{
+		Bucket: &m.config.BucketName,
+		Prefix: aws.String(journalTechniquePrefix(m.config.KeyPrefix, m.technique.ID)),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			raw, err := m.s3Get(*object.Key)
+			if err != nil {
+				return nil, err
+			}
+			entry, err := decodeJournal(raw)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read s3://%s/%s: %w", m.config.BucketName, *object.Key, err)
+			}
+			entries = append(entries, entry...)
+		}
+	}
+	sortJournal(entries)
+	return entries, nil
+}
+
+func (m *S3StateManager) GetExecutionLifetime() (*stratus.ExecutionLifetime, error) {
+	data, err := m.s3Get(m.s3Key(executionLifetimeArtifact.S3Key))
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseExecutionLifetime(data)
+}
+
+func (m *S3StateManager) WriteExecutionLifetime(lifetime stratus.ExecutionLifetime) error {
+	encoded, err := json.Marshal(lifetime)
+	if err != nil {
+		return err
+	}
+	return m.s3Put(m.s3Key(executionLifetimeArtifact.S3Key), encoded)
+}
+
+func (m *S3StateManager) ListExecutions() ([]string, error) {
+	techniquePrefix := m.config.KeyPrefix + m.technique.ID + "/"
+	var names []string
+	paginator := s3.NewListObjectsV2Paginator(m.s3Client, &s3.ListObjectsV2Input{
+		Bucket: &m.config.BucketName,
+		Prefix: aws.String(techniquePrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			names = append(names, *object.Key)
+		}
+	}
+	return executionsFromObjectNames(techniquePrefix, names), nil
+}
+
+func (m *S3StateManager) AcquireLock(lock stratus.StateLock) error {
+	return acquireLock(m, m.technique.ID, lock)
+}
+
+func (m *S3StateManager) ReleaseLock(lockID string) error {
+	return releaseLock(m, lockID)
+}
+
+func (m *S3StateManager) GetLock() (*stratus.StateLock, error) {
+	return getLock(m)
+}
+
+func (m *S3StateManager) BreakLock() error {
+	return breakLock(m)
+}
+
+// The S3 lock is an object written with conditional writes, versioned by its ETag.
+
+func (m *S3StateManager) readLock() ([]byte, string, error) {
+	result, err := m.s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: &m.config.BucketName,
+		Key:    aws.String(m.s3Key(lockArtifact.S3Key)),
+	})
+	if isNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer result.Body.Close()
+	raw, err := io.ReadAll(result.Body)
+	return raw, aws.ToString(result.ETag), err
+}
+
+func (m *S3StateManager) createLock(raw []byte) (bool, error) {
+	return m.putLock(raw, &s3.PutObjectInput{IfNoneMatch: aws.String("*")})
+}
+
+func (m *S3StateManager) replaceLock(raw []byte, version string) (bool, error) {
+	return m.putLock(raw, &s3.PutObjectInput{IfMatch: aws.String(version)})
+}
+
+func (m *S3StateManager) putLock(raw []byte, input *s3.PutObjectInput) (bool, error) {
+	input.Bucket = &m.config.BucketName
+	input.Key = aws.String(m.s3Key(lockArtifact.S3Key))
+	input.Body = bytes.NewReader(raw)
+	_, err := m.s3Client.PutObject(context.Background(), input)
+	if isConditionNotMet(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *S3StateManager) deleteLock(string) error {
+	// Not conditional, as not every S3-compatible storage supports conditional deletes
+	_, err := m.s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: &m.config.BucketName,
+		Key:    aws.String(m.s3Key(lockArtifact.S3Key)),
+	})
+	return err
+}
+
+// isConditionNotMet reports whether a conditional write lost against a concurrent one.
+func isConditionNotMet(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.ErrorCode() == "PreconditionFailed" || apiErr.ErrorCode() == "ConditionalRequestConflict"
+}
@@ -1,11 +1,14 @@
 package state
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/json"
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
@@ -51,6 +54,10 @@ func MarshalCorrelation(id uuid.UUID) string {
 const StratusStateTerraformFileName = "main.tf"
 const StratusStateTerraformInitializedFileName = ".terraform-initialized"
 
+// AWSEndpointsOverrideFileName is the Terraform override file that applies aws.endpoint_url to the
+// prerequisites of AWS techniques
+const AWSEndpointsOverrideFileName = "aws_endpoints_override.tf"
+
 // Artifacts are the important files. If using the S3StateManager, they are saved to the remote bucket.
 type stateArtifact struct {
 	FileName string
@@ -62,12 +69,14 @@ var (
 	terraformOutputsArtifact   = stateArtifact{FileName: ".terraform-outputs", S3Key: "outputs.json"}
 	terraformVariablesArtifact = stateArtifact{FileName: ".terraform-variables", S3Key: "variables.json"}
 	terraformStateArtifact     = stateArtifact{FileName: "terraform.tfstate", S3Key: "terraform.tfstate"}
+	executionLifetimeArtifact  = stateArtifact{FileName: ".lifetime", S3Key: "lifetime.json"}
 
 	stateArtifacts = []stateArtifact{
 		techniqueStateArtifact,
 		terraformOutputsArtifact,
 		terraformVariablesArtifact,
 		terraformStateArtifact,
+		executionLifetimeArtifact,
 	}
 )
 
@@ -123,10 +132,10 @@ func sanitizeExecutionSubdirectory(name string) string {
 	return name
 }
 
-// correlationIDFromVariables extracts the correlation ID persisted alongside a technique's
+// CorrelationIDFromVariables extracts the correlation ID persisted alongside a technique's
 // Terraform variables. It returns "" for state written before Stratus persisted the ID
 // (< v2.32.0), which is what makes such state ineligible for automatic migration.
-func correlationIDFromVariables(variables map[string]string) string {
+func CorrelationIDFromVariables(variables map[string]string) string {
 	raw, found := variables[TerraformCorrelationVarName]
 	if !found {
 		return ""
@@ -144,7 +153,7 @@ func flatStateBelongsToExecution(executionSubdirectory string, flatVariables map
 	if executionSubdirectory == "" {
 		return false
 	}
-	return correlationIDFromVariables(flatVariables) == executionSubdirectory
+	return CorrelationIDFromVariables(flatVariables) == executionSubdirectory
 }
 
 // [backward compatibility] isExecutionSubdirectory reports whether an entry of the technique
@@ -165,7 +174,9 @@ type FileSystemStateManager struct {
 }
 
 type FileSystem interface {
+	AppendFile(string, []byte, os.FileMode) error // Creates the file if it does not exist.
 	CreateDirectory(string, os.FileMode) error
+	CreateFile(string, []byte, os.FileMode) error // Must fail with an error matching fs.ErrExist if the file exists.
 	FileExists(string) bool
 	IsDirectory(string) bool
 	ListDirectory(string) ([]string, error)
@@ -178,11 +189,31 @@ type FileSystem interface {
 
 type LocalFileSystem struct{}
 
+// AppendFile appends content to file, creating it if needed.
+func (m *LocalFileSystem) AppendFile(file string, content []byte, mode os.FileMode) error {
+	handle, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, mode)
+	if err != nil {
+		return err
+	}
+	_, err = handle.Write(content)
+	return errors.Join(err, handle.Close())
+}
+
 // CreateDirectory creates dir and any missing parents.
 func (m *LocalFileSystem) CreateDirectory(dir string, mode os.FileMode) error {
 	return os.MkdirAll(dir, mode)
 }
 
+// CreateFile creates file with content, failing if it already exists.
+func (m *LocalFileSystem) CreateFile(file string, content []byte, mode os.FileMode) error {
+	handle, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	_, err = handle.Write(content)
+	return errors.Join(err, handle.Close())
+}
+
 func (m *LocalFileSystem) FileExists(fileName string) bool {
 	return utils.FileExists(fileName)
 }
@@ -235,6 +266,28 @@ type StateManager interface {
 	WriteTerraformVariables(variables map[string]string) error
 	GetTechniqueState() stratus.AttackTechniqueState
 	SetTechniqueState(state stratus.AttackTechniqueState) error
+
+	// AcquireLock takes the advisory lock on this execution's state, breaking it if it has expired.
+	// It returns a *stratus.StateLockedError if another command holds it.
+	AcquireLock(lock stratus.StateLock) error
+	// ReleaseLock releases the lock if it is still the one with this ID.
+	ReleaseLock(lockID string) error
+	// GetLock returns the lock on this execution's state, or nil if there is none.
+	GetLock() (*stratus.StateLock, error)
+	// BreakLock releases the lock, whoever holds it.
+	BreakLock() error
+
+	// AppendJournalEntry records a lifecycle operation in the append-only execution journal.
+	AppendJournalEntry(entry stratus.JournalEntry) error
+	// GetJournalEntries returns the journal entries of the technique across all of its executions, oldest first.
+	GetJournalEntries() ([]stratus.JournalEntry, error)
+
+	// GetExecutionLifetime returns when this execution was warmed up and expires, or nil if it was not recorded.
+	GetExecutionLifetime() (*stratus.ExecutionLifetime, error)
+	WriteExecutionLifetime(lifetime stratus.ExecutionLifetime) error
+	// ListExecutions returns the executions of the technique that have state, across all of its
+	// sub-directories. Each is identified by its sub-directory, or "" for the flat layout.
+	ListExecutions() ([]string, error)
 }
 
 func NewFileSystemStateManager(technique *stratus.AttackTechnique, opts ...ManagerOption) *FileSystemStateManager {
@@ -368,6 +421,12 @@ func writeSharedTerraformFiles(fileSystem FileSystem, directory string, techniqu
 		{"config.tf", config.SharedTerraformConfigVariable},
 		{"correlation.tf", sharedCorrelationVariable},
 	}
+	if usesAWSProvider(technique) {
+		files = append(files, struct {
+			name    string
+			content []byte
+		}{AWSEndpointsOverrideFileName, config.AWSEndpointsTerraformOverride})
+	}
 	for _, file := range files {
 		if err := fileSystem.WriteFile(filepath.Join(directory, file.name), file.content, 0644); err != nil {
 			return err
@@ -376,6 +435,15 @@ func writeSharedTerraformFiles(fileSystem FileSystem, directory string, techniqu
 	return nil
 }
 
+// usesAWSProvider reports whether the prerequisites of technique configure the "aws" provider, which
+// the AWS endpoints override file requires: Terraform refuses to override a block that does not exist.
+func usesAWSProvider(technique *stratus.AttackTechnique) bool {
+	if technique.Platform != stratus.AWS && technique.Platform != stratus.EKS {
+		return false
+	}
+	return bytes.Contains(technique.PrerequisitesTerraformCode, []byte(`provider "aws"`))
+}
+
 func (m *FileSystemStateManager) ExtractTechnique() error {
 	if err := m.ensureWorkingDirectory(); err != nil {
 		return err
@@ -387,24 +455,17 @@ func (m *FileSystemStateManager) CleanupTechnique() error {
 	return removeWorkingDirectory(m.FileSystem, m.getTechniqueDirectory(), m.ExecutionSubdirectory)
 }
 
-// removeWorkingDirectory removes this execution's working directory.
+// removeWorkingDirectory removes this execution's working directory, except for the lock of the
+// command cleaning it up: releasing the lock removes it, along with the directories left empty.
 //
 // In the flat layout the working directory is the technique directory itself, which may also
 // host concurrent executions' sub-directories, so we can't use a simple recursive remove.
 func removeWorkingDirectory(fileSystem FileSystem, techniqueDirectory string, executionSubdirectory string) error {
-	if executionSubdirectory != "" {
-		if err := fileSystem.RemoveAll(filepath.Join(techniqueDirectory, executionSubdirectory)); err != nil {
-			return err
-		}
-		// Try to remove the technique dir. If another execution is running, will rightfully fail
-		_ = fileSystem.RemoveEmptyDirectory(techniqueDirectory)
-		return nil
-	}
-
-	if !fileSystem.FileExists(techniqueDirectory) {
+	workingDirectory := filepath.Join(techniqueDirectory, executionSubdirectory)
+	if !fileSystem.FileExists(workingDirectory) {
 		return nil
 	}
-	names, err := fileSystem.ListDirectory(techniqueDirectory)
+	names, err := fileSystem.ListDirectory(workingDirectory)
 	if err != nil {
 		return err
 	}
@@ -414,28 +475,35 @@ func removeWorkingDirectory(fileSystem FileSystem, techniqueDirectory string, ex
 	var errs []error
 	stateFilePresent := false
 	for _, name := range names {
-		if isExecutionSubdirectory(fileSystem, techniqueDirectory, name) {
+		if executionSubdirectory == "" && isExecutionSubdirectory(fileSystem, techniqueDirectory, name) {
 			continue // don't remove concurrent executions
 		}
+		if name == lockArtifact.FileName { // held by the command cleaning up
+			continue
+		}
 		if name == techniqueStateArtifact.FileName { // skip state file, removed at the end
 			stateFilePresent = true
 			continue
 		}
-		if err := fileSystem.RemoveAll(filepath.Join(techniqueDirectory, name)); err != nil {
+		if err := fileSystem.RemoveAll(filepath.Join(workingDirectory, name)); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	// Removed last, and only once the rest is gone, so a partial cleanup still reports its real
 	// state rather than looking COLD while resources are live.
 	if stateFilePresent && len(errs) == 0 {
-		if err := fileSystem.RemoveAll(filepath.Join(techniqueDirectory, techniqueStateArtifact.FileName)); err != nil {
+		if err := fileSystem.RemoveAll(filepath.Join(workingDirectory, techniqueStateArtifact.FileName)); err != nil {
 			errs = append(errs, err)
 		}
 	}
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
-	_ = fileSystem.RemoveEmptyDirectory(techniqueDirectory)
+	_ = fileSystem.RemoveEmptyDirectory(workingDirectory)
+	if executionSubdirectory != "" {
+		// Try to remove the technique dir. If another execution is running, will rightfully fail
+		_ = fileSystem.RemoveEmptyDirectory(techniqueDirectory)
+	}
 	return nil
 }
 
@@ -537,3 +605,145 @@ func (m *FileSystemStateManager) GetRootDirectory() string {
 func (m *FileSystemStateManager) GetWorkingDirectory() string {
 	return m.getTechniqueStateDirectory()
 }
+
+func (m *FileSystemStateManager) AcquireLock(lock stratus.StateLock) error {
+	return acquireLock(m, m.Technique.ID, lock)
+}
+
+func (m *FileSystemStateManager) ReleaseLock(lockID string) error {
+	return releaseLock(m, lockID)
+}
+
+func (m *FileSystemStateManager) GetLock() (*stratus.StateLock, error) {
+	return getLock(m)
+}
+
+func (m *FileSystemStateManager) BreakLock() error {
+	return breakLock(m)
+}
+
+// The filesystem lock is a lock file, created exclusively. Taking over an expired lock file is
+// best-effort: two commands could both break it, but only one of them can then create it again.
+
+func (m *FileSystemStateManager) readLock() ([]byte, string, error) {
+	lockFile := m.getLockFile()
+	if !m.FileSystem.FileExists(lockFile) {
+		return nil, "", nil
+	}
+	raw, err := m.FileSystem.ReadFile(lockFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", nil
+	}
+	return raw, string(raw), err
+}
+
+func (m *FileSystemStateManager) createLock(raw []byte) (bool, error) {
+	if err := m.ensureWorkingDirectory(); err != nil {
+		return false, err
+	}
+	err := m.FileSystem.CreateFile(m.getLockFile(), raw, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *FileSystemStateManager) replaceLock(raw []byte, version string) (bool, error) {
+	current, _, err := m.readLock()
+	if err != nil || string(current) != version {
+		return false, err
+	}
+	if err := m.FileSystem.RemoveAll(m.getLockFile()); err != nil {
+		return false, err
+	}
+	return m.createLock(raw)
+}
+
+func (m *FileSystemStateManager) deleteLock(version string) error {
+	if version != "" {
+		current, _, err := m.readLock()
+		if err != nil || string(current) != version {
+			return err
+		}
+	}
+	if err := m.FileSystem.RemoveAll(m.getLockFile()); err != nil {
+		return err
+	}
+	// Don't leave behind the directory created for the lock alone
+	_ = m.FileSystem.RemoveEmptyDirectory(m.getTechniqueStateDirectory())
+	if m.ExecutionSubdirectory != "" {
+		_ = m.FileSystem.RemoveEmptyDirectory(m.getTechniqueDirectory())
+	}
+	return nil
+}
+
+func (m *FileSystemStateManager) getLockFile() string {
+	return filepath.Join(m.getTechniqueStateDirectory(), lockArtifact.FileName)
+}
+
+func (m *FileSystemStateManager) AppendJournalEntry(entry stratus.JournalEntry) error {
+	line, err := encodeJournalEntry(entry)
+	if err != nil {
+		return err
+	}
+	if err := m.FileSystem.CreateDirectory(m.RootDirectory, 0744); err != nil {
+		return err
+	}
+	// A single append of a line is atomic enough for concurrent commands on a local filesystem
+	return m.FileSystem.AppendFile(filepath.Join(m.RootDirectory, journalFileName), line, 0644)
+}
+
+func (m *FileSystemStateManager) GetJournalEntries() ([]stratus.JournalEntry, error) {
+	entries, err := readFileSystemJournal(m.FileSystem, m.RootDirectory)
+	if err != nil {
+		return nil, err
+	}
+	return filterJournal(entries, m.Technique.ID), nil
+}
+
+func (m *FileSystemStateManager) GetExecutionLifetime() (*stratus.ExecutionLifetime, error) {
+	path := filepath.Join(m.getTechniqueStateDirectory(), executionLifetimeArtifact.FileName)
+	if !m.FileSystem.FileExists(path) {
+		return nil, nil
+	}
+	raw, err := m.FileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseExecutionLifetime(raw)
+}
+
+func (m *FileSystemStateManager) WriteExecutionLifetime(lifetime stratus.ExecutionLifetime) error {
+	encoded, err := json.Marshal(lifetime)
+	if err != nil {
+		return err
+	}
+	if err := m.ensureWorkingDirectory(); err != nil {
+		return err
+	}
+	return m.FileSystem.WriteFile(filepath.Join(m.getTechniqueStateDirectory(), executionLifetimeArtifact.FileName), encoded, 0744)
+}
+
+func (m *FileSystemStateManager) ListExecutions() ([]string, error) {
+	techniqueDirectory := m.getTechniqueDirectory()
+	if !m.FileSystem.FileExists(techniqueDirectory) {
+		return []string{}, nil
+	}
+	names, err := m.FileSystem.ListDirectory(techniqueDirectory)
+	if err != nil {
+		return nil, err
+	}
+	executions := []string{}
+	for _, name := range names {
+		switch {
+		case name == techniqueStateArtifact.FileName:
+			executions = append(executions, "")
+		case isExecutionSubdirectory(m.FileSystem, techniqueDirectory, name):
+			if m.FileSystem.FileExists(filepath.Join(techniqueDirectory, name, techniqueStateArtifact.FileName)) {
+				executions = append(executions, name)
+			}
+		}
+	}
+	sort.Strings(executions)
+	return executions, nil
+}
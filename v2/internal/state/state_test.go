@@ -10,6 +10,7 @@ import (
 
 	"github.com/datadog/stratus-red-team/v2/internal/state/mocks"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -84,6 +85,41 @@ func TestStateManagerExtractsTechniqueTerraformFiles(t *testing.T) {
 		"/root/.stratus-red-team/my-technique/correlation.tf", mock.Anything, mock.Anything)
 }
 
+func TestStateManagerExtractsAWSEndpointsOverrideForAWSTechniques(t *testing.T) {
+	awsTerraform := []byte("provider \"aws\" {\n}\n")
+	tests := []struct {
+		name            string
+		technique       *stratus.AttackTechnique
+		expectsOverride bool
+	}{
+		{"AWS technique", &stratus.AttackTechnique{ID: "my-technique", Platform: stratus.AWS, PrerequisitesTerraformCode: awsTerraform}, true},
+		{"EKS technique", &stratus.AttackTechnique{ID: "my-technique", Platform: stratus.EKS, PrerequisitesTerraformCode: awsTerraform}, true},
+		{"AWS technique using the default provider", &stratus.AttackTechnique{ID: "my-technique", Platform: stratus.AWS, PrerequisitesTerraformCode: []byte("terraform")}, false},
+		{"GCP technique", &stratus.AttackTechnique{ID: "my-technique", Platform: stratus.GCP, PrerequisitesTerraformCode: []byte("terraform")}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fsMock := new(mocks.FileSystemMock)
+			fsMock.On("FileExists", mock.Anything).Return(false)
+			fsMock.On("CreateDirectory", mock.Anything, mock.Anything).Return(nil)
+			fsMock.On("WriteFile", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			statemanager := FileSystemStateManager{
+				RootDirectory: "/root/.stratus-red-team",
+				Technique:     tc.technique,
+				FileSystem:    fsMock,
+			}
+
+			assert.Nil(t, statemanager.ExtractTechnique())
+			overridePath := "/root/.stratus-red-team/my-technique/" + AWSEndpointsOverrideFileName
+			if tc.expectsOverride {
+				fsMock.AssertCalled(t, "WriteFile", overridePath, config.AWSEndpointsTerraformOverride, mock.Anything)
+			} else {
+				fsMock.AssertNotCalled(t, "WriteFile", overridePath, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestStateManagerRetrievesTechniqueOutputs(t *testing.T) {
 	fsMock := new(mocks.FileSystemMock)
 	fileMatcher := mock.MatchedBy(func(file string) bool {
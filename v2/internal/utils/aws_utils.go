@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
@@ -15,8 +16,11 @@ import (
 	"github.com/aws/smithy-go/middleware"
 	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/cenkalti/backoff/v4"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/tracing"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/useragent"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
@@ -26,9 +30,9 @@ import (
 	"time"
 )
 
-func GetCurrentAccountId(cfg aws.Config) (string, error) {
+func GetCurrentAccountId(ctx context.Context, cfg aws.Config) (string, error) {
 	stsClient := sts.NewFromConfig(cfg)
-	result, err := stsClient.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	result, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
 		return "", err
 	}
@@ -75,9 +79,31 @@ func CustomUserAgentApiOptions(uniqueCorrelationId uuid.UUID) config.LoadOptions
 	}())
 }
 
+// AddTracingMiddleware records a span for every AWS API call, as a child of the span carried by the context
+// of the call. Calls made outside of a span are not recorded.
+func AddTracingMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("StratusTracing", func(
+		ctx context.Context, input middleware.InitializeInput, next middleware.InitializeHandler,
+	) (out middleware.InitializeOutput, metadata middleware.Metadata, err error) {
+		service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+		ctx, span := tracing.Tracer(ctx).Start(ctx, service+"."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "aws-api"),
+				attribute.String("rpc.service", service),
+				attribute.String("rpc.method", operation),
+				attribute.String("cloud.region", awsmiddleware.GetRegion(ctx)),
+			),
+		)
+		out, metadata, err = next.HandleInitialize(ctx, input)
+		tracing.EndSpan(span, err)
+		return out, metadata, err
+	}), middleware.After)
+}
+
 // WaitForAndAssumeAWSRole waits for an AWS role to be assumable (due to eventual consistency)
 // then sets a credentials provider that can be used to assume the role.
-func WaitForAndAssumeAWSRole(awsConnection *aws.Config, roleArn string) error {
+func WaitForAndAssumeAWSRole(ctx context.Context, awsConnection *aws.Config, roleArn string) error {
 	assumeRoleProvider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(*awsConnection), roleArn)
 	backoffStrategy := backoff.NewExponentialBackOff()
 	backoffStrategy.InitialInterval = 1 * time.Second // try to assume the role after 1s
@@ -85,9 +111,9 @@ func WaitForAndAssumeAWSRole(awsConnection *aws.Config, roleArn string) error {
 	backoffStrategy.MaxInterval = 10 * time.Second    // never wait more than 10s between attempts
 	backoffStrategy.MaxElapsedTime = 1 * time.Minute  // stop trying after 1 minute
 	err := backoff.Retry(func() error {
-		_, err := assumeRoleProvider.Retrieve(context.Background())
+		_, err := assumeRoleProvider.Retrieve(ctx)
 		return err
-	}, backoffStrategy)
+	}, backoff.WithContext(backoffStrategy, ctx))
 	if err != nil {
 		return fmt.Errorf("unable to assume role %s: %v", roleArn, err)
 	}
@@ -119,10 +145,10 @@ func IsErrorDueToEBSEncryptionByDefault(err error) bool {
 
 // S3 utils
 
-func ListAllObjectVersions(s3Client *s3.Client, bucketName string) ([]s3types.ObjectIdentifier, error) {
+func ListAllObjectVersions(ctx context.Context, s3Client *s3.Client, bucketName string) ([]s3types.ObjectIdentifier, error) {
 	log.Println("Listing objects in bucket " + bucketName)
 	var result []s3types.ObjectIdentifier
-	objectVersions, err := s3Client.ListObjectVersions(context.Background(), &s3.ListObjectVersionsInput{Bucket: &bucketName})
+	objectVersions, err := s3Client.ListObjectVersions(ctx, &s3.ListObjectVersionsInput{Bucket: &bucketName})
 	if err != nil {
 		return nil, fmt.Errorf("unable to list bucket objects: %w", err)
 	}
@@ -132,19 +158,19 @@ func ListAllObjectVersions(s3Client *s3.Client, bucketName string) ([]s3types.Ob
 	return result, nil
 }
 
-func DownloadAllObjects(client *s3.Client, bucketName string) error {
+func DownloadAllObjects(ctx context.Context, client *s3.Client, bucketName string) error {
 	downloader := manager.NewDownloader(client)
 	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
 		Bucket: &bucketName,
 	})
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(context.Background())
+		page, err := paginator.NextPage(ctx)
 		if err != nil {
 			return fmt.Errorf("unable to list bucket objects: %w", err)
 		}
 		for _, obj := range page.Contents {
 			buf := manager.NewWriteAtBuffer([]byte{})
-			_, err := downloader.Download(context.Background(), buf, &s3.GetObjectInput{
+			_, err := downloader.Download(ctx, buf, &s3.GetObjectInput{
 				Bucket: &bucketName,
 				Key:    obj.Key,
 			})
@@ -158,8 +184,8 @@ func DownloadAllObjects(client *s3.Client, bucketName string) error {
 	return nil
 }
 
-func UploadFile(s3Client *s3.Client, bucketName string, filename string, contents io.Reader) error {
-	_, err := s3Client.PutObject(context.Background(), &s3.PutObjectInput{
+func UploadFile(ctx context.Context, s3Client *s3.Client, bucketName string, filename string, contents io.Reader) error {
+	_, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: &bucketName,
 		Key:    aws.String(filename),
 		Body:   contents,
@@ -171,7 +197,7 @@ func UploadFile(s3Client *s3.Client, bucketName string, filename string, content
 
 // WaitForInstancesToRegisterInSSM waits for a set of instances to be registered in SSM
 // may be slow (60+ seconds)
-func WaitForInstancesToRegisterInSSM(ssmClient *ssm.Client, instanceIds []string) error {
+func WaitForInstancesToRegisterInSSM(ctx context.Context, ssmClient *ssm.Client, instanceIds []string) error {
 	if len(instanceIds) == 1 {
 		log.Println("Waiting for instance " + instanceIds[0] + " to show up in AWS SSM")
 	} else {
@@ -179,8 +205,12 @@ func WaitForInstancesToRegisterInSSM(ssmClient *ssm.Client, instanceIds []string
 	}
 
 	for {
-		time.Sleep(1 * time.Second)
-		result, err := ssmClient.DescribeInstanceInformation(context.Background(), &ssm.DescribeInstanceInformationInput{
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+		result, err := ssmClient.DescribeInstanceInformation(ctx, &ssm.DescribeInstanceInformationInput{
 			Filters: []ssmtypes.InstanceInformationStringFilter{
 				{Key: aws.String("InstanceIds"), Values: instanceIds},
 			},
@@ -215,6 +245,6 @@ func WaitForInstancesToRegisterInSSM(ssmClient *ssm.Client, instanceIds []string
 }
 
 // utility function for a single instance
-func WaitForInstanceToRegisterInSSM(ssmClient *ssm.Client, instanceId string) error {
-	return WaitForInstancesToRegisterInSSM(ssmClient, []string{instanceId})
+func WaitForInstanceToRegisterInSSM(ctx context.Context, ssmClient *ssm.Client, instanceId string) error {
+	return WaitForInstancesToRegisterInSSM(ctx, ssmClient, []string{instanceId})
 }
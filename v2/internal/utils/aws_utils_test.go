@@ -1,10 +1,20 @@
 package utils
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/stretchr/testify/assert"
-	"testing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestIsErrorRelatedToEbsEncryptionByDefault(t *testing.T) {
@@ -18,3 +28,38 @@ func TestIsErrorRelatedToEbsEncryptionByDefault(t *testing.T) {
 		errors.New("operation error EC2: ModifyImageAttribute, https response error StatusCode: 400, RequestID: 85f85eff-4114-4861-a659-f9aeea48d78b, api error InvalidParameter: Snapshots encrypted with the AWS Managed CMK can't be shared. Specify another snapshot"),
 	))
 }
+
+type stubHTTPClient struct{}
+
+func (stubHTTPClient) Do(*http.Request) (*http.Response, error) {
+	return nil, errors.New("no network in tests")
+}
+
+func TestTracingMiddlewareRecordsAPICallsUnderTheCurrentSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := sts.New(sts.Options{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("AKIA", "secret", ""),
+		HTTPClient:       stubHTTPClient{},
+		RetryMaxAttempts: 1,
+		APIOptions:       []func(*middleware.Stack) error{AddTracingMiddleware},
+	})
+
+	// Outside of a span, nothing is recorded
+	_, err := client.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	assert.NotNil(t, err)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+	_, err = client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	assert.NotNil(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "STS.GetCallerIdentity", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("cloud.region", "us-east-1"))
+}
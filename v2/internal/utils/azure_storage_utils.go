@@ -13,14 +13,14 @@ import (
 	"io"
 )
 
-func GetAzureBlobClient(serviceURL string, subscriptionID string, defaultCredentials azcore.TokenCredential, clientOptions *arm.ClientOptions, params map[string]string) (*azblob.Client, error) {
+func GetAzureBlobClient(ctx context.Context, serviceURL string, subscriptionID string, defaultCredentials azcore.TokenCredential, clientOptions *arm.ClientOptions, params map[string]string) (*azblob.Client, error) {
 	account := params["storage_account_name"]
 	//Gets storage account keys to enable data-plan permissions
 	armClient, err := armstorage.NewAccountsClient(subscriptionID, defaultCredentials, clientOptions)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := armClient.ListKeys(context.Background(), params["resource_group_name"], account, nil)
+	resp, err := armClient.ListKeys(ctx, params["resource_group_name"], account, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -41,13 +41,13 @@ func GetAzureBlobClient(serviceURL string, subscriptionID string, defaultCredent
 
 }
 
-func ListAllBlobVersions(client *azblob.Client) (map[string]map[string][]*string, error) {
+func ListAllBlobVersions(ctx context.Context, client *azblob.Client) (map[string]map[string][]*string, error) {
 	containerPager := client.NewListContainersPager(&azblob.ListContainersOptions{
 		Include: azblob.ListContainersInclude{Metadata: true, Deleted: true},
 	})
 	result := make(map[string]map[string][]*string)
 	for containerPager.More() {
-		containerResp, err := containerPager.NextPage(context.Background())
+		containerResp, err := containerPager.NextPage(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("error when enumerating storage containers:  %w", err)
 		}
@@ -57,7 +57,7 @@ func ListAllBlobVersions(client *azblob.Client) (map[string]map[string][]*string
 				Include: azblob.ListBlobsInclude{Deleted: true, Versions: true},
 			})
 			for blobPager.More() {
-				blobResp, err := blobPager.NextPage(context.Background())
+				blobResp, err := blobPager.NextPage(ctx)
 				if err != nil {
 					return nil, fmt.Errorf("error when enumerating storage blobs in container %s: %w", *_container.Name, err)
 				}
@@ -70,11 +70,11 @@ func ListAllBlobVersions(client *azblob.Client) (map[string]map[string][]*string
 	return result, nil
 }
 
-func UploadBlob(client *azblob.Client, containerName string, blobName string, contents io.Reader) error {
-	_, err := client.CreateContainer(context.Background(), containerName, nil)
+func UploadBlob(ctx context.Context, client *azblob.Client, containerName string, blobName string, contents io.Reader) error {
+	_, err := client.CreateContainer(ctx, containerName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = client.UploadStream(context.Background(), containerName, blobName, contents, nil)
+	_, err = client.UploadStream(ctx, containerName, blobName, contents, nil)
 	return err
 }
@@ -1,11 +1,13 @@
 package utils
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"math/rand"
+	"time"
 )
 
 func CoalesceErr(args ...error) error {
@@ -18,6 +20,18 @@ func CoalesceErr(args ...error) error {
 	return nil
 }
 
+// SleepWithContext pauses for the given duration, returning early with the context error if it is done first
+func SleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func RandomString(length int) string {
 	const letterBytes = "abcdefghijklmnopqrstuvwxyz0123456789"
 	b := make([]byte, length)
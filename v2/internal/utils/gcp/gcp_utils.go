@@ -28,13 +28,13 @@ type SshKeyPair struct {
 // * Step 1: Read the project's IAM policy using [getIamPolicy](https://cloud.google.com/resource-manager/reference/rest/v1/projects/getIamPolicy)
 // * Step 2: Create a binding, or add the service account to an existing binding for the role to grant
 // * Step 3: Update the project's IAM policy using [setIamPolicy](https://cloud.google.com/resource-manager/reference/rest/v1/projects/setIamPolicy)
-func GCPAssignProjectRole(gcp *providers.GCPProvider, principal string, roleToGrant string) error {
-	resourceManager, err := cloudresourcemanager.NewService(context.Background(), gcp.Options())
+func GCPAssignProjectRole(ctx context.Context, gcp *providers.GCPProvider, principal string, roleToGrant string) error {
+	resourceManager, err := cloudresourcemanager.NewService(ctx, gcp.Options())
 	if err != nil {
 		return errors.New("unable to instantiate the GCP cloud resource manager: " + err.Error())
 	}
 
-	projectPolicy, err := resourceManager.Projects.GetIamPolicy(gcp.GetProjectId(), &cloudresourcemanager.GetIamPolicyRequest{}).Do()
+	projectPolicy, err := resourceManager.Projects.GetIamPolicy(gcp.GetProjectId(), &cloudresourcemanager.GetIamPolicyRequest{}).Context(ctx).Do()
 	if err != nil {
 		return err
 	}
@@ -56,7 +56,7 @@ func GCPAssignProjectRole(gcp *providers.GCPProvider, principal string, roleToGr
 
 	_, err = resourceManager.Projects.SetIamPolicy(gcp.GetProjectId(), &cloudresourcemanager.SetIamPolicyRequest{
 		Policy: projectPolicy,
-	}).Do()
+	}).Context(ctx).Do()
 
 	if err != nil {
 		return fmt.Errorf("failed to update project IAM policy: %w", err)
@@ -70,13 +70,13 @@ func GCPAssignProjectRole(gcp *providers.GCPProvider, principal string, roleToGr
 // * Step 2: Remove a binding, or remove the service account from an existing binding for the role to grant
 // * Step 3: Update the project's IAM policy using [setIamPolicy](https://cloud.google.com/resource-manager/reference/rest/v1/projects/setIamPolicy)
 // Note: no error is returned if the principal does not have a binding in the project's IAM policy
-func GCPUnassignProjectRole(gcp *providers.GCPProvider, principal string, roleToRemove string) error {
-	resourceManager, err := cloudresourcemanager.NewService(context.Background(), gcp.Options())
+func GCPUnassignProjectRole(ctx context.Context, gcp *providers.GCPProvider, principal string, roleToRemove string) error {
+	resourceManager, err := cloudresourcemanager.NewService(ctx, gcp.Options())
 	if err != nil {
 		return errors.New("unable to instantiate the GCP cloud resource manager: " + err.Error())
 	}
 
-	projectPolicy, err := resourceManager.Projects.GetIamPolicy(gcp.GetProjectId(), &cloudresourcemanager.GetIamPolicyRequest{}).Do()
+	projectPolicy, err := resourceManager.Projects.GetIamPolicy(gcp.GetProjectId(), &cloudresourcemanager.GetIamPolicyRequest{}).Context(ctx).Do()
 	if err != nil {
 		return errors.New("unable to retrieve the project's IAM policy: " + err.Error())
 	}
@@ -94,7 +94,7 @@ func GCPUnassignProjectRole(gcp *providers.GCPProvider, principal string, roleTo
 		log.Println("Updating project's IAM policy to remove reference to the principal " + principal + " for role " + roleToRemove)
 		_, err := resourceManager.Projects.SetIamPolicy(gcp.GetProjectId(), &cloudresourcemanager.SetIamPolicyRequest{
 			Policy: projectPolicy,
-		}).Do()
+		}).Context(ctx).Do()
 		if err != nil {
 			return errors.New("failed to update project IAM policy: " + err.Error())
 		}
@@ -1,6 +1,9 @@
 package stratus
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 )
 
@@ -17,13 +20,33 @@ type AttackTechnique struct {
 	// Pointer and leads for detection opportunities (multi-line)
 	Detection string `yaml:"-"`
 
+	// Structured detection opportunity, see 'stratus export sigma'
+	DetectionRule *DetectionRule `yaml:"detectionRule,omitempty"`
+
+	// Audit events that the detonation is expected to produce, see 'stratus verify'. When the technique
+	// also has a DetectionRule, each of its selections must match one of these events
+	ExpectedEvents []ExpectedEvent `yaml:"expectedEvents,omitempty"`
+
+	// User-tunable knobs of the detonation and reversion functions. The runner resolves them and passes them
+	// only through the context of DetonateWithContext and RevertWithContext, which read them with
+	// ParametersFromContext. Running a technique that declares parameters along with the legacy Detonate or
+	// Revert function fails, since these would never see them.
+	Parameters []TechniqueParameter `yaml:"parameters,omitempty"`
+
 	// Indicates if the technique is expected to be slow to warm-up or detonate
 	IsSlow bool `yaml:"isSlow"`
 
+	// How much damage the detonation does, see IsDestructive. Defaults to ImpactLow
+	Impact Impact `yaml:"impact,omitempty"`
+
 	// MITRE ATT&CK Tactics to which this technique maps
 	// see https://attack.mitre.org/techniques/enterprise/
 	MitreAttackTactics []mitreattack.Tactic `yaml:"mitreAttackTactics"`
 
+	// MITRE ATT&CK techniques and sub-techniques to which this technique maps, e.g. T1562.008
+	// see https://attack.mitre.org/matrices/enterprise/cloud/
+	MitreAttackTechniques []string `yaml:"mitreAttackTechniques,omitempty"`
+
 	// Mappings to other frameworks
 	FrameworkMappings []FrameworkMappings `yaml:"frameworkmappings,omitempty"`
 
@@ -35,13 +58,110 @@ type AttackTechnique struct {
 
 	// Detonation function
 	// Parameters are the Terraform outputs
+	//
+	// Deprecated: Use DetonateWithContext instead, which is cancelled along with the runner.
 	Detonate func(params map[string]string, providerFactory CloudProviders) error `yaml:"-"`
 
+	// Context-aware detonation function, preferred over Detonate when set
+	// Parameters are the Terraform outputs
+	DetonateWithContext func(ctx context.Context, params map[string]string, providerFactory CloudProviders) error `yaml:"-"`
+
 	// Indicates if the detonation function is idempotent, i.e. if it can be run multiple times without reverting it
 	IsIdempotent bool `yaml:"isIdempotent"`
 
 	// Reversion function, to revert the side effects of a detonation
+	//
+	// Deprecated: Use RevertWithContext instead, which is cancelled along with the runner.
 	Revert func(params map[string]string, providerFactory CloudProviders) error `yaml:"-"`
+
+	// Context-aware reversion function, preferred over Revert when set
+	RevertWithContext func(ctx context.Context, params map[string]string, providerFactory CloudProviders) error `yaml:"-"`
+
+	// Principal to detonate the technique as, built from the Terraform outputs, e.g. a low-privileged role
+	// created at warm-up. The detonation uses the default credentials when unset, and the reversion always
+	// does. An attacker identity set in the configuration file takes precedence.
+	AttackerIdentity func(outputs map[string]string) *Identity `yaml:"-"`
+}
+
+// Impact classifies how much damage the detonation of a technique does.
+type Impact string
+
+const (
+	// ImpactLow is the impact of techniques whose detonation is confined to their own prerequisites,
+	// or can be reverted
+	ImpactLow Impact = "low"
+	// ImpactDestructive is the impact of techniques whose detonation deletes or encrypts data, or
+	// changes the account itself, in a way that cannot always be reverted
+	ImpactDestructive Impact = "destructive"
+)
+
+// TechniqueFunc is the signature of context-aware detonation and reversion functions.
+type TechniqueFunc func(ctx context.Context, params map[string]string, providerFactory CloudProviders) error
+
+// WithContext adapts a detonation or reversion function using the legacy signature to a TechniqueFunc.
+// Since the legacy function cannot observe the context, cancellation is only honored before it starts.
+func WithContext(legacy func(params map[string]string, providerFactory CloudProviders) error) TechniqueFunc {
+	if legacy == nil {
+		return nil
+	}
+	return func(ctx context.Context, params map[string]string, providerFactory CloudProviders) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return legacy(params, providerFactory)
+	}
+}
+
+// WithoutContext adapts a TechniqueFunc to the legacy signature, running it with a background context.
+func WithoutContext(fn TechniqueFunc) func(params map[string]string, providerFactory CloudProviders) error {
+	if fn == nil {
+		return nil
+	}
+	return func(params map[string]string, providerFactory CloudProviders) error {
+		return fn(context.Background(), params, providerFactory)
+	}
+}
+
+// GetDetonate returns the detonation function of the technique, preferring DetonateWithContext
+// and falling back to an adapter around Detonate. Returns nil when the technique defines neither.
+func (m AttackTechnique) GetDetonate() TechniqueFunc {
+	if m.DetonateWithContext != nil {
+		return m.DetonateWithContext
+	}
+	return WithContext(m.Detonate)
+}
+
+// GetRevert returns the reversion function of the technique, preferring RevertWithContext
+// and falling back to an adapter around Revert. Returns nil when the technique cannot be reverted.
+func (m AttackTechnique) GetRevert() TechniqueFunc {
+	if m.RevertWithContext != nil {
+		return m.RevertWithContext
+	}
+	return WithContext(m.Revert)
+}
+
+// IsRevertible indicates if the technique has a reversion function
+func (m AttackTechnique) IsRevertible() bool {
+	return m.RevertWithContext != nil || m.Revert != nil
+}
+
+// GetMitreAttackTechniques returns the MITRE ATT&CK techniques and sub-techniques the technique maps to,
+// or an error if one of them is not a known cloud or container technique
+func (m AttackTechnique) GetMitreAttackTechniques() ([]mitreattack.Technique, error) {
+	techniques := make([]mitreattack.Technique, 0, len(m.MitreAttackTechniques))
+	for _, id := range m.MitreAttackTechniques {
+		technique, err := mitreattack.AttackTechniqueFromID(id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.ID, err)
+		}
+		techniques = append(techniques, technique)
+	}
+	return techniques, nil
+}
+
+// IsDestructive indicates if detonating the technique requires an explicit confirmation
+func (m AttackTechnique) IsDestructive() bool {
+	return m.Impact == ImpactDestructive
 }
 
 func (m AttackTechnique) String() string {
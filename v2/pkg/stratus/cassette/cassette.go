@@ -0,0 +1,234 @@
+// Package cassette records the HTTP exchanges of attack techniques with cloud APIs, and replays them
+// offline for regression tests.
+//
+// Recording is enabled by setting STRATUS_RECORD to a directory: the runner then stores a cassette per
+// detonation and reversion, under <directory>/<technique-id>/<operation>.yaml. Cassettes are sanitized
+// before being written: credentials and AWS account IDs are scrubbed, and only the headers needed to
+// replay the exchanges are kept.
+//
+// Replaying a cassette answers the calls of the technique with the recorded responses, without network
+// access, and fails if the technique does not make the recorded calls in the same order. See
+// stratustest.ReplayCassettes to replay them in tests.
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvVarRecord is the directory to record cassettes in
+	EnvVarRecord = "STRATUS_RECORD"
+
+	// FileExtension is the extension of cassette files
+	FileExtension = ".yaml"
+
+	// Operations whose HTTP exchanges are recorded
+	OperationDetonate = "detonate"
+	OperationRevert   = "revert"
+
+	// Values scrubbed identifiers are replaced with
+	RedactedAccountID      = "123456789012"
+	RedactedSubscriptionID = "00000000-0000-0000-0000-000000000000"
+	RedactedProjectID      = "stratus-red-team-project"
+	Redacted               = "REDACTED"
+)
+
+// Cassette holds the HTTP exchanges of a technique with cloud APIs during an operation, along with what
+// is needed to run the operation again offline
+type Cassette struct {
+	TechniqueID string            `yaml:"technique"`
+	Operation   string            `yaml:"operation"`
+	Environment Environment       `yaml:"environment"`
+	Outputs     map[string]string `yaml:"outputs,omitempty"`
+	Parameters  map[string]string `yaml:"parameters,omitempty"`
+	// Error is the error the operation failed with, if any
+	Error        string        `yaml:"error,omitempty"`
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Environment is where the recorded operation ran
+type Environment struct {
+	AWSRegion           string `yaml:"awsRegion,omitempty"`
+	GCPProjectID        string `yaml:"gcpProjectId,omitempty"`
+	AzureSubscriptionID string `yaml:"azureSubscriptionId,omitempty"`
+	KubernetesHost      string `yaml:"kubernetesHost,omitempty"`
+}
+
+// Interaction is an HTTP request and the response it got
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+type Request struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int               `yaml:"status"`
+	Headers    map[string]string `yaml:"headers,omitempty"`
+	Body       string            `yaml:"body,omitempty"`
+}
+
+// Path returns the path of the cassette of a technique operation, under directory
+func Path(directory string, techniqueID string, operation string) string {
+	return filepath.Join(directory, techniqueID, operation+FileExtension)
+}
+
+// DirectoryFromEnv returns the directory to record cassettes in, if recording is enabled
+func DirectoryFromEnv() string {
+	return os.Getenv(EnvVarRecord)
+}
+
+// Load reads a cassette from a file
+func Load(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := yaml.Unmarshal(raw, &cassette); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to a file, creating its directory if needed
+func (m *Cassette) Save(path string) error {
+	raw, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0744); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+// key identifies a request when replaying a cassette. Request bodies and query strings are not part of it,
+// since techniques often generate random names, but AWS operations are.
+func (m Request) key() (string, error) {
+	parsed, err := url.Parse(m.URL)
+	if err != nil {
+		return "", err
+	}
+	key := m.Method + " " + parsed.Host + parsed.Path
+	if target := m.Headers["X-Amz-Target"]; target != "" {
+		key += " " + target
+	} else if action := formValue(m, "Action"); action != "" {
+		key += " " + action
+	}
+	return key, nil
+}
+
+func formValue(request Request, name string) string {
+	if !strings.HasPrefix(request.Headers["Content-Type"], "application/x-www-form-urlencoded") {
+		return ""
+	}
+	values, err := url.ParseQuery(request.Body)
+	if err != nil {
+		return ""
+	}
+	return values.Get(name)
+}
+
+// keptRequestHeaders and keptResponseHeaders are the only headers stored in cassettes, so that credentials,
+// cookies or request IDs are never persisted
+var keptRequestHeaders = []string{"Content-Type", "X-Amz-Target"}
+var keptResponseHeaders = []string{
+	"Content-Type", "X-Amzn-Errortype", "X-Amzn-Query-Error", "Location", "Azure-Asyncoperation", "Operation-Location", "Retry-After",
+}
+
+func keepHeaders(header http.Header, names []string) map[string]string {
+	kept := map[string]string{}
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			kept[name] = value
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+var (
+	// GUIDs are matched too, so that their last group is not mistaken for an account ID
+	awsAccountID   = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}|\b\d{12}\b`)
+	awsAccessKeyID = regexp.MustCompile(`\b(AKIA|ASIA)[A-Z0-9]{16}\b`)
+	secretFields   = `(?i:SecretAccessKey|SessionToken|Password|SecretString|SecretBinary|PrivateKeyData|` +
+		`access_?token|refresh_?token|id_?token|client_?secret)`
+	secretJSONField = regexp.MustCompile(`("` + secretFields + `"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	secretXMLField  = regexp.MustCompile(`<(` + secretFields + `)>[^<]*</`)
+	secretFormField = regexp.MustCompile(`(^|&)(` + secretFields + `)=[^&]*`)
+)
+
+// Sanitizer scrubs credentials and identifiers from the exchanges recorded in cassettes
+type Sanitizer struct {
+	mutex    sync.Mutex
+	pairs    []string
+	replacer *strings.Replacer
+}
+
+// NewSanitizer returns a sanitizer scrubbing credentials and AWS account IDs
+func NewSanitizer() *Sanitizer {
+	return &Sanitizer{replacer: strings.NewReplacer()}
+}
+
+// Redact replaces value with redacted in everything sanitized from now on, e.g. the ID of an Azure subscription
+func (m *Sanitizer) Redact(value string, redacted string) {
+	if value == "" || value == redacted {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pairs = append(m.pairs, value, redacted)
+	m.replacer = strings.NewReplacer(m.pairs...)
+}
+
+// Sanitize scrubs a URL, body or output
+func (m *Sanitizer) Sanitize(value string) string {
+	m.mutex.Lock()
+	value = m.replacer.Replace(value)
+	m.mutex.Unlock()
+	value = secretJSONField.ReplaceAllString(value, `$1"`+Redacted+`"`)
+	value = secretXMLField.ReplaceAllString(value, `<$1>`+Redacted+`</`)
+	value = secretFormField.ReplaceAllString(value, `$1$2=`+Redacted)
+	value = awsAccessKeyID.ReplaceAllString(value, "${1}"+strings.Repeat("X", 16))
+	return awsAccountID.ReplaceAllStringFunc(value, func(match string) string {
+		if len(match) != len(RedactedAccountID) {
+			return match
+		}
+		return RedactedAccountID
+	})
+}
+
+func (m *Sanitizer) sanitizeMap(values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	sanitized := make(map[string]string, len(values))
+	for name, value := range values {
+		sanitized[name] = m.Sanitize(value)
+	}
+	return sanitized
+}
+
+// ErrUnexpectedRequest is returned when a replayed technique makes a call that was not recorded
+var ErrUnexpectedRequest = errors.New("unexpected request")
@@ -0,0 +1,50 @@
+package cassette
+
+import (
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/google/uuid"
+)
+
+// Recording records the exchanges of a technique operation with cloud APIs
+type Recording struct {
+	cassette  Cassette
+	sanitizer *Sanitizer
+	recorder  *Recorder
+	providers *CloudProviders
+}
+
+// StartRecording starts recording an operation of a technique, run with the given Terraform outputs
+// and parameters
+func StartRecording(correlationID uuid.UUID, techniqueID string, operation string, outputs map[string]string, parameters map[string]string) *Recording {
+	sanitizer := NewSanitizer()
+	recorder := NewRecorder(sanitizer)
+	return &Recording{
+		cassette: Cassette{
+			TechniqueID: techniqueID,
+			Operation:   operation,
+			Outputs:     outputs,
+			Parameters:  parameters,
+		},
+		sanitizer: sanitizer,
+		recorder:  recorder,
+		providers: NewRecordingProviders(correlationID, recorder, sanitizer),
+	}
+}
+
+// Providers returns the cloud providers to run the operation with
+func (m *Recording) Providers() stratus.CloudProviders {
+	return m.providers
+}
+
+// Save writes the cassette of the operation, which failed with operationErr if not nil
+func (m *Recording) Save(path string, operationErr error) error {
+	cassette := m.cassette
+	cassette.Environment = m.providers.Environment()
+	cassette.Outputs = m.sanitizer.sanitizeMap(cassette.Outputs)
+	cassette.Parameters = m.sanitizer.sanitizeMap(cassette.Parameters)
+	cassette.Interactions = m.recorder.Interactions()
+	if operationErr != nil {
+		cassette.Error = m.sanitizer.Sanitize(operationErr.Error())
+	}
+	return cassette.Save(path)
+}
@@ -0,0 +1,190 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// roundTripperFunc turns a function into an http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (m roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return m(request)
+}
+
+// Recorder records the HTTP exchanges going through the transports it wraps, sanitized
+type Recorder struct {
+	sanitizer    *Sanitizer
+	mutex        sync.Mutex
+	interactions []Interaction
+}
+
+func NewRecorder(sanitizer *Sanitizer) *Recorder {
+	return &Recorder{sanitizer: sanitizer}
+}
+
+// Wrap returns a transport sending requests through next, and recording them along with their responses
+func (m *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		request, requestBody, err := withReplayableBody(request)
+		if err != nil {
+			return nil, err
+		}
+		response, err := next.RoundTrip(request)
+		if err != nil {
+			// Network errors cannot be replayed, the technique is expected to fail on them anyway
+			return nil, err
+		}
+		responseBody, err := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		m.interactions = append(m.interactions, Interaction{
+			Request: Request{
+				Method:  request.Method,
+				URL:     m.sanitizer.Sanitize(request.URL.String()),
+				Headers: keepHeaders(request.Header, keptRequestHeaders),
+				Body:    m.sanitizer.Sanitize(string(requestBody)),
+			},
+			Response: Response{
+				StatusCode: response.StatusCode,
+				Headers:    m.sanitizer.sanitizeMap(keepHeaders(response.Header, keptResponseHeaders)),
+				Body:       m.sanitizer.Sanitize(string(responseBody)),
+			},
+		})
+		return response, nil
+	})
+}
+
+// Interactions returns the exchanges recorded so far, in order
+func (m *Recorder) Interactions() []Interaction {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]Interaction{}, m.interactions...)
+}
+
+// Player answers requests with the responses of a cassette, provided that they are the recorded requests,
+// in the same order. It never sends requests over the network.
+type Player struct {
+	sanitizer    *Sanitizer
+	mutex        sync.Mutex
+	interactions []Interaction
+	played       int
+	errs         []error
+}
+
+func NewPlayer(interactions []Interaction) *Player {
+	return &Player{sanitizer: NewSanitizer(), interactions: interactions}
+}
+
+func (m *Player) RoundTrip(request *http.Request) (*http.Response, error) {
+	request, requestBody, err := withReplayableBody(request)
+	if err != nil {
+		return nil, err
+	}
+	actual := Request{
+		Method:  request.Method,
+		URL:     m.sanitizer.Sanitize(request.URL.String()),
+		Headers: keepHeaders(request.Header, keptRequestHeaders),
+		Body:    m.sanitizer.Sanitize(string(requestBody)),
+	}
+	actualKey, err := actual.key()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.played >= len(m.interactions) {
+		err := fmt.Errorf("%w #%d: %s, the cassette has no more interactions", ErrUnexpectedRequest, m.played+1, actualKey)
+		m.errs = append(m.errs, err)
+		return nil, replayError{err}
+	}
+	interaction := m.interactions[m.played]
+	expectedKey, err := interaction.Request.key()
+	if err != nil {
+		return nil, err
+	}
+	if actualKey != expectedKey {
+		err := fmt.Errorf("%w #%d: %s, expected %s", ErrUnexpectedRequest, m.played+1, actualKey, expectedKey)
+		m.errs = append(m.errs, err)
+		return nil, replayError{err}
+	}
+	m.played++
+
+	header := http.Header{}
+	for name, value := range interaction.Response.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       request,
+	}, nil
+}
+
+// replayError is returned for requests that were not recorded. SDKs must not retry them, since the
+// cassette would not match any better.
+type replayError struct {
+	err error
+}
+
+func (m replayError) Error() string { return m.err.Error() }
+func (m replayError) Unwrap() error { return m.err }
+
+// RetryableError prevents the AWS SDK from retrying
+func (m replayError) RetryableError() bool { return false }
+
+// NonRetriable prevents the Azure SDK from retrying
+func (m replayError) NonRetriable() {}
+
+// Err returns the requests that did not match the cassette, and the recorded requests that were not made
+func (m *Player) Err() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	errs := append([]error{}, m.errs...)
+	if m.played < len(m.interactions) {
+		missing := make([]string, 0, len(m.interactions)-m.played)
+		for _, interaction := range m.interactions[m.played:] {
+			key, _ := interaction.Request.key()
+			missing = append(missing, key)
+		}
+		errs = append(errs, fmt.Errorf("%d recorded requests were not made: %s", len(missing), strings.Join(missing, ", ")))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the calls of the technique differ from its cassette:\n%w", errors.Join(errs...))
+}
+
+// withReplayableBody reads the body of a request, returning a copy of the request whose body can still be read
+func withReplayableBody(request *http.Request) (*http.Request, []byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return request, nil, nil
+	}
+	body, err := io.ReadAll(request.Body)
+	_ = request.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	request = request.Clone(request.Context())
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	return request, body, nil
+}
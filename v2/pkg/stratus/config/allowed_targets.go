@@ -0,0 +1,48 @@
+package config
+
+// AllowedTargets lists the cloud accounts, projects, subscriptions, tenants and Kubernetes contexts that
+// techniques may be warmed up and detonated in, under allowed_targets.
+//
+// Once the section is set, a platform whose list is empty is not allowed at all.
+type AllowedTargets struct {
+	AWSAccounts        []string `yaml:"aws_accounts"`
+	GCPProjects        []string `yaml:"gcp_projects"`
+	AzureSubscriptions []string `yaml:"azure_subscriptions"`
+	EntraIDTenants     []string `yaml:"entra_id_tenants"`
+	KubernetesContexts []string `yaml:"kubernetes_contexts"`
+}
+
+// GetAllowedTargets returns the allowlist of targets, or nil if the configuration file does not restrict them.
+func (c *ConfigImpl) GetAllowedTargets() *AllowedTargets {
+	if c == nil {
+		return nil
+	}
+	return c.allowedTargets
+}
+
+// parseAllowedTargets reads the allowed_targets section, which the schema has already validated
+func parseAllowedTargets(raw map[string]any) *AllowedTargets {
+	section, isSet := raw["allowed_targets"]
+	if !isSet {
+		return nil
+	}
+	targets := toStringMap(section)
+	return &AllowedTargets{
+		AWSAccounts:        toStringList(targets["aws_accounts"]),
+		GCPProjects:        toStringList(targets["gcp_projects"]),
+		AzureSubscriptions: toStringList(targets["azure_subscriptions"]),
+		EntraIDTenants:     toStringList(targets["entra_id_tenants"]),
+		KubernetesContexts: toStringList(targets["kubernetes_contexts"]),
+	}
+}
+
+func toStringList(v any) []string {
+	items, _ := v.([]any)
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if value, isString := item.(string); isString {
+			values = append(values, value)
+		}
+	}
+	return values
+}
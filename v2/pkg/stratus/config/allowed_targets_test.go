@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAllowedTargets(t *testing.T) {
+	cfg := newTestConfig(`
+allowed_targets:
+  aws_accounts: ["012345678901", "123456789012"]
+  kubernetes_contexts: [kind-stratus]
+`)
+
+	assert.Equal(t, &AllowedTargets{
+		AWSAccounts:        []string{"012345678901", "123456789012"},
+		GCPProjects:        []string{},
+		AzureSubscriptions: []string{},
+		EntraIDTenants:     []string{},
+		KubernetesContexts: []string{"kind-stratus"},
+	}, cfg.GetAllowedTargets())
+	assert.Nil(t, newTestConfig(`techniques: {}`).GetAllowedTargets())
+	assert.Nil(t, (*ConfigImpl)(nil).GetAllowedTargets())
+}
+
+func TestValidateAllowedTargets(t *testing.T) {
+	require.NoError(t, validateConfig([]byte(`
+allowed_targets:
+  aws_accounts: ["012345678901"]
+  gcp_projects: [my-project]
+  azure_subscriptions: [45e0ad3f-ff94-499a-a2f0-bbb884e9c4a3]
+  entra_id_tenants: [e0a8dc1e-7b8d-4f2b-a5d1-a3b2c0e9f8d7]
+`)))
+
+	// Unquoted, YAML parses account IDs as integers and drops their leading zeros
+	assert.Error(t, validateConfig([]byte(`
+allowed_targets:
+  aws_accounts: [012345678901]
+`)))
+	assert.Error(t, validateConfig([]byte(`
+allowed_targets:
+  aws_accounts: ["1234"]
+`)))
+	assert.Error(t, validateConfig([]byte(`
+allowed_targets:
+  gcp_accounts: [my-project]
+`)))
+}
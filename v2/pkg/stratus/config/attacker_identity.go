@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVarAttackerAzureClientSecret is the secret of the Azure service principal of the attacker identity, when
+// the configuration file does not set it
+const EnvVarAttackerAzureClientSecret = "STRATUS_ATTACKER_AZURE_CLIENT_SECRET"
+
+// AttackerIdentity is a principal that techniques are detonated as, instead of the credentials their
+// prerequisites are warmed up with, under attacker_identity or techniques.<id>.attacker_identity.
+//
+// Only the platforms it sets are affected, the others keep the default credentials.
+type AttackerIdentity struct {
+	AWS   *AWSAttackerIdentity   `yaml:"aws,omitempty"`
+	GCP   *GCPAttackerIdentity   `yaml:"gcp,omitempty"`
+	Azure *AzureAttackerIdentity `yaml:"azure,omitempty"`
+}
+
+// AWSAttackerIdentity is a chain of IAM roles, each assumed with the credentials of the previous one, and the
+// first one with the default credentials
+type AWSAttackerIdentity struct {
+	RoleARNs []string `yaml:"role_arns"`
+	// ExternalID is passed when assuming the last role of the chain, if set
+	ExternalID string `yaml:"external_id,omitempty"`
+}
+
+// GCPAttackerIdentity is a service account, impersonated with the default credentials
+type GCPAttackerIdentity struct {
+	ServiceAccount string `yaml:"service_account"`
+	// Delegates are the service accounts of a delegation chain, each allowed to impersonate the next one
+	Delegates []string `yaml:"delegates,omitempty"`
+}
+
+// AzureAttackerIdentity is a service principal, used for both Azure and Entra ID
+type AzureAttackerIdentity struct {
+	TenantID string `yaml:"tenant_id"`
+	ClientID string `yaml:"client_id"`
+	// ClientSecret defaults to STRATUS_ATTACKER_AZURE_CLIENT_SECRET, to keep it out of the configuration file
+	ClientSecret string `yaml:"client_secret,omitempty"`
+}
+
+func (m AttackerIdentity) String() string {
+	var principals []string
+	if m.AWS != nil && len(m.AWS.RoleARNs) > 0 {
+		principals = append(principals, "AWS role "+m.AWS.RoleARNs[len(m.AWS.RoleARNs)-1])
+	}
+	if m.GCP != nil {
+		principals = append(principals, "GCP service account "+m.GCP.ServiceAccount)
+	}
+	if m.Azure != nil {
+		principals = append(principals, "Azure service principal "+m.Azure.ClientID)
+	}
+	return strings.Join(principals, ", ")
+}
+
+// GetAttackerIdentity returns the identity to detonate a technique as, set under techniques.<id>.attacker_identity
+// or else under attacker_identity, or nil to detonate it with the default credentials.
+func (c *ConfigImpl) GetAttackerIdentity(techniqueID string) *AttackerIdentity {
+	if c == nil || c.techniques == nil {
+		return nil
+	}
+	raw := c.techniques.raw
+	if section, isSet := toStringMap(toStringMap(raw["techniques"])[techniqueID])["attacker_identity"]; isSet {
+		return parseAttackerIdentity(section)
+	}
+	if section, isSet := raw["attacker_identity"]; isSet {
+		return parseAttackerIdentity(section)
+	}
+	return nil
+}
+
+// parseAttackerIdentity reads an attacker_identity section, which the schema has already validated
+func parseAttackerIdentity(section any) *AttackerIdentity {
+	raw, err := yaml.Marshal(section)
+	if err != nil {
+		return nil
+	}
+	var identity AttackerIdentity
+	if err := yaml.Unmarshal(raw, &identity); err != nil {
+		return nil
+	}
+	if identity.Azure != nil && identity.Azure.ClientSecret == "" {
+		identity.Azure.ClientSecret = os.Getenv(EnvVarAttackerAzureClientSecret)
+	}
+	return &identity
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAttackerIdentity(t *testing.T) {
+	t.Setenv(EnvVarAttackerAzureClientSecret, "secret-from-env")
+	cfg := newTestConfig(`
+attacker_identity:
+  aws:
+    role_arns: ["arn:aws:iam::123456789012:role/attacker"]
+  azure:
+    tenant_id: tenant
+    client_id: client
+techniques:
+  "aws.persistence.iam-create-admin-user":
+    attacker_identity:
+      aws:
+        role_arns: ["arn:aws:iam::123456789012:role/pivot", "arn:aws:iam::210987654321:role/attacker"]
+        external_id: stratus
+`)
+
+	assert.Equal(t, &AttackerIdentity{
+		AWS:   &AWSAttackerIdentity{RoleARNs: []string{"arn:aws:iam::123456789012:role/attacker"}},
+		Azure: &AzureAttackerIdentity{TenantID: "tenant", ClientID: "client", ClientSecret: "secret-from-env"},
+	}, cfg.GetAttackerIdentity("aws.defense-evasion.cloudtrail-stop"))
+
+	// Technique-specific identities replace the default one entirely
+	assert.Equal(t, &AttackerIdentity{
+		AWS: &AWSAttackerIdentity{
+			RoleARNs:   []string{"arn:aws:iam::123456789012:role/pivot", "arn:aws:iam::210987654321:role/attacker"},
+			ExternalID: "stratus",
+		},
+	}, cfg.GetAttackerIdentity("aws.persistence.iam-create-admin-user"))
+
+	assert.Nil(t, newTestConfig(`techniques: {}`).GetAttackerIdentity("aws.defense-evasion.cloudtrail-stop"))
+	assert.Nil(t, (*ConfigImpl)(nil).GetAttackerIdentity("aws.defense-evasion.cloudtrail-stop"))
+}
+
+func TestAttackerIdentityString(t *testing.T) {
+	identity := AttackerIdentity{
+		AWS: &AWSAttackerIdentity{RoleARNs: []string{"arn:aws:iam::123456789012:role/pivot", "arn:aws:iam::123456789012:role/attacker"}},
+		GCP: &GCPAttackerIdentity{ServiceAccount: "attacker@project.iam.gserviceaccount.com"},
+	}
+	assert.Equal(t, "AWS role arn:aws:iam::123456789012:role/attacker, GCP service account attacker@project.iam.gserviceaccount.com", identity.String())
+}
+
+func TestValidateAttackerIdentity(t *testing.T) {
+	require.NoError(t, validateConfig([]byte(`
+attacker_identity:
+  aws:
+    role_arns: ["arn:aws:iam::123456789012:role/attacker"]
+  gcp:
+    service_account: attacker@project.iam.gserviceaccount.com
+    delegates: [pivot@project.iam.gserviceaccount.com]
+techniques:
+  "azure.execution.vm-run-command":
+    attacker_identity:
+      azure:
+        tenant_id: tenant
+        client_id: client
+        client_secret: secret
+`)))
+
+	assert.Error(t, validateConfig([]byte(`
+attacker_identity:
+  aws:
+    role_arns: ["arn:aws:iam::123456789012:user/attacker"]
+`)))
+	assert.Error(t, validateConfig([]byte(`
+attacker_identity:
+  gcp:
+    delegates: [pivot@project.iam.gserviceaccount.com]
+`)))
+	assert.Error(t, validateConfig([]byte(`
+attacker_identity:
+  azure:
+    client_id: client
+`)))
+}
@@ -1,18 +1,40 @@
 package config
 
+import "os"
+
+// EnvVarAWSEndpointURL is the standard AWS SDK variable that sends all AWS API calls to another endpoint,
+// e.g. LocalStack. It takes precedence over aws.endpoint_url in the configuration file.
+const EnvVarAWSEndpointURL = "AWS_ENDPOINT_URL"
+
 // AWSConfigImpl merges global AWS defaults with technique-specific settings
 // before they are passed to Terraform.
 type AWSConfigImpl struct {
 	raw map[string]any
 }
 
+// getEndpointURL returns the endpoint that AWS API calls are sent to instead of the AWS ones, if any
+func (a *AWSConfigImpl) getEndpointURL() string {
+	if endpointURL := os.Getenv(EnvVarAWSEndpointURL); endpointURL != "" {
+		return endpointURL
+	}
+	if a == nil {
+		return ""
+	}
+	endpointURL, _ := toStringMap(a.raw["aws"])["endpoint_url"].(string)
+	return endpointURL
+}
+
 func (a *AWSConfigImpl) getMergedConfig(techniqueID string, vars SubstitutionVars) map[string]any {
-	if a == nil || a.raw == nil {
+	if a == nil {
 		return nil
 	}
 
 	awsRaw := toStringMap(a.raw["aws"])
 	merged := make(map[string]any)
+	// Not a technique setting: the same endpoint is used for the SDK calls of every technique
+	if endpointURL := a.getEndpointURL(); endpointURL != "" {
+		merged["endpoint_url"] = endpointURL
+	}
 	if defaultRaw := awsRaw["default"]; defaultRaw != nil {
 		deepMerge(merged, cloneStringMap(defaultRaw))
 	}
@@ -0,0 +1,29 @@
+# Merged by Terraform into the "aws" provider block of AWS techniques, to send their API calls to
+# var.config.aws.endpoint_url when it is set, e.g. to LocalStack
+provider "aws" {
+  # Emulators are not reachable through virtual-hosted-style bucket URLs, e.g. bucket.localhost
+  s3_use_path_style = var.config.aws.endpoint_url != ""
+
+  dynamic "endpoints" {
+    for_each = var.config.aws.endpoint_url == "" ? [] : [var.config.aws.endpoint_url]
+    content {
+      autoscaling     = endpoints.value
+      cloudtrail      = endpoints.value
+      cloudwatchlogs  = endpoints.value
+      ec2             = endpoints.value
+      eks             = endpoints.value
+      iam             = endpoints.value
+      kms             = endpoints.value
+      lambda          = endpoints.value
+      organizations   = endpoints.value
+      rds             = endpoints.value
+      rolesanywhere   = endpoints.value
+      route53resolver = endpoints.value
+      s3              = endpoints.value
+      sagemaker       = endpoints.value
+      secretsmanager  = endpoints.value
+      ssm             = endpoints.value
+      sts             = endpoints.value
+    }
+  }
+}
@@ -115,3 +115,30 @@ aws:
 	assert.Equal(t, "production", override["tags"].(map[string]any)["Environment"])
 	assert.Equal(t, "test", defaults["tags"].(map[string]any)["Environment"])
 }
+
+func TestAWSEndpointURL(t *testing.T) {
+	cfg := newTestConfig(`
+aws:
+  endpoint_url: http://localhost.localstack.cloud:4566
+  default:
+    prefix: test-
+`)
+	t.Setenv(EnvVarAWSEndpointURL, "")
+	assert.Equal(t, "http://localhost.localstack.cloud:4566", cfg.GetAWSEndpointURL())
+
+	var parsed map[string]any
+	require.NoError(t, json.Unmarshal([]byte(cfg.GetTerraformVariables("aws.test.technique", SubstitutionVars{})["config"]), &parsed))
+	assert.Equal(t, map[string]any{
+		"aws": map[string]any{
+			"prefix":       "test-",
+			"endpoint_url": "http://localhost.localstack.cloud:4566",
+		},
+	}, parsed)
+
+	// The environment variable takes precedence, and is applied even without a configuration file
+	t.Setenv(EnvVarAWSEndpointURL, "http://127.0.0.1:5000")
+	assert.Equal(t, "http://127.0.0.1:5000", cfg.GetAWSEndpointURL())
+	noConfig := newTestConfig("")
+	assert.Equal(t, "http://127.0.0.1:5000", noConfig.GetAWSEndpointURL())
+	assert.JSONEq(t, `{"aws": {"endpoint_url": "http://127.0.0.1:5000"}}`, noConfig.GetTerraformVariables("aws.test.technique", SubstitutionVars{})["config"])
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/google/uuid"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
@@ -35,12 +36,24 @@ func newViper() *viper.Viper {
 type Config interface {
 	GetKubernetesConfig() KubernetesConfig
 	GetTerraformVariables(techniqueID string, vars SubstitutionVars) map[string]string
+	GetTechniqueParameters(techniqueID string) map[string]string
+	GetAllowedTargets() *AllowedTargets
+	GetAttackerIdentity(techniqueID string) *AttackerIdentity
+	GetTargets() []Target
+	GetStateBackend() string
+	GetCorrelationID() string
+	GetAWSEndpointURL() string
 }
 
 type ConfigImpl struct {
-	aws        *AWSConfigImpl
-	kubernetes *KubernetesConfigImpl
-	v          *viper.Viper
+	aws            *AWSConfigImpl
+	kubernetes     *KubernetesConfigImpl
+	techniques     *TechniquesConfigImpl
+	allowedTargets *AllowedTargets
+	targets        []Target
+	stateBackend   string
+	correlationID  uuid.UUID
+	v              *viper.Viper
 }
 
 var _ Config = &ConfigImpl{}
@@ -67,10 +80,27 @@ func LoadConfig() (Config, error) {
 			return nil, err
 		}
 	}
+	targets, err := parseTargets(raw)
+	if err != nil {
+		return nil, err
+	}
+	stateBackend, err := parseStateBackend(raw)
+	if err != nil {
+		return nil, err
+	}
+	correlationID, err := parseCorrelationID(raw)
+	if err != nil {
+		return nil, err
+	}
 	return &ConfigImpl{
-		aws:        &AWSConfigImpl{raw: raw},
-		kubernetes: &KubernetesConfigImpl{v: v},
-		v:          v,
+		aws:            &AWSConfigImpl{raw: raw},
+		kubernetes:     &KubernetesConfigImpl{v: v},
+		techniques:     &TechniquesConfigImpl{raw: raw},
+		allowedTargets: parseAllowedTargets(raw),
+		targets:        targets,
+		stateBackend:   stateBackend,
+		correlationID:  correlationID,
+		v:              v,
 	}, nil
 }
 
@@ -105,6 +135,15 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
+// GetAWSEndpointURL returns the endpoint that AWS API calls are sent to instead of the AWS ones, from
+// AWS_ENDPOINT_URL or aws.endpoint_url, or an empty string to use the AWS ones.
+func (c *ConfigImpl) GetAWSEndpointURL() string {
+	if c == nil {
+		return os.Getenv(EnvVarAWSEndpointURL)
+	}
+	return c.aws.getEndpointURL()
+}
+
 func (c *ConfigImpl) GetKubernetesConfig() KubernetesConfig {
 	return c.kubernetes
 }
@@ -1,9 +1,10 @@
 variable "config" {
   type = object({
     aws = optional(object({
-      prefix = optional(string, "")
-      tags   = optional(map(string), {})
-    }), { prefix = "", tags = {} })
+      prefix       = optional(string, "")
+      tags         = optional(map(string), {})
+      endpoint_url = optional(string, "")
+    }), { prefix = "", tags = {}, endpoint_url = "" })
     kubernetes = optional(object({
       namespace = optional(string, "")
       pod = optional(object({
@@ -23,8 +24,9 @@ variable "config" {
   })
   default = {
     aws = {
-      prefix = ""
-      tags   = {}
+      prefix       = ""
+      tags         = {}
+      endpoint_url = ""
     }
     kubernetes = {
       namespace = ""
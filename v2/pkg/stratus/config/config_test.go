@@ -54,10 +54,18 @@ func newTestConfig(yamlStr string) *ConfigImpl {
 	_ = v.ReadConfig(strings.NewReader(yamlStr))
 	var raw map[string]any
 	_ = yaml.Unmarshal([]byte(yamlStr), &raw)
+	targets, _ := parseTargets(raw)
+	stateBackend, _ := parseStateBackend(raw)
+	correlationID, _ := parseCorrelationID(raw)
 	return &ConfigImpl{
-		aws:        &AWSConfigImpl{raw: raw},
-		kubernetes: &KubernetesConfigImpl{v: v},
-		v:          v,
+		aws:            &AWSConfigImpl{raw: raw},
+		kubernetes:     &KubernetesConfigImpl{v: v},
+		techniques:     &TechniquesConfigImpl{raw: raw},
+		allowedTargets: parseAllowedTargets(raw),
+		targets:        targets,
+		stateBackend:   stateBackend,
+		correlationID:  correlationID,
+		v:              v,
 	}
 }
 
@@ -12,6 +12,82 @@ type Config struct {
 	mock.Mock
 }
 
+// GetAWSEndpointURL provides a mock function with no fields
+func (_m *Config) GetAWSEndpointURL() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAWSEndpointURL")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetAllowedTargets provides a mock function with no fields
+func (_m *Config) GetAllowedTargets() *config.AllowedTargets {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllowedTargets")
+	}
+
+	var r0 *config.AllowedTargets
+	if rf, ok := ret.Get(0).(func() *config.AllowedTargets); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.AllowedTargets)
+		}
+	}
+
+	return r0
+}
+
+// GetAttackerIdentity provides a mock function with given fields: techniqueID
+func (_m *Config) GetAttackerIdentity(techniqueID string) *config.AttackerIdentity {
+	ret := _m.Called(techniqueID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttackerIdentity")
+	}
+
+	var r0 *config.AttackerIdentity
+	if rf, ok := ret.Get(0).(func(string) *config.AttackerIdentity); ok {
+		r0 = rf(techniqueID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.AttackerIdentity)
+		}
+	}
+
+	return r0
+}
+
+// GetCorrelationID provides a mock function with no fields
+func (_m *Config) GetCorrelationID() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCorrelationID")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // GetKubernetesConfig provides a mock function with no fields
 func (_m *Config) GetKubernetesConfig() config.KubernetesConfig {
 	ret := _m.Called()
@@ -32,6 +108,64 @@ func (_m *Config) GetKubernetesConfig() config.KubernetesConfig {
 	return r0
 }
 
+// GetStateBackend provides a mock function with no fields
+func (_m *Config) GetStateBackend() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStateBackend")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetTargets provides a mock function with no fields
+func (_m *Config) GetTargets() []config.Target {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTargets")
+	}
+
+	var r0 []config.Target
+	if rf, ok := ret.Get(0).(func() []config.Target); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]config.Target)
+		}
+	}
+
+	return r0
+}
+
+// GetTechniqueParameters provides a mock function with given fields: techniqueID
+func (_m *Config) GetTechniqueParameters(techniqueID string) map[string]string {
+	ret := _m.Called(techniqueID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTechniqueParameters")
+	}
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(string) map[string]string); ok {
+		r0 = rf(techniqueID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	return r0
+}
+
 // GetTerraformVariables provides a mock function with given fields: techniqueID, vars
 func (_m *Config) GetTerraformVariables(techniqueID string, vars config.SubstitutionVars) map[string]string {
 	ret := _m.Called(techniqueID, vars)
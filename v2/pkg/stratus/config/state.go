@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// stateBackendSchemes are the URL schemes of the buckets and containers that state_backend can point at
+var stateBackendSchemes = map[string]bool{"s3": true, "gs": true, "azblob": true}
+
+// GetStateBackend returns the URL of the bucket or container to store state in, set with state_backend, e.g.
+// s3://my-bucket/stratus?region=us-east-1, or an empty string to store it on the local filesystem.
+func (c *ConfigImpl) GetStateBackend() string {
+	if c == nil {
+		return ""
+	}
+	return c.stateBackend
+}
+
+// GetCorrelationID returns the correlation ID that isolates the state of executions, set with correlation_id,
+// or an empty string to use the flat layout.
+func (c *ConfigImpl) GetCorrelationID() string {
+	if c == nil || c.correlationID == uuid.Nil {
+		return ""
+	}
+	return c.correlationID.String()
+}
+
+func parseStateBackend(raw map[string]any) (string, error) {
+	section, isSet := raw["state_backend"]
+	if !isSet {
+		return "", nil
+	}
+	stateBackend, isString := section.(string)
+	if !isString {
+		return "", fmt.Errorf("state_backend must be a string, got %v", section)
+	}
+	backendURL, err := url.Parse(stateBackend)
+	if err != nil {
+		return "", fmt.Errorf("invalid state_backend %s: %w", stateBackend, err)
+	}
+	if !stateBackendSchemes[backendURL.Scheme] || backendURL.Host == "" {
+		return "", fmt.Errorf("invalid state_backend %s, expected s3://bucket, gs://bucket or azblob://account/container", stateBackend)
+	}
+	return stateBackend, nil
+}
+
+func parseCorrelationID(raw map[string]any) (uuid.UUID, error) {
+	section, isSet := raw["correlation_id"]
+	if !isSet {
+		return uuid.Nil, nil
+	}
+	rawCorrelationID, isString := section.(string)
+	if !isString {
+		return uuid.Nil, fmt.Errorf("correlation_id must be a string, got %v", section)
+	}
+	correlationID, err := uuid.Parse(rawCorrelationID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid correlation_id %s: %w", rawCorrelationID, err)
+	}
+	return correlationID, nil
+}
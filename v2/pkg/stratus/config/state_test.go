@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStateSettings(t *testing.T) {
+	cfg := newTestConfig(`
+state_backend: "s3://my-bucket/stratus?region=eu-west-1"
+correlation_id: "7e1f3b5c-2d4a-4e8f-9b6c-0a1d2e3f4a5b"
+`)
+
+	assert.Equal(t, "s3://my-bucket/stratus?region=eu-west-1", cfg.GetStateBackend())
+	assert.Equal(t, "7e1f3b5c-2d4a-4e8f-9b6c-0a1d2e3f4a5b", cfg.GetCorrelationID())
+	assert.Empty(t, newTestConfig(`techniques: {}`).GetStateBackend())
+	assert.Empty(t, (*ConfigImpl)(nil).GetCorrelationID())
+}
+
+func TestValidateStateSettings(t *testing.T) {
+	require.NoError(t, validateConfig([]byte(`
+state_backend: "gs://my-bucket"
+correlation_id: "7e1f3b5c-2d4a-4e8f-9b6c-0a1d2e3f4a5b"
+`)))
+	require.NoError(t, validateConfig([]byte(`state_backend: "azblob://myaccount/stratus"`)))
+
+	assert.Error(t, validateConfig([]byte(`state_backend: "/var/lib/stratus"`)))
+	assert.Error(t, validateConfig([]byte(`state_backend: "s3://"`)))
+	assert.Error(t, validateConfig([]byte(`correlation_id: "my-execution"`)))
+}
+
+func TestParseStateSettings(t *testing.T) {
+	stateBackend, err := parseStateBackend(map[string]any{"state_backend": "gs://my-bucket/stratus"})
+	require.NoError(t, err)
+	assert.Equal(t, "gs://my-bucket/stratus", stateBackend)
+	correlationID, err := parseCorrelationID(map[string]any{"correlation_id": "7E1F3B5C-2D4A-4E8F-9B6C-0A1D2E3F4A5B"})
+	require.NoError(t, err)
+	assert.Equal(t, "7e1f3b5c-2d4a-4e8f-9b6c-0a1d2e3f4a5b", correlationID.String())
+
+	for _, invalid := range []any{42, "", "/var/lib/stratus", "file:///var/lib/stratus", "s3:///stratus", "ftp://my-bucket"} {
+		_, err := parseStateBackend(map[string]any{"state_backend": invalid})
+		assert.Error(t, err, invalid)
+	}
+	for _, invalid := range []any{42, "", "my-execution"} {
+		_, err := parseCorrelationID(map[string]any{"correlation_id": invalid})
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Target is an AWS account and region, a GCP project or an Azure subscription that the techniques of its
+// platform are run in, one after the other, under targets or in a file loaded with LoadTargets.
+//
+// Exactly one of AWS, GCP and Azure is set.
+type Target struct {
+	// Name identifies the target in summaries, and names the state subdirectory of the techniques run in it
+	Name  string
+	AWS   *AWSTarget
+	GCP   *GCPTarget
+	Azure *AzureTarget
+}
+
+// AWSTarget is an AWS region, in the account of a role assumed with the default credentials
+type AWSTarget struct {
+	// RoleARN is the role to assume, or empty to use the default credentials
+	RoleARN string
+	// ExternalID is passed when assuming the role, if set
+	ExternalID string
+	// Region is the region to run in, or empty to use the default region
+	Region string
+}
+
+// GCPTarget is a GCP project, used with the default credentials
+type GCPTarget struct {
+	ProjectID string `yaml:"project_id"`
+}
+
+// AzureTarget is an Azure subscription, used with the default credentials
+type AzureTarget struct {
+	SubscriptionID string `yaml:"subscription_id"`
+}
+
+func (m Target) String() string {
+	return m.Name
+}
+
+// targetEntry is an item of the targets section. An AWS entry with several regions expands to a target per region.
+type targetEntry struct {
+	Name string `yaml:"name"`
+	AWS  *struct {
+		RoleARN    string   `yaml:"role_arn"`
+		ExternalID string   `yaml:"external_id"`
+		Regions    []string `yaml:"regions"`
+	} `yaml:"aws"`
+	GCP   *GCPTarget   `yaml:"gcp"`
+	Azure *AzureTarget `yaml:"azure"`
+}
+
+// GetTargets returns the targets to run techniques in, or nil to run them once with the default credentials.
+func (c *ConfigImpl) GetTargets() []Target {
+	if c == nil {
+		return nil
+	}
+	return c.targets
+}
+
+// LoadTargets reads targets from a YAML file with a targets section, like the one of the configuration file.
+func LoadTargets(path string) ([]Target, error) {
+	rawYAML, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading targets file: %w", err)
+	}
+	if err := validateConfig(rawYAML); err != nil {
+		return nil, fmt.Errorf("invalid targets file %s: %w", path, err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(rawYAML, &raw); err != nil {
+		return nil, fmt.Errorf("parsing targets file: %w", err)
+	}
+	if _, isSet := raw["targets"]; !isSet {
+		return nil, fmt.Errorf("targets file %s has no targets section", path)
+	}
+	return parseTargets(raw)
+}
+
+// parseTargets reads the targets section, which the schema has already validated, and expands it into targets
+// with unique names
+func parseTargets(raw map[string]any) ([]Target, error) {
+	section, isSet := raw["targets"]
+	if !isSet {
+		return nil, nil
+	}
+	rawEntries, err := yaml.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	var entries []targetEntry
+	if err := yaml.Unmarshal(rawEntries, &entries); err != nil {
+		return nil, fmt.Errorf("parsing targets: %w", err)
+	}
+
+	var targets []Target
+	names := map[string]bool{}
+	for _, entry := range entries {
+		for _, target := range entry.expand() {
+			if names[target.Name] {
+				return nil, fmt.Errorf("several targets are named %s, set a distinct name on each of them", target.Name)
+			}
+			names[target.Name] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+func (m targetEntry) expand() []Target {
+	switch {
+	case m.GCP != nil:
+		return []Target{{Name: nameOr(m.Name, "gcp-"+m.GCP.ProjectID), GCP: m.GCP}}
+	case m.Azure != nil:
+		return []Target{{Name: nameOr(m.Name, "azure-"+m.Azure.SubscriptionID), Azure: m.Azure}}
+	case m.AWS != nil:
+		name := m.Name
+		if name == "" {
+			name = "aws"
+			if accountID := accountIDOfRole(m.AWS.RoleARN); accountID != "" {
+				name += "-" + accountID
+			}
+		}
+		if len(m.AWS.Regions) == 0 {
+			return []Target{{Name: name, AWS: &AWSTarget{RoleARN: m.AWS.RoleARN, ExternalID: m.AWS.ExternalID}}}
+		}
+		targets := make([]Target, 0, len(m.AWS.Regions))
+		for _, region := range m.AWS.Regions {
+			regionName := name
+			// A name set on a single region is kept as is
+			if m.Name == "" || len(m.AWS.Regions) > 1 {
+				regionName += "-" + region
+			}
+			targets = append(targets, Target{
+				Name: regionName,
+				AWS:  &AWSTarget{RoleARN: m.AWS.RoleARN, ExternalID: m.AWS.ExternalID, Region: region},
+			})
+		}
+		return targets
+	default:
+		return nil
+	}
+}
+
+func nameOr(name string, defaultName string) string {
+	if name != "" {
+		return name
+	}
+	return defaultName
+}
+
+// accountIDOfRole returns the account of an IAM role ARN, e.g. arn:aws:iam::123456789012:role/name
+func accountIDOfRole(roleARN string) string {
+	parts := strings.Split(roleARN, ":")
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTargets(t *testing.T) {
+	cfg := newTestConfig(`
+targets:
+  - aws:
+      role_arn: "arn:aws:iam::123456789012:role/stratus"
+      external_id: "secret"
+      regions: [us-east-1, eu-west-1]
+  - name: staging
+    aws:
+      regions: [us-west-2]
+  - aws:
+      role_arn: "arn:aws:iam::012345678901:role/stratus"
+  - name: sandbox
+    gcp:
+      project_id: my-project
+  - azure:
+      subscription_id: 45e0ad3f-ff94-499a-a2f0-bbb884e9c4a3
+`)
+
+	assert.Equal(t, []Target{
+		{Name: "aws-123456789012-us-east-1", AWS: &AWSTarget{RoleARN: "arn:aws:iam::123456789012:role/stratus", ExternalID: "secret", Region: "us-east-1"}},
+		{Name: "aws-123456789012-eu-west-1", AWS: &AWSTarget{RoleARN: "arn:aws:iam::123456789012:role/stratus", ExternalID: "secret", Region: "eu-west-1"}},
+		{Name: "staging", AWS: &AWSTarget{Region: "us-west-2"}},
+		{Name: "aws-012345678901", AWS: &AWSTarget{RoleARN: "arn:aws:iam::012345678901:role/stratus"}},
+		{Name: "sandbox", GCP: &GCPTarget{ProjectID: "my-project"}},
+		{Name: "azure-45e0ad3f-ff94-499a-a2f0-bbb884e9c4a3", Azure: &AzureTarget{SubscriptionID: "45e0ad3f-ff94-499a-a2f0-bbb884e9c4a3"}},
+	}, cfg.GetTargets())
+	assert.Nil(t, newTestConfig(`techniques: {}`).GetTargets())
+	assert.Nil(t, (*ConfigImpl)(nil).GetTargets())
+}
+
+func TestParseTargetsRejectsDuplicateNames(t *testing.T) {
+	_, err := parseTargets(map[string]any{"targets": []any{
+		map[string]any{"gcp": map[string]any{"project_id": "my-project"}},
+		map[string]any{"name": "gcp-my-project", "azure": map[string]any{"subscription_id": "45e0ad3f-ff94-499a-a2f0-bbb884e9c4a3"}},
+	}})
+
+	assert.ErrorContains(t, err, "several targets are named gcp-my-project")
+}
+
+func TestValidateTargets(t *testing.T) {
+	require.NoError(t, validateConfig([]byte(`
+targets:
+  - name: production
+    aws:
+      role_arn: "arn:aws:iam::123456789012:role/stratus"
+      regions: [us-east-1]
+  - gcp:
+      project_id: my-project
+`)))
+
+	// A target is in a single cloud
+	assert.Error(t, validateConfig([]byte(`
+targets:
+  - aws:
+      regions: [us-east-1]
+    gcp:
+      project_id: my-project
+`)))
+	assert.Error(t, validateConfig([]byte(`
+targets:
+  - name: production
+`)))
+	assert.Error(t, validateConfig([]byte(`
+targets:
+  - aws: {}
+`)))
+	// Names are state directories
+	assert.Error(t, validateConfig([]byte(`
+targets:
+  - name: ../production
+    gcp:
+      project_id: my-project
+`)))
+	assert.Error(t, validateConfig([]byte(`
+targets:
+  - aws:
+      role_arn: "arn:aws:iam::1234:role/stratus"
+`)))
+}
+
+func TestLoadTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+targets:
+  - aws:
+      regions: [us-east-1]
+`), 0600))
+
+	targets, err := LoadTargets(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{Name: "aws-us-east-1", AWS: &AWSTarget{Region: "us-east-1"}}}, targets)
+
+	require.NoError(t, os.WriteFile(path, []byte(`allowed_targets: {}`), 0600))
+	_, err = LoadTargets(path)
+	assert.ErrorContains(t, err, "no targets section")
+
+	_, err = LoadTargets(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TechniquesConfigImpl holds the platform-independent settings of techniques, under techniques.<id>.
+type TechniquesConfigImpl struct {
+	raw map[string]any
+}
+
+// GetTechniqueParameters returns the parameters set for a technique under techniques.<id>.parameters,
+// written the same way as on the command line: lists are joined with commas. The technique validates them.
+func (c *ConfigImpl) GetTechniqueParameters(techniqueID string) map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.techniques.getParameters(techniqueID)
+}
+
+func (t *TechniquesConfigImpl) getParameters(techniqueID string) map[string]string {
+	if t == nil || t.raw == nil {
+		return nil
+	}
+	technique := toStringMap(toStringMap(t.raw["techniques"])[techniqueID])
+	rawParameters := toStringMap(technique["parameters"])
+	if len(rawParameters) == 0 {
+		return nil
+	}
+	parameters := make(map[string]string, len(rawParameters))
+	for name, value := range rawParameters {
+		parameters[name] = parameterToString(value)
+	}
+	return parameters
+}
+
+func parameterToString(value any) string {
+	if items, isList := value.([]any); isList {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(value)
+}
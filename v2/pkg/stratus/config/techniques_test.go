@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTechniqueParameters(t *testing.T) {
+	cfg := newTestConfig(`
+techniques:
+  "aws.execution.ec2-launch-unusual-instances":
+    parameters:
+      instance_types: ["p2.xlarge", "p3.2xlarge"]
+      instance_count: 5
+      dry_run: true
+      name: stratus
+  "aws.other.technique": {}
+`)
+
+	assert.Equal(t, map[string]string{
+		"instance_types": "p2.xlarge,p3.2xlarge",
+		"instance_count": "5",
+		"dry_run":        "true",
+		"name":           "stratus",
+	}, cfg.GetTechniqueParameters("aws.execution.ec2-launch-unusual-instances"))
+	assert.Nil(t, cfg.GetTechniqueParameters("aws.other.technique"))
+	assert.Nil(t, cfg.GetTechniqueParameters("aws.unknown.technique"))
+	assert.Nil(t, newTestConfig(``).GetTechniqueParameters("aws.other.technique"))
+}
+
+func TestTechniqueParametersAreNotTerraformVariables(t *testing.T) {
+	cfg := newTestConfig(`
+techniques:
+  "aws.execution.ec2-launch-unusual-instances":
+    parameters:
+      instance_count: 5
+`)
+
+	assert.Nil(t, cfg.GetTerraformVariables("aws.execution.ec2-launch-unusual-instances", SubstitutionVars{}))
+}
+
+func TestExampleConfigIsValid(t *testing.T) {
+	example, err := os.ReadFile("config.example.yaml")
+	require.NoError(t, err)
+
+	assert.NoError(t, validateConfig(example))
+}
@@ -19,6 +19,12 @@ var configSchemaJSON []byte
 //go:embed config.tf
 var SharedTerraformConfigVariable []byte
 
+// AWSEndpointsTerraformOverride is a Terraform override file that points the "aws" provider block of
+// AWS techniques at aws.endpoint_url, injected alongside their main.tf files at warmup time.
+//
+//go:embed aws_endpoints_override.tf
+var AWSEndpointsTerraformOverride []byte
+
 // validateConfig validates raw YAML config bytes against the embedded JSON schema.
 // We validate raw YAML rather than Viper's output because Viper splits dotted keys
 // (e.g. technique ID "k8s.privilege-escalation.privileged-pod") into nested maps,
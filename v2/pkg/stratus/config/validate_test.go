@@ -148,6 +148,55 @@ kubernetes:
   default:
     pod:
       labels: "not-a-map"
+`,
+			wantError: true,
+		},
+		{
+			name: "valid-technique-parameters",
+			yaml: `
+techniques:
+  "aws.execution.ec2-launch-unusual-instances":
+    parameters:
+      instance_types: ["p2.xlarge", "p3.2xlarge"]
+      instance_count: 5
+      dry_run: false
+`,
+			wantError: false,
+		},
+		{
+			name: "unknown-key-in-technique-settings",
+			yaml: `
+techniques:
+  "aws.execution.ec2-launch-unusual-instances":
+    params:
+      instance_count: 5
+`,
+			wantError: true,
+		},
+		{
+			name: "nested-technique-parameter",
+			yaml: `
+techniques:
+  "aws.execution.ec2-launch-unusual-instances":
+    parameters:
+      instance_count:
+        value: 5
+`,
+			wantError: true,
+		},
+		{
+			name: "aws-endpoint-url",
+			yaml: `
+aws:
+  endpoint_url: http://localhost.localstack.cloud:4566
+`,
+			wantError: false,
+		},
+		{
+			name: "aws-endpoint-url-without-scheme",
+			yaml: `
+aws:
+  endpoint_url: localhost:4566
 `,
 			wantError: true,
 		},
@@ -0,0 +1,73 @@
+package stratus
+
+// DetectionRule is structured detection metadata of a technique, from which 'stratus export sigma'
+// generates a baseline Sigma rule.
+type DetectionRule struct {
+	// Logs in which the technique is visible. Defaults to the audit logs of the platform of the technique
+	LogSource LogSource `yaml:"logSource,omitempty"`
+
+	// Selections of log events, any of which triggers the rule. Each selection matches the events having
+	// all of its fields, keyed by their name in the Sigma taxonomy of the logs, optionally followed by Sigma
+	// modifiers (e.g. "userIdentity.arn|endswith"), with any of the listed values.
+	Selections []DetectionSelection `yaml:"selections"`
+
+	// Severity of the rule. Defaults to DetectionLevelMedium
+	Level DetectionLevel `yaml:"level,omitempty"`
+
+	// Legitimate activity that the rule may match
+	FalsePositives []string `yaml:"falsePositives,omitempty"`
+}
+
+// DetectionSelection maps log fields to the values they must match, e.g. {"eventName": {"StopLogging"}}
+type DetectionSelection map[string][]string
+
+// LogSource is a type of logs in which techniques are visible
+type LogSource string
+
+const (
+	LogSourceCloudTrail          LogSource = "cloudtrail"
+	LogSourceGCPAuditLogs        LogSource = "gcp-audit"
+	LogSourceAzureActivityLogs   LogSource = "azure-activity"
+	LogSourceEntraIDAuditLogs    LogSource = "entra-id-audit"
+	LogSourceKubernetesAuditLogs LogSource = "kubernetes-audit"
+)
+
+// DetectionLevel is the severity of a detection rule
+type DetectionLevel string
+
+const (
+	DetectionLevelInformational DetectionLevel = "informational"
+	DetectionLevelLow           DetectionLevel = "low"
+	DetectionLevelMedium        DetectionLevel = "medium"
+	DetectionLevelHigh          DetectionLevel = "high"
+	DetectionLevelCritical      DetectionLevel = "critical"
+)
+
+// GetLogSource returns the logs in which a technique of the given platform is visible
+func (m *DetectionRule) GetLogSource(platform Platform) LogSource {
+	if m.LogSource != "" {
+		return m.LogSource
+	}
+	switch platform {
+	case AWS, EKS:
+		return LogSourceCloudTrail
+	case GCP:
+		return LogSourceGCPAuditLogs
+	case Azure:
+		return LogSourceAzureActivityLogs
+	case EntraID:
+		return LogSourceEntraIDAuditLogs
+	case Kubernetes:
+		return LogSourceKubernetesAuditLogs
+	default:
+		return ""
+	}
+}
+
+// GetLevel returns the severity of the rule
+func (m *DetectionRule) GetLevel() DetectionLevel {
+	if m.Level == "" {
+		return DetectionLevelMedium
+	}
+	return m.Level
+}
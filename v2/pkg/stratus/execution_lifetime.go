@@ -0,0 +1,27 @@
+package stratus
+
+import "time"
+
+// ExecutionLifetime records when the prerequisites of a technique execution were warmed up and, if
+// it was given a TTL, when it should be cleaned up by 'stratus reap'.
+type ExecutionLifetime struct {
+	WarmedUpAt time.Time `json:"warmed_up_at"`
+	// Nil when the execution has no TTL, and is kept until explicitly cleaned up
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewExecutionLifetime builds the lifetime of an execution warmed up at warmedUpAt, expiring after ttl.
+// A ttl of zero means that the execution never expires.
+func NewExecutionLifetime(warmedUpAt time.Time, ttl time.Duration) ExecutionLifetime {
+	lifetime := ExecutionLifetime{WarmedUpAt: warmedUpAt.UTC()}
+	if ttl > 0 {
+		lifetime.ExpiresAt = new(time.Time)
+		*lifetime.ExpiresAt = lifetime.WarmedUpAt.Add(ttl)
+	}
+	return lifetime
+}
+
+// IsExpired indicates if the execution has outlived its TTL.
+func (m *ExecutionLifetime) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
@@ -0,0 +1,17 @@
+package stratus
+
+// ExpectedEvent is an audit event that the detonation of a technique is expected to produce,
+// so that detection pipelines can be validated against exported logs.
+type ExpectedEvent struct {
+	// Service emitting the event, e.g. "cloudtrail.amazonaws.com" in CloudTrail or
+	// "secretmanager.googleapis.com" in GCP Cloud Audit Logs. Unused for Kubernetes audit logs,
+	// which have no such notion.
+	EventSource string `yaml:"eventSource,omitempty" json:"eventSource,omitempty"`
+
+	// Name of the event, e.g. "StopLogging" in CloudTrail or the verb ("list") in Kubernetes audit logs
+	EventName string `yaml:"eventName" json:"eventName"`
+
+	// Additional fields the event must have, keyed by their dot-separated path in the event,
+	// e.g. "objectRef.resource": "pods"
+	Fields map[string]string `yaml:"fields,omitempty" json:"fields,omitempty"`
+}
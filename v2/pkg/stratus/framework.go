@@ -11,17 +11,17 @@ const (
 // TechniqueMapping represents a mapping to a specific technique in a framework.
 type TechniqueMapping struct {
 	// Name of the tactic, e.g. "Initial Access"
-	Name string `yaml:"name"`
+	Name string `yaml:"name" json:"name"`
 	// ID of the tactic, e.g. "TA0001"
-	ID string `yaml:"id"`
+	ID string `yaml:"id" json:"id"`
 	// URL to the tactic definition
-	URL string `yaml:"url"`
+	URL string `yaml:"url" json:"url"`
 }
 
 // FrameworkMappings represents a mapping of an attack technique to a framework.
 type FrameworkMappings struct {
 	// Name of the framework
-	Framework Framework `yaml:"framework"`
+	Framework Framework `yaml:"framework" json:"framework"`
 	// List of technique mappings
-	Techniques []TechniqueMapping `yaml:"techniques"`
+	Techniques []TechniqueMapping `yaml:"techniques" json:"techniques"`
 }
@@ -0,0 +1,131 @@
+package stratus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/google/uuid"
+)
+
+// Identity is a principal that techniques are detonated as, see AttackTechnique.AttackerIdentity.
+// Re-exported from the config package, where users can set one.
+type (
+	Identity      = config.AttackerIdentity
+	AWSIdentity   = config.AWSAttackerIdentity
+	GCPIdentity   = config.GCPAttackerIdentity
+	AzureIdentity = config.AzureAttackerIdentity
+)
+
+// attackerCloudProviders are cloud providers authenticated as an identity, built from the ones of the admin
+// identity. Platforms the identity does not set keep the admin providers.
+type attackerCloudProviders struct {
+	ctx           context.Context
+	correlationID uuid.UUID
+	identity      Identity
+	admin         CloudProviders
+
+	mutex           sync.Mutex
+	awsProvider     *providers.AWSProvider
+	gcpProvider     *providers.GCPProvider
+	azureProvider   *providers.AzureProvider
+	entraIdProvider *providers.EntraIdProvider
+	eksProvider     *providers.EKSProvider
+}
+
+// NewAttackerCloudProviders returns cloud providers authenticated as identity, using the admin providers to
+// assume its AWS roles, impersonate its GCP service account, and reach the APIs. ctx bounds the refresh of
+// the impersonated GCP credentials.
+func NewAttackerCloudProviders(ctx context.Context, correlationID uuid.UUID, identity Identity, admin CloudProviders) CloudProviders {
+	return &attackerCloudProviders{ctx: ctx, correlationID: correlationID, identity: identity, admin: admin}
+}
+
+func (m *attackerCloudProviders) AWS() *providers.AWSProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.aws()
+}
+
+func (m *attackerCloudProviders) aws() *providers.AWSProvider {
+	if m.identity.AWS == nil {
+		return m.admin.AWS()
+	}
+	if m.awsProvider == nil {
+		sessionName := "stratus-red-team-" + CorrelationShortID(m.correlationID)
+		m.awsProvider = m.admin.AWS().AssumeRoles(m.identity.AWS.RoleARNs, m.identity.AWS.ExternalID, sessionName)
+	}
+	return m.awsProvider
+}
+
+func (m *attackerCloudProviders) GCP() *providers.GCPProvider {
+	if m.identity.GCP == nil {
+		return m.admin.GCP()
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.gcpProvider == nil {
+		provider, err := m.admin.GCP().Impersonate(m.ctx, m.identity.GCP.ServiceAccount, m.identity.GCP.Delegates)
+		if err != nil {
+			log.Fatalf("unable to impersonate the GCP service account %s: %v", m.identity.GCP.ServiceAccount, err)
+		}
+		m.gcpProvider = provider
+	}
+	return m.gcpProvider
+}
+
+func (m *attackerCloudProviders) Azure() *providers.AzureProvider {
+	if m.identity.Azure == nil {
+		return m.admin.Azure()
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.azureProvider == nil {
+		azure := m.identity.Azure
+		provider, err := m.admin.Azure().AsServicePrincipal(azure.TenantID, azure.ClientID, azure.ClientSecret)
+		if err != nil {
+			log.Fatalf("unable to authenticate as the Azure service principal %s: %v", azure.ClientID, err)
+		}
+		m.azureProvider = provider
+	}
+	return m.azureProvider
+}
+
+func (m *attackerCloudProviders) EntraId() *providers.EntraIdProvider {
+	if m.identity.Azure == nil {
+		return m.admin.EntraId()
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.entraIdProvider == nil {
+		azure := m.identity.Azure
+		provider, err := m.admin.EntraId().AsServicePrincipal(azure.TenantID, azure.ClientID, azure.ClientSecret)
+		if err != nil {
+			log.Fatalf("unable to authenticate as the Entra ID service principal %s: %v", azure.ClientID, err)
+		}
+		m.entraIdProvider = provider
+	}
+	return m.entraIdProvider
+}
+
+// K8s returns the admin provider, since Kubernetes identities are not cloud principals
+func (m *attackerCloudProviders) K8s() *providers.K8sProvider {
+	return m.admin.K8s()
+}
+
+// EKS returns a provider calling AWS as the attacker, and the cluster with the admin credentials
+func (m *attackerCloudProviders) EKS() *providers.EKSProvider {
+	if m.identity.AWS == nil {
+		return m.admin.EKS()
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.eksProvider == nil {
+		m.eksProvider = providers.NewEKSProvider(m.correlationID,
+			providers.WithEKSAWSProvider(m.aws()),
+			providers.WithEKSK8sProvider(m.admin.K8s()),
+		)
+	}
+	return m.eksProvider
+}
@@ -0,0 +1,43 @@
+package stratus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/stratustest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iam/v1"
+)
+
+func TestAttackerCloudProviders(t *testing.T) {
+	admin := stratustest.New(t)
+	admin.Server.Respond(
+		"POST iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/attacker@stratus-red-team.iam.gserviceaccount.com:generateAccessToken",
+		stratustest.JSONResponse(map[string]string{"accessToken": "attacker-token", "expireTime": "2100-01-01T00:00:00Z"}),
+	)
+	attacker := stratus.NewAttackerCloudProviders(context.Background(), uuid.New(), stratus.Identity{
+		GCP: &stratus.GCPIdentity{ServiceAccount: "attacker@stratus-red-team.iam.gserviceaccount.com"},
+	}, admin)
+
+	t.Run("impersonates the GCP service account", func(t *testing.T) {
+		assert.Equal(t, stratustest.DefaultGCPProjectID, attacker.GCP().GetProjectId())
+		service, err := iam.NewService(context.Background(), attacker.GCP().Options())
+		require.NoError(t, err)
+		_, err = service.Projects.ServiceAccounts.List("projects/" + stratustest.DefaultGCPProjectID).Do()
+		require.NoError(t, err)
+
+		calls := admin.Server.CallsTo("GET iam.googleapis.com/v1/projects/" + stratustest.DefaultGCPProjectID + "/serviceAccounts")
+		require.Len(t, calls, 1)
+		assert.Equal(t, "Bearer attacker-token", calls[0].Header.Get("Authorization"))
+		assert.Contains(t, calls[0].Header.Get("User-Agent"), "stratus-red-team")
+	})
+
+	t.Run("keeps the admin providers of the other platforms", func(t *testing.T) {
+		assert.Same(t, admin.AWS(), attacker.AWS())
+		assert.Same(t, admin.Azure(), attacker.Azure())
+		assert.Same(t, admin.K8s(), attacker.K8s())
+	})
+}
@@ -0,0 +1,55 @@
+package stratus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// JournalOperation is a lifecycle operation recorded in the execution journal.
+type JournalOperation string
+
+const (
+	JournalOperationWarmUp   JournalOperation = "warmup"
+	JournalOperationDetonate JournalOperation = "detonate"
+	JournalOperationRevert   JournalOperation = "revert"
+	JournalOperationCleanUp  JournalOperation = "cleanup"
+)
+
+// JournalEntry records a lifecycle operation on a technique in the append-only execution journal,
+// which keeps an audit trail of what was done, by whom and when, even once the state is cleaned up.
+type JournalEntry struct {
+	TechniqueID   string           `json:"technique_id"`
+	Operation     JournalOperation `json:"operation"`
+	CorrelationID string           `json:"correlation_id"`
+	// Caller identifies the operator, as user@hostname unless the runner was given a state lock owner
+	Caller     string    `json:"caller"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Duration is in seconds
+	Duration    float64              `json:"duration"`
+	StateBefore AttackTechniqueState `json:"state_before"`
+	StateAfter  AttackTechniqueState `json:"state_after"`
+	// TerraformVariablesHash fingerprints the variables the prerequisites were warmed up with
+	TerraformVariablesHash string `json:"terraform_variables_hash,omitempty"`
+	// Error is empty if the operation succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded indicates if the operation completed without error.
+func (m *JournalEntry) Succeeded() bool {
+	return m.Error == ""
+}
+
+// HashTerraformVariables returns a SHA-256 fingerprint of Terraform variables, that does not
+// depend on the iteration order of the map.
+func HashTerraformVariables(variables map[string]string) string {
+	if len(variables) == 0 {
+		return ""
+	}
+	// encoding/json sorts map keys
+	encoded, _ := json.Marshal(variables)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
@@ -20,6 +20,7 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 	"sync/atomic"
 )
 
@@ -87,14 +88,47 @@ func fatalMessage(msg string) {
 // mirroring the standard library's log.Fatal.
 func Fatal(v ...any) {
 	fatalMessage(fmt.Sprint(v...))
-	os.Exit(1)
+	Exit(1)
 }
 
 // Fatalf logs a formatted message at error level and then exits with status 1,
 // mirroring the standard library's log.Fatalf.
 func Fatalf(format string, v ...any) {
 	fatalMessage(fmt.Sprintf(format, v...))
-	os.Exit(1)
+	Exit(1)
+}
+
+// exitHandlers run before the process exits through Fatal, Fatalf or Exit.
+var exitHandlers struct {
+	sync.Mutex
+	handlers []func()
+}
+
+// RegisterExitHandler registers a function to run before Fatal, Fatalf and Exit
+// exit the process, for instance to flush traces that would otherwise be lost.
+func RegisterExitHandler(handler func()) {
+	exitHandlers.Lock()
+	defer exitHandlers.Unlock()
+	exitHandlers.handlers = append(exitHandlers.handlers, handler)
+}
+
+// Exit runs the exit handlers and then exits with the given status code,
+// mirroring os.Exit.
+func Exit(code int) {
+	runExitHandlers()
+	os.Exit(code)
+}
+
+// runExitHandlers runs the registered handlers once, in registration order. A
+// handler that exits itself does not run them again.
+func runExitHandlers() {
+	exitHandlers.Lock()
+	handlers := exitHandlers.handlers
+	exitHandlers.handlers = nil
+	exitHandlers.Unlock()
+	for _, handler := range handlers {
+		handler()
+	}
 }
 
 // Info logs a structured message at info level.
@@ -119,6 +119,23 @@ func TestSetLoggerIgnoresNil(t *testing.T) {
 	}
 }
 
+func TestExitHandlersRunOnceInOrder(t *testing.T) {
+	var calls []string
+	RegisterExitHandler(func() { calls = append(calls, "first") })
+	RegisterExitHandler(func() {
+		calls = append(calls, "second")
+		// e.g. a handler failing with Fatal
+		runExitHandlers()
+	})
+
+	runExitHandlers()
+	runExitHandlers()
+
+	if strings.Join(calls, ",") != "first,second" {
+		t.Fatalf("exit handlers ran as %v, want first then second, once", calls)
+	}
+}
+
 func TestDisableSilencesLogging(t *testing.T) {
 	withRestoredLogger(t, slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)))
 
@@ -0,0 +1,96 @@
+package mitreattack
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Technique is a MITRE ATT&CK technique or sub-technique, e.g. T1562.008 (Disable or Modify Cloud Logs)
+type Technique struct {
+	// ID of the technique, e.g. T1562 or T1562.008 for a sub-technique
+	ID string `yaml:"id"`
+	// Name of the technique, e.g. "Disable or Modify Cloud Logs"
+	Name string `yaml:"name"`
+}
+
+//go:embed techniques.yaml
+var techniquesYAML []byte
+
+var techniqueIDPattern = regexp.MustCompile(`^T[0-9]{4}(\.[0-9]{3})?$`)
+
+// techniques are the ATT&CK Enterprise techniques that apply to cloud and container platforms, by ID
+var techniques, techniqueIDs = loadTechniques()
+
+func loadTechniques() (map[string]Technique, []string) {
+	var list []Technique
+	if err := yaml.Unmarshal(techniquesYAML, &list); err != nil {
+		panic("invalid embedded MITRE ATT&CK techniques: " + err.Error())
+	}
+	byID := make(map[string]Technique, len(list))
+	ids := make([]string, 0, len(list))
+	for _, technique := range list {
+		byID[technique.ID] = technique
+		ids = append(ids, technique.ID)
+	}
+	slices.Sort(ids)
+	return byID, ids
+}
+
+// IsSubTechnique indicates if the technique is a sub-technique, e.g. T1562.008
+func (t Technique) IsSubTechnique() bool {
+	return strings.Contains(t.ID, ".")
+}
+
+// ParentID returns the ID of the technique a sub-technique belongs to, e.g. T1562 for T1562.008, or the
+// ID of the technique itself if it is not a sub-technique
+func (t Technique) ParentID() string {
+	parentID, _, _ := strings.Cut(t.ID, ".")
+	return parentID
+}
+
+// URL returns the page of the technique on the MITRE ATT&CK website
+func (t Technique) URL() string {
+	return "https://attack.mitre.org/techniques/" + strings.ReplaceAll(t.ID, ".", "/") + "/"
+}
+
+// FullName returns the name of the technique, prefixed with the name of its parent for sub-techniques,
+// e.g. "Impair Defenses: Disable or Modify Cloud Logs"
+func (t Technique) FullName() string {
+	if !t.IsSubTechnique() {
+		return t.Name
+	}
+	return techniques[t.ParentID()].Name + ": " + t.Name
+}
+
+func (t Technique) String() string {
+	return t.ID
+}
+
+// AttackTechniqueFromID returns the cloud or container ATT&CK technique with the given ID, e.g. T1098.001
+func AttackTechniqueFromID(id string) (Technique, error) {
+	normalizedID := strings.ToUpper(strings.TrimSpace(id))
+	if !techniqueIDPattern.MatchString(normalizedID) {
+		return Technique{}, errors.New("invalid MITRE ATT&CK technique ID " + id + ", expected e.g. T1098 or T1098.001")
+	}
+	technique, found := techniques[normalizedID]
+	if !found {
+		return Technique{}, fmt.Errorf("unknown MITRE ATT&CK technique %s, see https://attack.mitre.org/matrices/enterprise/cloud/", id)
+	}
+	return technique, nil
+}
+
+// GetAllMitreAttackTechniques returns the ATT&CK techniques that apply to cloud and container platforms,
+// sorted by ID, so that each technique is followed by its sub-techniques
+func GetAllMitreAttackTechniques() []Technique {
+	all := make([]Technique, 0, len(techniqueIDs))
+	for _, id := range techniqueIDs {
+		all = append(all, techniques[id])
+	}
+	return all
+}
@@ -0,0 +1,42 @@
+package mitreattack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttackTechniqueFromID(t *testing.T) {
+	technique, err := AttackTechniqueFromID("T1562.008")
+	require.NoError(t, err)
+	assert.Equal(t, "Disable or Modify Cloud Logs", technique.Name)
+	assert.Equal(t, "Impair Defenses: Disable or Modify Cloud Logs", technique.FullName())
+	assert.Equal(t, "https://attack.mitre.org/techniques/T1562/008/", technique.URL())
+	assert.True(t, technique.IsSubTechnique())
+	assert.Equal(t, "T1562", technique.ParentID())
+
+	technique, err = AttackTechniqueFromID(" t1098 ")
+	require.NoError(t, err)
+	assert.Equal(t, "T1098", technique.ID)
+	assert.Equal(t, "Account Manipulation", technique.FullName())
+	assert.False(t, technique.IsSubTechnique())
+	assert.Equal(t, "T1098", technique.ParentID())
+
+	_, err = AttackTechniqueFromID("T1003.001") // OS Credential Dumping: LSASS Memory, not a cloud technique
+	assert.ErrorContains(t, err, "unknown MITRE ATT&CK technique")
+	_, err = AttackTechniqueFromID("TA0003")
+	assert.ErrorContains(t, err, "invalid MITRE ATT&CK technique ID")
+}
+
+func TestEmbeddedMitreAttackTechniques(t *testing.T) {
+	all := GetAllMitreAttackTechniques()
+	require.Len(t, all, len(techniques), "technique IDs must be unique")
+	for _, technique := range all {
+		assert.Regexp(t, techniqueIDPattern, technique.ID)
+		assert.NotEmpty(t, technique.Name, technique.ID)
+		if technique.IsSubTechnique() {
+			assert.Contains(t, techniques, technique.ParentID(), "sub-technique %s needs its parent", technique.ID)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package navigator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+)
+
+const (
+	// Versions of ATT&CK, of the Navigator and of the layer format the layers are generated for
+	attackVersion    = "16"
+	navigatorVersion = "5.1.0"
+	layerVersion     = "4.5"
+
+	colorDetonated    = "#8ec843"
+	colorNotDetonated = "#ff6666"
+)
+
+// Layer is a MITRE ATT&CK Navigator layer, see
+// https://github.com/mitre-attack/attack-navigator/blob/master/layers/spec/v4.5/layerformat.md
+type Layer struct {
+	Name                          string       `json:"name"`
+	Versions                      Versions     `json:"versions"`
+	Domain                        string       `json:"domain"`
+	Description                   string       `json:"description"`
+	Filters                       Filters      `json:"filters"`
+	Sorting                       int          `json:"sorting"`
+	Layout                        Layout       `json:"layout"`
+	HideDisabled                  bool         `json:"hideDisabled"`
+	Techniques                    []Technique  `json:"techniques"`
+	Gradient                      Gradient     `json:"gradient"`
+	LegendItems                   []LegendItem `json:"legendItems"`
+	ShowTacticRowBackground       bool         `json:"showTacticRowBackground"`
+	SelectTechniquesAcrossTactics bool         `json:"selectTechniquesAcrossTactics"`
+	SelectSubtechniquesWithParent bool         `json:"selectSubtechniquesWithParent"`
+	SelectVisibleTechniques       bool         `json:"selectVisibleTechniques"`
+}
+
+type Versions struct {
+	Attack    string `json:"attack"`
+	Navigator string `json:"navigator"`
+	Layer     string `json:"layer"`
+}
+
+type Filters struct {
+	Platforms []string `json:"platforms"`
+}
+
+type Layout struct {
+	Layout              string `json:"layout"`
+	AggregateFunction   string `json:"aggregateFunction"`
+	ShowID              bool   `json:"showID"`
+	ShowName            bool   `json:"showName"`
+	ShowAggregateScores bool   `json:"showAggregateScores"`
+	CountUnscored       bool   `json:"countUnscored"`
+}
+
+// Technique is the annotation of an ATT&CK technique or sub-technique in a layer
+type Technique struct {
+	TechniqueID string `json:"techniqueID"`
+	// Score is omitted for techniques only listed to expand their covered sub-techniques
+	Score             *int       `json:"score,omitempty"`
+	Color             string     `json:"color"`
+	Comment           string     `json:"comment"`
+	Enabled           bool       `json:"enabled"`
+	Metadata          []Metadata `json:"metadata"`
+	Links             []Link     `json:"links"`
+	ShowSubtechniques bool       `json:"showSubtechniques"`
+}
+
+type Metadata struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Link struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+type Gradient struct {
+	Colors   []string `json:"colors"`
+	MinValue int      `json:"minValue"`
+	MaxValue int      `json:"maxValue"`
+}
+
+type LegendItem struct {
+	Label string `json:"label"`
+	Color string `json:"color"`
+}
+
+// LayerOptions customizes the layer generated by NewLayer
+type LayerOptions struct {
+	// Name of the layer, defaults to "Stratus Red Team coverage"
+	Name string
+	// Detonations maps Stratus Red Team technique IDs to their last successful detonation. When set,
+	// ATT&CK techniques are coloured by whether at least one of the Stratus techniques covering them
+	// was detonated, instead of by score.
+	Detonations map[string]time.Time
+}
+
+// NewLayer generates a Navigator layer scoring each ATT&CK technique and sub-technique by the number of
+// Stratus Red Team techniques mapping to it. The layer is filtered on the Navigator platforms of these techniques.
+func NewLayer(techniques []*stratus.AttackTechnique, options LayerOptions) (*Layer, error) {
+	// MITRE ATT&CK technique ID => stratus techniques mapping to it
+	coverage := map[string][]*stratus.AttackTechnique{}
+	platforms := []string{}
+	for _, technique := range techniques {
+		mitreTechniques, err := technique.GetMitreAttackTechniques()
+		if err != nil {
+			return nil, err
+		}
+		for _, mitreTechnique := range mitreTechniques {
+			coverage[mitreTechnique.ID] = append(coverage[mitreTechnique.ID], technique)
+		}
+		for _, platform := range navigatorPlatforms(technique.Platform) {
+			if !slices.Contains(platforms, platform) {
+				platforms = append(platforms, platform)
+			}
+		}
+	}
+	slices.Sort(platforms)
+
+	name := options.Name
+	if name == "" {
+		name = "Stratus Red Team coverage"
+	}
+	layer := &Layer{
+		Name:        name,
+		Versions:    Versions{Attack: attackVersion, Navigator: navigatorVersion, Layer: layerVersion},
+		Domain:      "enterprise-attack",
+		Description: "MITRE ATT&CK techniques covered by Stratus Red Team, scored by number of Stratus Red Team techniques",
+		Filters:     Filters{Platforms: platforms},
+		Layout: Layout{
+			Layout:              "side",
+			AggregateFunction:   "max",
+			ShowID:              true,
+			ShowName:            true,
+			ShowAggregateScores: false,
+			CountUnscored:       false,
+		},
+		Techniques:                    []Technique{},
+		Gradient:                      Gradient{Colors: []string{"#ffffff", "#66b1ff"}, MinValue: 0, MaxValue: 1},
+		LegendItems:                   []LegendItem{},
+		SelectTechniquesAcrossTactics: true,
+		SelectSubtechniquesWithParent: false,
+	}
+	if options.Detonations != nil {
+		layer.Description += ", coloured by whether they were detonated"
+		layer.LegendItems = []LegendItem{
+			{Label: "Detonated", Color: colorDetonated},
+			{Label: "Not detonated", Color: colorNotDetonated},
+		}
+	}
+
+	for _, mitreTechnique := range mitreattack.GetAllMitreAttackTechniques() {
+		covered := coverage[mitreTechnique.ID]
+		if len(covered) == 0 {
+			if !mitreTechnique.IsSubTechnique() && hasCoveredSubTechnique(coverage, mitreTechnique.ID) {
+				layer.Techniques = append(layer.Techniques, Technique{
+					TechniqueID:       mitreTechnique.ID,
+					Enabled:           true,
+					Metadata:          []Metadata{},
+					Links:             []Link{},
+					ShowSubtechniques: true,
+				})
+			}
+			continue
+		}
+		score := len(covered)
+		layer.Gradient.MaxValue = max(layer.Gradient.MaxValue, score)
+		layer.Techniques = append(layer.Techniques, newTechnique(mitreTechnique, covered, score, options.Detonations, hasCoveredSubTechnique(coverage, mitreTechnique.ID)))
+	}
+	return layer, nil
+}
+
+func newTechnique(mitreTechnique mitreattack.Technique, covered []*stratus.AttackTechnique, score int, detonations map[string]time.Time, showSubtechniques bool) Technique {
+	technique := Technique{
+		TechniqueID:       mitreTechnique.ID,
+		Score:             &score,
+		Enabled:           true,
+		Metadata:          []Metadata{},
+		Links:             []Link{},
+		ShowSubtechniques: showSubtechniques,
+	}
+	ids := make([]string, 0, len(covered))
+	detonated := false
+	for _, stratusTechnique := range covered {
+		ids = append(ids, stratusTechnique.ID)
+		metadata := Metadata{Name: "Stratus Red Team technique", Value: stratusTechnique.ID}
+		if detonations != nil {
+			if detonatedAt, found := detonations[stratusTechnique.ID]; found {
+				detonated = true
+				metadata.Value += " (last detonated " + detonatedAt.UTC().Format(time.RFC3339) + ")"
+			} else {
+				metadata.Value += " (never detonated)"
+			}
+		}
+		technique.Metadata = append(technique.Metadata, metadata)
+		technique.Links = append(technique.Links, Link{
+			Label: stratusTechnique.ID,
+			URL:   fmt.Sprintf("https://stratus-red-team.cloud/attack-techniques/%s/%s/", stratusTechnique.Platform, stratusTechnique.ID),
+		})
+	}
+	technique.Comment = "Covered by " + strings.Join(ids, ", ")
+	if detonations != nil {
+		technique.Color = colorNotDetonated
+		if detonated {
+			technique.Color = colorDetonated
+		}
+	}
+	return technique
+}
+
+func hasCoveredSubTechnique(coverage map[string][]*stratus.AttackTechnique, parentID string) bool {
+	for id := range coverage {
+		if strings.HasPrefix(id, parentID+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// navigatorPlatforms returns the ATT&CK platforms on which the techniques of a Stratus Red Team platform apply
+func navigatorPlatforms(platform stratus.Platform) []string {
+	switch platform {
+	case stratus.AWS, stratus.Azure, stratus.GCP:
+		return []string{"IaaS"}
+	case stratus.EntraID:
+		return []string{"Identity Provider"}
+	case stratus.Kubernetes:
+		return []string{"Containers"}
+	case stratus.EKS:
+		return []string{"Containers", "IaaS"}
+	default:
+		return nil
+	}
+}
+
+// LastDetonations returns the time of the last successful detonation of each technique in the execution journal
+func LastDetonations(journal []stratus.JournalEntry) map[string]time.Time {
+	detonations := map[string]time.Time{}
+	for _, entry := range journal {
+		if entry.Operation != stratus.JournalOperationDetonate || !entry.Succeeded() {
+			continue
+		}
+		if entry.FinishedAt.After(detonations[entry.TechniqueID]) {
+			detonations[entry.TechniqueID] = entry.FinishedAt
+		}
+	}
+	return detonations
+}
@@ -0,0 +1,84 @@
+package navigator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var techniques = []*stratus.AttackTechnique{
+	{ID: "aws.defense-evasion.cloudtrail-stop", Platform: stratus.AWS, MitreAttackTechniques: []string{"T1562.008"}},
+	{ID: "aws.defense-evasion.cloudtrail-delete", Platform: stratus.AWS, MitreAttackTechniques: []string{"T1562.008"}},
+	{ID: "k8s.privilege-escalation.privileged-pod", Platform: stratus.Kubernetes, MitreAttackTechniques: []string{"T1610", "T1611"}},
+}
+
+func findTechnique(layer *Layer, id string) *Technique {
+	for i := range layer.Techniques {
+		if layer.Techniques[i].TechniqueID == id {
+			return &layer.Techniques[i]
+		}
+	}
+	return nil
+}
+
+func TestNewLayerScoresTechniques(t *testing.T) {
+	layer, err := NewLayer(techniques, LayerOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "enterprise-attack", layer.Domain)
+	assert.Equal(t, []string{"Containers", "IaaS"}, layer.Filters.Platforms)
+	assert.Equal(t, 2, layer.Gradient.MaxValue)
+	assert.Empty(t, layer.LegendItems)
+
+	cloudLogs := findTechnique(layer, "T1562.008")
+	require.NotNil(t, cloudLogs)
+	assert.Equal(t, 2, *cloudLogs.Score)
+	assert.Empty(t, cloudLogs.Color)
+	assert.Equal(t, "Covered by aws.defense-evasion.cloudtrail-stop, aws.defense-evasion.cloudtrail-delete", cloudLogs.Comment)
+	assert.Equal(t, "https://stratus-red-team.cloud/attack-techniques/AWS/aws.defense-evasion.cloudtrail-stop/", cloudLogs.Links[0].URL)
+
+	impairDefenses := findTechnique(layer, "T1562")
+	require.NotNil(t, impairDefenses, "parents of covered sub-techniques should expand them")
+	assert.Nil(t, impairDefenses.Score)
+	assert.True(t, impairDefenses.ShowSubtechniques)
+
+	assert.Equal(t, 1, *findTechnique(layer, "T1610").Score)
+	assert.Nil(t, findTechnique(layer, "T1098"))
+}
+
+func TestNewLayerColoursDetonatedTechniques(t *testing.T) {
+	detonatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	layer, err := NewLayer(techniques, LayerOptions{
+		Name:        "Our detonations",
+		Detonations: map[string]time.Time{"aws.defense-evasion.cloudtrail-delete": detonatedAt},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Our detonations", layer.Name)
+	assert.Len(t, layer.LegendItems, 2)
+	cloudLogs := findTechnique(layer, "T1562.008")
+	assert.Equal(t, colorDetonated, cloudLogs.Color)
+	assert.Equal(t, "aws.defense-evasion.cloudtrail-stop (never detonated)", cloudLogs.Metadata[0].Value)
+	assert.Equal(t, "aws.defense-evasion.cloudtrail-delete (last detonated 2026-10-01T12:00:00Z)", cloudLogs.Metadata[1].Value)
+	assert.Equal(t, colorNotDetonated, findTechnique(layer, "T1610").Color)
+}
+
+func TestNewLayerRejectsUnknownTechniques(t *testing.T) {
+	_, err := NewLayer([]*stratus.AttackTechnique{{ID: "foo", MitreAttackTechniques: []string{"T9999"}}}, LayerOptions{})
+	assert.ErrorContains(t, err, "foo")
+}
+
+func TestLastDetonations(t *testing.T) {
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	detonations := LastDetonations([]stratus.JournalEntry{
+		{TechniqueID: "foo", Operation: stratus.JournalOperationDetonate, FinishedAt: second},
+		{TechniqueID: "foo", Operation: stratus.JournalOperationDetonate, FinishedAt: first},
+		{TechniqueID: "bar", Operation: stratus.JournalOperationDetonate, FinishedAt: second, Error: "access denied"},
+		{TechniqueID: "baz", Operation: stratus.JournalOperationWarmUp, FinishedAt: second},
+	})
+	assert.Equal(t, map[string]time.Time{"foo": second}, detonations)
+}
@@ -0,0 +1,311 @@
+// Package packs loads third-party attack techniques from technique packs at runtime, so that they can be
+// listed and detonated like built-in ones without recompiling Stratus Red Team.
+//
+// A technique pack is a directory holding one technique per subdirectory. Each technique has a
+// technique.yaml manifest and an optional main.tf with its prerequisites. It is detonated and reverted
+// either by executables, or by the declarative steps of its manifest, see Step.
+package packs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ManifestFileName is the name of the file describing a technique of a pack
+	ManifestFileName = "technique.yaml"
+	// TerraformFileName is the name of the file holding the prerequisites of a technique of a pack
+	TerraformFileName = "main.tf"
+
+	// EnvVarTechniquePacks lists directories of technique packs to load, separated like PATH
+	EnvVarTechniquePacks = "STRATUS_TECHNIQUE_PACKS"
+	// DefaultDirectoryName is the directory of technique packs loaded by default, under ~/.stratus-red-team
+	DefaultDirectoryName = "packs"
+
+	// Environment variables with which detonation and reversion executables are run
+	EnvVarTechniqueID     = "STRATUS_TECHNIQUE_ID"
+	EnvVarOutputPrefix    = "STRATUS_OUTPUT_"
+	EnvVarParameterPrefix = "STRATUS_PARAMETER_"
+)
+
+var envVarUnsafeCharacters = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// Manifest describes a technique of a pack, in its technique.yaml file
+type Manifest struct {
+	ID                    string                       `yaml:"id"`
+	Name                  string                       `yaml:"name"`
+	Platform              string                       `yaml:"platform"`
+	MitreAttackTactics    []string                     `yaml:"mitreAttackTactics"`
+	MitreAttackTechniques []string                     `yaml:"mitreAttackTechniques"`
+	Description           string                       `yaml:"description"`
+	Detection             string                       `yaml:"detection"`
+	DetectionRule         *stratus.DetectionRule       `yaml:"detectionRule"`
+	Parameters            []stratus.TechniqueParameter `yaml:"parameters"`
+	IsSlow                bool                         `yaml:"isSlow"`
+	IsIdempotent          bool                         `yaml:"isIdempotent"`
+	Impact                stratus.Impact               `yaml:"impact"`
+	Terraform             string                       `yaml:"terraform"`
+	Detonate              *Action                      `yaml:"detonate"`
+	Revert                *Action                      `yaml:"revert"`
+}
+
+// Action is how a technique of a pack is detonated or reverted: either an executable, or steps
+type Action struct {
+	// Executable to run, relative to the directory of the technique unless absolute
+	Executable string `yaml:"executable"`
+	// Arguments passed to the executable
+	Args []string `yaml:"args"`
+	// Steps run in order, stopping at the first failing one
+	Steps []Step `yaml:"steps"`
+}
+
+// DefaultDirectories returns the directories of technique packs listed in STRATUS_TECHNIQUE_PACKS,
+// followed by ~/.stratus-red-team/packs if it exists
+func DefaultDirectories() []string {
+	var directories []string
+	for _, directory := range filepath.SplitList(os.Getenv(EnvVarTechniquePacks)) {
+		if directory != "" {
+			directories = append(directories, directory)
+		}
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		defaultDirectory := filepath.Join(homeDir, ".stratus-red-team", DefaultDirectoryName)
+		if info, err := os.Stat(defaultDirectory); err == nil && info.IsDir() {
+			directories = append(directories, defaultDirectory)
+		}
+	}
+	return directories
+}
+
+// LoadAndRegister loads the techniques of the packs in the given directories, and registers them into
+// registry. A technique whose ID is already registered is an error, so that packs cannot shadow built-ins.
+func LoadAndRegister(registry *stratus.Registry, directories ...string) error {
+	var errs []error
+	for _, directory := range directories {
+		techniques, err := Load(directory)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, technique := range techniques {
+			if registry.GetAttackTechniqueByName(technique.ID) != nil {
+				errs = append(errs, fmt.Errorf("technique pack %s: %s is already registered", directory, technique.ID))
+				continue
+			}
+			registry.RegisterAttackTechnique(technique)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Load loads the techniques of the pack in directory, i.e. of every technique.yaml manifest under it
+func Load(directory string) ([]*stratus.AttackTechnique, error) {
+	var manifestPaths []string
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && entry.Name() == ManifestFileName {
+			manifestPaths = append(manifestPaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading technique pack %s: %w", directory, err)
+	}
+	sort.Strings(manifestPaths)
+
+	techniques := make([]*stratus.AttackTechnique, 0, len(manifestPaths))
+	for _, manifestPath := range manifestPaths {
+		technique, err := LoadTechnique(filepath.Dir(manifestPath))
+		if err != nil {
+			return nil, err
+		}
+		techniques = append(techniques, technique)
+	}
+	return techniques, nil
+}
+
+// LoadTechnique loads the technique of a pack in directory, from its technique.yaml manifest
+func LoadTechnique(directory string) (*stratus.AttackTechnique, error) {
+	manifestPath := filepath.Join(directory, ManifestFileName)
+	rawManifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading technique manifest: %w", err)
+	}
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(rawManifest))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("parsing technique manifest %s: %w", manifestPath, err)
+	}
+	technique, err := manifest.toAttackTechnique(directory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid technique manifest %s: %w", manifestPath, err)
+	}
+	return technique, nil
+}
+
+func (m *Manifest) toAttackTechnique(directory string) (*stratus.AttackTechnique, error) {
+	if m.ID == "" {
+		return nil, errors.New("missing technique ID")
+	}
+	platform, err := stratus.PlatformFromString(m.Platform)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.MitreAttackTactics) == 0 {
+		return nil, errors.New("missing MITRE ATT&CK tactics")
+	}
+	tactics := make([]mitreattack.Tactic, 0, len(m.MitreAttackTactics))
+	for _, name := range m.MitreAttackTactics {
+		tactic, err := mitreattack.AttackTacticFromString(name)
+		if err != nil {
+			return nil, err
+		}
+		tactics = append(tactics, tactic)
+	}
+	switch m.Impact {
+	case "", stratus.ImpactLow, stratus.ImpactDestructive:
+	default:
+		return nil, fmt.Errorf("unknown impact %q, expected %s or %s", m.Impact, stratus.ImpactLow, stratus.ImpactDestructive)
+	}
+	if m.Detonate == nil {
+		return nil, errors.New("missing detonation")
+	}
+	if err := m.Detonate.validate(platform); err != nil {
+		return nil, fmt.Errorf("detonation: %w", err)
+	}
+	if m.Revert != nil {
+		if err := m.Revert.validate(platform); err != nil {
+			return nil, fmt.Errorf("reversion: %w", err)
+		}
+	}
+
+	technique := &stratus.AttackTechnique{
+		ID:                    m.ID,
+		FriendlyName:          m.Name,
+		Description:           m.Description,
+		Detection:             m.Detection,
+		DetectionRule:         m.DetectionRule,
+		Parameters:            m.Parameters,
+		IsSlow:                m.IsSlow,
+		IsIdempotent:          m.IsIdempotent,
+		Impact:                m.Impact,
+		MitreAttackTactics:    tactics,
+		MitreAttackTechniques: m.MitreAttackTechniques,
+		Platform:              platform,
+	}
+	if _, err := technique.GetMitreAttackTechniques(); err != nil {
+		return nil, err
+	}
+
+	// An explicit path to the prerequisites must exist, the default one is optional
+	terraformPath := m.Terraform
+	if terraformPath == "" {
+		terraformPath = TerraformFileName
+	}
+	if !filepath.IsAbs(terraformPath) {
+		terraformPath = filepath.Join(directory, terraformPath)
+	}
+	terraformCode, err := os.ReadFile(terraformPath)
+	switch {
+	case err == nil:
+		technique.PrerequisitesTerraformCode = terraformCode
+	case m.Terraform != "" || !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("reading prerequisites: %w", err)
+	}
+
+	technique.DetonateWithContext = m.Detonate.run(technique, directory)
+	if m.Revert != nil {
+		technique.RevertWithContext = m.Revert.run(technique, directory)
+	}
+	return technique, nil
+}
+
+func (m *Action) validate(platform stratus.Platform) error {
+	switch {
+	case m.Executable != "" && len(m.Steps) > 0:
+		return errors.New("set either an executable or steps, not both")
+	case m.Executable == "" && len(m.Steps) == 0:
+		return errors.New("missing executable or steps")
+	}
+	for i := range m.Steps {
+		if err := m.Steps[i].validate(platform); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// run returns a function running the steps of the action, or its executable
+func (m *Action) run(technique *stratus.AttackTechnique, directory string) stratus.TechniqueFunc {
+	if len(m.Steps) > 0 {
+		return func(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
+			return runSteps(ctx, m.Steps, technique, directory, params, providers)
+		}
+	}
+	return m.runExecutable(technique.ID, directory)
+}
+
+// runExecutable returns a function running the executable of the action from the directory of the technique,
+// with the Terraform outputs and the parameters of the technique in its environment
+func (m *Action) runExecutable(techniqueID string, directory string) stratus.TechniqueFunc {
+	return func(ctx context.Context, params map[string]string, _ stratus.CloudProviders) error {
+		executable := m.Executable
+		if !filepath.IsAbs(executable) {
+			executable = filepath.Join(directory, executable)
+		}
+		cmd := exec.CommandContext(ctx, executable, m.Args...)
+		cmd.Dir = directory
+		cmd.Env = append(os.Environ(), techniqueEnvironment(ctx, techniqueID, params)...)
+
+		output, err := cmd.CombinedOutput()
+		logOutput(output)
+		if err != nil {
+			return fmt.Errorf("running %s: %w", m.Executable, err)
+		}
+		return nil
+	}
+}
+
+// techniqueEnvironment returns the environment variables holding the ID, Terraform outputs and parameters
+// of a technique
+func techniqueEnvironment(ctx context.Context, techniqueID string, params map[string]string) []string {
+	variables := []string{EnvVarTechniqueID + "=" + techniqueID}
+	variables = append(variables, environment(EnvVarOutputPrefix, params)...)
+	return append(variables, environment(EnvVarParameterPrefix, stratus.ParametersFromContext(ctx).Strings())...)
+}
+
+func logOutput(output []byte) {
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line != "" {
+			log.Println(line)
+		}
+	}
+}
+
+// environment turns values into environment variables, e.g. bucket_name into STRATUS_OUTPUT_BUCKET_NAME
+func environment(prefix string, values map[string]string) []string {
+	variables := make([]string, 0, len(values))
+	for name, value := range values {
+		name = envVarUnsafeCharacters.ReplaceAllString(strings.ToUpper(name), "_")
+		variables = append(variables, prefix+name+"="+value)
+	}
+	sort.Strings(variables)
+	return variables
+}
@@ -0,0 +1,176 @@
+package packs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifest = `id: acme.persistence.backdoor-thing
+name: Backdoor a thing
+platform: aws
+mitreAttackTactics: [Persistence, Privilege Escalation]
+mitreAttackTechniques: [T1098]
+description: Backdoors a thing.
+impact: low
+parameters:
+  - name: principal
+    type: string
+    default: arn:aws:iam::123456789012:root
+detonate:
+  executable: detonate.sh
+  args: [--verbose]
+revert:
+  executable: revert.sh
+`
+
+// writePack writes a pack with a single technique, whose detonation script dumps its arguments and
+// environment to a file
+func writePack(t *testing.T, techniqueManifest string) (string, string) {
+	packDirectory := t.TempDir()
+	techniqueDirectory := filepath.Join(packDirectory, "backdoor-thing")
+	require.NoError(t, os.MkdirAll(techniqueDirectory, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(techniqueDirectory, ManifestFileName), []byte(techniqueManifest), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(techniqueDirectory, TerraformFileName), []byte(`output "bucket_name" { value = "foo" }`), 0644))
+
+	dump := filepath.Join(t.TempDir(), "dump")
+	script := "#!/bin/sh\necho \"$@ $STRATUS_TECHNIQUE_ID $STRATUS_OUTPUT_BUCKET_NAME $STRATUS_PARAMETER_PRINCIPAL\" > " + dump + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(techniqueDirectory, "detonate.sh"), []byte(script), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(techniqueDirectory, "revert.sh"), []byte("#!/bin/sh\necho reverting >&2\nexit 3\n"), 0755))
+	return packDirectory, dump
+}
+
+func TestLoadTechnique(t *testing.T) {
+	packDirectory, _ := writePack(t, manifest)
+
+	techniques, err := Load(packDirectory)
+
+	require.NoError(t, err)
+	require.Len(t, techniques, 1)
+	technique := techniques[0]
+	assert.Equal(t, "acme.persistence.backdoor-thing", technique.ID)
+	assert.Equal(t, "Backdoor a thing", technique.FriendlyName)
+	assert.Equal(t, stratus.Platform(stratus.AWS), technique.Platform)
+	assert.Equal(t, []mitreattack.Tactic{mitreattack.Persistence, mitreattack.PrivilegeEscalation}, technique.MitreAttackTactics)
+	assert.Equal(t, `output "bucket_name" { value = "foo" }`, string(technique.PrerequisitesTerraformCode))
+	assert.False(t, technique.IsDestructive())
+	assert.True(t, technique.IsRevertible())
+}
+
+func TestDetonateRunsExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("detonation scripts of the test pack are shell scripts")
+	}
+	packDirectory, dump := writePack(t, manifest)
+	techniques, err := Load(packDirectory)
+	require.NoError(t, err)
+	technique := techniques[0]
+	parameters, err := technique.ResolveParameters()
+	require.NoError(t, err)
+	ctx := stratus.ContextWithParameters(context.Background(), parameters)
+
+	err = technique.GetDetonate()(ctx, map[string]string{"bucket_name": "my-bucket"}, nil)
+
+	require.NoError(t, err)
+	output, err := os.ReadFile(dump)
+	require.NoError(t, err)
+	assert.Equal(t, "--verbose acme.persistence.backdoor-thing my-bucket arn:aws:iam::123456789012:root\n", string(output))
+
+	err = technique.GetRevert()(ctx, map[string]string{}, nil)
+	assert.ErrorContains(t, err, "running revert.sh: exit status 3")
+}
+
+func TestLoadAndRegister(t *testing.T) {
+	packDirectory, _ := writePack(t, manifest)
+	registry := stratus.NewRegistry()
+	registry.RegisterAttackTechnique(&stratus.AttackTechnique{ID: "aws.defense-evasion.cloudtrail-stop"})
+
+	require.NoError(t, LoadAndRegister(&registry, packDirectory))
+	assert.NotNil(t, registry.GetAttackTechniqueByName("acme.persistence.backdoor-thing"))
+	assert.Len(t, registry.GetAttackTechniques(&stratus.AttackTechniqueFilter{Platform: stratus.AWS}), 1)
+
+	err := LoadAndRegister(&registry, packDirectory)
+	assert.ErrorContains(t, err, "acme.persistence.backdoor-thing is already registered")
+	assert.Len(t, registry.ListAttackTechniques(), 2)
+}
+
+func TestLoadRejectsInvalidManifests(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Manifest      string
+		ExpectedError string
+	}{
+		{
+			Name:          "unknown field",
+			Manifest:      manifest + "foo: bar\n",
+			ExpectedError: "field foo not found",
+		},
+		{
+			Name:          "unknown platform",
+			Manifest:      "id: foo\nplatform: oracle\nmitreAttackTactics: [Impact]\ndetonate: {executable: detonate.sh}\n",
+			ExpectedError: "unknown platform: oracle",
+		},
+		{
+			Name:          "unknown tactic",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Mayhem]\ndetonate: {executable: detonate.sh}\n",
+			ExpectedError: "unknown MITRE ATT&CK tactic: Mayhem",
+		},
+		{
+			Name:          "unknown MITRE ATT&CK technique",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Impact]\nmitreAttackTechniques: [T9999]\ndetonate: {executable: detonate.sh}\n",
+			ExpectedError: "T9999",
+		},
+		{
+			Name:          "no detonation",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Impact]\n",
+			ExpectedError: "missing detonation",
+		},
+		{
+			Name:          "executable and steps",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Impact]\ndetonate: {executable: detonate.sh, steps: [{shell: 'true'}]}\n",
+			ExpectedError: "detonation: set either an executable or steps, not both",
+		},
+		{
+			Name:          "step doing several things",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Impact]\ndetonate: {steps: [{shell: 'true', action: sleep}]}\n",
+			ExpectedError: "detonation: step 1: set exactly one of shell, http or action",
+		},
+		{
+			Name:          "unknown action",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Impact]\ndetonate: {steps: [{action: explode}]}\n",
+			ExpectedError: `unknown action "explode"`,
+		},
+		{
+			Name:          "unsigned AWS request",
+			Manifest:      "id: foo\nplatform: aws\nmitreAttackTactics: [Impact]\nrevert: {steps: [{http: {url: 'https://iam.amazonaws.com'}}]}\ndetonate: {executable: detonate.sh}\n",
+			ExpectedError: "reversion: step 1: missing AWS service to sign the HTTP request for",
+		},
+		{
+			Name:          "missing prerequisites",
+			Manifest:      "id: foo\nplatform: gcp\nmitreAttackTactics: [Impact]\nterraform: ../prerequisites/main.tf\ndetonate: {executable: detonate.sh}\n",
+			ExpectedError: "reading prerequisites",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			packDirectory, _ := writePack(t, testCase.Manifest)
+			_, err := Load(packDirectory)
+			assert.ErrorContains(t, err, testCase.ExpectedError)
+		})
+	}
+}
+
+func TestDefaultDirectories(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvVarTechniquePacks, "/opt/packs"+string(os.PathListSeparator)+"/srv/packs")
+
+	assert.Equal(t, []string{"/opt/packs", "/srv/packs"}, DefaultDirectories())
+}
@@ -0,0 +1,409 @@
+package packs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"golang.org/x/oauth2/google"
+	"k8s.io/client-go/rest"
+)
+
+// Authentication schemes of HTTP steps
+const (
+	AuthNone       = "none"
+	AuthAWS        = "aws"
+	AuthGCP        = "gcp"
+	AuthAzure      = "azure"
+	AuthEntraID    = "entra-id"
+	AuthKubernetes = "kubernetes"
+)
+
+// Step is a declarative detonation or reversion step. It does exactly one of: running a shell command,
+// sending an HTTP request, or calling a built-in action.
+//
+// Its fields are Go templates with <% %> delimiters, like the configuration file, which can reference
+// <% .TechniqueID %>, the Terraform outputs as <% .Outputs.name %>, the parameters as
+// <% .Parameters.name %>, and the output of previous named steps as <% .Steps.name %>.
+type Step struct {
+	// Name under which later steps reference the output of the step
+	Name string `yaml:"name"`
+
+	// Shell command, run with sh from the directory of the technique. Its environment holds the credentials
+	// of the platform of the technique, e.g. AWS_ACCESS_KEY_ID, and the variables of technique executables.
+	// Its output is what it writes to stdout.
+	Shell string `yaml:"shell"`
+
+	// HTTP request, authenticated with the credentials of a platform. Its output is the response body.
+	HTTP *HTTPRequest `yaml:"http"`
+
+	// Name of a built-in action, see RegisterAction, called with the arguments in With
+	Action string            `yaml:"action"`
+	With   map[string]string `yaml:"with"`
+}
+
+// HTTPRequest is the request sent by an HTTP step
+type HTTPRequest struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+
+	// How to authenticate the request: aws (SigV4), gcp, azure, entra-id, kubernetes or none. Defaults to
+	// the platform of the technique, with EKS techniques signing their requests for AWS.
+	Auth string `yaml:"auth"`
+	// AWS service and region to sign requests for, e.g. "iam" and "us-east-1". The region defaults to the
+	// one of the AWS configuration
+	Service string `yaml:"service"`
+	Region  string `yaml:"region"`
+
+	// Status codes of successful responses. Defaults to any 2xx
+	ExpectedStatus []int `yaml:"expectedStatus"`
+}
+
+// ActionFunc is a built-in action, returning the output of the step calling it
+type ActionFunc func(ctx context.Context, args map[string]string, providers stratus.CloudProviders) (string, error)
+
+var actions = map[string]ActionFunc{
+	"sleep":                 sleepAction,
+	"log":                   logAction,
+	"aws.account-id":        awsAccountIDAction,
+	"gcp.project-id":        gcpProjectIDAction,
+	"azure.subscription-id": azureSubscriptionIDAction,
+}
+
+// RegisterAction makes a built-in action available to the steps of techniques loaded afterward
+func RegisterAction(name string, action ActionFunc) {
+	actions[name] = action
+}
+
+// stepData is what the templates of steps can reference
+type stepData struct {
+	TechniqueID string
+	Outputs     map[string]string
+	Parameters  map[string]string
+	Steps       map[string]string
+}
+
+func (m *Step) validate(platform stratus.Platform) error {
+	kinds := 0
+	for _, set := range []bool{m.Shell != "", m.HTTP != nil, m.Action != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New("set exactly one of shell, http or action")
+	}
+	switch {
+	case m.HTTP != nil:
+		if m.HTTP.URL == "" {
+			return errors.New("missing HTTP URL")
+		}
+		auth := m.HTTP.auth(platform)
+		if !slices.Contains([]string{AuthNone, AuthAWS, AuthGCP, AuthAzure, AuthEntraID, AuthKubernetes}, auth) {
+			return fmt.Errorf("unknown HTTP authentication %q", auth)
+		}
+		if auth == AuthAWS && m.HTTP.Service == "" {
+			return errors.New("missing AWS service to sign the HTTP request for")
+		}
+	case m.Action != "":
+		if _, found := actions[m.Action]; !found {
+			return fmt.Errorf("unknown action %q", m.Action)
+		}
+	}
+	return nil
+}
+
+func (m *HTTPRequest) auth(platform stratus.Platform) string {
+	if m.Auth != "" {
+		return m.Auth
+	}
+	switch platform {
+	case stratus.AWS, stratus.EKS:
+		return AuthAWS
+	case stratus.GCP:
+		return AuthGCP
+	case stratus.Azure:
+		return AuthAzure
+	case stratus.EntraID:
+		return AuthEntraID
+	case stratus.Kubernetes:
+		return AuthKubernetes
+	default:
+		return AuthNone
+	}
+}
+
+// runSteps runs steps in order, stopping at the first failing one
+func runSteps(ctx context.Context, steps []Step, technique *stratus.AttackTechnique, directory string, params map[string]string, cloudProviders stratus.CloudProviders) error {
+	data := &stepData{
+		TechniqueID: technique.ID,
+		Outputs:     params,
+		Parameters:  stratus.ParametersFromContext(ctx).Strings(),
+		Steps:       map[string]string{},
+	}
+	for i := range steps {
+		step := &steps[i]
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i+1)
+		}
+		var output string
+		var err error
+		switch {
+		case step.Shell != "":
+			output, err = step.runShell(ctx, data, technique, directory, params, cloudProviders)
+		case step.HTTP != nil:
+			output, err = step.sendRequest(ctx, data, technique.Platform, cloudProviders)
+		default:
+			output, err = step.callAction(ctx, data, cloudProviders)
+		}
+		if err != nil {
+			return fmt.Errorf("step %s: %w", name, err)
+		}
+		if step.Name != "" {
+			data.Steps[step.Name] = output
+		}
+	}
+	return nil
+}
+
+// render executes a field of a step as a template
+func render(field string, data *stepData) (string, error) {
+	tmpl, err := template.New("").Delims("<%", "%>").Option("missingkey=error").Parse(field)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (m *Step) runShell(ctx context.Context, data *stepData, technique *stratus.AttackTechnique, directory string, params map[string]string, cloudProviders stratus.CloudProviders) (string, error) {
+	script, err := render(m.Shell, data)
+	if err != nil {
+		return "", err
+	}
+	environment, err := providerEnvironment(ctx, technique.Platform, cloudProviders)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve the credentials of %s: %w", technique.Platform, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Dir = directory
+	cmd.Env = append(os.Environ(), techniqueEnvironment(ctx, technique.ID, params)...)
+	cmd.Env = append(cmd.Env, environment...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	logOutput(stdout.Bytes())
+	logOutput(stderr.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("running shell command: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// providerEnvironment returns the environment variables with which CLIs of the platform use the same
+// credentials and target as the technique
+func providerEnvironment(ctx context.Context, platform stratus.Platform, cloudProviders stratus.CloudProviders) ([]string, error) {
+	switch platform {
+	case stratus.AWS, stratus.EKS:
+		awsConfig := cloudProviders.AWS().GetConnection()
+		credentials, err := awsConfig.Credentials.Retrieve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		environment := []string{
+			"AWS_ACCESS_KEY_ID=" + credentials.AccessKeyID,
+			"AWS_SECRET_ACCESS_KEY=" + credentials.SecretAccessKey,
+			"AWS_SESSION_TOKEN=" + credentials.SessionToken,
+			"AWS_REGION=" + awsConfig.Region,
+			"AWS_DEFAULT_REGION=" + awsConfig.Region,
+		}
+		if platform == stratus.EKS {
+			environment = append(environment, "KUBECONFIG="+providers.GetKubeConfigPath())
+		}
+		return environment, nil
+	case stratus.GCP:
+		projectID := cloudProviders.GCP().GetProjectId()
+		return []string{"GOOGLE_PROJECT=" + projectID, "CLOUDSDK_CORE_PROJECT=" + projectID}, nil
+	case stratus.Azure:
+		return []string{"AZURE_SUBSCRIPTION_ID=" + cloudProviders.Azure().SubscriptionID}, nil
+	case stratus.Kubernetes:
+		return []string{"KUBECONFIG=" + providers.GetKubeConfigPath()}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (m *Step) sendRequest(ctx context.Context, data *stepData, platform stratus.Platform, cloudProviders stratus.CloudProviders) (string, error) {
+	url, err := render(m.HTTP.URL, data)
+	if err != nil {
+		return "", err
+	}
+	body, err := render(m.HTTP.Body, data)
+	if err != nil {
+		return "", err
+	}
+	method := m.HTTP.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	auth := m.HTTP.auth(platform)
+	client := http.DefaultClient
+	if auth == AuthKubernetes {
+		restConfig := cloudProviders.K8s().GetRestConfig()
+		if strings.HasPrefix(url, "/") {
+			url = strings.TrimSuffix(restConfig.Host, "/") + url
+		}
+		if client, err = rest.HTTPClientFor(restConfig); err != nil {
+			return "", err
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	for name, value := range m.HTTP.Headers {
+		if value, err = render(value, data); err != nil {
+			return "", err
+		}
+		request.Header.Set(name, value)
+	}
+	if err := m.HTTP.authenticate(ctx, request, auth, []byte(body), cloudProviders); err != nil {
+		return "", fmt.Errorf("unable to authenticate HTTP request: %w", err)
+	}
+
+	log.Printf("Sending %s %s", method, url)
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if !m.HTTP.isExpected(response.StatusCode) {
+		return "", fmt.Errorf("unexpected HTTP status %s: %s", response.Status, strings.TrimSpace(string(responseBody)))
+	}
+	return strings.TrimSpace(string(responseBody)), nil
+}
+
+func (m *HTTPRequest) isExpected(statusCode int) bool {
+	if len(m.ExpectedStatus) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	return slices.Contains(m.ExpectedStatus, statusCode)
+}
+
+// authenticate adds the credentials of a platform to a request
+func (m *HTTPRequest) authenticate(ctx context.Context, request *http.Request, auth string, body []byte, cloudProviders stratus.CloudProviders) error {
+	switch auth {
+	case AuthAWS:
+		awsConfig := cloudProviders.AWS().GetConnection()
+		credentials, err := awsConfig.Credentials.Retrieve(ctx)
+		if err != nil {
+			return err
+		}
+		region := m.Region
+		if region == "" {
+			region = awsConfig.Region
+		}
+		payloadHash := sha256.Sum256(body)
+		return v4.NewSigner().SignHTTP(ctx, credentials, request, hex.EncodeToString(payloadHash[:]), m.Service, region, time.Now())
+	case AuthGCP:
+		tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			return err
+		}
+		token, err := tokenSource.Token()
+		if err != nil {
+			return err
+		}
+		token.SetAuthHeader(request)
+	case AuthAzure:
+		return setBearerToken(ctx, request, cloudProviders.Azure().GetCredentials(), "https://management.azure.com/.default")
+	case AuthEntraID:
+		return setBearerToken(ctx, request, cloudProviders.EntraId().Credentials, "https://graph.microsoft.com/.default")
+	}
+	// Kubernetes requests are authenticated by their client
+	return nil
+}
+
+func setBearerToken(ctx context.Context, request *http.Request, credentials azcore.TokenCredential, scope string) error {
+	token, err := credentials.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token.Token)
+	return nil
+}
+
+func (m *Step) callAction(ctx context.Context, data *stepData, cloudProviders stratus.CloudProviders) (string, error) {
+	args := make(map[string]string, len(m.With))
+	for name, value := range m.With {
+		rendered, err := render(value, data)
+		if err != nil {
+			return "", err
+		}
+		args[name] = rendered
+	}
+	return actions[m.Action](ctx, args, cloudProviders)
+}
+
+// sleepAction waits for the duration in its "duration" argument, e.g. "30s"
+func sleepAction(ctx context.Context, args map[string]string, _ stratus.CloudProviders) (string, error) {
+	duration, err := time.ParseDuration(args["duration"])
+	if err != nil {
+		return "", fmt.Errorf("invalid duration: %w", err)
+	}
+	select {
+	case <-time.After(duration):
+		return "", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// logAction prints its "message" argument
+func logAction(_ context.Context, args map[string]string, _ stratus.CloudProviders) (string, error) {
+	log.Println(args["message"])
+	return args["message"], nil
+}
+
+func awsAccountIDAction(ctx context.Context, _ map[string]string, cloudProviders stratus.CloudProviders) (string, error) {
+	return cloudProviders.AWS().GetAccountID(ctx)
+}
+
+func gcpProjectIDAction(_ context.Context, _ map[string]string, cloudProviders stratus.CloudProviders) (string, error) {
+	return cloudProviders.GCP().GetProjectId(), nil
+}
+
+func azureSubscriptionIDAction(_ context.Context, _ map[string]string, cloudProviders stratus.CloudProviders) (string, error) {
+	return cloudProviders.Azure().SubscriptionID, nil
+}
@@ -0,0 +1,167 @@
+package packs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// awsProviders returns providers with static AWS credentials
+func awsProviders() stratus.CloudProviders {
+	awsConfig := aws.Config{
+		Region:      "eu-west-3",
+		Credentials: credentials.NewStaticCredentialsProvider("AKIAEXAMPLE", "secret", "token"),
+	}
+	return stratus.CloudProvidersImpl{AWSProvider: stratus.NewAWSProvider(uuid.New(), stratus.WithAWSConfig(awsConfig))}
+}
+
+func loadDeclarativeTechnique(t *testing.T, techniqueManifest string) *stratus.AttackTechnique {
+	directory := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(directory, ManifestFileName), []byte(techniqueManifest), 0644))
+	technique, err := LoadTechnique(directory)
+	require.NoError(t, err)
+	return technique
+}
+
+func TestStepsSendSignedHTTPRequests(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		if r.URL.Path == "/trails" {
+			_, _ = w.Write([]byte("my-trail\n"))
+		}
+	}))
+	defer server.Close()
+
+	technique := loadDeclarativeTechnique(t, `id: acme.defense-evasion.stop-trail
+platform: AWS
+mitreAttackTactics: [Defense Evasion]
+parameters:
+  - name: reason
+    type: string
+    default: maintenance
+detonate:
+  steps:
+    - name: trail
+      http:
+        url: `+server.URL+`/trails
+        service: cloudtrail
+    - http:
+        method: POST
+        url: `+server.URL+`/stop?bucket=<% .Outputs.bucket_name %>
+        headers:
+          X-Reason: <% .Parameters.reason %>
+        body: '{"Name": "<% .Steps.trail %>"}'
+        service: cloudtrail
+        region: us-east-1
+`)
+	parameters, err := technique.ResolveParameters()
+	require.NoError(t, err)
+	ctx := stratus.ContextWithParameters(context.Background(), parameters)
+
+	err = technique.GetDetonate()(ctx, map[string]string{"bucket_name": "my-bucket"}, awsProviders())
+
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodGet, requests[0].Method)
+	assert.Contains(t, requests[0].Header.Get("Authorization"), "Credential=AKIAEXAMPLE/")
+	assert.Contains(t, requests[0].Header.Get("Authorization"), "/eu-west-3/cloudtrail/aws4_request")
+	assert.Equal(t, "token", requests[0].Header.Get("X-Amz-Security-Token"))
+
+	assert.Equal(t, http.MethodPost, requests[1].Method)
+	assert.Equal(t, "my-bucket", requests[1].URL.Query().Get("bucket"))
+	assert.Equal(t, "maintenance", requests[1].Header.Get("X-Reason"))
+	assert.Equal(t, `{"Name": "my-trail"}`, bodies[1])
+	assert.Contains(t, requests[1].Header.Get("Authorization"), "/us-east-1/cloudtrail/aws4_request")
+}
+
+func TestStepsFailOnUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "access denied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	technique := loadDeclarativeTechnique(t, `id: foo
+platform: GCP
+mitreAttackTactics: [Impact]
+detonate:
+  steps:
+    - name: forbidden
+      http: {url: `+server.URL+`, auth: none}
+revert:
+  steps:
+    - http: {url: `+server.URL+`, auth: none, expectedStatus: [403]}
+`)
+
+	err := technique.GetDetonate()(context.Background(), nil, nil)
+	assert.ErrorContains(t, err, "step forbidden: unexpected HTTP status 403 Forbidden: access denied")
+	assert.NoError(t, technique.GetRevert()(context.Background(), nil, nil))
+}
+
+func TestStepsRunShellCommandsWithProviderEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell steps require sh")
+	}
+	dump := filepath.Join(t.TempDir(), "dump")
+	technique := loadDeclarativeTechnique(t, `id: acme.execution.foo
+platform: AWS
+mitreAttackTactics: [Execution]
+detonate:
+  steps:
+    - name: greeting
+      shell: echo hello
+    - shell: echo "<% .Steps.greeting %> $AWS_ACCESS_KEY_ID $AWS_REGION $STRATUS_OUTPUT_INSTANCE_ID <% .TechniqueID %>" > `+dump+`
+    - shell: exit 4
+`)
+
+	err := technique.GetDetonate()(context.Background(), map[string]string{"instance_id": "i-123"}, awsProviders())
+
+	assert.ErrorContains(t, err, "step 3: running shell command: exit status 4")
+	output, err := os.ReadFile(dump)
+	require.NoError(t, err)
+	assert.Equal(t, "hello AKIAEXAMPLE eu-west-3 i-123 acme.execution.foo\n", string(output))
+}
+
+func TestStepsCallActions(t *testing.T) {
+	var calls []map[string]string
+	RegisterAction("test.record", func(_ context.Context, args map[string]string, _ stratus.CloudProviders) (string, error) {
+		calls = append(calls, args)
+		return strings.ToUpper(args["value"]), nil
+	})
+	technique := loadDeclarativeTechnique(t, `id: foo
+platform: kubernetes
+mitreAttackTactics: [Impact]
+detonate:
+  steps:
+    - name: first
+      action: test.record
+      with: {value: <% .Outputs.namespace %>}
+    - action: test.record
+      with: {value: <% .Steps.first %>}
+    - action: sleep
+      with: {duration: 1ms}
+    - action: test.record
+      with: {value: <% .Outputs.missing %>}
+`)
+
+	err := technique.GetDetonate()(context.Background(), map[string]string{"namespace": "stratus"}, nil)
+
+	assert.ErrorContains(t, err, "step 4:")
+	assert.Equal(t, []map[string]string{{"value": "stratus"}, {"value": "STRATUS"}}, calls)
+}
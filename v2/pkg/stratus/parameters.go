@@ -0,0 +1,208 @@
+package stratus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParameterType is the type of the value of a technique parameter.
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeInteger ParameterType = "integer"
+	ParameterTypeBoolean ParameterType = "boolean"
+	// ParameterTypeStringList values are written as comma-separated strings, e.g. "p2.xlarge,p3.2xlarge"
+	ParameterTypeStringList ParameterType = "stringList"
+)
+
+// TechniqueParameter is a user-tunable knob of a technique, read by its detonation and reversion
+// functions. Users set it under techniques.<id>.parameters in the configuration file, or with --param.
+type TechniqueParameter struct {
+	// Name of the parameter, e.g. "instance_count"
+	Name string `yaml:"name" json:"name"`
+
+	Description string `yaml:"description" json:"description"`
+
+	Type ParameterType `yaml:"type" json:"type"`
+
+	// Default value, written the same way users set it, e.g. "10" for an integer
+	Default string `yaml:"default" json:"default"`
+
+	// If set, the only values the parameter, or each item of a string list, can take
+	AllowedValues []string `yaml:"allowedValues,omitempty" json:"allowedValues,omitempty"`
+}
+
+// parse converts a raw value to the type of the parameter, and validates it.
+func (m TechniqueParameter) parse(raw string) (any, error) {
+	switch m.Type {
+	case ParameterTypeString:
+		return raw, m.checkAllowed(raw)
+	case ParameterTypeInteger:
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return value, m.checkAllowed(strconv.Itoa(value))
+	case ParameterTypeBoolean:
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return value, nil
+	case ParameterTypeStringList:
+		values := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if err := m.checkAllowed(item); err != nil {
+				return nil, err
+			}
+			values = append(values, item)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %q", m.Type)
+	}
+}
+
+func (m TechniqueParameter) checkAllowed(value string) error {
+	if len(m.AllowedValues) == 0 || slices.Contains(m.AllowedValues, value) {
+		return nil
+	}
+	return fmt.Errorf("%q is not one of the allowed values: %s", value, strings.Join(m.AllowedValues, ", "))
+}
+
+// Parameters are the typed values of the parameters of a technique, once resolved from their
+// defaults and the values set by the user.
+type Parameters struct {
+	values map[string]any
+}
+
+// GetString returns the value of a string parameter, or "" if the technique does not declare it.
+func (m Parameters) GetString(name string) string {
+	value, _ := m.values[name].(string)
+	return value
+}
+
+// GetInt returns the value of an integer parameter, or 0 if the technique does not declare it.
+func (m Parameters) GetInt(name string) int {
+	value, _ := m.values[name].(int)
+	return value
+}
+
+// GetBool returns the value of a boolean parameter, or false if the technique does not declare it.
+func (m Parameters) GetBool(name string) bool {
+	value, _ := m.values[name].(bool)
+	return value
+}
+
+// GetStringList returns the value of a string list parameter, or nil if the technique does not declare it.
+func (m Parameters) GetStringList(name string) []string {
+	value, _ := m.values[name].([]string)
+	return value
+}
+
+// Strings returns the values of the parameters written the same way users set them, e.g. "p2.xlarge,p3.2xlarge"
+// for a string list, for techniques that hand them over to other programs.
+func (m Parameters) Strings() map[string]string {
+	values := make(map[string]string, len(m.values))
+	for name, value := range m.values {
+		if items, isList := value.([]string); isList {
+			values[name] = strings.Join(items, ",")
+		} else {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+// ResolveParameters validates the values set by the user for the parameters of the technique, and
+// fills in the defaults of the others. Values later in the list take precedence, which lets callers
+// pass the configuration file first and the command line flags last.
+func (m AttackTechnique) ResolveParameters(values ...map[string]string) (Parameters, error) {
+	if err := m.checkParametersAreReadable(); err != nil {
+		return Parameters{}, err
+	}
+	raw := map[string]string{}
+	for _, parameter := range m.Parameters {
+		raw[parameter.Name] = parameter.Default
+	}
+	var errs []error
+	for _, overrides := range values {
+		for name, value := range overrides {
+			if _, declared := raw[name]; !declared {
+				errs = append(errs, fmt.Errorf("%s has no parameter %q%s", m.ID, name, m.describeParameterNames()))
+				continue
+			}
+			raw[name] = value
+		}
+	}
+
+	resolved := Parameters{values: make(map[string]any, len(m.Parameters))}
+	for _, parameter := range m.Parameters {
+		value, err := parameter.parse(raw[parameter.Name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for the parameter %s of %s: %w", parameter.Name, m.ID, err))
+			continue
+		}
+		resolved.values[parameter.Name] = value
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return Parameters{}, errors.Join(errs...)
+	}
+	return resolved, nil
+}
+
+// checkParametersAreReadable ensures that the functions of a technique that declares parameters can read
+// them. Parameters are only passed through the context, which the legacy Detonate and Revert functions
+// do not get.
+func (m AttackTechnique) checkParametersAreReadable() error {
+	if len(m.Parameters) == 0 {
+		return nil
+	}
+	if m.DetonateWithContext == nil && m.Detonate != nil {
+		return fmt.Errorf("%s declares parameters, which its legacy Detonate function cannot read, use DetonateWithContext instead", m.ID)
+	}
+	if m.RevertWithContext == nil && m.Revert != nil {
+		return fmt.Errorf("%s declares parameters, which its legacy Revert function cannot read, use RevertWithContext instead", m.ID)
+	}
+	return nil
+}
+
+// HasParameter indicates if the technique declares a parameter with this name.
+func (m AttackTechnique) HasParameter(name string) bool {
+	return slices.ContainsFunc(m.Parameters, func(parameter TechniqueParameter) bool { return parameter.Name == name })
+}
+
+func (m AttackTechnique) describeParameterNames() string {
+	if len(m.Parameters) == 0 {
+		return ", it does not take any"
+	}
+	names := make([]string, 0, len(m.Parameters))
+	for _, parameter := range m.Parameters {
+		names = append(names, parameter.Name)
+	}
+	return " (available: " + strings.Join(names, ", ") + ")"
+}
+
+type parametersContextKey struct{}
+
+// ContextWithParameters returns a copy of ctx carrying the parameters of a technique.
+func ContextWithParameters(ctx context.Context, parameters Parameters) context.Context {
+	return context.WithValue(ctx, parametersContextKey{}, parameters)
+}
+
+// ParametersFromContext returns the parameters the runner resolved for the technique, from the context
+// that DetonateWithContext and RevertWithContext are given. Outside of a runner, no parameter is set.
+func ParametersFromContext(ctx context.Context) Parameters {
+	parameters, _ := ctx.Value(parametersContextKey{}).(Parameters)
+	return parameters
+}
@@ -0,0 +1,105 @@
+package stratus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parametrizedTechnique() AttackTechnique {
+	return AttackTechnique{
+		ID: "foo",
+		Parameters: []TechniqueParameter{
+			{Name: "count", Type: ParameterTypeInteger, Default: "10"},
+			{Name: "verbose", Type: ParameterTypeBoolean, Default: "false"},
+			{Name: "name", Type: ParameterTypeString, Default: "stratus"},
+			{Name: "instance_types", Type: ParameterTypeStringList, Default: "p2.xlarge", AllowedValues: []string{"p2.xlarge", "p3.2xlarge"}},
+		},
+	}
+}
+
+func TestResolveParametersDefaults(t *testing.T) {
+	technique := parametrizedTechnique()
+
+	parameters, err := technique.ResolveParameters()
+
+	require.Nil(t, err)
+	assert.Equal(t, 10, parameters.GetInt("count"))
+	assert.False(t, parameters.GetBool("verbose"))
+	assert.Equal(t, "stratus", parameters.GetString("name"))
+	assert.Equal(t, []string{"p2.xlarge"}, parameters.GetStringList("instance_types"))
+	assert.Equal(t, 0, parameters.GetInt("name"), "accessing a parameter with the wrong type should return the zero value")
+	assert.Equal(t, "", parameters.GetString("undeclared"))
+}
+
+func TestResolveParametersOverrides(t *testing.T) {
+	technique := parametrizedTechnique()
+
+	parameters, err := technique.ResolveParameters(
+		map[string]string{"count": "5", "name": "from-config"},
+		map[string]string{"count": " 3 ", "verbose": "true", "instance_types": "p2.xlarge, p3.2xlarge"},
+	)
+
+	require.Nil(t, err)
+	assert.Equal(t, 3, parameters.GetInt("count"), "later values should take precedence")
+	assert.True(t, parameters.GetBool("verbose"))
+	assert.Equal(t, "from-config", parameters.GetString("name"))
+	assert.Equal(t, []string{"p2.xlarge", "p3.2xlarge"}, parameters.GetStringList("instance_types"))
+	assert.Equal(t, map[string]string{
+		"count":          "3",
+		"verbose":        "true",
+		"name":           "from-config",
+		"instance_types": "p2.xlarge,p3.2xlarge",
+	}, parameters.Strings())
+}
+
+func TestResolveParametersValidation(t *testing.T) {
+	technique := parametrizedTechnique()
+
+	_, err := technique.ResolveParameters(map[string]string{"count": "ten", "verbose": "maybe", "instance_types": "t2.micro", "nope": "1"})
+
+	require.NotNil(t, err)
+	assert.ErrorContains(t, err, `"ten" is not an integer`)
+	assert.ErrorContains(t, err, `"maybe" is not a boolean`)
+	assert.ErrorContains(t, err, `"t2.micro" is not one of the allowed values: p2.xlarge, p3.2xlarge`)
+	assert.ErrorContains(t, err, `foo has no parameter "nope" (available: count, verbose, name, instance_types)`)
+
+	_, err = (&AttackTechnique{ID: "bar"}).ResolveParameters(map[string]string{"count": "1"})
+	assert.ErrorContains(t, err, `bar has no parameter "count", it does not take any`)
+}
+
+func TestResolveParametersRejectsLegacyFunctions(t *testing.T) {
+	legacy := func(map[string]string, CloudProviders) error { return nil }
+	withContext := func(context.Context, map[string]string, CloudProviders) error { return nil }
+
+	technique := parametrizedTechnique()
+	technique.Detonate = legacy
+	_, err := technique.ResolveParameters()
+	assert.ErrorContains(t, err, "foo declares parameters, which its legacy Detonate function cannot read")
+
+	technique = parametrizedTechnique()
+	technique.DetonateWithContext = withContext
+	technique.Revert = legacy
+	_, err = technique.ResolveParameters()
+	assert.ErrorContains(t, err, "foo declares parameters, which its legacy Revert function cannot read")
+
+	technique.RevertWithContext = withContext
+	_, err = technique.ResolveParameters()
+	assert.NoError(t, err, "the context-aware functions are preferred over the legacy ones")
+
+	_, err = AttackTechnique{ID: "bar", Detonate: legacy}.ResolveParameters()
+	assert.NoError(t, err, "techniques without parameters can keep the legacy functions")
+}
+
+func TestParametersContext(t *testing.T) {
+	technique := parametrizedTechnique()
+	parameters, err := technique.ResolveParameters()
+	require.Nil(t, err)
+
+	ctx := ContextWithParameters(context.Background(), parameters)
+
+	assert.Equal(t, 10, ParametersFromContext(ctx).GetInt("count"))
+	assert.Equal(t, 0, ParametersFromContext(context.Background()).GetInt("count"))
+}
@@ -0,0 +1,26 @@
+package stratus
+
+// PlannedResourceAction is what warming up a technique would do to one of its prerequisite resources.
+type PlannedResourceAction string
+
+const (
+	PlannedResourceActionCreate  PlannedResourceAction = "create"
+	PlannedResourceActionUpdate  PlannedResourceAction = "update"
+	PlannedResourceActionReplace PlannedResourceAction = "replace"
+	PlannedResourceActionDelete  PlannedResourceAction = "delete"
+)
+
+// PlannedResource is a prerequisite resource that warming up a technique would change,
+// as reported by a Terraform plan.
+type PlannedResource struct {
+	// Address of the resource in the Terraform code of the technique, e.g. "aws_cloudtrail.trail"
+	Address string `json:"address" yaml:"address"`
+
+	// Type of the resource, e.g. "aws_cloudtrail"
+	Type string `json:"type" yaml:"type"`
+
+	// ProviderName is the Terraform provider managing the resource, e.g. "registry.terraform.io/hashicorp/aws"
+	ProviderName string `json:"providerName" yaml:"providerName"`
+
+	Action PlannedResourceAction `json:"action" yaml:"action"`
+}
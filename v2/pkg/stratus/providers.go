@@ -2,12 +2,16 @@ package stratus
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/datadog/stratus-red-team/v2/internal/providers"
 	"github.com/datadog/stratus-red-team/v2/internal/utils"
 	"github.com/google/uuid"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -25,12 +29,18 @@ type (
 // AWS provider options
 
 func WithAWSConfig(cfg aws.Config) AWSProviderOption { return providers.WithAWSConfig(cfg) }
+func WithAWSLoadOptions(opts ...func(*config.LoadOptions) error) AWSProviderOption {
+	return providers.WithAWSLoadOptions(opts...)
+}
 
 // GCP provider options
 
 func WithGCPProjectID(projectId string) GCPProviderOption {
 	return providers.WithGCPProjectID(projectId)
 }
+func WithGCPHTTPClient(client *http.Client) GCPProviderOption {
+	return providers.WithGCPHTTPClient(client)
+}
 
 // Azure provider options
 
@@ -40,16 +50,25 @@ func WithAzureCredentials(cred azcore.TokenCredential) AzureProviderOption {
 func WithAzureSubscriptionID(id string) AzureProviderOption {
 	return providers.WithAzureSubscriptionID(id)
 }
+func WithAzureTransport(transport policy.Transporter) AzureProviderOption {
+	return providers.WithAzureTransport(transport)
+}
 
 // Entra ID provider options
 
 func WithEntraIdCredentials(cred azcore.TokenCredential) EntraIdProviderOption {
 	return providers.WithEntraIdCredentials(cred)
 }
+func WithEntraIdTransport(transport http.RoundTripper) EntraIdProviderOption {
+	return providers.WithEntraIdTransport(transport)
+}
 
 // Kubernetes provider options
 
 func WithK8sRestConfig(cfg *rest.Config) K8sProviderOption { return providers.WithK8sRestConfig(cfg) }
+func WithK8sClient(client kubernetes.Interface) K8sProviderOption {
+	return providers.WithK8sClient(client)
+}
 
 // EKS provider options
 
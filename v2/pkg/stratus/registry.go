@@ -1,6 +1,8 @@
 package stratus
 
 import (
+	"strings"
+
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 )
 
@@ -56,6 +58,8 @@ func (m *Registry) ListAttackTechniques() []*AttackTechnique {
 type AttackTechniqueFilter struct {
 	Platform Platform
 	Tactic   mitreattack.Tactic
+	// ID of a MITRE ATT&CK technique, e.g. T1098, which matches its sub-techniques too, or of a sub-technique
+	MitreAttackTechnique string
 }
 
 func (m *AttackTechniqueFilter) matches(technique *AttackTechnique) bool {
@@ -77,5 +81,17 @@ func (m *AttackTechniqueFilter) matches(technique *AttackTechnique) bool {
 		}
 	}
 
-	return platformMatches && mitreAttackTacticMatches
+	return platformMatches && mitreAttackTacticMatches && m.matchesMitreAttackTechnique(technique)
+}
+
+func (m *AttackTechniqueFilter) matchesMitreAttackTechnique(technique *AttackTechnique) bool {
+	if m.MitreAttackTechnique == "" {
+		return true
+	}
+	for _, id := range technique.MitreAttackTechniques {
+		if id == m.MitreAttackTechnique || strings.HasPrefix(id, m.MitreAttackTechnique+".") {
+			return true
+		}
+	}
+	return false
 }
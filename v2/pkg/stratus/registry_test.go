@@ -32,3 +32,17 @@ func TestRegistryFiltering(t *testing.T) {
 	assert.Len(t, registry.GetAttackTechniques(&AttackTechniqueFilter{Tactic: mitreattack.Discovery}), 1)
 
 }
+
+func TestRegistryFilteringByMitreAttackTechnique(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterAttackTechnique(&AttackTechnique{ID: "foo", MitreAttackTechniques: []string{"T1098.001"}})
+	registry.RegisterAttackTechnique(&AttackTechnique{ID: "bar", MitreAttackTechniques: []string{"T1098"}})
+	registry.RegisterAttackTechnique(&AttackTechnique{ID: "baz", MitreAttackTechniques: []string{"T1562.008", "T1098.003"}})
+	registry.RegisterAttackTechnique(&AttackTechnique{ID: "qux", MitreAttackTechniques: []string{"T10981"}})
+
+	assert.Len(t, registry.GetAttackTechniques(&AttackTechniqueFilter{MitreAttackTechnique: "T1098"}), 3)
+	assert.Len(t, registry.GetAttackTechniques(&AttackTechniqueFilter{MitreAttackTechnique: "T1098.001"}), 1)
+	assert.Len(t, registry.GetAttackTechniques(&AttackTechniqueFilter{MitreAttackTechnique: "T1562"}), 1)
+	assert.Len(t, registry.GetAttackTechniques(&AttackTechniqueFilter{MitreAttackTechnique: "T1537"}), 0)
+	assert.Len(t, registry.GetAttackTechniques(&AttackTechniqueFilter{}), 4)
+}
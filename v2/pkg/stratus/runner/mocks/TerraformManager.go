@@ -2,7 +2,10 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	stratus "github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // TerraformManager is an autogenerated mock type for the TerraformManager type
 type TerraformManager struct {
@@ -62,6 +65,36 @@ func (_m *TerraformManager) TerraformInitAndApply(directory string, variables ma
 	return r0, r1
 }
 
+// TerraformPlan provides a mock function with given fields: directory, variables
+func (_m *TerraformManager) TerraformPlan(directory string, variables map[string]string) ([]stratus.PlannedResource, error) {
+	ret := _m.Called(directory, variables)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TerraformPlan")
+	}
+
+	var r0 []stratus.PlannedResource
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, map[string]string) ([]stratus.PlannedResource, error)); ok {
+		return rf(directory, variables)
+	}
+	if rf, ok := ret.Get(0).(func(string, map[string]string) []stratus.PlannedResource); ok {
+		r0 = rf(directory, variables)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]stratus.PlannedResource)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, map[string]string) error); ok {
+		r1 = rf(directory, variables)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewTerraformManager creates a new instance of TerraformManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTerraformManager(t interface {
@@ -7,19 +7,32 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/datadog/stratus-red-team/v2/internal/state"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/cassette"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/tracing"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/useragent"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // S3BackendConfig is re-exported for external consumers that cannot import
 // internal/state directly.
 type S3BackendConfig = state.S3BackendConfig
 
+// GCSBackendConfig is re-exported for external consumers that cannot import
+// internal/state directly.
+type GCSBackendConfig = state.GCSBackendConfig
+
+// AzureBlobBackendConfig is re-exported for external consumers that cannot import
+// internal/state directly.
+type AzureBlobBackendConfig = state.AzureBlobBackendConfig
+
 const StratusRunnerForce = true
 const StratusRunnerNoForce = false
 
@@ -34,6 +47,10 @@ const EnvVarStratusTerraformBinaryPath = "STRATUS_TERRAFORM_BINARY_PATH"
 // pluginCacheDirectoryName is the provider cache shared by every execution.
 const pluginCacheDirectoryName = "plugin-cache"
 
+// DefaultStateLockTTL is how long the state lock is held before other commands may break it,
+// should the command holding it have crashed. It must outlast the slowest warmup or cleanup.
+const DefaultStateLockTTL = time.Hour
+
 // RunnerOption configures optional dependencies on a Runner.
 // When no options are provided, the runner uses its default implementations
 // (filesystem state, bundled Terraform, default cloud provider credentials).
@@ -76,6 +93,77 @@ func WithS3Backend(cfg state.S3BackendConfig) RunnerOption {
 	return func(r *runnerImpl) { r.s3BackendConfig = &cfg }
 }
 
+// WithGCSBackend configures the runner to store both Terraform state and
+// Stratus internal state in a Google Cloud Storage bucket. Replaces the default
+// filesystem state manager and injects backend credentials into the TerraformManager.
+func WithGCSBackend(cfg state.GCSBackendConfig) RunnerOption {
+	return func(r *runnerImpl) { r.gcsBackendConfig = &cfg }
+}
+
+// WithAzureBlobBackend configures the runner to store both Terraform state and
+// Stratus internal state in an Azure Blob Storage container. Replaces the default
+// filesystem state manager and injects backend credentials into the TerraformManager.
+func WithAzureBlobBackend(cfg state.AzureBlobBackendConfig) RunnerOption {
+	return func(r *runnerImpl) { r.azureBlobBackendConfig = &cfg }
+}
+
+// WithStateLockOwner sets who the state lock is held by. Defaults to user@hostname.
+func WithStateLockOwner(owner string) RunnerOption {
+	return func(r *runnerImpl) { r.stateLockOwner = owner }
+}
+
+// WithStateLockTTL sets how long the state lock is held before other commands may break it.
+// Defaults to DefaultStateLockTTL.
+func WithStateLockTTL(ttl time.Duration) RunnerOption {
+	return func(r *runnerImpl) { r.stateLockTTL = ttl }
+}
+
+// WithParameters sets parameters of the technique, taking precedence over those of the config file.
+func WithParameters(parameters map[string]string) RunnerOption {
+	return func(r *runnerImpl) { r.parameters = parameters }
+}
+
+// WithTTL sets how long the prerequisites of the technique are kept after being warmed up, before
+// 'stratus reap' cleans them up. Defaults to keeping them until explicitly cleaned up.
+func WithTTL(ttl time.Duration) RunnerOption {
+	return func(r *runnerImpl) { r.ttl = ttl }
+}
+
+// WithDestructiveImpactAcknowledged confirms that techniques with a destructive impact may be detonated.
+// Without it, the runner refuses to detonate them.
+func WithDestructiveImpactAcknowledged() RunnerOption {
+	return func(r *runnerImpl) { r.destructiveImpactAcknowledged = true }
+}
+
+// WithTracerProvider records a span for each lifecycle operation, with child spans for Terraform and the
+// cloud API calls of the technique. Defaults to the global tracer provider of OpenTelemetry.
+func WithTracerProvider(tracerProvider trace.TracerProvider) RunnerOption {
+	return func(r *runnerImpl) { r.tracerProvider = tracerProvider }
+}
+
+// WithRecording records the HTTP exchanges of the detonation and reversion of the technique with cloud APIs,
+// into cassettes under directory to replay them in tests, see the cassette package. Defaults to the
+// STRATUS_RECORD environment variable. It has no effect along with WithProviderFactory.
+func WithRecording(directory string) RunnerOption {
+	return func(r *runnerImpl) { r.recordDirectory = directory }
+}
+
+// WithAttackerIdentity detonates the technique as identity, instead of the attacker identity of the config file
+// or of the technique. Warm-up, reversion and cleanup still use the default credentials.
+func WithAttackerIdentity(identity stratus.Identity) RunnerOption {
+	return func(r *runnerImpl) { r.attackerIdentity = &identity }
+}
+
+// WithTarget runs the technique in the AWS account and region, GCP project or Azure subscription of target.
+// The cloud providers, injected with WithProviderFactory or default ones, and Terraform are pointed at the
+// target, and the state of the technique is kept in a subdirectory named after the target.
+func WithTarget(target config.Target) RunnerOption {
+	return func(r *runnerImpl) { r.target = &target }
+}
+
+// targetResolver returns the targets that the techniques of a platform would run in, see stratus.ResolveTargets.
+type targetResolver func(ctx context.Context, platform stratus.Platform, providerFactory stratus.CloudProviders) ([]stratus.Target, error)
+
 type runnerImpl struct {
 	Technique               *stratus.AttackTechnique
 	TechniqueState          stratus.AttackTechniqueState
@@ -89,14 +177,35 @@ type runnerImpl struct {
 	Context                 context.Context
 	terraformBackendConfigs map[string]string
 	s3BackendConfig         *state.S3BackendConfig
+	gcsBackendConfig        *state.GCSBackendConfig
+	azureBlobBackendConfig  *state.AzureBlobBackendConfig
 	// correlationIDProvided records that the caller supplied the correlation ID, as opposed
 	// to one being generated. Only a caller-supplied ID isolates state, since a generated one
 	// is different on every command and could never find the previous step's state.
 	correlationIDProvided bool
+	stateLockOwner        string
+	stateLockTTL          time.Duration
+	parameters            map[string]string
+	ttl                   time.Duration
+	// destructiveImpactAcknowledged is required to detonate techniques with a destructive impact
+	destructiveImpactAcknowledged bool
+	// resolveTargets checks where techniques would run, when the config restricts allowed targets
+	resolveTargets targetResolver
+	tracerProvider trace.TracerProvider
+	// recordDirectory is where cassettes of the calls to cloud APIs are recorded, if set
+	recordDirectory string
+	// attackerIdentity is the principal to detonate the technique as, overriding the config and the technique
+	attackerIdentity *stratus.Identity
+	// target is where the technique runs, instead of where the default credentials point at
+	target *config.Target
+	// stateLockDepth counts the nested operations holding the state lock, for instance WarmUp
+	// within Detonate, so that only the outermost one acquires and releases it.
+	stateLockDepth int
 }
 
 type Runner interface {
 	WarmUp() (map[string]string, error)
+	Plan() ([]stratus.PlannedResource, error)
 	Detonate() error
 	Revert() error
 	CleanUp() error
@@ -126,6 +235,9 @@ func NewRunnerWithContext(ctx context.Context, technique *stratus.AttackTechniqu
 	if runner.UniqueCorrelationID == uuid.Nil {
 		runner.UniqueCorrelationID, runner.correlationIDProvided = resolveCorrelationID()
 	}
+	if runner.recordDirectory == "" {
+		runner.recordDirectory = cassette.DirectoryFromEnv()
+	}
 
 	if runner.Config == nil {
 		cfg, err := config.LoadConfig()
@@ -138,18 +250,38 @@ func NewRunnerWithContext(ctx context.Context, technique *stratus.AttackTechniqu
 	// Built after the correlation ID is resolved, since the state layout depends on it.
 	// An explicitly injected state manager always wins, whatever the option order.
 	var stateOpts []state.ManagerOption
-	if runner.correlationIDProvided {
-		stateOpts = append(stateOpts, state.WithExecutionSubdirectory(runner.UniqueCorrelationID.String()))
+	if subdirectory := runner.executionSubdirectory(); subdirectory != "" {
+		stateOpts = append(stateOpts, state.WithExecutionSubdirectory(subdirectory))
 	}
 	switch {
 	case runner.StateManager != nil:
-		if runner.s3BackendConfig != nil {
-			log.Warn("Both WithStateManager and WithS3Backend were provided, ignoring the S3 backend")
+		if runner.remoteBackendCount() > 0 {
+			log.Warn("Both WithStateManager and a remote state backend were provided, ignoring the remote backend")
+		}
+		// Remote state managers, e.g. built with StateBackend.NewStateManager, also hold the Terraform state
+		if remoteState, ok := runner.StateManager.(interface{ BackendConfigs() map[string]string }); ok {
+			runner.terraformBackendConfigs = remoteState.BackendConfigs()
 		}
 	case runner.s3BackendConfig != nil:
+		runner.warnAboutIgnoredBackends("S3")
 		s3State := state.NewS3StateManager(technique, *runner.s3BackendConfig, stateOpts...)
 		runner.StateManager = s3State
 		runner.terraformBackendConfigs = s3State.BackendConfigs()
+	case runner.gcsBackendConfig != nil:
+		runner.warnAboutIgnoredBackends("GCS")
+		gcsState, err := state.NewGCSStateManager(technique, *runner.gcsBackendConfig, stateOpts...)
+		if err != nil {
+			log.Fatalf("error setting up the GCS state backend: %s", err)
+		}
+		runner.StateManager = gcsState
+		runner.terraformBackendConfigs = gcsState.BackendConfigs()
+	case runner.azureBlobBackendConfig != nil:
+		azureBlobState, err := state.NewAzureBlobStateManager(technique, *runner.azureBlobBackendConfig, stateOpts...)
+		if err != nil {
+			log.Fatalf("error setting up the Azure Blob Storage state backend: %s", err)
+		}
+		runner.StateManager = azureBlobState
+		runner.terraformBackendConfigs = azureBlobState.BackendConfigs()
 	default:
 		runner.StateManager = state.NewFileSystemStateManager(technique, stateOpts...)
 	}
@@ -165,6 +297,9 @@ func NewRunnerWithContext(ctx context.Context, technique *stratus.AttackTechniqu
 		if len(runner.terraformBackendConfigs) > 0 {
 			tfOpts = append(tfOpts, WithBackendConfigs(runner.terraformBackendConfigs))
 		}
+		if runner.target != nil {
+			tfOpts = append(tfOpts, WithEnvironment(runner.targetTerraformEnvironment))
+		}
 		runner.TerraformManager = NewTerraformManagerWithContext(
 			ctx,
 			terraformBinaryPath,
@@ -178,6 +313,49 @@ func NewRunnerWithContext(ctx context.Context, technique *stratus.AttackTechniqu
 	return runner
 }
 
+// remoteBackendCount returns how many remote state backends were configured.
+func (m *runnerImpl) remoteBackendCount() int {
+	count := 0
+	for _, configured := range []bool{m.s3BackendConfig != nil, m.gcsBackendConfig != nil, m.azureBlobBackendConfig != nil} {
+		if configured {
+			count++
+		}
+	}
+	return count
+}
+
+// warnAboutIgnoredBackends warns when several remote state backends were configured, since only
+// the first one, in the order S3, GCS, Azure Blob Storage, is used.
+func (m *runnerImpl) warnAboutIgnoredBackends(used string) {
+	if m.remoteBackendCount() > 1 {
+		log.Warnf("Several remote state backends were provided, only using the %s backend", used)
+	}
+}
+
+// executionSubdirectory returns the state subdirectory of the technique, isolating the executions with a
+// caller-supplied correlation ID and those in a target. It is empty to keep the flat layout.
+func (m *runnerImpl) executionSubdirectory() string {
+	correlationID := uuid.Nil
+	if m.correlationIDProvided {
+		correlationID = m.UniqueCorrelationID
+	}
+	return ExecutionSubdirectory(correlationID, m.target)
+}
+
+// ExecutionSubdirectory returns the state subdirectory of an execution, named after its correlation ID unless
+// it is uuid.Nil, and after its target unless it is nil, e.g. "<correlation ID>_<target name>". It is empty
+// for the flat layout.
+func ExecutionSubdirectory(correlationID uuid.UUID, target *config.Target) string {
+	var segments []string
+	if correlationID != uuid.Nil {
+		segments = append(segments, correlationID.String())
+	}
+	if target != nil {
+		segments = append(segments, target.Name)
+	}
+	return strings.Join(segments, "_")
+}
+
 // resolveCorrelationID returns the correlation ID from the environment variable
 // STRATUS_RED_TEAM_CORRELATION_ID (or the deprecated STRATUS_RED_TEAM_DETONATION_ID)
 // if set and valid, otherwise generates a new one.
@@ -216,18 +394,199 @@ func ExecutionSubdirectoryFromEnv() string {
 }
 
 func (m *runnerImpl) initialize() {
+	if m.Context == nil {
+		m.Context = context.Background()
+	}
 	m.TerraformDir = m.StateManager.GetWorkingDirectory()
 	m.TechniqueState = m.StateManager.GetTechniqueState()
 	if m.TechniqueState == "" {
 		m.TechniqueState = stratus.AttackTechniqueStatusCold
 	}
+	if m.recordDirectory != "" && (m.ProviderFactory != nil || m.target != nil) {
+		log.Warn("Cannot record the calls to cloud APIs of injected cloud providers or in a target, not recording")
+		m.recordDirectory = ""
+	}
 	// Only set default provider factory if not already injected
 	if m.ProviderFactory == nil {
 		m.ProviderFactory = stratus.CloudProvidersImpl{UniqueCorrelationID: m.UniqueCorrelationID}
 	}
+	if m.target != nil {
+		m.ProviderFactory = stratus.NewTargetCloudProviders(m.UniqueCorrelationID, *m.target, m.ProviderFactory)
+	}
+	if m.stateLockOwner == "" {
+		m.stateLockOwner = stratus.DefaultStateLockOwner()
+	}
+	if m.stateLockTTL <= 0 {
+		m.stateLockTTL = DefaultStateLockTTL
+	}
+	if m.resolveTargets == nil {
+		m.resolveTargets = stratus.ResolveTargets
+	}
+	if m.tracerProvider == nil {
+		m.tracerProvider = otel.GetTracerProvider()
+	}
+}
+
+// targetTerraformEnvironment points Terraform at the target, with the credentials of the assumed AWS role if any.
+// It is called before each Terraform command, so that the credentials are fresh.
+func (m *runnerImpl) targetTerraformEnvironment(ctx context.Context) (map[string]string, error) {
+	env := map[string]string{}
+	switch {
+	case m.target.AWS != nil:
+		if region := m.target.AWS.Region; region != "" {
+			env["AWS_REGION"] = region
+			env["AWS_DEFAULT_REGION"] = region
+		}
+		if m.target.AWS.RoleARN != "" {
+			credentials, err := m.ProviderFactory.AWS().GetConnection().Credentials.Retrieve(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to assume %s: %w", m.target.AWS.RoleARN, err)
+			}
+			env["AWS_ACCESS_KEY_ID"] = credentials.AccessKeyID
+			env["AWS_SECRET_ACCESS_KEY"] = credentials.SecretAccessKey
+			env["AWS_SESSION_TOKEN"] = credentials.SessionToken
+			// A profile could take precedence over the credentials of the role
+			env["AWS_PROFILE"] = ""
+		}
+	case m.target.GCP != nil:
+		env["GOOGLE_PROJECT"] = m.target.GCP.ProjectID
+	case m.target.Azure != nil:
+		env["AZURE_SUBSCRIPTION_ID"] = m.target.Azure.SubscriptionID
+		env["ARM_SUBSCRIPTION_ID"] = m.target.Azure.SubscriptionID
+	}
+	return env, nil
+}
+
+// ensureTargetIsAllowed refuses to run the technique with providerFactory outside the allowed targets of
+// the config, if set.
+func (m *runnerImpl) ensureTargetIsAllowed(providerFactory stratus.CloudProviders) error {
+	allowed := m.Config.GetAllowedTargets()
+	if allowed == nil {
+		return nil
+	}
+	targets, err := m.resolveTargets(m.Context, m.Technique.Platform, providerFactory)
+	if err != nil {
+		return fmt.Errorf("unable to check that %s runs in an allowed target: %w", m.Technique.ID, err)
+	}
+	if err := stratus.CheckAllowedTargets(targets, allowed); err != nil {
+		return fmt.Errorf("refusing to run %s: %w", m.Technique.ID, err)
+	}
+	return nil
+}
+
+// withStateLock runs operation while holding the state lock, so that no other command changes
+// the state of the technique in the meantime.
+func (m *runnerImpl) withStateLock(operation func() error) error {
+	if m.stateLockDepth > 0 {
+		return operation()
+	}
+
+	lock := stratus.NewStateLock(m.stateLockOwner, m.stateLockTTL)
+	if err := m.StateManager.AcquireLock(lock); err != nil {
+		return err
+	}
+	defer func() {
+		if err := m.StateManager.ReleaseLock(lock.ID); err != nil {
+			log.Warnf("unable to release the state lock of %s: %s", m.Technique.ID, err.Error())
+		}
+	}()
+	// The state may have changed between the creation of the runner and the acquisition of the lock
+	m.TechniqueState = m.StateManager.GetTechniqueState()
+	if m.TechniqueState == "" {
+		m.TechniqueState = stratus.AttackTechniqueStatusCold
+	}
+
+	m.stateLockDepth++
+	defer func() { m.stateLockDepth-- }()
+	return operation()
+}
+
+// traced runs operation under a span, which Terraform and the cloud providers record their own spans under.
+func (m *runnerImpl) traced(operation string, run func() error) error {
+	ctx, span := m.tracerProvider.Tracer(tracing.TracerName).Start(m.Context, "stratus."+operation, trace.WithAttributes(
+		tracing.AttributeTechniqueID.String(m.Technique.ID),
+		tracing.AttributeCorrelationID.String(m.UniqueCorrelationID.String()),
+		tracing.AttributePlatform.String(string(m.Technique.Platform)),
+	))
+	parentContext := m.Context
+	m.Context = ctx
+	err := run()
+	m.Context = parentContext
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// terraform returns the Terraform manager, tracing its commands under the span of the current operation
+// if it supports it.
+func (m *runnerImpl) terraform() TerraformManager {
+	if traceable, ok := m.TerraformManager.(interface {
+		withSpanOf(ctx context.Context) TerraformManager
+	}); ok {
+		return traceable.withSpanOf(m.Context)
+	}
+	return m.TerraformManager
+}
+
+// journaled runs operation under the state lock and a span, and records it in the execution journal.
+func (m *runnerImpl) journaled(operation stratus.JournalOperation, run func() error) error {
+	entry := stratus.JournalEntry{
+		TechniqueID:   m.Technique.ID,
+		Operation:     operation,
+		CorrelationID: m.UniqueCorrelationID.String(),
+		Caller:        m.stateLockOwner,
+		StartedAt:     time.Now().UTC(),
+		StateBefore:   m.TechniqueState,
+	}
+	// Cleaning up removes the variables, so also fingerprint them before the operation
+	variablesHashBefore := m.terraformVariablesHash()
+
+	err := m.traced(string(operation), func() error {
+		return m.withStateLock(func() error {
+			// Re-read once the lock is held
+			entry.StateBefore = m.TechniqueState
+			return run()
+		})
+	})
+
+	entry.FinishedAt = time.Now().UTC()
+	entry.Duration = entry.FinishedAt.Sub(entry.StartedAt).Seconds()
+	entry.StateAfter = m.TechniqueState
+	entry.TerraformVariablesHash = m.terraformVariablesHash()
+	if entry.TerraformVariablesHash == "" {
+		entry.TerraformVariablesHash = variablesHashBefore
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if journalErr := m.StateManager.AppendJournalEntry(entry); journalErr != nil {
+		log.Warnf("unable to record the %s of %s in the execution journal: %s", operation, m.Technique.ID, journalErr.Error())
+	}
+	return err
+}
+
+// terraformVariablesHash fingerprints the Terraform variables persisted for this execution, if any.
+func (m *runnerImpl) terraformVariablesHash() string {
+	variables, err := m.StateManager.GetTerraformVariables()
+	if err != nil {
+		return ""
+	}
+	return stratus.HashTerraformVariables(variables)
 }
 
 func (m *runnerImpl) WarmUp() (map[string]string, error) {
+	var outputs map[string]string
+	err := m.journaled(stratus.JournalOperationWarmUp, func() error {
+		if err := m.ensureTargetIsAllowed(m.ProviderFactory); err != nil {
+			return err
+		}
+		var err error
+		outputs, err = m.warmUp()
+		return err
+	})
+	return outputs, err
+}
+
+func (m *runnerImpl) warmUp() (map[string]string, error) {
 	// No prerequisites to spin-up
 	if m.Technique.PrerequisitesTerraformCode == nil {
 		return map[string]string{}, nil
@@ -253,6 +612,9 @@ func (m *runnerImpl) WarmUp() (map[string]string, error) {
 	}
 
 	if !willWarmUp {
+		if m.ttl > 0 {
+			m.recordLifetime(false)
+		}
 		outputs, err := m.StateManager.GetTerraformOutputs()
 		return outputs, err
 	}
@@ -263,14 +625,14 @@ func (m *runnerImpl) WarmUp() (map[string]string, error) {
 	if err := m.StateManager.WriteTerraformVariables(overrideVars); err != nil {
 		return nil, fmt.Errorf("unable to persist Terraform variables: %w", err)
 	}
-	outputs, err := m.TerraformManager.TerraformInitAndApply(m.TerraformDir, overrideVars)
+	outputs, err := m.terraform().TerraformInitAndApply(m.TerraformDir, overrideVars)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			// Resources may already exist, keep the state/vars for cleanup
 			return nil, err
 		}
 		log.Println("Error during warm up. Cleaning up technique prerequisites with terraform destroy")
-		_ = m.TerraformManager.TerraformDestroy(m.TerraformDir, overrideVars)
+		_ = m.terraform().TerraformDestroy(m.TerraformDir, overrideVars)
 		// Drop the technique directory and any managed artifacts so a failed
 		// warm-up does not leak a terraform.tfstate: on warmup failure TF sets
 		// the `resource` key of the tfstate file to an empty array but doesn't
@@ -283,6 +645,7 @@ func (m *runnerImpl) WarmUp() (map[string]string, error) {
 
 	// Resources are created, set state to warm
 	m.setState(stratus.AttackTechniqueStatusWarm)
+	m.recordLifetime(true)
 	if display, ok := outputs["display"]; ok {
 		display := strings.ReplaceAll(display, "\\n", "\n")
 		log.Println(display)
@@ -296,11 +659,74 @@ func (m *runnerImpl) WarmUp() (map[string]string, error) {
 	return outputs, nil
 }
 
+// Plan returns the prerequisite resources that WarmUp would create or change, without changing anything.
+func (m *runnerImpl) Plan() ([]stratus.PlannedResource, error) {
+	resources := []stratus.PlannedResource{}
+	// No prerequisites to spin-up
+	if m.Technique.PrerequisitesTerraformCode == nil {
+		return resources, nil
+	}
+
+	err := m.traced("plan", func() error {
+		return m.withStateLock(func() error {
+			return m.plan(&resources)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// plan mirrors the decision WarmUp would make, and plans the prerequisites if it would warm them up.
+func (m *runnerImpl) plan(resources *[]stratus.PlannedResource) error {
+	if m.TechniqueState == stratus.AttackTechniqueStatusWarm && !m.ShouldForce {
+		log.Println(m.Technique.ID + " is already warm, warming it up would not change anything. Use --force to plan anyway")
+		return nil
+	}
+	if m.TechniqueState == stratus.AttackTechniqueStatusDetonated {
+		log.Println(m.Technique.ID + " has been detonated but not cleaned up, warming it up would not change anything")
+		return nil
+	}
+
+	// Planning changes nothing, so the working directory of a COLD technique goes once planned. Unless
+	// an interrupted warm-up left its Terraform variables behind, which cleaning it up needs.
+	if m.TechniqueState == stratus.AttackTechniqueStatusCold {
+		if variables, err := m.StateManager.GetTerraformVariables(); err == nil && len(variables) == 0 {
+			defer func() {
+				if err := m.StateManager.CleanupTechnique(); err != nil {
+					log.Warnf("failed to remove the working directory of %s after planning: %s", m.Technique.ID, err.Error())
+				}
+			}()
+		}
+	}
+	if err := m.StateManager.ExtractTechnique(); err != nil {
+		return fmt.Errorf("unable to extract Terraform file: %w", err)
+	}
+	planned, err := m.terraform().TerraformPlan(m.TerraformDir, m.buildTerraformVariables())
+	if err != nil {
+		return fmt.Errorf("unable to run terraform plan on prerequisite: %s", errorMessageFromTerraformError(err))
+	}
+	*resources = planned
+	return nil
+}
+
 func (m *runnerImpl) Detonate() error {
+	return m.journaled(stratus.JournalOperationDetonate, m.detonate)
+}
+
+func (m *runnerImpl) detonate() error {
 	willWarmUp := true
-	var err error
 	var outputs map[string]string
 
+	if m.Technique.IsDestructive() && !m.destructiveImpactAcknowledged {
+		return errors.New(m.Technique.ID + " has a destructive impact, which may not be reverted. " +
+			"Confirm that you want to detonate it with --i-understand")
+	}
+	if err := m.ensureTargetIsAllowed(m.ProviderFactory); err != nil {
+		return err
+	}
+
 	// If the attack technique has already been detonated, make sure it's idempotent
 	if m.GetState() == stratus.AttackTechniqueStatusDetonated {
 		if !m.Technique.IsIdempotent && !m.ShouldForce {
@@ -310,12 +736,18 @@ func (m *runnerImpl) Detonate() error {
 		willWarmUp = false
 	}
 
+	// Don't warm up a technique that couldn't be detonated anyway
+	ctx, err := m.contextWithParameters()
+	if err != nil {
+		return err
+	}
+
 	if m.Technique.IsSlow {
 		log.Println("Note: This is a slow attack technique, it might take a long time to warm up or detonate")
 	}
 
 	if willWarmUp {
-		outputs, err = m.WarmUp()
+		outputs, err = m.warmUp()
 	} else {
 		outputs, err = m.StateManager.GetTerraformOutputs()
 	}
@@ -325,19 +757,42 @@ func (m *runnerImpl) Detonate() error {
 	}
 
 	// Detonate
-	err = m.Technique.Detonate(outputs, m.ProviderFactory)
+	detonate := m.Technique.GetDetonate()
+	if detonate == nil {
+		return errors.New(m.Technique.ID + " has no detonation function")
+	}
+	identity := m.resolveAttackerIdentity(outputs)
+	if identity != nil {
+		log.Println("Detonating " + m.Technique.ID + " as " + identity.String())
+		// The attacker may not live where the prerequisites were warmed up, e.g. behind a cross-account role
+		if err := m.ensureTargetIsAllowed(m.providersAs(m.ProviderFactory, identity)); err != nil {
+			return err
+		}
+	}
+	err = m.runTechniqueFunc(ctx, cassette.OperationDetonate, detonate, outputs, identity)
 	if err != nil {
-		return errors.New("Error while detonating attack technique " + m.Technique.ID + ": " + err.Error())
+		return fmt.Errorf("Error while detonating attack technique %s: %w", m.Technique.ID, err)
 	}
 	m.setState(stratus.AttackTechniqueStatusDetonated)
+	// Techniques without prerequisites are never warmed up, but their detonation may still need reverting
+	m.recordLifetime(false)
 	return nil
 }
 
 func (m *runnerImpl) Revert() error {
+	return m.journaled(stratus.JournalOperationRevert, m.revert)
+}
+
+func (m *runnerImpl) revert() error {
 	if m.GetState() != stratus.AttackTechniqueStatusDetonated && !m.ShouldForce {
 		return errors.New(m.Technique.ID + " is not in DETONATED state and should not need to be reverted, use --force to force")
 	}
 
+	ctx, err := m.contextWithParameters()
+	if err != nil {
+		return err
+	}
+
 	outputs, err := m.StateManager.GetTerraformOutputs()
 	if err != nil {
 		return fmt.Errorf("unable to retrieve outputs of %s: %w", m.Technique.ID, err)
@@ -345,8 +800,8 @@ func (m *runnerImpl) Revert() error {
 
 	log.Println("Reverting detonation of technique " + m.Technique.ID)
 
-	if m.Technique.Revert != nil {
-		err = m.Technique.Revert(outputs, m.ProviderFactory)
+	if revert := m.Technique.GetRevert(); revert != nil {
+		err = m.runTechniqueFunc(ctx, cassette.OperationRevert, revert, outputs, nil)
 		if err != nil {
 			return fmt.Errorf("unable to revert detonation of %s: %w", m.Technique.ID, err)
 		}
@@ -357,7 +812,53 @@ func (m *runnerImpl) Revert() error {
 	return nil
 }
 
+// runTechniqueFunc runs the detonation or reversion of the technique as identity, or with the default
+// credentials if nil, recording its calls to cloud APIs if enabled
+func (m *runnerImpl) runTechniqueFunc(ctx context.Context, operation string, run stratus.TechniqueFunc, outputs map[string]string, identity *stratus.Identity) error {
+	if m.recordDirectory == "" {
+		return run(ctx, outputs, m.providersAs(m.ProviderFactory, identity))
+	}
+
+	recording := cassette.StartRecording(m.UniqueCorrelationID, m.Technique.ID, operation, outputs, stratus.ParametersFromContext(ctx).Strings())
+	err := run(ctx, outputs, m.providersAs(recording.Providers(), identity))
+	path := cassette.Path(m.recordDirectory, m.Technique.ID, operation)
+	if saveErr := recording.Save(path, err); saveErr != nil {
+		log.Warnf("unable to save the cassette of the %s of %s: %s", operation, m.Technique.ID, saveErr.Error())
+	} else {
+		log.Println("Recorded the calls to cloud APIs in " + path)
+	}
+	return err
+}
+
+// resolveAttackerIdentity returns the principal to detonate the technique as: the one of the WithAttackerIdentity
+// option, of the config file, or the one the technique declares from its outputs. Returns nil to detonate it
+// with the default credentials.
+func (m *runnerImpl) resolveAttackerIdentity(outputs map[string]string) *stratus.Identity {
+	if m.attackerIdentity != nil {
+		return m.attackerIdentity
+	}
+	if identity := m.Config.GetAttackerIdentity(m.Technique.ID); identity != nil {
+		return identity
+	}
+	if m.Technique.AttackerIdentity != nil {
+		return m.Technique.AttackerIdentity(outputs)
+	}
+	return nil
+}
+
+// providersAs returns cloud providers authenticated as identity, built from admin, or admin itself if identity is nil
+func (m *runnerImpl) providersAs(admin stratus.CloudProviders, identity *stratus.Identity) stratus.CloudProviders {
+	if identity == nil {
+		return admin
+	}
+	return stratus.NewAttackerCloudProviders(m.Context, m.UniqueCorrelationID, *identity, admin)
+}
+
 func (m *runnerImpl) CleanUp() error {
+	return m.journaled(stratus.JournalOperationCleanUp, m.cleanUp)
+}
+
+func (m *runnerImpl) cleanUp() error {
 	// Has the technique already been cleaned up?
 	if m.TechniqueState == stratus.AttackTechniqueStatusCold && !m.ShouldForce {
 		return errors.New(m.Technique.ID + " is already COLD and should already be clean, use --force to force cleanup")
@@ -366,8 +867,8 @@ func (m *runnerImpl) CleanUp() error {
 	log.Println("Cleaning up " + m.Technique.ID)
 
 	// Revert detonation
-	if m.Technique.Revert != nil && m.GetState() == stratus.AttackTechniqueStatusDetonated {
-		err := m.Revert()
+	if m.Technique.IsRevertible() && m.GetState() == stratus.AttackTechniqueStatusDetonated {
+		err := m.revert()
 		if err != nil {
 			if m.ShouldForce {
 				log.Warnf("failed to revert detonation of %s. Ignoring and cleaning up anyway as --force was used.", m.Technique.ID)
@@ -392,7 +893,7 @@ func (m *runnerImpl) CleanUp() error {
 		}
 
 		log.Println("Cleaning up technique prerequisites with terraform destroy")
-		err = m.TerraformManager.TerraformDestroy(m.TerraformDir, persistedVars)
+		err = m.terraform().TerraformDestroy(m.TerraformDir, persistedVars)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return err
@@ -422,6 +923,28 @@ func (m *runnerImpl) setState(state stratus.AttackTechniqueState) {
 	m.TechniqueState = state
 }
 
+// recordLifetime records when the execution was warmed up and when it expires. Unless warmedUp is
+// set, the warmup time of a previous warmup is kept, and so is its expiry time if no TTL is given.
+func (m *runnerImpl) recordLifetime(warmedUp bool) {
+	now := time.Now()
+	lifetime := stratus.NewExecutionLifetime(now, m.ttl)
+	if !warmedUp {
+		previous, err := m.StateManager.GetExecutionLifetime()
+		if err != nil {
+			log.Warnf("unable to read the lifetime of %s: %s", m.Technique.ID, err.Error())
+		}
+		if previous != nil {
+			lifetime.WarmedUpAt = previous.WarmedUpAt
+			if m.ttl <= 0 {
+				lifetime.ExpiresAt = previous.ExpiresAt
+			}
+		}
+	}
+	if err := m.StateManager.WriteExecutionLifetime(lifetime); err != nil {
+		log.Warnf("unable to record the lifetime of %s: %s", m.Technique.ID, err.Error())
+	}
+}
+
 // GetUniqueExecutionId returns an unique execution ID, unique for each runner instance
 func (m *runnerImpl) GetUniqueExecutionId() string {
 	return m.UniqueCorrelationID.String()
@@ -438,6 +961,16 @@ func (m *runnerImpl) buildTerraformVariables() map[string]string {
 	return vars
 }
 
+// contextWithParameters resolves the parameters of the technique, from the config file and the
+// WithParameters option, and returns the context the technique functions are called with.
+func (m *runnerImpl) contextWithParameters() (context.Context, error) {
+	parameters, err := m.Technique.ResolveParameters(m.Config.GetTechniqueParameters(m.Technique.ID), m.parameters)
+	if err != nil {
+		return nil, err
+	}
+	return stratus.ContextWithParameters(m.Context, parameters), nil
+}
+
 // Utility function to display better error messages than the Terraform ones
 func errorMessageFromTerraformError(err error) string {
 	const MissingRegionErrorMessage = "The argument \"region\" is required, but no definition was found"
@@ -3,18 +3,32 @@ package runner
 import (
 	"context"
 	"errors"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/datadog/stratus-red-team/v2/internal/state"
 	statemocks "github.com/datadog/stratus-red-team/v2/internal/state/mocks"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/cassette"
+	stratusconfig "github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
 	configmocks "github.com/datadog/stratus-red-team/v2/pkg/stratus/config/mocks"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner/mocks"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/stratustest"
 	"github.com/google/uuid"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var testCorrelationID = uuid.MustParse("11111111-2222-3333-4444-555555555555")
@@ -26,6 +40,15 @@ func varsHaveCorrelation(id uuid.UUID) any {
 	})
 }
 
+// newConfigMock returns a config that does not set any technique parameter.
+func newConfigMock() *configmocks.Config {
+	config := new(configmocks.Config)
+	config.On("GetTechniqueParameters", mock.Anything).Return(map[string]string(nil)).Maybe()
+	config.On("GetAllowedTargets").Return((*stratusconfig.AllowedTargets)(nil)).Maybe()
+	config.On("GetAttackerIdentity", mock.Anything).Return((*stratusconfig.AttackerIdentity)(nil)).Maybe()
+	return config
+}
+
 func TestRunnerWarmUp(t *testing.T) {
 
 	type RunnerWarmupTestScenario struct {
@@ -125,12 +148,18 @@ func TestRunnerWarmUp(t *testing.T) {
 	}
 
 	for i := range scenario {
-		config := new(configmocks.Config)
+		config := newConfigMock()
 		state := new(statemocks.StateManager)
 		terraform := new(mocks.TerraformManager)
 
 		config.On("GetTerraformVariables", mock.Anything, mock.Anything).Return(map[string]string{})
 		state.On("GetWorkingDirectory").Return("/root/foo")
+		state.On("AcquireLock", mock.Anything).Return(nil)
+		state.On("AppendJournalEntry", mock.Anything).Return(nil)
+		state.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		state.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		state.On("GetTerraformVariables").Return(map[string]string{}, nil)
+		state.On("ReleaseLock", mock.Anything).Return(nil)
 		state.On("ExtractTechnique").Return(nil)
 		state.On("GetTechniqueState", mock.Anything).Return(scenario[i].InitialTechniqueState, nil)
 		state.On("GetTerraformOutputs").Return(scenario[i].PersistedOutputs, nil)
@@ -155,6 +184,155 @@ func TestRunnerWarmUp(t *testing.T) {
 	}
 }
 
+func TestRunnerPlan(t *testing.T) {
+	planned := []stratus.PlannedResource{
+		{Address: "aws_cloudtrail.trail", Type: "aws_cloudtrail", ProviderName: "registry.terraform.io/hashicorp/aws", Action: stratus.PlannedResourceActionCreate},
+	}
+
+	type RunnerPlanTestScenario struct {
+		Name                  string
+		Technique             *stratus.AttackTechnique
+		ShouldForce           bool
+		InitialTechniqueState stratus.AttackTechniqueState
+		TerraformVariables    map[string]string
+		Error                 error
+		// results
+		CheckExpectations func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error)
+	}
+
+	var scenario = []RunnerPlanTestScenario{
+		{
+			Name:                  "Planning a technique without prerequisite Terraform code",
+			Technique:             &stratus.AttackTechnique{ID: "foo"},
+			InitialTechniqueState: stratus.AttackTechniqueStatusCold,
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				terraform.AssertNotCalled(t, "TerraformPlan", mock.Anything, mock.Anything)
+				state.AssertNotCalled(t, "AcquireLock", mock.Anything)
+				assert.Nil(t, err)
+				assert.Empty(t, resources)
+			},
+		},
+		{
+			Name:                  "Planning a COLD technique",
+			Technique:             &stratus.AttackTechnique{ID: "foo", PrerequisitesTerraformCode: []byte("foo")},
+			InitialTechniqueState: stratus.AttackTechniqueStatusCold,
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				state.AssertCalled(t, "ExtractTechnique")
+				terraform.AssertCalled(t, "TerraformPlan", "/root/foo", varsHaveCorrelation(testCorrelationID))
+				terraform.AssertNotCalled(t, "TerraformInitAndApply", mock.Anything, mock.Anything)
+				state.AssertNotCalled(t, "WriteTerraformVariables", mock.Anything)
+				state.AssertNotCalled(t, "SetTechniqueState", mock.Anything)
+				state.AssertNotCalled(t, "AppendJournalEntry", mock.Anything)
+				state.AssertCalled(t, "CleanupTechnique")
+				assert.Nil(t, err)
+				assert.Equal(t, planned, resources)
+			},
+		},
+		{
+			Name:                  "Planning a COLD technique whose warm-up was interrupted",
+			Technique:             &stratus.AttackTechnique{ID: "foo", PrerequisitesTerraformCode: []byte("foo")},
+			InitialTechniqueState: stratus.AttackTechniqueStatusCold,
+			TerraformVariables:    map[string]string{"correlation": "{}"},
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				terraform.AssertCalled(t, "TerraformPlan", "/root/foo", mock.Anything)
+				state.AssertNotCalled(t, "CleanupTechnique")
+				assert.Nil(t, err)
+			},
+		},
+		{
+			Name:                  "Planning a WARM technique without force flag",
+			Technique:             &stratus.AttackTechnique{ID: "foo", PrerequisitesTerraformCode: []byte("foo")},
+			InitialTechniqueState: stratus.AttackTechniqueStatusWarm,
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				terraform.AssertNotCalled(t, "TerraformPlan", mock.Anything, mock.Anything)
+				assert.Nil(t, err)
+				assert.Empty(t, resources)
+			},
+		},
+		{
+			Name:                  "Planning a WARM technique with force flag",
+			Technique:             &stratus.AttackTechnique{ID: "foo", PrerequisitesTerraformCode: []byte("foo")},
+			ShouldForce:           true,
+			InitialTechniqueState: stratus.AttackTechniqueStatusWarm,
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				terraform.AssertCalled(t, "TerraformPlan", "/root/foo", varsHaveCorrelation(testCorrelationID))
+				state.AssertNotCalled(t, "CleanupTechnique")
+				assert.Nil(t, err)
+				assert.Equal(t, planned, resources)
+			},
+		},
+		{
+			Name:                  "Planning a DETONATED technique",
+			Technique:             &stratus.AttackTechnique{ID: "foo", PrerequisitesTerraformCode: []byte("foo")},
+			ShouldForce:           true,
+			InitialTechniqueState: stratus.AttackTechniqueStatusDetonated,
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				terraform.AssertNotCalled(t, "TerraformPlan", mock.Anything, mock.Anything)
+				assert.Nil(t, err)
+				assert.Empty(t, resources)
+			},
+		},
+		{
+			Name:                  "Planning a technique whose plan fails",
+			Technique:             &stratus.AttackTechnique{ID: "foo", PrerequisitesTerraformCode: []byte("foo")},
+			InitialTechniqueState: stratus.AttackTechniqueStatusCold,
+			Error:                 errors.New("invalid credentials"),
+			CheckExpectations: func(t *testing.T, terraform *mocks.TerraformManager, state *statemocks.StateManager, resources []stratus.PlannedResource, err error) {
+				state.AssertCalled(t, "CleanupTechnique")
+				assert.ErrorContains(t, err, "invalid credentials")
+				assert.Nil(t, resources)
+			},
+		},
+	}
+
+	for i := range scenario {
+		config := newConfigMock()
+		state := new(statemocks.StateManager)
+		terraform := new(mocks.TerraformManager)
+
+		config.On("GetTerraformVariables", mock.Anything, mock.Anything).Return(map[string]string{})
+		state.On("GetWorkingDirectory").Return("/root/foo")
+		state.On("AcquireLock", mock.Anything).Return(nil)
+		state.On("ReleaseLock", mock.Anything).Return(nil)
+		state.On("ExtractTechnique").Return(nil)
+		state.On("CleanupTechnique").Return(nil)
+		state.On("GetTerraformVariables").Return(scenario[i].TerraformVariables, nil)
+		state.On("GetTechniqueState", mock.Anything).Return(scenario[i].InitialTechniqueState, nil)
+		var resources []stratus.PlannedResource
+		if scenario[i].Error == nil {
+			resources = planned
+		}
+		terraform.On("TerraformPlan", mock.Anything, mock.Anything).Return(resources, scenario[i].Error)
+
+		runner := runnerImpl{
+			Technique:           scenario[i].Technique,
+			ShouldForce:         scenario[i].ShouldForce,
+			Config:              config,
+			TerraformManager:    terraform,
+			StateManager:        state,
+			UniqueCorrelationID: testCorrelationID,
+		}
+		runner.initialize()
+		result, err := runner.Plan()
+		t.Run(scenario[i].Name, func(t *testing.T) { scenario[i].CheckExpectations(t, terraform, state, result, err) })
+	}
+}
+
+func TestPlannedResources(t *testing.T) {
+	plan := &tfjson.Plan{ResourceChanges: []*tfjson.ResourceChange{
+		{Address: "aws_s3_bucket.bucket", Type: "aws_s3_bucket", Mode: tfjson.ManagedResourceMode, ProviderName: "aws", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}}},
+		{Address: "aws_iam_role.role", Type: "aws_iam_role", Mode: tfjson.ManagedResourceMode, ProviderName: "aws", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate}}},
+		{Address: "aws_iam_policy.policy", Type: "aws_iam_policy", Mode: tfjson.ManagedResourceMode, ProviderName: "aws", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}}},
+		{Address: "data.aws_caller_identity.current", Type: "aws_caller_identity", Mode: tfjson.DataResourceMode, ProviderName: "aws", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionRead}}},
+	}}
+
+	resources := plannedResources(plan)
+
+	require.Len(t, resources, 2, "no-ops and data sources should be left out")
+	assert.Equal(t, stratus.PlannedResource{Address: "aws_s3_bucket.bucket", Type: "aws_s3_bucket", ProviderName: "aws", Action: stratus.PlannedResourceActionCreate}, resources[0])
+	assert.Equal(t, stratus.PlannedResourceActionReplace, resources[1].Action)
+}
+
 func TestRunnerDetonate(t *testing.T) {
 
 	type TestDetonationScenario struct {
@@ -223,12 +401,18 @@ func TestRunnerDetonate(t *testing.T) {
 
 	for i := range scenario {
 		t.Run(scenario[i].Name, func(t *testing.T) {
-			config := new(configmocks.Config)
+			config := newConfigMock()
 			state := new(statemocks.StateManager)
 			terraform := new(mocks.TerraformManager)
 
 			config.On("GetTerraformVariables", mock.Anything, mock.Anything).Return(map[string]string{})
 			state.On("GetWorkingDirectory").Return("/root/sample-technique")
+			state.On("AcquireLock", mock.Anything).Return(nil)
+			state.On("AppendJournalEntry", mock.Anything).Return(nil)
+			state.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+			state.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+			state.On("GetTerraformVariables").Return(map[string]string{}, nil)
+			state.On("ReleaseLock", mock.Anything).Return(nil)
 			state.On("ExtractTechnique").Return(nil)
 			state.On("GetTechniqueState", mock.Anything).Return(scenario[i].TechniqueState, nil)
 			terraform.On("TerraformInitAndApply", mock.Anything, mock.Anything).Return(map[string]string{}, nil)
@@ -323,6 +507,12 @@ func TestRunnerRevert(t *testing.T) {
 		t.Run(scenario[i].Name, func(t *testing.T) {
 			state := new(statemocks.StateManager)
 			state.On("GetWorkingDirectory").Return("/root/foo")
+			state.On("AcquireLock", mock.Anything).Return(nil)
+			state.On("AppendJournalEntry", mock.Anything).Return(nil)
+			state.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+			state.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+			state.On("GetTerraformVariables").Return(map[string]string{}, nil)
+			state.On("ReleaseLock", mock.Anything).Return(nil)
 			state.On("ExtractTechnique").Return(nil)
 			state.On("GetTerraformOutputs").Return(map[string]string{"foo": "bar"}, nil)
 			state.On("GetTechniqueState", mock.Anything).Return(scenario[i].TechniqueState)
@@ -340,6 +530,7 @@ func TestRunnerRevert(t *testing.T) {
 				},
 				ShouldForce:  scenario[i].Force,
 				StateManager: state,
+				Config:       newConfigMock(),
 			}
 			runner.initialize()
 
@@ -459,6 +650,12 @@ func TestRunnerCleanup(t *testing.T) {
 
 		state.On("GetTerraformVariables").Return(map[string]string{}, nil)
 		state.On("GetWorkingDirectory").Return("/root/foo")
+		state.On("AcquireLock", mock.Anything).Return(nil)
+		state.On("AppendJournalEntry", mock.Anything).Return(nil)
+		state.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		state.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		state.On("GetTerraformVariables").Return(map[string]string{}, nil)
+		state.On("ReleaseLock", mock.Anything).Return(nil)
 		state.On("ExtractTechnique").Return(nil)
 		state.On("GetTechniqueState", mock.Anything).Return(scenario[i].InitialTechniqueState, nil)
 		state.On("SetTechniqueState", mock.Anything).Return(nil)
@@ -479,6 +676,7 @@ func TestRunnerCleanup(t *testing.T) {
 			ShouldForce:      scenario[i].ShouldForce,
 			TerraformManager: terraform,
 			StateManager:     state,
+			Config:           newConfigMock(),
 		}
 		runner.initialize()
 		err := runner.CleanUp()
@@ -491,10 +689,18 @@ func TestRunnerCleanup(t *testing.T) {
 func TestNewRunnerWithOptions(t *testing.T) {
 	stateMock := new(statemocks.StateManager)
 	tfMock := new(mocks.TerraformManager)
-	configMock := new(configmocks.Config)
+	configMock := newConfigMock()
 	correlationID := uuid.MustParse("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
 
 	stateMock.On("GetWorkingDirectory").Return("/custom/root/test.technique")
+
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
 	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
 
 	technique := &stratus.AttackTechnique{ID: "test.technique"}
@@ -522,9 +728,17 @@ func TestNewRunnerWithOptions(t *testing.T) {
 func TestNewRunnerWithProviderFactory(t *testing.T) {
 	stateMock := new(statemocks.StateManager)
 	tfMock := new(mocks.TerraformManager)
-	configMock := new(configmocks.Config)
+	configMock := newConfigMock()
 
 	stateMock.On("GetWorkingDirectory").Return("/root/test.provider-injection")
+
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
 	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
 
 	customProviders := stratus.CloudProvidersImpl{
@@ -573,10 +787,18 @@ func TestNewRunnerWithProviderFactory(t *testing.T) {
 func TestProviderCredentialInjection(t *testing.T) {
 	stateMock := new(statemocks.StateManager)
 	tfMock := new(mocks.TerraformManager)
-	configMock := new(configmocks.Config)
+	configMock := newConfigMock()
 	correlationID := uuid.New()
 
 	stateMock.On("GetWorkingDirectory").Return("/root/test.credential-injection")
+
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
 	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
 	stateMock.On("ExtractTechnique").Return(nil)
 	stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
@@ -636,6 +858,400 @@ func TestProviderCredentialInjection(t *testing.T) {
 	assert.Equal(t, "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", creds.SecretAccessKey)
 }
 
+// TestRunnerPassesContextToTechnique verifies that the context given to NewRunnerWithContext reaches
+// context-aware detonation and reversion functions, and that they win over the legacy ones.
+func TestRunnerPassesContextToTechnique(t *testing.T) {
+	type contextKey struct{}
+	stateMock := new(statemocks.StateManager)
+	stateMock.On("GetWorkingDirectory").Return("/root/test.context")
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+	techniqueState := stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold)
+	stateMock.On("GetTechniqueState").Return(func() stratus.AttackTechniqueState { return techniqueState })
+	stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
+	stateMock.On("SetTechniqueState", mock.Anything).Run(func(args mock.Arguments) {
+		techniqueState = args.Get(0).(stratus.AttackTechniqueState)
+	}).Return(nil)
+
+	var detonateValue, revertValue any
+	technique := &stratus.AttackTechnique{
+		ID: "test.context",
+		Detonate: func(map[string]string, stratus.CloudProviders) error {
+			t.Error("legacy Detonate should not be called when DetonateWithContext is set")
+			return nil
+		},
+		DetonateWithContext: func(ctx context.Context, params map[string]string, pf stratus.CloudProviders) error {
+			detonateValue = ctx.Value(contextKey{})
+			return nil
+		},
+		RevertWithContext: func(ctx context.Context, params map[string]string, pf stratus.CloudProviders) error {
+			revertValue = ctx.Value(contextKey{})
+			return nil
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "expected")
+	r := NewRunnerWithContext(ctx, technique, false,
+		WithStateManager(stateMock),
+		WithTerraformManager(new(mocks.TerraformManager)),
+		WithConfig(newConfigMock()),
+	)
+
+	assert.Nil(t, r.Detonate())
+	assert.Nil(t, r.Revert())
+	assert.Equal(t, "expected", detonateValue)
+	assert.Equal(t, "expected", revertValue)
+}
+
+func TestRunnerPassesParametersToTechnique(t *testing.T) {
+	newTechnique := func(detonated *stratus.Parameters) *stratus.AttackTechnique {
+		return &stratus.AttackTechnique{
+			ID: "test.parameters",
+			Parameters: []stratus.TechniqueParameter{
+				{Name: "count", Type: stratus.ParameterTypeInteger, Default: "10"},
+				{Name: "name", Type: stratus.ParameterTypeString, Default: "default"},
+				{Name: "verbose", Type: stratus.ParameterTypeBoolean, Default: "false"},
+			},
+			DetonateWithContext: func(ctx context.Context, params map[string]string, pf stratus.CloudProviders) error {
+				*detonated = stratus.ParametersFromContext(ctx)
+				return nil
+			},
+		}
+	}
+	newStateMock := func() *statemocks.StateManager {
+		stateMock := new(statemocks.StateManager)
+		stateMock.On("GetWorkingDirectory").Return("/root/test.parameters")
+		stateMock.On("AcquireLock", mock.Anything).Return(nil)
+		stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+		stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+		stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+		stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+		stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+		return stateMock
+	}
+
+	t.Run("the flags take precedence over the config file", func(t *testing.T) {
+		configMock := new(configmocks.Config)
+		configMock.On("GetTechniqueParameters", "test.parameters").Return(map[string]string{"count": "5", "name": "from-config"})
+		configMock.On("GetAllowedTargets").Return((*stratusconfig.AllowedTargets)(nil))
+		configMock.On("GetAttackerIdentity", "test.parameters").Return((*stratusconfig.AttackerIdentity)(nil))
+		var detonated stratus.Parameters
+
+		r := NewRunner(newTechnique(&detonated), false,
+			WithStateManager(newStateMock()),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(configMock),
+			WithParameters(map[string]string{"count": "3"}),
+		)
+
+		require.Nil(t, r.Detonate())
+		assert.Equal(t, 3, detonated.GetInt("count"))
+		assert.Equal(t, "from-config", detonated.GetString("name"))
+		assert.False(t, detonated.GetBool("verbose"))
+	})
+
+	t.Run("invalid parameters fail before warming up", func(t *testing.T) {
+		stateMock := newStateMock()
+		var detonated stratus.Parameters
+		technique := newTechnique(&detonated)
+		technique.PrerequisitesTerraformCode = []byte("foo")
+
+		r := NewRunner(technique, false,
+			WithStateManager(stateMock),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+			WithParameters(map[string]string{"count": "ten", "nope": "1"}),
+		)
+		err := r.Detonate()
+
+		assert.ErrorContains(t, err, `"ten" is not an integer`)
+		assert.ErrorContains(t, err, `test.parameters has no parameter "nope"`)
+		stateMock.AssertNotCalled(t, "ExtractTechnique")
+		stateMock.AssertNotCalled(t, "SetTechniqueState", mock.Anything)
+	})
+}
+
+func TestRunnerRecordsCassettes(t *testing.T) {
+	newStateMock := func() *statemocks.StateManager {
+		stateMock := new(statemocks.StateManager)
+		stateMock.On("GetWorkingDirectory").Return("/root/test.recording")
+		stateMock.On("AcquireLock", mock.Anything).Return(nil)
+		stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+		stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+		stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+		stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusDetonated))
+		stateMock.On("GetTerraformOutputs").Return(map[string]string{"role_arn": "arn:aws:iam::210987654321:role/foo"}, nil)
+		stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+		return stateMock
+	}
+	technique := &stratus.AttackTechnique{
+		ID:           "test.recording",
+		IsIdempotent: true,
+		Parameters:   []stratus.TechniqueParameter{{Name: "name", Type: stratus.ParameterTypeString, Default: "foo"}},
+		DetonateWithContext: func(context.Context, map[string]string, stratus.CloudProviders) error {
+			return nil
+		},
+		RevertWithContext: func(context.Context, map[string]string, stratus.CloudProviders) error {
+			return errors.New("access denied")
+		},
+	}
+
+	t.Run("records the detonation and reversion", func(t *testing.T) {
+		directory := t.TempDir()
+		r := NewRunner(technique, false,
+			WithStateManager(newStateMock()),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+			WithRecording(directory),
+		)
+
+		require.NoError(t, r.Detonate())
+		require.Error(t, r.Revert())
+
+		detonation, err := cassette.Load(cassette.Path(directory, technique.ID, cassette.OperationDetonate))
+		require.NoError(t, err)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/foo", detonation.Outputs["role_arn"])
+		assert.Equal(t, map[string]string{"name": "foo"}, detonation.Parameters)
+		assert.Empty(t, detonation.Error)
+		reversion, err := cassette.Load(cassette.Path(directory, technique.ID, cassette.OperationRevert))
+		require.NoError(t, err)
+		assert.Equal(t, "access denied", reversion.Error)
+	})
+
+	t.Run("does not record injected cloud providers", func(t *testing.T) {
+		directory := t.TempDir()
+		t.Setenv(cassette.EnvVarRecord, directory)
+		r := NewRunner(technique, false,
+			WithStateManager(newStateMock()),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+			WithProviderFactory(stratus.CloudProvidersImpl{}),
+		)
+
+		require.NoError(t, r.Detonate())
+		assert.NoFileExists(t, cassette.Path(directory, technique.ID, cassette.OperationDetonate))
+	})
+}
+
+func TestRunnerDetonatesAsAttackerIdentity(t *testing.T) {
+	newStateMock := func() *statemocks.StateManager {
+		stateMock := new(statemocks.StateManager)
+		stateMock.On("GetWorkingDirectory").Return("/root/test.attacker")
+		stateMock.On("AcquireLock", mock.Anything).Return(nil)
+		stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+		stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+		stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+		stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusDetonated))
+		stateMock.On("GetTerraformOutputs").Return(map[string]string{"role_arn": "arn:aws:iam::123456789012:role/from-warmup"}, nil)
+		stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+		return stateMock
+	}
+	stopLogging := func(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
+		_, err := cloudtrail.NewFromConfig(providers.AWS().GetConnection()).StopLogging(ctx, &cloudtrail.StopLoggingInput{Name: aws.String("trail")})
+		return err
+	}
+	technique := &stratus.AttackTechnique{
+		ID:                  "test.attacker",
+		IsIdempotent:        true,
+		DetonateWithContext: stopLogging,
+		RevertWithContext:   stopLogging,
+		AttackerIdentity: func(outputs map[string]string) *stratus.Identity {
+			return &stratus.Identity{AWS: &stratus.AWSIdentity{RoleARNs: []string{outputs["role_arn"]}}}
+		},
+	}
+	newFakeProviders := func(t *testing.T) *stratustest.CloudProviders {
+		providers := stratustest.New(t)
+		providers.Server.Respond("sts:AssumeRole", stratustest.Response{
+			StatusCode: http.StatusOK,
+			Header:     map[string]string{"Content-Type": "text/xml"},
+			Body: `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials>` +
+				`<AccessKeyId>ASIAATTACKER</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+				`<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`,
+		})
+		return providers
+	}
+	accessKeyOf := func(call stratustest.Call) string {
+		credential := strings.SplitN(call.Header.Get("Authorization"), "Credential=", 2)
+		require.Len(t, credential, 2)
+		return strings.Split(credential[1], "/")[0]
+	}
+
+	t.Run("detonates as the identity of the technique and reverts as the admin", func(t *testing.T) {
+		providers := newFakeProviders(t)
+		r := NewRunner(technique, false,
+			WithStateManager(newStateMock()),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+			WithProviderFactory(providers),
+			WithCorrelationID(testCorrelationID),
+		)
+
+		require.NoError(t, r.Detonate())
+		require.NoError(t, r.Revert())
+
+		providers.Server.AssertCalled(t, "sts:AssumeRole",
+			stratustest.WithParam("RoleArn", "arn:aws:iam::123456789012:role/from-warmup"),
+			stratustest.WithParam("RoleSessionName", "stratus-red-team-11111111"),
+		)
+		calls := providers.Server.CallsTo("cloudtrail:StopLogging")
+		require.Len(t, calls, 2)
+		assert.Equal(t, "ASIAATTACKER", accessKeyOf(calls[0]))
+		assert.Equal(t, "AKIASTRATUSREDTEAM", accessKeyOf(calls[1]))
+	})
+
+	t.Run("the config file takes precedence over the technique", func(t *testing.T) {
+		providers := newFakeProviders(t)
+		configMock := new(configmocks.Config)
+		configMock.On("GetTechniqueParameters", mock.Anything).Return(map[string]string(nil))
+		configMock.On("GetAllowedTargets").Return((*stratusconfig.AllowedTargets)(nil))
+		configMock.On("GetAttackerIdentity", "test.attacker").Return(&stratusconfig.AttackerIdentity{
+			AWS: &stratusconfig.AWSAttackerIdentity{RoleARNs: []string{"arn:aws:iam::123456789012:role/from-config"}, ExternalID: "external"},
+		})
+		r := NewRunner(technique, false,
+			WithStateManager(newStateMock()),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(configMock),
+			WithProviderFactory(providers),
+		)
+
+		require.NoError(t, r.Detonate())
+		providers.Server.AssertCalled(t, "sts:AssumeRole",
+			stratustest.WithParam("RoleArn", "arn:aws:iam::123456789012:role/from-config"),
+			stratustest.WithParam("ExternalId", "external"),
+		)
+		providers.Server.AssertNotCalled(t, "sts:AssumeRole", stratustest.WithParam("RoleArn", "arn:aws:iam::123456789012:role/from-warmup"))
+	})
+
+	t.Run("WithAttackerIdentity takes precedence over the technique", func(t *testing.T) {
+		providers := newFakeProviders(t)
+		r := NewRunner(technique, false,
+			WithStateManager(newStateMock()),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+			WithProviderFactory(providers),
+			WithAttackerIdentity(stratus.Identity{AWS: &stratus.AWSIdentity{RoleARNs: []string{
+				"arn:aws:iam::123456789012:role/pivot", "arn:aws:iam::123456789012:role/from-option",
+			}}}),
+		)
+
+		require.NoError(t, r.Detonate())
+		assumedRoles := providers.Server.CallsTo("sts:AssumeRole")
+		require.Len(t, assumedRoles, 2)
+		pivot, _ := assumedRoles[0].Param("RoleArn")
+		assert.Equal(t, "arn:aws:iam::123456789012:role/pivot", pivot)
+		assert.Equal(t, "AKIASTRATUSREDTEAM", accessKeyOf(assumedRoles[0]))
+		attacker, _ := assumedRoles[1].Param("RoleArn")
+		assert.Equal(t, "arn:aws:iam::123456789012:role/from-option", attacker)
+		assert.Equal(t, "ASIAATTACKER", accessKeyOf(assumedRoles[1]))
+	})
+}
+
+func TestRunnerRunsInTarget(t *testing.T) {
+	stateMock := new(statemocks.StateManager)
+	stateMock.On("GetWorkingDirectory").Return("/root/test.target/production")
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusWarm))
+	stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
+	stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+	providers := stratustest.New(t)
+	providers.Server.Respond("sts:AssumeRole", stratustest.Response{
+		StatusCode: http.StatusOK,
+		Header:     map[string]string{"Content-Type": "text/xml"},
+		Body: `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials>` +
+			`<AccessKeyId>ASIATARGET</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+			`<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`,
+	})
+	technique := &stratus.AttackTechnique{
+		ID:           "test.target",
+		IsIdempotent: true,
+		DetonateWithContext: func(ctx context.Context, _ map[string]string, providers stratus.CloudProviders) error {
+			_, err := cloudtrail.NewFromConfig(providers.AWS().GetConnection()).StopLogging(ctx, &cloudtrail.StopLoggingInput{Name: aws.String("trail")})
+			return err
+		},
+	}
+	r := NewRunner(technique, false,
+		WithStateManager(stateMock),
+		WithTerraformManager(new(mocks.TerraformManager)),
+		WithConfig(newConfigMock()),
+		WithProviderFactory(providers),
+		WithTarget(stratusconfig.Target{
+			Name: "production",
+			AWS:  &stratusconfig.AWSTarget{RoleARN: "arn:aws:iam::123456789012:role/stratus", Region: "eu-west-3"},
+		}),
+	).(*runnerImpl)
+
+	require.NoError(t, r.Detonate())
+	providers.Server.AssertCalled(t, "sts:AssumeRole", stratustest.WithParam("RoleArn", "arn:aws:iam::123456789012:role/stratus"))
+	calls := providers.Server.CallsTo("cloudtrail:StopLogging")
+	require.Len(t, calls, 1)
+	assert.Contains(t, calls[0].Header.Get("Authorization"), "Credential=ASIATARGET/")
+	assert.Contains(t, calls[0].Header.Get("Authorization"), "/eu-west-3/cloudtrail/")
+
+	env, err := r.targetTerraformEnvironment(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"AWS_REGION":            "eu-west-3",
+		"AWS_DEFAULT_REGION":    "eu-west-3",
+		"AWS_ACCESS_KEY_ID":     "ASIATARGET",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+		"AWS_SESSION_TOKEN":     "token",
+		"AWS_PROFILE":           "",
+	}, env)
+}
+
+// TestRunnerCancelledContextStopsLegacyDetonation verifies that a technique using the legacy signature
+// is not started once the runner's context is cancelled.
+func TestRunnerCancelledContextStopsLegacyDetonation(t *testing.T) {
+	stateMock := new(statemocks.StateManager)
+	stateMock.On("GetWorkingDirectory").Return("/root/test.cancelled")
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+	stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
+	stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+
+	wasDetonated := false
+	technique := &stratus.AttackTechnique{
+		ID: "test.cancelled",
+		Detonate: func(map[string]string, stratus.CloudProviders) error {
+			wasDetonated = true
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := NewRunnerWithContext(ctx, technique, false,
+		WithStateManager(stateMock),
+		WithTerraformManager(new(mocks.TerraformManager)),
+		WithConfig(newConfigMock()),
+	)
+
+	err := r.Detonate()
+	assert.NotNil(t, err)
+	assert.False(t, wasDetonated)
+	stateMock.AssertNotCalled(t, "SetTechniqueState", mock.Anything)
+}
+
 // Whether the correlation ID was *provided* decides the state layout, so it must be
 // distinguishable from a generated one. A generated ID differs on every command and could
 // never find the state written by a previous step.
@@ -695,6 +1311,13 @@ func TestRunnerStateLayoutFollowsCorrelationID(t *testing.T) {
 		{Name: "WithCorrelationID nests", Option: []RunnerOption{WithCorrelationID(uuid.MustParse(providedID))}, ExpectNestedDir: providedID},
 		{Name: "WithCorrelationID(Nil) falls back to a generated id", Option: []RunnerOption{WithCorrelationID(uuid.Nil)}},
 		{Name: "an invalid correlation id does not isolate", CorrelationIDEnv: "not-a-uuid"},
+		{Name: "a target nests", Option: []RunnerOption{WithTarget(stratusconfig.Target{Name: "production", GCP: &stratusconfig.GCPTarget{ProjectID: "production"}})}, ExpectNestedDir: "production"},
+		{
+			Name:             "a target nests under the correlation id",
+			CorrelationIDEnv: providedID,
+			Option:           []RunnerOption{WithTarget(stratusconfig.Target{Name: "production", GCP: &stratusconfig.GCPTarget{ProjectID: "production"}})},
+			ExpectNestedDir:  providedID + "_production",
+		},
 	}
 
 	for i := range scenario {
@@ -704,7 +1327,7 @@ func TestRunnerStateLayoutFollowsCorrelationID(t *testing.T) {
 			t.Setenv(EnvVarStratusRedTeamCorrelationId, scenario[i].CorrelationIDEnv)
 			t.Setenv(EnvVarStratusRedTeamDetonationId, scenario[i].DetonationIDEnv)
 
-			opts := append([]RunnerOption{WithTerraformManager(new(mocks.TerraformManager)), WithConfig(new(configmocks.Config))}, scenario[i].Option...)
+			opts := append([]RunnerOption{WithTerraformManager(new(mocks.TerraformManager)), WithConfig(newConfigMock())}, scenario[i].Option...)
 			r := NewRunner(technique, StratusRunnerNoForce, opts...).(*runnerImpl)
 
 			expected := filepath.Join(home, ".stratus-red-team", technique.ID)
@@ -725,9 +1348,393 @@ func TestRunnerGeneratedCorrelationIDKeepsFlatLayout(t *testing.T) {
 	t.Setenv(EnvVarStratusRedTeamDetonationId, "")
 	technique := &stratus.AttackTechnique{ID: "aws.test.generated"}
 
-	first := NewRunner(technique, StratusRunnerNoForce, WithTerraformManager(new(mocks.TerraformManager)), WithConfig(new(configmocks.Config))).(*runnerImpl)
-	second := NewRunner(technique, StratusRunnerNoForce, WithTerraformManager(new(mocks.TerraformManager)), WithConfig(new(configmocks.Config))).(*runnerImpl)
+	first := NewRunner(technique, StratusRunnerNoForce, WithTerraformManager(new(mocks.TerraformManager)), WithConfig(newConfigMock())).(*runnerImpl)
+	second := NewRunner(technique, StratusRunnerNoForce, WithTerraformManager(new(mocks.TerraformManager)), WithConfig(newConfigMock())).(*runnerImpl)
 
 	assert.NotEqual(t, first.GetUniqueExecutionId(), second.GetUniqueExecutionId())
 	assert.Equal(t, first.TerraformDir, second.TerraformDir, "two commands must resolve to the same state")
 }
+
+// TestRunnerHoldsStateLock verifies that operations hold the state lock once, even when nested,
+// and that a locked state stops them before anything changes.
+func TestRunnerHoldsStateLock(t *testing.T) {
+	technique := &stratus.AttackTechnique{
+		ID:       "test.lock",
+		Detonate: func(map[string]string, stratus.CloudProviders) error { return nil },
+	}
+
+	t.Run("nested operations acquire the lock once", func(t *testing.T) {
+		stateMock := new(statemocks.StateManager)
+		stateMock.On("GetWorkingDirectory").Return("/root/test.lock")
+		stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+		stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+		var acquired stratus.StateLock
+		stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+		stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+		stateMock.On("AcquireLock", mock.Anything).Run(func(args mock.Arguments) {
+			acquired = args.Get(0).(stratus.StateLock)
+		}).Return(nil).Once()
+		stateMock.On("ReleaseLock", mock.Anything).Return(nil).Once()
+
+		r := NewRunner(technique, false,
+			WithStateManager(stateMock),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+			WithStateLockOwner("alice@laptop"),
+			WithStateLockTTL(10*time.Minute),
+		)
+		assert.Nil(t, r.Detonate())
+
+		stateMock.AssertExpectations(t)
+		stateMock.AssertCalled(t, "ReleaseLock", acquired.ID)
+		assert.Equal(t, "alice@laptop", acquired.Owner)
+		assert.Equal(t, 10*time.Minute, acquired.ExpiresAt.Sub(acquired.AcquiredAt))
+	})
+
+	t.Run("a locked state is left untouched", func(t *testing.T) {
+		stateMock := new(statemocks.StateManager)
+		stateMock.On("GetWorkingDirectory").Return("/root/test.lock")
+		stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+		lockedErr := &stratus.StateLockedError{TechniqueID: technique.ID, Lock: stratus.NewStateLock("bob@laptop", time.Hour)}
+		stateMock.On("AcquireLock", mock.Anything).Return(lockedErr)
+		stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+		stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+		stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+		stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+
+		r := NewRunner(technique, false,
+			WithStateManager(stateMock),
+			WithTerraformManager(new(mocks.TerraformManager)),
+			WithConfig(newConfigMock()),
+		)
+		err := r.Detonate()
+
+		assert.ErrorIs(t, err, lockedErr)
+		stateMock.AssertNotCalled(t, "SetTechniqueState", mock.Anything)
+		stateMock.AssertNotCalled(t, "ReleaseLock", mock.Anything)
+	})
+}
+
+func TestRunnerRecordsJournalEntries(t *testing.T) {
+	detonationErr := errors.New("access denied")
+	technique := &stratus.AttackTechnique{
+		ID:       "test.journal",
+		Detonate: func(map[string]string, stratus.CloudProviders) error { return nil },
+		Revert:   func(map[string]string, stratus.CloudProviders) error { return detonationErr },
+	}
+	stateMock := new(statemocks.StateManager)
+	stateMock.On("GetWorkingDirectory").Return("/root/test.journal")
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+	techniqueState := stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold)
+	stateMock.On("GetTechniqueState").Return(func() stratus.AttackTechniqueState { return techniqueState })
+	stateMock.On("SetTechniqueState", mock.Anything).Run(func(args mock.Arguments) {
+		techniqueState = args.Get(0).(stratus.AttackTechniqueState)
+	}).Return(nil)
+	stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
+	stateMock.On("GetTerraformVariables").Return(map[string]string{"foo": "bar"}, nil)
+	var entries []stratus.JournalEntry
+	stateMock.On("AppendJournalEntry", mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, args.Get(0).(stratus.JournalEntry))
+	}).Return(nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil)
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil)
+
+	r := NewRunner(technique, false,
+		WithStateManager(stateMock),
+		WithTerraformManager(new(mocks.TerraformManager)),
+		WithConfig(newConfigMock()),
+		WithCorrelationID(testCorrelationID),
+		WithStateLockOwner("alice@laptop"),
+	)
+	assert.Nil(t, r.Detonate())
+	assert.NotNil(t, r.Revert())
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, stratus.JournalOperationDetonate, entries[0].Operation)
+	assert.Equal(t, "test.journal", entries[0].TechniqueID)
+	assert.Equal(t, testCorrelationID.String(), entries[0].CorrelationID)
+	assert.Equal(t, "alice@laptop", entries[0].Caller)
+	assert.Equal(t, stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold), entries[0].StateBefore)
+	assert.Equal(t, stratus.AttackTechniqueState(stratus.AttackTechniqueStatusDetonated), entries[0].StateAfter)
+	assert.Equal(t, stratus.HashTerraformVariables(map[string]string{"foo": "bar"}), entries[0].TerraformVariablesHash)
+	assert.False(t, entries[0].FinishedAt.Before(entries[0].StartedAt))
+	assert.True(t, entries[0].Succeeded())
+
+	assert.Equal(t, stratus.JournalOperationRevert, entries[1].Operation)
+	assert.Contains(t, entries[1].Error, "access denied", "a failed revert should be recorded")
+	assert.Equal(t, stratus.AttackTechniqueState(stratus.AttackTechniqueStatusDetonated), entries[1].StateAfter)
+}
+
+func TestRunnerRecordsExecutionLifetime(t *testing.T) {
+	warmedUpAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := warmedUpAt.Add(time.Hour)
+	previous := &stratus.ExecutionLifetime{WarmedUpAt: warmedUpAt, ExpiresAt: &expiresAt}
+
+	type RunnerLifetimeTestScenario struct {
+		Name                  string
+		InitialTechniqueState stratus.AttackTechniqueState
+		PreviousLifetime      *stratus.ExecutionLifetime
+		TTL                   time.Duration
+		Run                   func(r Runner) error
+		// results
+		CheckLifetime func(t *testing.T, lifetime stratus.ExecutionLifetime)
+	}
+
+	scenarios := []RunnerLifetimeTestScenario{
+		{
+			Name:                  "Warming up a COLD technique with a TTL",
+			InitialTechniqueState: stratus.AttackTechniqueStatusCold,
+			TTL:                   2 * time.Hour,
+			Run:                   func(r Runner) error { _, err := r.WarmUp(); return err },
+			CheckLifetime: func(t *testing.T, lifetime stratus.ExecutionLifetime) {
+				assert.WithinDuration(t, time.Now(), lifetime.WarmedUpAt, time.Minute)
+				require.NotNil(t, lifetime.ExpiresAt)
+				assert.Equal(t, 2*time.Hour, lifetime.ExpiresAt.Sub(lifetime.WarmedUpAt))
+			},
+		},
+		{
+			Name:                  "Warming up a COLD technique without a TTL",
+			InitialTechniqueState: stratus.AttackTechniqueStatusCold,
+			Run:                   func(r Runner) error { _, err := r.WarmUp(); return err },
+			CheckLifetime: func(t *testing.T, lifetime stratus.ExecutionLifetime) {
+				assert.WithinDuration(t, time.Now(), lifetime.WarmedUpAt, time.Minute)
+				assert.Nil(t, lifetime.ExpiresAt)
+			},
+		},
+		{
+			Name:                  "Warming up a WARM technique with a TTL extends it",
+			InitialTechniqueState: stratus.AttackTechniqueStatusWarm,
+			PreviousLifetime:      previous,
+			TTL:                   2 * time.Hour,
+			Run:                   func(r Runner) error { _, err := r.WarmUp(); return err },
+			CheckLifetime: func(t *testing.T, lifetime stratus.ExecutionLifetime) {
+				assert.Equal(t, warmedUpAt, lifetime.WarmedUpAt)
+				require.NotNil(t, lifetime.ExpiresAt)
+				assert.WithinDuration(t, time.Now().Add(2*time.Hour), *lifetime.ExpiresAt, time.Minute)
+			},
+		},
+		{
+			Name:                  "Detonating a WARM technique without a TTL keeps its expiry",
+			InitialTechniqueState: stratus.AttackTechniqueStatusWarm,
+			PreviousLifetime:      previous,
+			Run:                   func(r Runner) error { return r.Detonate() },
+			CheckLifetime: func(t *testing.T, lifetime stratus.ExecutionLifetime) {
+				assert.Equal(t, *previous, lifetime)
+			},
+		},
+	}
+
+	for i := range scenarios {
+		t.Run(scenarios[i].Name, func(t *testing.T) {
+			technique := &stratus.AttackTechnique{
+				ID:                         "foo",
+				PrerequisitesTerraformCode: []byte("foo"),
+				Detonate:                   func(map[string]string, stratus.CloudProviders) error { return nil },
+			}
+			config := newConfigMock()
+			config.On("GetTerraformVariables", mock.Anything, mock.Anything).Return(map[string]string{})
+			stateMock := new(statemocks.StateManager)
+			stateMock.On("GetWorkingDirectory").Return("/root/foo")
+			stateMock.On("AcquireLock", mock.Anything).Return(nil)
+			stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+			stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+			stateMock.On("GetTechniqueState").Return(scenarios[i].InitialTechniqueState)
+			stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+			stateMock.On("ExtractTechnique").Return(nil)
+			stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
+			stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+			stateMock.On("WriteTerraformVariables", mock.Anything).Return(nil)
+			stateMock.On("WriteTerraformOutputs", mock.Anything).Return(nil)
+			stateMock.On("GetExecutionLifetime").Return(scenarios[i].PreviousLifetime, nil)
+			var lifetime stratus.ExecutionLifetime
+			stateMock.On("WriteExecutionLifetime", mock.Anything).Run(func(args mock.Arguments) {
+				lifetime = args.Get(0).(stratus.ExecutionLifetime)
+			}).Return(nil)
+			terraform := new(mocks.TerraformManager)
+			terraform.On("TerraformInitAndApply", mock.Anything, mock.Anything).Return(map[string]string{}, nil)
+
+			r := NewRunner(technique, false,
+				WithStateManager(stateMock),
+				WithTerraformManager(terraform),
+				WithConfig(config),
+				WithTTL(scenarios[i].TTL),
+			)
+			require.Nil(t, scenarios[i].Run(r))
+			stateMock.AssertCalled(t, "WriteExecutionLifetime", mock.Anything)
+			scenarios[i].CheckLifetime(t, lifetime)
+		})
+	}
+}
+
+func TestRunnerGuardrails(t *testing.T) {
+	allowed := &stratusconfig.AllowedTargets{AWSAccounts: []string{"123456789012"}}
+	resolveTo := func(accountID string) targetResolver {
+		return func(context.Context, stratus.Platform, stratus.CloudProviders) ([]stratus.Target, error) {
+			return []stratus.Target{{Kind: stratus.TargetKindAWSAccount, ID: accountID}}, nil
+		}
+	}
+
+	type RunnerGuardrailsTestScenario struct {
+		Name           string
+		Impact         stratus.Impact
+		Acknowledged   bool
+		AllowedTargets *stratusconfig.AllowedTargets
+		ResolveTargets targetResolver
+		Run            func(r Runner) error
+		// results
+		ExpectedError  string
+		ExpectDetonate bool
+	}
+
+	scenarios := []RunnerGuardrailsTestScenario{
+		{
+			Name:           "Detonating without allowlist",
+			Run:            func(r Runner) error { return r.Detonate() },
+			ExpectDetonate: true,
+		},
+		{
+			Name:           "Detonating in an allowed account",
+			AllowedTargets: allowed,
+			ResolveTargets: resolveTo("123456789012"),
+			Run:            func(r Runner) error { return r.Detonate() },
+			ExpectDetonate: true,
+		},
+		{
+			Name:           "Detonating in another account",
+			AllowedTargets: allowed,
+			ResolveTargets: resolveTo("210987654321"),
+			Run:            func(r Runner) error { return r.Detonate() },
+			ExpectedError:  `AWS account "210987654321" is not in allowed_targets`,
+		},
+		{
+			Name:           "Warming up in another account",
+			AllowedTargets: allowed,
+			ResolveTargets: resolveTo("210987654321"),
+			Run:            func(r Runner) error { _, err := r.WarmUp(); return err },
+			ExpectedError:  `AWS account "210987654321" is not in allowed_targets`,
+		},
+		{
+			Name:           "Failing to resolve the account",
+			AllowedTargets: allowed,
+			ResolveTargets: func(context.Context, stratus.Platform, stratus.CloudProviders) ([]stratus.Target, error) {
+				return nil, errors.New("expired token")
+			},
+			Run:           func(r Runner) error { return r.Detonate() },
+			ExpectedError: "expired token",
+		},
+		{
+			Name:          "Detonating a destructive technique without confirmation",
+			Impact:        stratus.ImpactDestructive,
+			Run:           func(r Runner) error { return r.Detonate() },
+			ExpectedError: "--i-understand",
+		},
+		{
+			Name:           "Detonating a destructive technique with confirmation",
+			Impact:         stratus.ImpactDestructive,
+			Acknowledged:   true,
+			Run:            func(r Runner) error { return r.Detonate() },
+			ExpectDetonate: true,
+		},
+		{
+			Name:   "Warming up a destructive technique without confirmation",
+			Impact: stratus.ImpactDestructive,
+			Run:    func(r Runner) error { _, err := r.WarmUp(); return err },
+		},
+	}
+
+	for i := range scenarios {
+		t.Run(scenarios[i].Name, func(t *testing.T) {
+			detonated := false
+			technique := &stratus.AttackTechnique{
+				ID:       "foo",
+				Platform: stratus.AWS,
+				Impact:   scenarios[i].Impact,
+				Detonate: func(map[string]string, stratus.CloudProviders) error { detonated = true; return nil },
+			}
+			config := new(configmocks.Config)
+			config.On("GetTechniqueParameters", mock.Anything).Return(map[string]string(nil)).Maybe()
+			config.On("GetAllowedTargets").Return(scenarios[i].AllowedTargets).Maybe()
+			config.On("GetAttackerIdentity", mock.Anything).Return((*stratusconfig.AttackerIdentity)(nil)).Maybe()
+			stateMock := new(statemocks.StateManager)
+			stateMock.On("GetWorkingDirectory").Return("/root/foo")
+			stateMock.On("AcquireLock", mock.Anything).Return(nil)
+			stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+			stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+			stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+			stateMock.On("SetTechniqueState", mock.Anything).Return(nil)
+			stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+			stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+			stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+
+			opts := []RunnerOption{
+				WithStateManager(stateMock),
+				WithTerraformManager(new(mocks.TerraformManager)),
+				WithConfig(config),
+			}
+			if scenarios[i].Acknowledged {
+				opts = append(opts, WithDestructiveImpactAcknowledged())
+			}
+			r := NewRunner(technique, false, opts...)
+			r.(*runnerImpl).resolveTargets = scenarios[i].ResolveTargets
+
+			err := scenarios[i].Run(r)
+			if scenarios[i].ExpectedError != "" {
+				assert.ErrorContains(t, err, scenarios[i].ExpectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, scenarios[i].ExpectDetonate, detonated)
+		})
+	}
+}
+
+func TestRunnerRecordsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	var detonationSpan trace.SpanContext
+	technique := &stratus.AttackTechnique{
+		ID:                         "foo",
+		Platform:                   stratus.AWS,
+		PrerequisitesTerraformCode: []byte("foo"),
+		DetonateWithContext: func(ctx context.Context, _ map[string]string, _ stratus.CloudProviders) error {
+			detonationSpan = trace.SpanContextFromContext(ctx)
+			return errors.New("access denied")
+		},
+	}
+	stateMock := new(statemocks.StateManager)
+	stateMock.On("GetWorkingDirectory").Return("/root/foo")
+	stateMock.On("AcquireLock", mock.Anything).Return(nil)
+	stateMock.On("ReleaseLock", mock.Anything).Return(nil)
+	stateMock.On("AppendJournalEntry", mock.Anything).Return(nil)
+	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusWarm))
+	stateMock.On("ExtractTechnique").Return(nil)
+	stateMock.On("GetTerraformOutputs").Return(map[string]string{}, nil)
+	stateMock.On("GetTerraformVariables").Return(map[string]string{}, nil)
+	stateMock.On("GetExecutionLifetime").Return(nil, nil).Maybe()
+	stateMock.On("WriteExecutionLifetime", mock.Anything).Return(nil).Maybe()
+
+	r := NewRunner(technique, false,
+		WithStateManager(stateMock),
+		WithTerraformManager(new(mocks.TerraformManager)),
+		WithConfig(newConfigMock()),
+		WithCorrelationID(testCorrelationID),
+		WithTracerProvider(tracerProvider),
+	)
+	_, err := r.WarmUp()
+	require.Nil(t, err)
+	require.NotNil(t, r.Detonate())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "stratus.warmup", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "stratus.detonate", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), attribute.String("stratus.technique.id", "foo"))
+	assert.Contains(t, spans[1].Attributes(), attribute.String("stratus.correlation_id", testCorrelationID.String()))
+	// The technique is handed the span of the operation, to trace its API calls under it
+	assert.Equal(t, spans[1].SpanContext().SpanID(), detonationSpan.SpanID())
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+)
+
+// StateBackend is a bucket or container that state is stored in, built with ParseStateBackend. Exactly one of
+// S3, GCS and AzureBlob is set.
+type StateBackend struct {
+	S3        *S3BackendConfig
+	GCS       *GCSBackendConfig
+	AzureBlob *AzureBlobBackendConfig
+}
+
+// ParseStateBackend reads the URL of a state backend, one of:
+//
+//   - s3://bucket/prefix?region=us-east-1, using the default AWS credentials
+//   - gs://bucket/prefix?credentials_file=/path/to/key.json, using Application Default Credentials unless a key file is set
+//   - azblob://account/container/prefix, using DefaultAzureCredential
+//
+// The prefix is optional, and defaults to stratus/.
+func ParseStateBackend(ctx context.Context, rawURL string) (*StateBackend, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state backend %s: %w", rawURL, err)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("invalid state backend %s, expected e.g. s3://bucket/prefix", rawURL)
+	}
+	query := parsed.Query()
+	prefix := strings.Trim(parsed.Path, "/")
+
+	switch parsed.Scheme {
+	case "s3":
+		var loadOptions []func(*awsconfig.LoadOptions) error
+		if region := query.Get("region"); region != "" {
+			loadOptions = append(loadOptions, awsconfig.WithRegion(region))
+		}
+		awsConfig, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the AWS configuration of the state backend: %w", err)
+		}
+		return &StateBackend{S3: &S3BackendConfig{
+			BucketName: parsed.Host,
+			Region:     awsConfig.Region,
+			AWSConfig:  awsConfig,
+			KeyPrefix:  keyPrefix(prefix),
+		}}, nil
+	case "gs":
+		return &StateBackend{GCS: &GCSBackendConfig{
+			BucketName:      parsed.Host,
+			KeyPrefix:       keyPrefix(prefix),
+			CredentialsFile: query.Get("credentials_file"),
+		}}, nil
+	case "azblob":
+		container, blobPrefix, _ := strings.Cut(prefix, "/")
+		if container == "" {
+			return nil, fmt.Errorf("invalid state backend %s, expected azblob://account/container/prefix", rawURL)
+		}
+		return &StateBackend{AzureBlob: &AzureBlobBackendConfig{
+			AccountName:   parsed.Host,
+			ContainerName: container,
+			KeyPrefix:     keyPrefix(blobPrefix),
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported state backend %s, expected an s3://, gs:// or azblob:// URL", rawURL)
+	}
+}
+
+// keyPrefix returns the prefix of the keys of a backend, or an empty string for the default one
+func keyPrefix(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// WithStateBackend stores state in backend, like WithS3Backend, WithGCSBackend or WithAzureBlobBackend.
+func WithStateBackend(backend StateBackend) RunnerOption {
+	return func(r *runnerImpl) {
+		r.s3BackendConfig = backend.S3
+		r.gcsBackendConfig = backend.GCS
+		r.azureBlobBackendConfig = backend.AzureBlob
+	}
+}
+
+// NewStateManager returns the state manager of a technique in the backend, for instance to read its state
+// with state.WithReadOnlyState().
+func (m StateBackend) NewStateManager(technique *stratus.AttackTechnique, opts ...state.ManagerOption) (state.StateManager, error) {
+	switch {
+	case m.S3 != nil:
+		return state.NewS3StateManager(technique, *m.S3, opts...), nil
+	case m.GCS != nil:
+		gcsState, err := state.NewGCSStateManager(technique, *m.GCS, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up the GCS state backend: %w", err)
+		}
+		return gcsState, nil
+	case m.AzureBlob != nil:
+		azureBlobState, err := state.NewAzureBlobStateManager(technique, *m.AzureBlob, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up the Azure Blob Storage state backend: %w", err)
+		}
+		return azureBlobState, nil
+	default:
+		return nil, errors.New("no state backend is set")
+	}
+}
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	statemocks "github.com/datadog/stratus-red-team/v2/internal/state/mocks"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStateBackend(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	backend, err := ParseStateBackend(context.Background(), "s3://my-bucket/team/stratus?region=eu-west-3")
+	require.NoError(t, err)
+	require.NotNil(t, backend.S3)
+	assert.Nil(t, backend.GCS)
+	assert.Nil(t, backend.AzureBlob)
+	assert.Equal(t, "my-bucket", backend.S3.BucketName)
+	assert.Equal(t, "team/stratus/", backend.S3.KeyPrefix)
+	assert.Equal(t, "eu-west-3", backend.S3.Region)
+	assert.Equal(t, "eu-west-3", backend.S3.AWSConfig.Region)
+
+	backend, err = ParseStateBackend(context.Background(), "gs://my-bucket?credentials_file=/tmp/key.json")
+	require.NoError(t, err)
+	assert.Equal(t, &StateBackend{GCS: &GCSBackendConfig{BucketName: "my-bucket", CredentialsFile: "/tmp/key.json"}}, backend)
+
+	backend, err = ParseStateBackend(context.Background(), "azblob://account/container/stratus/")
+	require.NoError(t, err)
+	assert.Equal(t, &StateBackend{AzureBlob: &AzureBlobBackendConfig{AccountName: "account", ContainerName: "container", KeyPrefix: "stratus/"}}, backend)
+
+	for _, invalid := range []string{"", "my-bucket", "file:///tmp/state", "s3:///prefix", "azblob://account"} {
+		_, err := ParseStateBackend(context.Background(), invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// remoteStateMock is a state manager that also holds the Terraform state, like the S3 one
+type remoteStateMock struct {
+	*statemocks.StateManager
+}
+
+func (m remoteStateMock) BackendConfigs() map[string]string {
+	return map[string]string{"access_key": "AKIAEXAMPLE"}
+}
+
+func TestRunnerUsesBackendConfigsOfInjectedStateManager(t *testing.T) {
+	stateMock := new(statemocks.StateManager)
+	stateMock.On("GetWorkingDirectory").Return("/remote/test.technique")
+	stateMock.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+
+	r := NewRunner(&stratus.AttackTechnique{ID: "test.technique"}, false,
+		WithStateManager(remoteStateMock{stateMock}),
+		WithTerraformManager(new(mocks.TerraformManager)),
+		WithConfig(newConfigMock()),
+	).(*runnerImpl)
+
+	assert.Equal(t, map[string]string{"access_key": "AKIAEXAMPLE"}, r.terraformBackendConfigs)
+}
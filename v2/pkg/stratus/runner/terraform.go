@@ -10,11 +10,16 @@ import (
 
 	"github.com/datadog/stratus-red-team/v2/internal/state"
 	"github.com/datadog/stratus-red-team/v2/internal/utils"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/tracing"
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hc-install/product"
 	"github.com/hashicorp/hc-install/releases"
 	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const TerraformVersion = "1.3.10"
@@ -22,12 +27,16 @@ const TerraformVersion = "1.3.10"
 // pluginCacheEnvVar tells Terraform where to cache provider plugins across working directories.
 const pluginCacheEnvVar = "TF_PLUGIN_CACHE_DIR"
 
+// planFileName is where terraform plan writes the plan, in the working directory of the technique.
+const planFileName = "stratus.tfplan"
+
 // Terraform plugin cache initialization is not concurrency safe, so we gate terraform init with a mutex.
 var terraformInitMutex sync.Mutex
 
 type TerraformManager interface {
 	Initialize()
 	TerraformInitAndApply(directory string, variables map[string]string) (map[string]string, error)
+	TerraformPlan(directory string, variables map[string]string) ([]stratus.PlannedResource, error)
 	TerraformDestroy(directory string, variables map[string]string) error
 }
 
@@ -37,6 +46,7 @@ type TerraformManagerImpl struct {
 	terraformUserAgent   string
 	backendConfigs       map[string]string
 	pluginCacheDirectory string
+	environment          func(ctx context.Context) (map[string]string, error)
 	context              context.Context
 }
 
@@ -57,6 +67,13 @@ func WithPluginCacheDirectory(directory string) TerraformManagerOption {
 	return func(m *TerraformManagerImpl) { m.pluginCacheDirectory = directory }
 }
 
+// WithEnvironment sets variables of the environment Terraform runs with, on top of the one of Stratus Red Team.
+// environment is called before each Terraform command, so that it can return short-lived credentials. Variables
+// it sets to an empty value are removed from the environment.
+func WithEnvironment(environment func(ctx context.Context) (map[string]string, error)) TerraformManagerOption {
+	return func(m *TerraformManagerImpl) { m.environment = environment }
+}
+
 func NewTerraformManager(terraformBinaryPath string, userAgent string, opts ...TerraformManagerOption) TerraformManager {
 	return NewTerraformManagerWithContext(context.Background(), terraformBinaryPath, userAgent, opts...)
 }
@@ -75,6 +92,19 @@ func NewTerraformManagerWithContext(ctx context.Context, terraformBinaryPath str
 	return &manager
 }
 
+// withSpanOf returns a copy of the manager whose commands are traced as children of the span carried
+// by ctx, typically the one of a runner operation. It keeps running them under its own context.
+func (m *TerraformManagerImpl) withSpanOf(ctx context.Context) TerraformManager {
+	traced := *m
+	traced.context = trace.ContextWithSpan(m.context, trace.SpanFromContext(ctx))
+	return &traced
+}
+
+// startSpan starts the span of a Terraform command, returning the context to run it with.
+func (m *TerraformManagerImpl) startSpan(name string, directory string) (context.Context, trace.Span) {
+	return tracing.Tracer(m.context).Start(m.context, name, trace.WithAttributes(attribute.String("terraform.directory", directory)))
+}
+
 func (m *TerraformManagerImpl) Initialize() {
 	if utils.FileExists(m.terraformBinaryPath) {
 		if m.existingBinaryVersionSufficient() {
@@ -83,13 +113,15 @@ func (m *TerraformManagerImpl) Initialize() {
 		log.Printf("Terraform binary at %s is below required version %s, downloading the correct version", m.terraformBinaryPath, m.terraformVersion)
 	}
 
+	ctx, span := m.startSpan("terraform.install", filepath.Dir(m.terraformBinaryPath))
 	terraformInstaller := &releases.ExactVersion{
 		Product:                  product.Terraform,
 		Version:                  version.Must(version.NewVersion(TerraformVersion)),
 		InstallDir:               filepath.Dir(m.terraformBinaryPath),
 		SkipChecksumVerification: false,
 	}
-	_, err := terraformInstaller.Install(m.context)
+	_, err := terraformInstaller.Install(ctx)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		log.Fatalf("error installing Terraform: %s", err)
 	}
@@ -115,16 +147,19 @@ func (m *TerraformManagerImpl) TerraformInitAndApply(directory string, variables
 	}
 
 	log.Println("Applying Terraform to spin up technique prerequisites")
+	ctx, span := m.startSpan("terraform.apply", directory)
 	applyOptions := []tfexec.ApplyOption{tfexec.Refresh(false)}
 	for key, value := range variables {
 		applyOptions = append(applyOptions, tfexec.Var(key+"="+value))
 	}
-	err = terraform.Apply(m.context, applyOptions...)
+	err = terraform.Apply(ctx, applyOptions...)
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, fmt.Errorf("unable to apply Terraform: %w", err)
 	}
 
-	rawOutputs, _ := terraform.Output(m.context)
+	rawOutputs, _ := terraform.Output(ctx)
+	span.End()
 	outputs := make(map[string]string, len(rawOutputs))
 	for outputName, outputRawValue := range rawOutputs {
 		outputValue := string(outputRawValue.Value)
@@ -135,6 +170,77 @@ func (m *TerraformManagerImpl) TerraformInitAndApply(directory string, variables
 	return outputs, nil
 }
 
+// TerraformPlan runs terraform plan, and returns the resources that applying it would change.
+func (m *TerraformManagerImpl) TerraformPlan(directory string, variables map[string]string) ([]stratus.PlannedResource, error) {
+	terraform, err := tfexec.NewTerraform(directory, m.terraformBinaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to instantiate Terraform: %w", err)
+	}
+
+	if err := m.configureEnvironment(terraform); err != nil {
+		return nil, fmt.Errorf("unable to configure Terraform environment: %w", err)
+	}
+
+	if err := terraform.SetAppendUserAgent(m.terraformUserAgent); err != nil {
+		return nil, fmt.Errorf("unable to configure Terraform: %w", err)
+	}
+
+	if err := m.ensureInitialized(terraform, directory); err != nil {
+		return nil, fmt.Errorf("unable to initialize Terraform: %w", err)
+	}
+
+	log.Println("Planning the technique prerequisites")
+	ctx, span := m.startSpan("terraform.plan", directory)
+	// The plan file may hold sensitive values, don't leave it behind
+	planFile := filepath.Join(directory, planFileName)
+	defer os.Remove(planFile)
+	planOptions := []tfexec.PlanOption{tfexec.Out(planFile)}
+	for key, value := range variables {
+		planOptions = append(planOptions, tfexec.Var(key+"="+value))
+	}
+	if _, err := terraform.Plan(ctx, planOptions...); err != nil {
+		tracing.EndSpan(span, err)
+		return nil, fmt.Errorf("unable to plan Terraform: %w", err)
+	}
+
+	plan, err := terraform.ShowPlanFile(ctx, planFile)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the Terraform plan: %w", err)
+	}
+	return plannedResources(plan), nil
+}
+
+// plannedResources lists the managed resources a plan changes, leaving out data sources and no-ops.
+func plannedResources(plan *tfjson.Plan) []stratus.PlannedResource {
+	resources := []stratus.PlannedResource{}
+	for _, change := range plan.ResourceChanges {
+		if change.Mode != tfjson.ManagedResourceMode || change.Change == nil {
+			continue
+		}
+		var action stratus.PlannedResourceAction
+		switch actions := change.Change.Actions; {
+		case actions.Replace():
+			action = stratus.PlannedResourceActionReplace
+		case actions.Create():
+			action = stratus.PlannedResourceActionCreate
+		case actions.Update():
+			action = stratus.PlannedResourceActionUpdate
+		case actions.Delete():
+			action = stratus.PlannedResourceActionDelete
+		default:
+			continue
+		}
+		resources = append(resources, stratus.PlannedResource{
+			Address:      change.Address,
+			Type:         change.Type,
+			ProviderName: change.ProviderName,
+			Action:       action,
+		})
+	}
+	return resources
+}
+
 func (m *TerraformManagerImpl) TerraformDestroy(directory string, variables map[string]string) error {
 	terraform, err := tfexec.NewTerraform(directory, m.terraformBinaryPath)
 	if err != nil {
@@ -149,11 +255,14 @@ func (m *TerraformManagerImpl) TerraformDestroy(directory string, variables map[
 		return fmt.Errorf("unable to initialize Terraform for destroy: %w", err)
 	}
 
+	ctx, span := m.startSpan("terraform.destroy", directory)
 	destroyOptions := []tfexec.DestroyOption{}
 	for key, value := range variables {
 		destroyOptions = append(destroyOptions, tfexec.Var(key+"="+value))
 	}
-	return terraform.Destroy(m.context, destroyOptions...)
+	err = terraform.Destroy(ctx, destroyOptions...)
+	tracing.EndSpan(span, err)
+	return err
 }
 
 // existingBinaryVersionSufficient checks whether the terraform binary at terraformBinaryPath has a
@@ -204,6 +313,20 @@ func (m *TerraformManagerImpl) configureEnvironment(tf *tfexec.Terraform) error
 		env[pluginCacheEnvVar] = cacheDirectory
 	}
 
+	if m.environment != nil {
+		overrides, err := m.environment(m.context)
+		if err != nil {
+			return err
+		}
+		for name, value := range overrides {
+			if value == "" {
+				delete(env, name)
+			} else {
+				env[name] = value
+			}
+		}
+	}
+
 	return tf.SetEnv(tfexec.CleanEnv(env))
 }
 
@@ -233,12 +356,15 @@ func (m *TerraformManagerImpl) ensureInitialized(tf *tfexec.Terraform, directory
 	defer terraformInitMutex.Unlock()
 
 	log.Println("Initializing Terraform")
+	ctx, span := m.startSpan("terraform.init", directory)
 	var initOpts []tfexec.InitOption
 	for key, value := range m.backendConfigs {
 		initOpts = append(initOpts, tfexec.BackendConfig(key+"="+value))
 	}
 
-	if err := tf.Init(m.context, initOpts...); err != nil {
+	err := tf.Init(ctx, initOpts...)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,255 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/internal/utils"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/google/uuid"
+)
+
+// StepStatus is the outcome of a step.
+type StepStatus string
+
+const (
+	StepStatusSucceeded StepStatus = "SUCCEEDED"
+	StepStatusFailed    StepStatus = "FAILED"
+	// StepStatusSkipped is reported for steps that did not run because a dependency did not succeed.
+	StepStatusSkipped StepStatus = "SKIPPED"
+)
+
+// StepResult reports how a step went.
+type StepResult struct {
+	StepID    string
+	Technique string
+	Status    StepStatus
+	Duration  time.Duration
+	Error     error
+	// CleanupError is set when the step ran but its prerequisites could not be cleaned up.
+	CleanupError error
+}
+
+// Result reports how a scenario went, with one StepResult per step in the order of the scenario.
+type Result struct {
+	CorrelationID uuid.UUID
+	Steps         []StepResult
+}
+
+// Failed indicates if any step failed or could not be cleaned up.
+func (m *Result) Failed() bool {
+	for i := range m.Steps {
+		if m.Steps[i].Status != StepStatusSucceeded || m.Steps[i].CleanupError != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns the errors of every step that failed or could not be cleaned up.
+func (m *Result) Errors() error {
+	var errs []error
+	for i := range m.Steps {
+		step := m.Steps[i]
+		if step.Error != nil {
+			errs = append(errs, fmt.Errorf("step %s: %w", step.StepID, step.Error))
+		}
+		if step.CleanupError != nil {
+			errs = append(errs, fmt.Errorf("step %s cleanup: %w", step.StepID, step.CleanupError))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunnerFactory builds the runner of a step. It defaults to runner.NewRunnerWithContext.
+type RunnerFactory func(ctx context.Context, technique *stratus.AttackTechnique, force bool, opts ...runner.RunnerOption) runner.Runner
+
+// ExecutorOption configures optional settings on an Executor.
+type ExecutorOption func(*Executor)
+
+// WithRunnerOptions passes additional options to the runner of every step, for instance a state backend.
+// The correlation ID of the scenario always takes precedence over WithCorrelationID.
+func WithRunnerOptions(opts ...runner.RunnerOption) ExecutorOption {
+	return func(e *Executor) { e.runnerOptions = append(e.runnerOptions, opts...) }
+}
+
+// WithRunnerFactory overrides how runners are built.
+func WithRunnerFactory(factory RunnerFactory) ExecutorOption {
+	return func(e *Executor) { e.runnerFactory = factory }
+}
+
+// WithRegistry overrides the registry used to resolve techniques, which defaults to stratus.GetRegistry().
+func WithRegistry(registry *stratus.Registry) ExecutorOption {
+	return func(e *Executor) { e.registry = registry }
+}
+
+// Executor runs the steps of a scenario, concurrently when their dependencies allow it.
+type Executor struct {
+	scenario      *Scenario
+	correlationID uuid.UUID
+	registry      *stratus.Registry
+	runnerFactory RunnerFactory
+	runnerOptions []runner.RunnerOption
+}
+
+// NewExecutor builds an executor for a scenario. The scenario is validated when it runs.
+func NewExecutor(scenario *Scenario, opts ...ExecutorOption) *Executor {
+	executor := &Executor{
+		scenario:      scenario,
+		registry:      stratus.GetRegistry(),
+		runnerFactory: runner.NewRunnerWithContext,
+	}
+	for _, opt := range opts {
+		opt(executor)
+	}
+	executor.correlationID = uuid.New()
+	if parsed, err := uuid.Parse(scenario.CorrelationID); err == nil {
+		executor.correlationID = parsed
+	}
+	return executor
+}
+
+// CorrelationID returns the correlation ID shared by every step of the scenario.
+func (m *Executor) CorrelationID() uuid.UUID {
+	return m.correlationID
+}
+
+// stepExecution tracks a step while the scenario runs.
+type stepExecution struct {
+	step      *Step
+	technique *stratus.AttackTechnique
+	runner    runner.Runner
+	result    StepResult
+	done      chan struct{}
+}
+
+// Run detonates every step of the scenario, then cleans up the steps using the CleanupAtEnd policy.
+//
+// A failing step does not stop the steps that do not depend on it. Cancelling ctx stops the steps
+// in flight and skips the remaining ones, but the steps that ran are still cleaned up according to
+// their policy.
+func (m *Executor) Run(ctx context.Context) (*Result, error) {
+	if err := m.scenario.Validate(m.registry); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	techniques, err := m.scenario.Techniques(m.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Running scenario %s with correlation ID %s", m.displayName(), m.correlationID)
+	executions := make(map[string]*stepExecution, len(m.scenario.Steps))
+	ordered := make([]*stepExecution, 0, len(m.scenario.Steps))
+	for i := range m.scenario.Steps {
+		execution := &stepExecution{
+			step:      &m.scenario.Steps[i],
+			technique: techniques[i],
+			result:    StepResult{StepID: m.scenario.Steps[i].ID, Technique: techniques[i].ID},
+			done:      make(chan struct{}),
+		}
+		executions[execution.step.ID] = execution
+		ordered = append(ordered, execution)
+	}
+
+	var wg sync.WaitGroup
+	for _, execution := range ordered {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(execution.done)
+			m.runStep(ctx, execution, executions)
+		}()
+	}
+	wg.Wait()
+
+	m.cleanUpAtEnd(ctx, ordered)
+
+	result := &Result{CorrelationID: m.correlationID}
+	for _, execution := range ordered {
+		result.Steps = append(result.Steps, execution.result)
+	}
+	return result, nil
+}
+
+func (m *Executor) runStep(ctx context.Context, execution *stepExecution, executions map[string]*stepExecution) {
+	step := execution.step
+	for _, dependency := range step.DependsOn {
+		<-executions[dependency].done
+		if status := executions[dependency].result.Status; status != StepStatusSucceeded {
+			log.Warnf("[%s] skipping, as step %s did not succeed", step.ID, dependency)
+			execution.result.Status = StepStatusSkipped
+			return
+		}
+	}
+	if step.Delay > 0 {
+		log.Infof("[%s] waiting %s before detonating %s", step.ID, step.Delay, step.Technique)
+		if err := utils.SleepWithContext(ctx, step.Delay); err != nil {
+			execution.result.Status = StepStatusSkipped
+			execution.result.Error = err
+			return
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		execution.result.Status = StepStatusSkipped
+		execution.result.Error = err
+		return
+	}
+
+	log.Infof("[%s] detonating %s", step.ID, step.Technique)
+	start := time.Now()
+	execution.runner = m.runnerFactory(ctx, execution.technique, step.Force, m.stepRunnerOptions()...)
+	err := execution.runner.Detonate()
+	execution.result.Duration = time.Since(start)
+	if err != nil {
+		log.Errorf("[%s] %s", step.ID, err.Error())
+		execution.result.Status = StepStatusFailed
+		execution.result.Error = err
+	} else {
+		execution.result.Status = StepStatusSucceeded
+	}
+
+	if step.Cleanup == CleanupAfterStep {
+		m.cleanUp(ctx, execution)
+	}
+}
+
+func (m *Executor) stepRunnerOptions() []runner.RunnerOption {
+	return append(append([]runner.RunnerOption{}, m.runnerOptions...), runner.WithCorrelationID(m.correlationID))
+}
+
+// cleanUp cleans up a step, unless its technique is COLD, for instance because it failed to warm up.
+// The runner cleaning up has a context that is not cancelled along with ctx, so that interrupting a
+// scenario still tears down what its steps created. Force only applies to detonations, not to cleanups.
+func (m *Executor) cleanUp(ctx context.Context, execution *stepExecution) {
+	cleanupRunner := m.runnerFactory(context.WithoutCancel(ctx), execution.technique, runner.StratusRunnerNoForce, m.stepRunnerOptions()...)
+	if cleanupRunner.GetState() == stratus.AttackTechniqueStatusCold {
+		return
+	}
+	log.Infof("[%s] cleaning up %s", execution.step.ID, execution.step.Technique)
+	if err := cleanupRunner.CleanUp(); err != nil {
+		execution.result.CleanupError = err
+	}
+}
+
+// cleanUpAtEnd cleans up the steps using the CleanupAtEnd policy, in reverse order so that later
+// steps, which may build on earlier ones, are torn down first.
+func (m *Executor) cleanUpAtEnd(ctx context.Context, ordered []*stepExecution) {
+	for i := len(ordered) - 1; i >= 0; i-- {
+		execution := ordered[i]
+		if execution.step.Cleanup == CleanupAtEnd && execution.runner != nil {
+			m.cleanUp(ctx, execution)
+		}
+	}
+}
+
+func (m *Executor) displayName() string {
+	if m.scenario.Name != "" {
+		return m.scenario.Name
+	}
+	return "(unnamed)"
+}
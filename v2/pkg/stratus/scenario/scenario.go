@@ -0,0 +1,198 @@
+// Package scenario chains several attack techniques into a single attack campaign.
+//
+// A scenario is a YAML file listing ordered steps. Each step detonates one technique, and every
+// step of a scenario shares the same correlation ID, so that the whole campaign can be traced
+// end-to-end in the audit logs of the target platforms.
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// CleanupPolicy determines when the prerequisites of a step are cleaned up.
+type CleanupPolicy string
+
+const (
+	// CleanupNever leaves the technique WARM or DETONATED, like `stratus detonate`.
+	CleanupNever CleanupPolicy = "never"
+	// CleanupAfterStep cleans up right after the step is detonated, like `stratus detonate --cleanup`.
+	CleanupAfterStep CleanupPolicy = "after-step"
+	// CleanupAtEnd cleans up once every step of the scenario has completed, whether it succeeded or not.
+	CleanupAtEnd CleanupPolicy = "end"
+)
+
+// Scenario is an ordered list of attack techniques detonated as one attack campaign.
+type Scenario struct {
+	// Friendly-looking name of the scenario
+	Name string `yaml:"name"`
+
+	// Free-form description of the attack story
+	Description string `yaml:"description,omitempty"`
+
+	// Correlation ID shared by every step. Generated when empty.
+	CorrelationID string `yaml:"correlation_id,omitempty"`
+
+	// Steps of the scenario, in order
+	Steps []Step `yaml:"steps"`
+}
+
+// Step detonates a single attack technique as part of a scenario.
+type Step struct {
+	// Unique identifier of the step within the scenario. Defaults to the technique ID.
+	ID string `yaml:"id,omitempty"`
+
+	// ID of the attack technique to detonate, e.g. aws.persistence.iam-create-admin-user
+	Technique string `yaml:"technique"`
+
+	// Steps that must have succeeded before this one starts.
+	// When omitted, the step depends on the step right before it. Use an empty list to start it right away.
+	DependsOn []string `yaml:"depends_on"`
+
+	// Time to wait once the dependencies have succeeded, before detonating the technique
+	Delay time.Duration `yaml:"delay,omitempty"`
+
+	// Force detonation even if the technique is not idempotent and has already been detonated
+	Force bool `yaml:"force,omitempty"`
+
+	// When to clean up the prerequisites of the technique. Defaults to CleanupNever.
+	Cleanup CleanupPolicy `yaml:"cleanup,omitempty"`
+}
+
+// Load reads and parses a scenario file.
+func Load(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scenario file: %w", err)
+	}
+	return Parse(raw)
+}
+
+// Parse parses a YAML scenario and fills in defaults. It does not validate it, see Validate.
+func Parse(raw []byte) (*Scenario, error) {
+	var scenario Scenario
+	if err := yaml.Unmarshal(raw, &scenario); err != nil {
+		return nil, fmt.Errorf("parsing scenario YAML: %w", err)
+	}
+	for i := range scenario.Steps {
+		step := &scenario.Steps[i]
+		if step.ID == "" {
+			step.ID = step.Technique
+		}
+		if step.Cleanup == "" {
+			step.Cleanup = CleanupNever
+		}
+		if step.DependsOn == nil && i > 0 {
+			step.DependsOn = []string{scenario.Steps[i-1].ID}
+		}
+	}
+	return &scenario, nil
+}
+
+// Validate ensures that the scenario only references known techniques and steps, and that
+// its dependencies do not form a cycle.
+func (m *Scenario) Validate(registry *stratus.Registry) error {
+	if len(m.Steps) == 0 {
+		return errors.New("the scenario has no steps")
+	}
+	if m.CorrelationID != "" {
+		if _, err := uuid.Parse(m.CorrelationID); err != nil {
+			return fmt.Errorf("correlation_id is not a valid UUID: %w", err)
+		}
+	}
+
+	steps := make(map[string]*Step, len(m.Steps))
+	techniques := make(map[string]string, len(m.Steps))
+	var errs []error
+	for i := range m.Steps {
+		step := &m.Steps[i]
+		if step.Technique == "" {
+			errs = append(errs, fmt.Errorf("step %d has no technique", i+1))
+			continue
+		}
+		if registry.GetAttackTechniqueByName(step.Technique) == nil {
+			errs = append(errs, fmt.Errorf("step %s: unknown technique name %s", step.ID, step.Technique))
+		}
+		if _, duplicate := steps[step.ID]; duplicate {
+			errs = append(errs, fmt.Errorf("duplicate step ID %s", step.ID))
+		}
+		// Steps sharing the correlation ID, the same technique twice would share its state too.
+		if other, duplicate := techniques[step.Technique]; duplicate {
+			errs = append(errs, fmt.Errorf("steps %s and %s both use technique %s, which is only allowed once per scenario", other, step.ID, step.Technique))
+		}
+		switch step.Cleanup {
+		case CleanupNever, CleanupAfterStep, CleanupAtEnd:
+		default:
+			errs = append(errs, fmt.Errorf("step %s: unknown cleanup policy %q", step.ID, step.Cleanup))
+		}
+		if step.Delay < 0 {
+			errs = append(errs, fmt.Errorf("step %s: delay cannot be negative", step.ID))
+		}
+		steps[step.ID] = step
+		techniques[step.Technique] = step.ID
+	}
+	for i := range m.Steps {
+		for _, dependency := range m.Steps[i].DependsOn {
+			if _, found := steps[dependency]; !found {
+				errs = append(errs, fmt.Errorf("step %s depends on unknown step %s", m.Steps[i].ID, dependency))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return m.ensureAcyclic(steps)
+}
+
+// ensureAcyclic returns an error if the dependencies between steps form a cycle, which would
+// otherwise leave the scenario waiting forever.
+func (m *Scenario) ensureAcyclic(steps map[string]*Step) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	status := make(map[string]int, len(steps))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch status[id] {
+		case visiting:
+			return fmt.Errorf("the dependencies of step %s form a cycle", id)
+		case visited:
+			return nil
+		}
+		status[id] = visiting
+		for _, dependency := range steps[id].DependsOn {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		status[id] = visited
+		return nil
+	}
+	for i := range m.Steps {
+		if err := visit(m.Steps[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Techniques returns the attack techniques of the scenario, in the order of its steps.
+func (m *Scenario) Techniques(registry *stratus.Registry) ([]*stratus.AttackTechnique, error) {
+	techniques := make([]*stratus.AttackTechnique, 0, len(m.Steps))
+	for i := range m.Steps {
+		technique := registry.GetAttackTechniqueByName(m.Steps[i].Technique)
+		if technique == nil {
+			return nil, errors.New("unknown technique name " + m.Steps[i].Technique)
+		}
+		techniques = append(techniques, technique)
+	}
+	return techniques, nil
+}
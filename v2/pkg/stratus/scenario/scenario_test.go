@@ -0,0 +1,329 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	statemocks "github.com/datadog/stratus-red-team/v2/internal/state/mocks"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	configmocks "github.com/datadog/stratus-red-team/v2/pkg/stratus/config/mocks"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	runnermocks "github.com/datadog/stratus-red-team/v2/pkg/stratus/runner/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRegistry(ids ...string) *stratus.Registry {
+	registry := stratus.NewRegistry()
+	for _, id := range ids {
+		registry.RegisterAttackTechnique(&stratus.AttackTechnique{ID: id})
+	}
+	return &registry
+}
+
+func TestParseFillsDefaults(t *testing.T) {
+	scenario, err := Parse([]byte(`
+name: campaign
+steps:
+  - technique: aws.initial-access.console-login-without-mfa
+  - id: persistence
+    technique: aws.persistence.iam-create-admin-user
+    delay: 30s
+    cleanup: end
+  - technique: aws.defense-evasion.cloudtrail-stop
+    depends_on: []
+    force: true
+`))
+	require.Nil(t, err)
+	require.Len(t, scenario.Steps, 3)
+
+	assert.Equal(t, "aws.initial-access.console-login-without-mfa", scenario.Steps[0].ID)
+	assert.Empty(t, scenario.Steps[0].DependsOn)
+	assert.Equal(t, CleanupNever, scenario.Steps[0].Cleanup)
+
+	assert.Equal(t, "persistence", scenario.Steps[1].ID)
+	assert.Equal(t, []string{"aws.initial-access.console-login-without-mfa"}, scenario.Steps[1].DependsOn, "steps depend on the previous one by default")
+	assert.Equal(t, 30*time.Second, scenario.Steps[1].Delay)
+	assert.Equal(t, CleanupAtEnd, scenario.Steps[1].Cleanup)
+
+	assert.Empty(t, scenario.Steps[2].DependsOn, "an explicitly empty depends_on starts right away")
+	assert.True(t, scenario.Steps[2].Force)
+}
+
+func TestValidate(t *testing.T) {
+	registry := testRegistry("foo", "bar")
+	scenarios := []struct {
+		Name          string
+		Scenario      Scenario
+		ExpectedError string
+	}{
+		{
+			Name:     "valid scenario",
+			Scenario: Scenario{Steps: []Step{{ID: "foo", Technique: "foo", Cleanup: CleanupNever}, {ID: "bar", Technique: "bar", DependsOn: []string{"foo"}, Cleanup: CleanupAtEnd}}},
+		},
+		{
+			Name:          "no steps",
+			Scenario:      Scenario{},
+			ExpectedError: "the scenario has no steps",
+		},
+		{
+			Name:          "unknown technique",
+			Scenario:      Scenario{Steps: []Step{{ID: "baz", Technique: "baz", Cleanup: CleanupNever}}},
+			ExpectedError: "step baz: unknown technique name baz",
+		},
+		{
+			Name:          "unknown dependency",
+			Scenario:      Scenario{Steps: []Step{{ID: "foo", Technique: "foo", DependsOn: []string{"nope"}, Cleanup: CleanupNever}}},
+			ExpectedError: "step foo depends on unknown step nope",
+		},
+		{
+			Name:          "same technique twice",
+			Scenario:      Scenario{Steps: []Step{{ID: "first", Technique: "foo", Cleanup: CleanupNever}, {ID: "second", Technique: "foo", Cleanup: CleanupNever}}},
+			ExpectedError: "steps first and second both use technique foo, which is only allowed once per scenario",
+		},
+		{
+			Name:          "unknown cleanup policy",
+			Scenario:      Scenario{Steps: []Step{{ID: "foo", Technique: "foo", Cleanup: "sometimes"}}},
+			ExpectedError: `step foo: unknown cleanup policy "sometimes"`,
+		},
+		{
+			Name:          "invalid correlation ID",
+			Scenario:      Scenario{CorrelationID: "not-a-uuid", Steps: []Step{{ID: "foo", Technique: "foo", Cleanup: CleanupNever}}},
+			ExpectedError: "correlation_id is not a valid UUID: invalid UUID length: 10",
+		},
+		{
+			Name:          "cycle",
+			Scenario:      Scenario{Steps: []Step{{ID: "foo", Technique: "foo", DependsOn: []string{"bar"}, Cleanup: CleanupNever}, {ID: "bar", Technique: "bar", DependsOn: []string{"foo"}, Cleanup: CleanupNever}}},
+			ExpectedError: "the dependencies of step foo form a cycle",
+		},
+	}
+
+	for i := range scenarios {
+		t.Run(scenarios[i].Name, func(t *testing.T) {
+			err := scenarios[i].Scenario.Validate(registry)
+			if scenarios[i].ExpectedError == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, scenarios[i].ExpectedError)
+			}
+		})
+	}
+}
+
+// fakeRunner records the lifecycle calls made by the executor. Like actual runners, it fails once its
+// context is cancelled, and its state is shared with the other runners of the same technique.
+type fakeRunner struct {
+	ctx         context.Context
+	technique   string
+	detonateErr error
+	onDetonate  func()
+	state       *stratus.AttackTechniqueState
+	events      *[]string
+	mutex       *sync.Mutex
+}
+
+var _ runner.Runner = &fakeRunner{}
+
+func (m *fakeRunner) record(event string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	*m.events = append(*m.events, event+" "+m.technique)
+}
+
+func (m *fakeRunner) WarmUp() (map[string]string, error)       { return nil, nil }
+func (m *fakeRunner) Plan() ([]stratus.PlannedResource, error) { return nil, nil }
+func (m *fakeRunner) Detonate() error {
+	m.record("detonate")
+	*m.state = stratus.AttackTechniqueStatusDetonated
+	if m.onDetonate != nil {
+		m.onDetonate()
+	}
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	return m.detonateErr
+}
+func (m *fakeRunner) Revert() error { return nil }
+func (m *fakeRunner) CleanUp() error {
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	m.record("cleanup")
+	*m.state = stratus.AttackTechniqueStatusCold
+	return nil
+}
+func (m *fakeRunner) GetState() stratus.AttackTechniqueState { return *m.state }
+func (m *fakeRunner) GetUniqueExecutionId() string           { return "" }
+
+func TestExecutorRun(t *testing.T) {
+	registry := testRegistry("initial-access", "persistence", "exfiltration", "discovery")
+	scenario, err := Parse([]byte(`
+name: campaign
+correlation_id: 11111111-2222-3333-4444-555555555555
+steps:
+  - technique: initial-access
+    cleanup: end
+  - technique: persistence
+    cleanup: after-step
+  - technique: exfiltration
+    cleanup: end
+  - technique: discovery
+    depends_on: [initial-access]
+`))
+	require.Nil(t, err)
+
+	var events []string
+	var mutex sync.Mutex
+	var correlationIDs []string
+	states := map[string]*stratus.AttackTechniqueState{}
+	factory := func(ctx context.Context, technique *stratus.AttackTechnique, force bool, opts ...runner.RunnerOption) runner.Runner {
+		correlationID := resolveCorrelationOption(opts)
+		mutex.Lock()
+		defer mutex.Unlock()
+		correlationIDs = append(correlationIDs, correlationID)
+		if states[technique.ID] == nil {
+			cold := stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold)
+			states[technique.ID] = &cold
+		}
+		fake := &fakeRunner{ctx: ctx, technique: technique.ID, events: &events, mutex: &mutex, state: states[technique.ID]}
+		if technique.ID == "persistence" {
+			fake.detonateErr = errors.New("access denied")
+		}
+		return fake
+	}
+
+	result, err := NewExecutor(scenario, WithRegistry(registry), WithRunnerFactory(factory)).Run(context.Background())
+	require.Nil(t, err)
+
+	assert.Equal(t, uuid.MustParse("11111111-2222-3333-4444-555555555555"), result.CorrelationID)
+	assert.True(t, result.Failed())
+	statuses := map[string]StepStatus{}
+	for _, step := range result.Steps {
+		statuses[step.StepID] = step.Status
+	}
+	assert.Equal(t, map[string]StepStatus{
+		"initial-access": StepStatusSucceeded,
+		"persistence":    StepStatusFailed,
+		"exfiltration":   StepStatusSkipped, // depends on persistence, which failed
+		"discovery":      StepStatusSucceeded,
+	}, statuses)
+
+	assert.Contains(t, events, "cleanup persistence", "a failed step is still cleaned up after it runs")
+	assert.Equal(t, "cleanup initial-access", events[len(events)-1], "end cleanup happens last")
+	assert.NotContains(t, events, "detonate exfiltration")
+	assert.NotContains(t, events, "cleanup discovery")
+	for _, id := range correlationIDs {
+		assert.Equal(t, "11111111-2222-3333-4444-555555555555", id, "every step shares the scenario correlation ID")
+	}
+}
+
+func TestExecutorRunCancelled(t *testing.T) {
+	registry := testRegistry("foo")
+	scenario, err := Parse([]byte("steps:\n  - technique: foo\n    delay: 1h\n"))
+	require.Nil(t, err)
+
+	factory := func(context.Context, *stratus.AttackTechnique, bool, ...runner.RunnerOption) runner.Runner {
+		t.Error("no runner should be built once the scenario is cancelled")
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := NewExecutor(scenario, WithRegistry(registry), WithRunnerFactory(factory)).Run(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, StepStatusSkipped, result.Steps[0].Status)
+	assert.ErrorIs(t, result.Errors(), context.Canceled)
+}
+
+func TestExecutorRunInterruptedStillCleansUp(t *testing.T) {
+	registry := testRegistry("initial-access", "persistence")
+	scenario, err := Parse([]byte(`
+steps:
+  - technique: initial-access
+    cleanup: end
+  - technique: persistence
+    cleanup: after-step
+    depends_on: [initial-access]
+`))
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var events []string
+	var mutex sync.Mutex
+	state := stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold)
+	factory := func(ctx context.Context, technique *stratus.AttackTechnique, force bool, opts ...runner.RunnerOption) runner.Runner {
+		// Ctrl+C while the first step detonates
+		return &fakeRunner{ctx: ctx, technique: technique.ID, events: &events, mutex: &mutex, state: &state, onDetonate: cancel}
+	}
+
+	result, err := NewExecutor(scenario, WithRegistry(registry), WithRunnerFactory(factory)).Run(ctx)
+	require.Nil(t, err)
+
+	assert.Equal(t, StepStatusFailed, result.Steps[0].Status)
+	assert.ErrorIs(t, result.Steps[0].Error, context.Canceled)
+	assert.NoError(t, result.Steps[0].CleanupError)
+	assert.Equal(t, StepStatusSkipped, result.Steps[1].Status)
+	assert.Equal(t, []string{"detonate initial-access", "cleanup initial-access"}, events)
+}
+
+func TestExecutorCleansUpAfterStepWithoutForce(t *testing.T) {
+	registry := testRegistry("initial-access", "persistence")
+	scenario, err := Parse([]byte(`
+steps:
+  - technique: initial-access
+    cleanup: after-step
+    force: true
+  - technique: persistence
+    cleanup: after-step
+    force: true
+    depends_on: []
+`))
+	require.Nil(t, err)
+
+	var events []string
+	var mutex sync.Mutex
+	forces := map[string][]bool{}
+	states := map[string]*stratus.AttackTechniqueState{}
+	factory := func(ctx context.Context, technique *stratus.AttackTechnique, force bool, opts ...runner.RunnerOption) runner.Runner {
+		mutex.Lock()
+		defer mutex.Unlock()
+		forces[technique.ID] = append(forces[technique.ID], force)
+		if states[technique.ID] == nil {
+			cold := stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold)
+			states[technique.ID] = &cold
+		}
+		fake := &fakeRunner{ctx: ctx, technique: technique.ID, events: &events, mutex: &mutex, state: states[technique.ID]}
+		if technique.ID == "initial-access" {
+			// Fails to warm up, leaving nothing to clean up
+			fake.onDetonate = func() { *fake.state = stratus.AttackTechniqueStatusCold }
+			fake.detonateErr = errors.New("access denied")
+		}
+		return fake
+	}
+
+	result, err := NewExecutor(scenario, WithRegistry(registry), WithRunnerFactory(factory)).Run(context.Background())
+	require.Nil(t, err)
+
+	assert.Equal(t, StepStatusFailed, result.Steps[0].Status)
+	assert.NotContains(t, events, "cleanup initial-access", "a COLD technique is not cleaned up")
+	assert.Contains(t, events, "cleanup persistence")
+	assert.Equal(t, map[string][]bool{"initial-access": {true, false}, "persistence": {true, false}}, forces, "only detonations are forced")
+}
+
+// resolveCorrelationOption returns the correlation ID a set of runner options resolves to.
+func resolveCorrelationOption(opts []runner.RunnerOption) string {
+	stateManager := new(statemocks.StateManager)
+	stateManager.On("GetWorkingDirectory").Return("")
+	stateManager.On("GetTechniqueState").Return(stratus.AttackTechniqueState(stratus.AttackTechniqueStatusCold))
+	opts = append([]runner.RunnerOption{
+		runner.WithStateManager(stateManager),
+		runner.WithTerraformManager(new(runnermocks.TerraformManager)),
+		runner.WithConfig(new(configmocks.Config)),
+	}, opts...)
+	return runner.NewRunner(&stratus.AttackTechnique{ID: "test.scenario"}, false, opts...).GetUniqueExecutionId()
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation is a lifecycle operation that runs as a job.
+type Operation string
+
+const (
+	OperationWarmUp   Operation = "warmup"
+	OperationDetonate Operation = "detonate"
+	OperationRevert   Operation = "revert"
+	OperationCleanUp  Operation = "cleanup"
+)
+
+var operations = []Operation{OperationWarmUp, OperationDetonate, OperationRevert, OperationCleanUp}
+
+// JobStatus is the status of a job.
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusSucceeded JobStatus = "SUCCEEDED"
+	JobStatusFailed    JobStatus = "FAILED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+)
+
+// Job is an operation run asynchronously on an attack technique.
+type Job struct {
+	ID            string            `json:"id"`
+	Technique     string            `json:"technique"`
+	Operation     Operation         `json:"operation"`
+	CorrelationID string            `json:"correlationId"`
+	Force         bool              `json:"force"`
+	Status        JobStatus         `json:"status"`
+	Error         string            `json:"error,omitempty"`
+	Outputs       map[string]string `json:"outputs,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	FinishedAt    *time.Time        `json:"finishedAt,omitempty"`
+}
+
+// LogEntry is a log record emitted while a job was running.
+type LogEntry struct {
+	Time       time.Time      `json:"time"`
+	Level      string         `json:"level"`
+	Message    string         `json:"message"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// job tracks a Job while it runs, along with its logs.
+type job struct {
+	lock   sync.Mutex
+	job    Job
+	logs   []LogEntry
+	cancel context.CancelFunc
+	// changed is closed, then replaced, whenever the job logs something or finishes
+	changed chan struct{}
+	done    chan struct{}
+}
+
+func newJob(technique string, operation Operation, correlationID uuid.UUID, force bool) *job {
+	return &job{
+		job: Job{
+			ID:            uuid.New().String(),
+			Technique:     technique,
+			Operation:     operation,
+			CorrelationID: correlationID.String(),
+			Force:         force,
+			Status:        JobStatusRunning,
+			CreatedAt:     time.Now(),
+		},
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// snapshot returns a copy of the job that is safe to serialize while it runs.
+func (m *job) snapshot() Job {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.job
+}
+
+func (m *job) appendLog(entry LogEntry) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.logs = append(m.logs, entry)
+	m.notify()
+}
+
+// logsSince returns the log entries from index offset, whether the job is finished, and a
+// channel closed on the next change.
+func (m *job) logsSince(offset int) ([]LogEntry, bool, <-chan struct{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	finished := m.job.Status != JobStatusRunning
+	if offset >= len(m.logs) {
+		return nil, finished, m.changed
+	}
+	return append([]LogEntry(nil), m.logs[offset:]...), finished, m.changed
+}
+
+func (m *job) finish(status JobStatus, outputs map[string]string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	now := time.Now()
+	m.job.Status = status
+	m.job.Outputs = outputs
+	m.job.FinishedAt = &now
+	if err != nil {
+		m.job.Error = err.Error()
+	}
+	m.notify()
+	close(m.done)
+}
+
+// notify wakes up the readers of the job logs. The caller must hold the lock.
+func (m *job) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// DefaultJobRetention is how long finished jobs and their logs are kept, unless WithJobRetention is set.
+const DefaultJobRetention = 24 * time.Hour
+
+// maxFinishedJobs bounds how many finished jobs are kept, whatever their age, so that a burst of jobs
+// cannot exhaust the memory of the server.
+const maxFinishedJobs = 1000
+
+// jobStore holds the jobs that are running, or that finished within the retention.
+type jobStore struct {
+	lock      sync.RWMutex
+	retention time.Duration
+	jobs      map[string]*job
+	// ordered lists the jobs by creation time
+	ordered []*job
+}
+
+func newJobStore(retention time.Duration) *jobStore {
+	return &jobStore{retention: retention, jobs: map[string]*job{}}
+}
+
+func (m *jobStore) add(j *job) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.prune(time.Now())
+	m.jobs[j.job.ID] = j
+	m.ordered = append(m.ordered, j)
+}
+
+// prune forgets the jobs that finished before the retention, and the oldest finished jobs beyond
+// maxFinishedJobs. Running jobs are always kept. The caller must hold the lock.
+func (m *jobStore) prune(now time.Time) {
+	finished := 0
+	for _, j := range m.ordered {
+		if j.snapshot().FinishedAt != nil {
+			finished++
+		}
+	}
+	kept := m.ordered[:0]
+	for _, j := range m.ordered {
+		finishedAt := j.snapshot().FinishedAt
+		if finishedAt != nil && (now.Sub(*finishedAt) > m.retention || finished > maxFinishedJobs) {
+			delete(m.jobs, j.job.ID)
+			finished--
+			continue
+		}
+		kept = append(kept, j)
+	}
+	clear(m.ordered[len(kept):])
+	m.ordered = kept
+}
+
+func (m *jobStore) get(id string) *job {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.jobs[id]
+}
+
+func (m *jobStore) list() []*job {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return append([]*job(nil), m.ordered...)
+}
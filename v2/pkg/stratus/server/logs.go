@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// jobQueue runs jobs one at a time. The Stratus logger is process-global (see pkg/stratus/log), so a
+// record cannot be attributed to the job that emitted it if several jobs run concurrently, and the logs
+// of a team's job could leak the output of another team's, e.g. the credentials a technique prints.
+type jobQueue struct {
+	// slot is held by the running job
+	slot chan struct{}
+	// current is the running job, or nil
+	current atomic.Pointer[job]
+}
+
+func newJobQueue() *jobQueue {
+	return &jobQueue{slot: make(chan struct{}, 1)}
+}
+
+// run waits for the running job to finish, and then runs fn on behalf of j. It returns the error of ctx
+// without running fn if ctx is cancelled while waiting.
+func (m *jobQueue) run(ctx context.Context, j *job, fn func()) error {
+	select {
+	case m.slot <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	m.current.Store(j)
+	defer func() {
+		m.current.Store(nil)
+		<-m.slot
+	}()
+	fn()
+	return nil
+}
+
+// jobLogHandler forwards records to the logger the server replaced, and appends them to the logs
+// of the job running at that time.
+type jobLogHandler struct {
+	next  slog.Handler
+	queue *jobQueue
+	attrs []slog.Attr
+}
+
+func (h *jobLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || h.next.Enabled(ctx, level)
+}
+
+func (h *jobLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var err error
+	if h.next.Enabled(ctx, record.Level) {
+		err = h.next.Handle(ctx, record)
+	}
+	if record.Level < slog.LevelInfo {
+		return err
+	}
+
+	running := h.queue.current.Load()
+	if running == nil {
+		return err
+	}
+	entry := LogEntry{Time: record.Time, Level: record.Level.String(), Message: record.Message}
+	addAttribute := func(attr slog.Attr) bool {
+		if entry.Attributes == nil {
+			entry.Attributes = map[string]any{}
+		}
+		entry.Attributes[attr.Key] = attr.Value.Resolve().Any()
+		return true
+	}
+	for _, attr := range h.attrs {
+		addAttribute(attr)
+	}
+	record.Attrs(addAttribute)
+	running.appendLog(entry)
+	return err
+}
+
+func (h *jobLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &jobLogHandler{
+		next:  h.next.WithAttrs(attrs),
+		queue: h.queue,
+		attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...),
+	}
+}
+
+// WithGroup only groups the attributes of the records forwarded to the replaced logger, job logs stay flat.
+func (h *jobLogHandler) WithGroup(name string) slog.Handler {
+	return &jobLogHandler{next: h.next.WithGroup(name), queue: h.queue, attrs: h.attrs}
+}
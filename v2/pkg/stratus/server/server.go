@@ -0,0 +1,447 @@
+// Package server exposes the lifecycle of attack techniques over an HTTP API, so that Stratus
+// Red Team can run as a service shared by several teams.
+//
+// Lifecycle operations (warm-up, detonation, reversion, cleanup) run as asynchronous jobs. Each job
+// has its own correlation ID, which isolates its state from the other executions of the technique,
+// unless the request reuses the correlation ID of a previous job to act on the same execution.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/internal/state"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/google/uuid"
+)
+
+// RunnerFactory builds the runner of a job. It defaults to runner.NewRunnerWithContext.
+type RunnerFactory func(ctx context.Context, technique *stratus.AttackTechnique, force bool, opts ...runner.RunnerOption) runner.Runner
+
+// ServerOption configures optional settings on a Server.
+type ServerOption func(*Server)
+
+// WithRunnerOptions passes additional options to the runner of every job, for instance a config.
+// The correlation ID of the job always takes precedence over WithCorrelationID.
+func WithRunnerOptions(opts ...runner.RunnerOption) ServerOption {
+	return func(s *Server) { s.runnerOptions = append(s.runnerOptions, opts...) }
+}
+
+// WithStateBackend stores the state of every job in backend, and reads the status of executions from it.
+// State is stored on the local filesystem otherwise.
+func WithStateBackend(backend runner.StateBackend) ServerOption {
+	return func(s *Server) {
+		s.stateBackend = &backend
+		s.runnerOptions = append(s.runnerOptions, runner.WithStateBackend(backend))
+	}
+}
+
+// WithRunnerFactory overrides how runners are built.
+func WithRunnerFactory(factory RunnerFactory) ServerOption {
+	return func(s *Server) { s.runnerFactory = factory }
+}
+
+// WithRegistry overrides the registry used to resolve techniques, which defaults to stratus.GetRegistry().
+func WithRegistry(registry *stratus.Registry) ServerOption {
+	return func(s *Server) { s.registry = registry }
+}
+
+// WithBearerToken requires every request to carry an "Authorization: Bearer <token>" header.
+func WithBearerToken(token string) ServerOption {
+	return func(s *Server) { s.bearerToken = token }
+}
+
+// WithJobRetention sets how long finished jobs and their logs are kept, which defaults to DefaultJobRetention.
+func WithJobRetention(retention time.Duration) ServerOption {
+	return func(s *Server) { s.jobs.retention = retention }
+}
+
+// Server runs lifecycle operations on attack techniques on behalf of HTTP clients.
+type Server struct {
+	registry      *stratus.Registry
+	runnerFactory RunnerFactory
+	runnerOptions []runner.RunnerOption
+	stateBackend  *runner.StateBackend
+	bearerToken   string
+	jobs          *jobStore
+	queue         *jobQueue
+	// ctx is the parent of the context of every job, cancelled by Close
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	previousLogger *slog.Logger
+}
+
+// NewServer builds a server. It captures the Stratus logs, to expose them in the logs of the
+// jobs, until Close is called.
+func NewServer(opts ...ServerOption) *Server {
+	server := &Server{
+		registry:      stratus.GetRegistry(),
+		runnerFactory: runner.NewRunnerWithContext,
+		jobs:          newJobStore(DefaultJobRetention),
+		queue:         newJobQueue(),
+	}
+	for _, opt := range opts {
+		opt(server)
+	}
+	server.ctx, server.cancel = context.WithCancel(context.Background())
+	server.previousLogger = log.Logger()
+	log.SetLogger(slog.New(&jobLogHandler{next: server.previousLogger.Handler(), queue: server.queue}))
+	return server
+}
+
+// Close cancels the jobs in flight, waits for them to return, and restores the Stratus logger.
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+	log.SetLogger(s.previousLogger)
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/techniques", s.handleListTechniques)
+	mux.HandleFunc("GET /v1/techniques/{id}", s.handleGetTechnique)
+	mux.HandleFunc("GET /v1/techniques/{id}/status", s.handleGetStatus)
+	mux.HandleFunc("POST /v1/techniques/{id}/{operation}", s.handleStartJob)
+	mux.HandleFunc("GET /v1/jobs", s.handleListJobs)
+	mux.HandleFunc("GET /v1/jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("DELETE /v1/jobs/{id}", s.handleCancelJob)
+	mux.HandleFunc("GET /v1/jobs/{id}/logs", s.handleGetJobLogs)
+	if s.bearerToken == "" {
+		return mux
+	}
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + s.bearerToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Technique is the representation of an attack technique in the API.
+type Technique struct {
+	ID                    string                      `json:"id"`
+	Name                  string                      `json:"name"`
+	Platform              string                      `json:"platform"`
+	MitreAttackTactics    []string                    `json:"mitreAttackTactics"`
+	MitreAttackTechniques []string                    `json:"mitreAttackTechniques"`
+	FrameworkMappings     []stratus.FrameworkMappings `json:"frameworkMappings,omitempty"`
+	IsSlow                bool                        `json:"isSlow"`
+	IsIdempotent          bool                        `json:"isIdempotent"`
+	Description           string                      `json:"description,omitempty"`
+	Detection             string                      `json:"detection,omitempty"`
+}
+
+func newTechnique(technique *stratus.AttackTechnique) Technique {
+	tactics := []string{}
+	for _, tactic := range technique.MitreAttackTactics {
+		tactics = append(tactics, mitreattack.AttackTacticToString(tactic))
+	}
+	return Technique{
+		ID:                    technique.ID,
+		Name:                  technique.FriendlyName,
+		Platform:              string(technique.Platform),
+		MitreAttackTactics:    tactics,
+		MitreAttackTechniques: technique.MitreAttackTechniques,
+		FrameworkMappings:     technique.FrameworkMappings,
+		IsSlow:                technique.IsSlow,
+		IsIdempotent:          technique.IsIdempotent,
+	}
+}
+
+// Status is the state of an execution of an attack technique.
+type Status struct {
+	ID               string            `json:"id"`
+	CorrelationID    string            `json:"correlationId,omitempty"`
+	State            string            `json:"state"`
+	TerraformOutputs map[string]string `json:"terraformOutputs"`
+}
+
+// JobRequest is the optional body of the requests starting a job.
+type JobRequest struct {
+	// CorrelationID of a previous job, to act on the same execution. A new one is generated when empty.
+	CorrelationID string `json:"correlationId"`
+	Force         bool   `json:"force"`
+}
+
+func (s *Server) handleListTechniques(w http.ResponseWriter, r *http.Request) {
+	filter := stratus.AttackTechniqueFilter{}
+	if platform := r.URL.Query().Get("platform"); platform != "" {
+		parsed, err := stratus.PlatformFromString(platform)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.Platform = parsed
+	}
+	if tactic := r.URL.Query().Get("mitre-attack-tactic"); tactic != "" {
+		parsed, err := mitreattack.AttackTacticFromString(tactic)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.Tactic = parsed
+	}
+	if id := r.URL.Query().Get("mitre-attack-technique"); id != "" {
+		parsed, err := mitreattack.AttackTechniqueFromID(id)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.MitreAttackTechnique = parsed.ID
+	}
+	result := []Technique{}
+	for _, technique := range s.registry.GetAttackTechniques(&filter) {
+		result = append(result, newTechnique(technique))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleGetTechnique(w http.ResponseWriter, r *http.Request) {
+	technique, ok := s.resolveTechnique(w, r)
+	if !ok {
+		return
+	}
+	result := newTechnique(technique)
+	result.Description = technique.Description
+	result.Detection = technique.Detection
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleGetStatus reports the state of an execution, from the state backend of the server.
+func (s *Server) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	technique, ok := s.resolveTechnique(w, r)
+	if !ok {
+		return
+	}
+	stateOpts := []state.ManagerOption{state.WithReadOnlyState()}
+	correlationID := r.URL.Query().Get("correlationId")
+	if correlationID != "" {
+		parsed, err := uuid.Parse(correlationID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid correlation ID: %w", err))
+			return
+		}
+		stateOpts = append(stateOpts, state.WithExecutionSubdirectory(parsed.String()))
+	}
+	stateManager, err := s.newStateManager(technique, stateOpts...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	techniqueState := stateManager.GetTechniqueState()
+	if techniqueState == "" {
+		techniqueState = stratus.AttackTechniqueStatusCold
+	}
+	outputs, err := stateManager.GetTerraformOutputs()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Status{
+		ID:               technique.ID,
+		CorrelationID:    correlationID,
+		State:            string(techniqueState),
+		TerraformOutputs: outputs,
+	})
+}
+
+// newStateManager returns the state manager of a technique in the state backend of the server, or else
+// on the local filesystem.
+func (s *Server) newStateManager(technique *stratus.AttackTechnique, opts ...state.ManagerOption) (state.StateManager, error) {
+	if s.stateBackend == nil {
+		return state.NewFileSystemStateManager(technique, opts...), nil
+	}
+	return s.stateBackend.NewStateManager(technique, opts...)
+}
+
+func (s *Server) handleStartJob(w http.ResponseWriter, r *http.Request) {
+	technique, ok := s.resolveTechnique(w, r)
+	if !ok {
+		return
+	}
+	operation := Operation(r.PathValue("operation"))
+	if !slices.Contains(operations, operation) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown operation %q", operation))
+		return
+	}
+
+	var request JobRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+	correlationID := uuid.New()
+	if request.CorrelationID != "" {
+		parsed, err := uuid.Parse(request.CorrelationID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid correlation ID: %w", err))
+			return
+		}
+		correlationID = parsed
+	}
+
+	j := s.startJob(technique, operation, correlationID, request.Force)
+	w.Header().Set("Location", "/v1/jobs/"+j.job.ID)
+	writeJSON(w, http.StatusAccepted, j.snapshot())
+}
+
+func (s *Server) startJob(technique *stratus.AttackTechnique, operation Operation, correlationID uuid.UUID, force bool) *job {
+	j := newJob(technique.ID, operation, correlationID, force)
+	ctx, cancel := context.WithCancel(s.ctx)
+	j.cancel = cancel
+	s.jobs.add(j)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		var outputs map[string]string
+		var err error
+		status := JobStatusCancelled
+		// Jobs run one at a time, so that their logs only hold their own records
+		if queueErr := s.queue.run(ctx, j, func() {
+			log.Infof("Starting job %s: %s %s with correlation ID %s", j.job.ID, operation, technique.ID, correlationID)
+			opts := append(slices.Clone(s.runnerOptions), runner.WithCorrelationID(correlationID))
+			stratusRunner := s.runnerFactory(ctx, technique, force, opts...)
+			outputs, err = runOperation(stratusRunner, operation)
+
+			status = JobStatusSucceeded
+			switch {
+			case err != nil && ctx.Err() != nil:
+				status = JobStatusCancelled
+			case err != nil:
+				status = JobStatusFailed
+			}
+			log.Infof("Job %s finished with status %s", j.job.ID, status)
+		}); queueErr != nil {
+			err = queueErr
+		}
+		j.finish(status, outputs, err)
+	}()
+	return j
+}
+
+func runOperation(stratusRunner runner.Runner, operation Operation) (map[string]string, error) {
+	switch operation {
+	case OperationWarmUp:
+		return stratusRunner.WarmUp()
+	case OperationDetonate:
+		return nil, stratusRunner.Detonate()
+	case OperationRevert:
+		return nil, stratusRunner.Revert()
+	case OperationCleanUp:
+		return nil, stratusRunner.CleanUp()
+	default:
+		return nil, fmt.Errorf("unknown operation %q", operation)
+	}
+}
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	result := []Job{}
+	for _, j := range s.jobs.list() {
+		result = append(result, j.snapshot())
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	if j, ok := s.resolveJob(w, r); ok {
+		writeJSON(w, http.StatusOK, j.snapshot())
+	}
+}
+
+// handleCancelJob cancels the context of the runner of a job. The job reports CANCELLED once the
+// runner returns.
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if j, ok := s.resolveJob(w, r); ok {
+		j.cancel()
+		writeJSON(w, http.StatusAccepted, j.snapshot())
+	}
+}
+
+// handleGetJobLogs streams the logs of a job as newline-delimited JSON, until the job finishes.
+// With ?follow=false, it only returns the logs emitted so far.
+func (s *Server) handleGetJobLogs(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.resolveJob(w, r)
+	if !ok {
+		return
+	}
+	follow := r.URL.Query().Get("follow") != "false"
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	offset := 0
+	for {
+		entries, finished, changed := j.logsSince(offset)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return
+			}
+		}
+		offset += len(entries)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if finished || !follow {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) resolveTechnique(w http.ResponseWriter, r *http.Request) (*stratus.AttackTechnique, bool) {
+	technique := s.registry.GetAttackTechniqueByName(r.PathValue("id"))
+	if technique == nil {
+		writeError(w, http.StatusNotFound, errors.New("unknown technique name "+r.PathValue("id")))
+		return nil, false
+	}
+	return technique, true
+}
+
+func (s *Server) resolveJob(w http.ResponseWriter, r *http.Request) (*job, bool) {
+	j := s.jobs.get(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, errors.New("unknown job "+r.PathValue("id")))
+		return nil, false
+	}
+	return j, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Warnf("unable to write the HTTP response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
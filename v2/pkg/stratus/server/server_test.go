@@ -0,0 +1,337 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/log"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/runner"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner detonates by logging, and blocks until its context is cancelled when told to.
+type fakeRunner struct {
+	ctx   context.Context
+	block bool
+}
+
+func (m *fakeRunner) WarmUp() (map[string]string, error) {
+	log.Println("Warming up")
+	return map[string]string{"bucket_name": "my-bucket"}, nil
+}
+
+func (m *fakeRunner) Plan() ([]stratus.PlannedResource, error) { return nil, nil }
+
+func (m *fakeRunner) Detonate() error {
+	log.Println("Detonating")
+	if m.block {
+		<-m.ctx.Done()
+		return m.ctx.Err()
+	}
+	return nil
+}
+
+func (m *fakeRunner) Revert() error  { return errors.New("not revertible") }
+func (m *fakeRunner) CleanUp() error { return nil }
+func (m *fakeRunner) GetState() stratus.AttackTechniqueState {
+	return stratus.AttackTechniqueStatusCold
+}
+func (m *fakeRunner) GetUniqueExecutionId() string { return "" }
+
+func newTestServer(t *testing.T, block bool, opts ...ServerOption) *httptest.Server {
+	registry := stratus.NewRegistry()
+	registry.RegisterAttackTechnique(&stratus.AttackTechnique{
+		ID:                    "aws.test.technique",
+		FriendlyName:          "Test technique",
+		Platform:              stratus.AWS,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.Persistence},
+		MitreAttackTechniques: []string{"T1098.001"},
+		Detection:             "Look for it",
+	})
+	factory := func(ctx context.Context, technique *stratus.AttackTechnique, force bool, opts ...runner.RunnerOption) runner.Runner {
+		return &fakeRunner{ctx: ctx, block: block}
+	}
+	server := NewServer(append([]ServerOption{WithRegistry(&registry), WithRunnerFactory(factory)}, opts...)...)
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Close()
+	})
+	return httpServer
+}
+
+func request(t *testing.T, method string, url string, body string, result any) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.Nil(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	if result != nil {
+		require.Nil(t, json.NewDecoder(resp.Body).Decode(result))
+	}
+	return resp.StatusCode
+}
+
+// waitForJob polls a job until it finishes.
+func waitForJob(t *testing.T, url string) Job {
+	var job Job
+	require.Eventually(t, func() bool {
+		request(t, http.MethodGet, url, "", &job)
+		return job.Status != JobStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestServerListsTechniques(t *testing.T) {
+	server := newTestServer(t, false)
+
+	var techniques []Technique
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques?platform=aws", "", &techniques))
+	require.Len(t, techniques, 1)
+	assert.Equal(t, "aws.test.technique", techniques[0].ID)
+	assert.Equal(t, []string{"Persistence"}, techniques[0].MitreAttackTactics)
+	assert.Empty(t, techniques[0].Detection)
+
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques?platform=gcp", "", &techniques))
+	assert.Empty(t, techniques)
+	assert.Equal(t, http.StatusBadRequest, request(t, http.MethodGet, server.URL+"/v1/techniques?platform=foo", "", nil))
+
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques?mitre-attack-technique=T1098", "", &techniques))
+	require.Len(t, techniques, 1)
+	assert.Equal(t, []string{"T1098.001"}, techniques[0].MitreAttackTechniques)
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques?mitre-attack-technique=T1562.008", "", &techniques))
+	assert.Empty(t, techniques)
+	assert.Equal(t, http.StatusBadRequest, request(t, http.MethodGet, server.URL+"/v1/techniques?mitre-attack-technique=TA0003", "", nil))
+
+	var technique Technique
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques/aws.test.technique", "", &technique))
+	assert.Equal(t, "Look for it", technique.Detection)
+	assert.Equal(t, http.StatusNotFound, request(t, http.MethodGet, server.URL+"/v1/techniques/aws.unknown", "", nil))
+}
+
+func TestServerRunsJobs(t *testing.T) {
+	server := newTestServer(t, false)
+
+	var job Job
+	status := request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/warmup", "", &job)
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, OperationWarmUp, job.Operation)
+	assert.NotEmpty(t, job.CorrelationID, "every job should get its own correlation ID")
+
+	job = waitForJob(t, server.URL+"/v1/jobs/"+job.ID)
+	assert.Equal(t, JobStatusSucceeded, job.Status)
+	assert.Equal(t, map[string]string{"bucket_name": "my-bucket"}, job.Outputs)
+
+	// A job can act on the execution of a previous one
+	correlationID := job.CorrelationID
+	status = request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/revert", `{"correlationId": "`+correlationID+`"}`, &job)
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, correlationID, job.CorrelationID)
+	job = waitForJob(t, server.URL+"/v1/jobs/"+job.ID)
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Equal(t, "not revertible", job.Error)
+
+	var jobs []Job
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/jobs", "", &jobs))
+	assert.Len(t, jobs, 2)
+}
+
+func TestServerRejectsInvalidJobs(t *testing.T) {
+	server := newTestServer(t, false)
+
+	assert.Equal(t, http.StatusNotFound, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/explode", "", nil))
+	assert.Equal(t, http.StatusNotFound, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.unknown/detonate", "", nil))
+	assert.Equal(t, http.StatusBadRequest, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/detonate", `{"correlationId": "foo"}`, nil))
+	assert.Equal(t, http.StatusNotFound, request(t, http.MethodGet, server.URL+"/v1/jobs/foo", "", nil))
+}
+
+func TestServerCancelsJobs(t *testing.T) {
+	server := newTestServer(t, true)
+
+	var job Job
+	require.Equal(t, http.StatusAccepted, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/detonate", "", &job))
+	assert.Equal(t, http.StatusAccepted, request(t, http.MethodDelete, server.URL+"/v1/jobs/"+job.ID, "", nil))
+
+	job = waitForJob(t, server.URL+"/v1/jobs/"+job.ID)
+	assert.Equal(t, JobStatusCancelled, job.Status)
+	assert.Equal(t, context.Canceled.Error(), job.Error)
+}
+
+func TestServerStreamsJobLogs(t *testing.T) {
+	server := newTestServer(t, true)
+
+	var job Job
+	require.Equal(t, http.StatusAccepted, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/detonate", "", &job))
+	resp, err := http.Get(server.URL + "/v1/jobs/" + job.ID + "/logs")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	// Logs are streamed while the job runs, and the stream ends with the job
+	lines := bufio.NewScanner(resp.Body)
+	var messages []string
+	for lines.Scan() {
+		var entry LogEntry
+		require.Nil(t, json.Unmarshal(lines.Bytes(), &entry))
+		messages = append(messages, entry.Message)
+		if entry.Message == "Detonating" {
+			request(t, http.MethodDelete, server.URL+"/v1/jobs/"+job.ID, "", nil)
+		}
+	}
+	require.Nil(t, lines.Err())
+	assert.Contains(t, messages, "Detonating")
+	assert.Contains(t, messages[len(messages)-1], "finished with status CANCELLED")
+}
+
+// jobMessages returns the messages logged by a job so far
+func jobMessages(t *testing.T, url string) []string {
+	resp, err := http.Get(url + "/logs?follow=false")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	var messages []string
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		var entry LogEntry
+		require.Nil(t, json.Unmarshal(lines.Bytes(), &entry))
+		messages = append(messages, entry.Message)
+	}
+	require.Nil(t, lines.Err())
+	return messages
+}
+
+func TestServerKeepsJobLogsApart(t *testing.T) {
+	server := newTestServer(t, true)
+
+	var detonation, warmUp, cancelled Job
+	require.Equal(t, http.StatusAccepted, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/detonate", "", &detonation))
+	require.Eventually(t, func() bool {
+		return slices.Contains(jobMessages(t, server.URL+"/v1/jobs/"+detonation.ID), "Detonating")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, http.StatusAccepted, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/warmup", "", &warmUp))
+	require.Equal(t, http.StatusAccepted, request(t, http.MethodPost, server.URL+"/v1/techniques/aws.test.technique/warmup", "", &cancelled))
+
+	// Jobs wait for the running one, and can be cancelled before they start
+	request(t, http.MethodDelete, server.URL+"/v1/jobs/"+cancelled.ID, "", nil)
+	assert.Equal(t, JobStatusCancelled, waitForJob(t, server.URL+"/v1/jobs/"+cancelled.ID).Status)
+	request(t, http.MethodGet, server.URL+"/v1/jobs/"+warmUp.ID, "", &warmUp)
+	assert.Equal(t, JobStatusRunning, warmUp.Status)
+	assert.Empty(t, jobMessages(t, server.URL+"/v1/jobs/"+warmUp.ID))
+	request(t, http.MethodDelete, server.URL+"/v1/jobs/"+detonation.ID, "", nil)
+	assert.Equal(t, JobStatusSucceeded, waitForJob(t, server.URL+"/v1/jobs/"+warmUp.ID).Status)
+
+	detonationMessages := jobMessages(t, server.URL+"/v1/jobs/"+detonation.ID)
+	assert.Contains(t, detonationMessages, "Detonating")
+	assert.NotContains(t, detonationMessages, "Warming up")
+	warmUpMessages := jobMessages(t, server.URL+"/v1/jobs/"+warmUp.ID)
+	assert.Contains(t, warmUpMessages, "Warming up")
+	assert.NotContains(t, warmUpMessages, "Detonating")
+	assert.Empty(t, jobMessages(t, server.URL+"/v1/jobs/"+cancelled.ID))
+}
+
+// fakeGCSBucket serves the objects of a GCS bucket, for the storage client pointed at it by STORAGE_EMULATOR_HOST
+type fakeGCSBucket struct {
+	name    string
+	objects map[string]string
+}
+
+func (m *fakeGCSBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	object, found := m.objects[strings.TrimPrefix(r.URL.Path, "/"+m.name+"/")]
+	if r.Method != http.MethodGet || !found {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = io.WriteString(w, object)
+}
+
+func TestServerReadsStatusFromStateBackend(t *testing.T) {
+	correlationID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	bucket := httptest.NewServer(&fakeGCSBucket{name: "stratus-state", objects: map[string]string{
+		"team/aws.test.technique/" + correlationID.String() + "/state":        "WARM",
+		"team/aws.test.technique/" + correlationID.String() + "/outputs.json": `{"bucket_name":"my-bucket"}`,
+	}})
+	t.Cleanup(bucket.Close)
+	t.Setenv("STORAGE_EMULATOR_HOST", bucket.URL)
+	server := newTestServer(t, false, WithStateBackend(runner.StateBackend{
+		GCS: &runner.GCSBackendConfig{BucketName: "stratus-state", KeyPrefix: "team/"},
+	}))
+
+	var status Status
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques/aws.test.technique/status?correlationId="+correlationID.String(), "", &status))
+	assert.Equal(t, "WARM", status.State)
+	assert.Equal(t, map[string]string{"bucket_name": "my-bucket"}, status.TerraformOutputs)
+
+	var otherStatus Status
+	assert.Equal(t, http.StatusOK, request(t, http.MethodGet, server.URL+"/v1/techniques/aws.test.technique/status?correlationId="+uuid.NewString(), "", &otherStatus))
+	assert.Equal(t, "COLD", otherStatus.State)
+	assert.Empty(t, otherStatus.TerraformOutputs)
+}
+
+func TestServerRequiresBearerToken(t *testing.T) {
+	server := newTestServer(t, false, WithBearerToken("s3cr3t"))
+
+	assert.Equal(t, http.StatusUnauthorized, request(t, http.MethodGet, server.URL+"/v1/techniques", "", nil))
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/techniques", nil)
+	require.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestJobStoreForgetsFinishedJobs(t *testing.T) {
+	store := newJobStore(time.Hour)
+	finishedAgo := func(age time.Duration) *job {
+		j := newJob("foo", OperationDetonate, uuid.New(), false)
+		j.finish(JobStatusSucceeded, nil, nil)
+		finishedAt := time.Now().Add(-age)
+		j.job.FinishedAt = &finishedAt
+		return j
+	}
+	expired := finishedAgo(2 * time.Hour)
+	recent := finishedAgo(time.Minute)
+	running := newJob("foo", OperationWarmUp, uuid.New(), false)
+	running.job.CreatedAt = time.Now().Add(-48 * time.Hour)
+	store.add(expired)
+	store.add(running)
+	store.add(recent)
+
+	store.add(newJob("foo", OperationCleanUp, uuid.New(), false))
+
+	assert.Nil(t, store.get(expired.job.ID), "jobs are forgotten once they finished before the retention")
+	assert.Same(t, running, store.get(running.job.ID), "running jobs are kept, however old")
+	assert.Same(t, recent, store.get(recent.job.ID))
+	assert.Len(t, store.list(), 3)
+}
+
+func TestJobStoreBoundsFinishedJobs(t *testing.T) {
+	store := newJobStore(DefaultJobRetention)
+	var jobs []*job
+	for i := 0; i < maxFinishedJobs+10; i++ {
+		j := newJob("foo", OperationDetonate, uuid.New(), false)
+		j.finish(JobStatusSucceeded, nil, nil)
+		store.add(j)
+		jobs = append(jobs, j)
+	}
+
+	store.add(newJob("foo", OperationDetonate, uuid.New(), false))
+
+	assert.Len(t, store.list(), maxFinishedJobs+1)
+	assert.Nil(t, store.get(jobs[0].job.ID), "the oldest finished jobs are forgotten first")
+	assert.NotNil(t, store.get(jobs[len(jobs)-1].job.ID))
+}
@@ -0,0 +1,225 @@
+package sigma
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed sigma-detection-rule-schema.json
+var ruleSchemaJSON []byte
+
+// ruleIDNamespace derives stable rule IDs from technique IDs, so that regenerating a rule keeps its ID
+var ruleIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://stratus-red-team.cloud/"))
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// Rule is a Sigma detection rule, see https://github.com/SigmaHQ/sigma-specification
+type Rule struct {
+	Title          string    `yaml:"title"`
+	ID             string    `yaml:"id"`
+	Status         string    `yaml:"status"`
+	Description    string    `yaml:"description"`
+	References     []string  `yaml:"references"`
+	Author         string    `yaml:"author"`
+	Date           string    `yaml:"date"`
+	Tags           []string  `yaml:"tags"`
+	LogSource      LogSource `yaml:"logsource"`
+	Detection      Detection `yaml:"detection"`
+	FalsePositives []string  `yaml:"falsepositives"`
+	Level          string    `yaml:"level"`
+}
+
+type LogSource struct {
+	Product string `yaml:"product"`
+	Service string `yaml:"service"`
+}
+
+// Detection holds the named selections of a rule and the condition combining them
+type Detection struct {
+	Selections []Selection
+	Condition  string
+}
+
+type Selection struct {
+	Name   string
+	Fields stratus.DetectionSelection
+}
+
+// logSources maps the logs in which techniques are visible to their Sigma log source
+var logSources = map[stratus.LogSource]LogSource{
+	stratus.LogSourceCloudTrail:          {Product: "aws", Service: "cloudtrail"},
+	stratus.LogSourceGCPAuditLogs:        {Product: "gcp", Service: "gcp.audit"},
+	stratus.LogSourceAzureActivityLogs:   {Product: "azure", Service: "activitylogs"},
+	stratus.LogSourceEntraIDAuditLogs:    {Product: "azure", Service: "auditlogs"},
+	stratus.LogSourceKubernetesAuditLogs: {Product: "kubernetes", Service: "audit"},
+}
+
+// NewRule generates a baseline Sigma rule from the detection rule of a technique, dated date
+func NewRule(technique *stratus.AttackTechnique, date time.Time) (*Rule, error) {
+	detectionRule := technique.DetectionRule
+	if detectionRule == nil || len(detectionRule.Selections) == 0 {
+		return nil, fmt.Errorf("%s has no detection rule", technique.ID)
+	}
+	logSource, found := logSources[detectionRule.GetLogSource(technique.Platform)]
+	if !found {
+		return nil, fmt.Errorf("%s: unsupported log source %q", technique.ID, detectionRule.GetLogSource(technique.Platform))
+	}
+
+	docsURL := fmt.Sprintf("https://stratus-red-team.cloud/attack-techniques/%s/%s/", technique.Platform, technique.ID)
+	rule := &Rule{
+		Title:       technique.FriendlyName,
+		ID:          uuid.NewSHA1(ruleIDNamespace, []byte(technique.ID)).String(),
+		Status:      "experimental",
+		Description: summarize(technique),
+		References:  []string{docsURL},
+		Author:      "Stratus Red Team",
+		Date:        date.Format(time.DateOnly),
+		LogSource:   logSource,
+		Detection:   Detection{Condition: "selection"},
+		Level:       string(detectionRule.GetLevel()),
+	}
+	if rule.Title == "" {
+		rule.Title = technique.ID
+	}
+
+	for _, tactic := range technique.MitreAttackTactics {
+		name := mitreattack.AttackTacticToString(tactic)
+		rule.Tags = append(rule.Tags, "attack."+strings.ReplaceAll(strings.ToLower(name), " ", "-"))
+	}
+	mitreTechniques, err := technique.GetMitreAttackTechniques()
+	if err != nil {
+		return nil, err
+	}
+	for _, mitreTechnique := range mitreTechniques {
+		rule.Tags = append(rule.Tags, "attack."+strings.ToLower(mitreTechnique.ID))
+	}
+
+	for i, fields := range detectionRule.Selections {
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s: selection %d of the detection rule is empty", technique.ID, i+1)
+		}
+		name := "selection"
+		if len(detectionRule.Selections) > 1 {
+			name = fmt.Sprintf("selection_%d", i+1)
+			rule.Detection.Condition = "1 of selection_*"
+		}
+		rule.Detection.Selections = append(rule.Detection.Selections, Selection{Name: name, Fields: fields})
+	}
+
+	rule.FalsePositives = detectionRule.FalsePositives
+	if len(rule.FalsePositives) == 0 {
+		rule.FalsePositives = []string{"Unknown"}
+	}
+	return rule, nil
+}
+
+// summarize returns the first paragraph of the description of a technique, on a single line
+func summarize(technique *stratus.AttackTechnique) string {
+	for _, paragraph := range strings.Split(strings.TrimSpace(technique.Description), "\n\n") {
+		if summary := strings.TrimSpace(whitespace.ReplaceAllString(paragraph, " ")); summary != "" {
+			return summary
+		}
+	}
+	return "Detects the Stratus Red Team attack technique " + technique.ID
+}
+
+// MarshalYAML writes the selections in order, followed by the condition
+func (d Detection) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, selection := range d.Selections {
+		fields := &yaml.Node{Kind: yaml.MappingNode}
+		names := make([]string, 0, len(selection.Fields))
+		for name := range selection.Fields {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			values := selection.Fields[name]
+			if len(values) == 0 {
+				return nil, fmt.Errorf("field %s of %s has no value", name, selection.Name)
+			}
+			var value *yaml.Node
+			if len(values) == 1 {
+				value = stringNode(values[0])
+			} else {
+				value = &yaml.Node{Kind: yaml.SequenceNode}
+				for _, v := range values {
+					value.Content = append(value.Content, stringNode(v))
+				}
+			}
+			fields.Content = append(fields.Content, stringNode(name), value)
+		}
+		node.Content = append(node.Content, stringNode(selection.Name), fields)
+	}
+	node.Content = append(node.Content, stringNode("condition"), stringNode(d.Condition))
+	return node, nil
+}
+
+func stringNode(value string) *yaml.Node {
+	// Tagging values as strings keeps e.g. "true" or "0.0.0.0/0" from being read back as other types
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// Marshal writes a rule as YAML
+func (r *Rule) Marshal() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(4)
+	if err := encoder.Encode(r); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Validate validates a YAML Sigma rule against the JSON schema of Sigma detection rules
+func Validate(rawRule []byte) error {
+	var rule any
+	if err := yaml.Unmarshal(rawRule, &rule); err != nil {
+		return fmt.Errorf("parsing rule YAML: %w", err)
+	}
+	if rule == nil {
+		return errors.New("empty rule")
+	}
+	// Round-trip through JSON to normalize yaml.v3 types into JSON-compatible types
+	jsonBytes, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("normalizing rule to JSON: %w", err)
+	}
+	var jsonData any
+	if err := json.Unmarshal(jsonBytes, &jsonData); err != nil {
+		return fmt.Errorf("parsing normalized rule JSON: %w", err)
+	}
+
+	schema, err := jsonschema.UnmarshalJSON(bytes.NewReader(ruleSchemaJSON))
+	if err != nil {
+		return fmt.Errorf("parsing Sigma rule schema: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("sigma-detection-rule-schema.json", schema); err != nil {
+		return fmt.Errorf("adding schema resource: %w", err)
+	}
+	compiled, err := compiler.Compile("sigma-detection-rule-schema.json")
+	if err != nil {
+		return fmt.Errorf("compiling Sigma rule schema: %w", err)
+	}
+	if err := compiled.Validate(jsonData); err != nil {
+		return fmt.Errorf("Sigma rule validation failed: %w", err)
+	}
+	return nil
+}
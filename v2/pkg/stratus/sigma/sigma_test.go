@@ -0,0 +1,142 @@
+package sigma
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var date = time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+var stopTrail = &stratus.AttackTechnique{
+	ID:                    "aws.defense-evasion.cloudtrail-stop",
+	FriendlyName:          "Stop CloudTrail Trail",
+	Platform:              stratus.AWS,
+	MitreAttackTactics:    []mitreattack.Tactic{mitreattack.DefenseEvasion},
+	MitreAttackTechniques: []string{"T1562.008"},
+	Description: `
+Stops a CloudTrail Trail from logging. Simulates an attacker disrupting CloudTrail logging.
+
+Warm-up:
+
+- Create a CloudTrail Trail.
+`,
+	DetectionRule: &stratus.DetectionRule{
+		Selections: []stratus.DetectionSelection{
+			{"eventSource": {"cloudtrail.amazonaws.com"}, "eventName": {"StopLogging"}},
+		},
+		Level: stratus.DetectionLevelHigh,
+	},
+}
+
+func TestNewRuleForCloudTrail(t *testing.T) {
+	rule, err := NewRule(stopTrail, date)
+	require.NoError(t, err)
+	raw, err := rule.Marshal()
+	require.NoError(t, err)
+
+	assert.Equal(t, `title: Stop CloudTrail Trail
+id: `+rule.ID+`
+status: experimental
+description: Stops a CloudTrail Trail from logging. Simulates an attacker disrupting CloudTrail logging.
+references:
+    - https://stratus-red-team.cloud/attack-techniques/AWS/aws.defense-evasion.cloudtrail-stop/
+author: Stratus Red Team
+date: "2026-10-18"
+tags:
+    - attack.defense-evasion
+    - attack.t1562.008
+logsource:
+    product: aws
+    service: cloudtrail
+detection:
+    selection:
+        eventName: StopLogging
+        eventSource: cloudtrail.amazonaws.com
+    condition: selection
+falsepositives:
+    - Unknown
+level: high
+`, string(raw))
+	assert.NoError(t, Validate(raw))
+
+	again, err := NewRule(stopTrail, date.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, rule.ID, again.ID, "rule IDs should be stable across generations")
+}
+
+func TestNewRuleForKubernetesWithSeveralSelections(t *testing.T) {
+	technique := &stratus.AttackTechnique{
+		ID:                    "k8s.privilege-escalation.privileged-pod",
+		FriendlyName:          "Run a Privileged Pod",
+		Platform:              stratus.Kubernetes,
+		MitreAttackTactics:    []mitreattack.Tactic{mitreattack.PrivilegeEscalation},
+		MitreAttackTechniques: []string{"T1610", "T1611"},
+		DetectionRule: &stratus.DetectionRule{
+			Selections: []stratus.DetectionSelection{
+				{"verb": {"create"}, "objectRef.resource": {"pods"}, "requestObject.spec.containers.securityContext.privileged": {"true"}},
+				{"verb": {"create"}, "objectRef.resource": {"pods"}, "requestObject.spec.initContainers.securityContext.privileged": {"true"}},
+			},
+			FalsePositives: []string{"Node agents such as CNI plugins"},
+		},
+	}
+
+	rule, err := NewRule(technique, date)
+	require.NoError(t, err)
+	assert.Equal(t, LogSource{Product: "kubernetes", Service: "audit"}, rule.LogSource)
+	assert.Equal(t, "1 of selection_*", rule.Detection.Condition)
+	assert.Equal(t, "selection_2", rule.Detection.Selections[1].Name)
+	assert.Equal(t, []string{"attack.privilege-escalation", "attack.t1610", "attack.t1611"}, rule.Tags)
+	assert.Equal(t, "medium", rule.Level)
+	assert.Equal(t, "Detects the Stratus Red Team attack technique k8s.privilege-escalation.privileged-pod", rule.Description)
+
+	raw, err := rule.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `requestObject.spec.containers.securityContext.privileged: "true"`)
+	assert.NoError(t, Validate(raw))
+}
+
+func TestNewRuleUsesLogSourceOverride(t *testing.T) {
+	technique := &stratus.AttackTechnique{
+		ID:       "eks.persistence.backdoor-aws-auth-configmap",
+		Platform: stratus.EKS,
+		DetectionRule: &stratus.DetectionRule{
+			LogSource:  stratus.LogSourceKubernetesAuditLogs,
+			Selections: []stratus.DetectionSelection{{"objectRef.name": {"aws-auth"}}},
+		},
+	}
+	rule, err := NewRule(technique, date)
+	require.NoError(t, err)
+	assert.Equal(t, "kubernetes", rule.LogSource.Product)
+	assert.Equal(t, technique.ID, rule.Title)
+}
+
+func TestNewRuleRequiresDetectionRule(t *testing.T) {
+	_, err := NewRule(&stratus.AttackTechnique{ID: "foo", Platform: stratus.AWS}, date)
+	assert.ErrorContains(t, err, "foo has no detection rule")
+
+	_, err = NewRule(&stratus.AttackTechnique{
+		ID:            "foo",
+		Platform:      stratus.AWS,
+		DetectionRule: &stratus.DetectionRule{Selections: []stratus.DetectionSelection{{}}},
+	}, date)
+	assert.ErrorContains(t, err, "selection 1 of the detection rule is empty")
+}
+
+func TestValidateRejectsInvalidRules(t *testing.T) {
+	assert.ErrorContains(t, Validate([]byte("title: foo\nlogsource:\n    product: aws\n")), "validation failed")
+	assert.ErrorContains(t, Validate([]byte(`title: foo
+logsource:
+    product: aws
+detection:
+    selection:
+        eventName: StopLogging
+    condition: selection
+level: urgent
+`)), "validation failed")
+	assert.Error(t, Validate([]byte("")))
+}
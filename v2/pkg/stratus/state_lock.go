@@ -0,0 +1,64 @@
+package stratus
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StateLock is an advisory lock on the state of a technique execution, held while a command changes it
+// so that two operators sharing a state backend cannot warm up and clean up the same technique at once.
+//
+// The lock expires after its TTL, so that a crashed command does not lock the state forever.
+type StateLock struct {
+	// ID identifies this acquisition, so that a command only ever releases the lock it acquired
+	ID         string    `json:"id"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewStateLock builds a lock for owner, expiring after ttl.
+func NewStateLock(owner string, ttl time.Duration) StateLock {
+	now := time.Now().UTC()
+	return StateLock{
+		ID:         uuid.New().String(),
+		Owner:      owner,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+}
+
+// IsExpired indicates if the lock can be broken without asking its owner.
+func (m *StateLock) IsExpired(now time.Time) bool {
+	return !now.Before(m.ExpiresAt)
+}
+
+// DefaultStateLockOwner identifies the current operator, as user@hostname.
+func DefaultStateLockOwner() string {
+	username := "unknown"
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return username + "@" + hostname
+}
+
+// StateLockedError is returned when acquiring a lock that another owner holds and that has not expired.
+type StateLockedError struct {
+	TechniqueID string
+	Lock        StateLock
+}
+
+func (m *StateLockedError) Error() string {
+	return fmt.Sprintf(
+		"the state of %s is locked by %s since %s (expires at %s). If it is stale, break it with 'stratus unlock %s'",
+		m.TechniqueID, m.Lock.Owner, m.Lock.AcquiredAt.Format(time.RFC3339), m.Lock.ExpiresAt.Format(time.RFC3339), m.TechniqueID,
+	)
+}
@@ -0,0 +1,63 @@
+package stratustest
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/cassette"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// ReplayCassettes runs the detonation and reversion of a technique offline, against the cassettes recorded
+// for it under directory with STRATUS_RECORD, see the cassette package. The test fails if the technique
+// does not make the recorded calls in the same order, or does not fail like it did when recorded.
+// It returns whether any cassette was found.
+func ReplayCassettes(t testing.TB, technique *stratus.AttackTechnique, directory string) bool {
+	t.Helper()
+	operations := []struct {
+		name string
+		run  stratus.TechniqueFunc
+	}{
+		{cassette.OperationDetonate, technique.GetDetonate()},
+		{cassette.OperationRevert, technique.GetRevert()},
+	}
+	found := false
+	for _, operation := range operations {
+		path := cassette.Path(directory, technique.ID, operation.name)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) || operation.run == nil {
+			continue
+		}
+		found = true
+		replay(t, technique, path, operation.run)
+	}
+	return found
+}
+
+func replay(t testing.TB, technique *stratus.AttackTechnique, path string, run stratus.TechniqueFunc) {
+	t.Helper()
+	recorded, err := cassette.Load(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	parameters, err := technique.ResolveParameters(recorded.Parameters)
+	if !assert.NoError(t, err, "parameters of %s", path) {
+		return
+	}
+	ctx := stratus.ContextWithParameters(context.Background(), parameters)
+	player := cassette.NewPlayer(recorded.Interactions)
+	providers := cassette.NewReplayingProviders(uuid.New(), recorded.Environment, player)
+
+	err = run(ctx, recorded.Outputs, providers)
+
+	assert.NoError(t, player.Err(), "replaying %s", path)
+	if recorded.Error != "" {
+		assert.Error(t, err, "replaying %s, which recorded a failure: %s", path, recorded.Error)
+	} else {
+		assert.NoError(t, err, "replaying %s", path)
+	}
+}
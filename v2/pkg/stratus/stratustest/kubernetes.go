@@ -0,0 +1,38 @@
+package stratustest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// KubernetesActions returns the actions performed against the fake Kubernetes clientset with verb on
+// resource, e.g. KubernetesActions("create", "clusterrolebindings")
+func (m *CloudProviders) KubernetesActions(verb string, resource string) []k8stesting.Action {
+	var actions []k8stesting.Action
+	for _, action := range m.Kubernetes.Actions() {
+		if action.Matches(verb, resource) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// AssertKubernetesAction asserts that the fake Kubernetes clientset was called with verb on resource
+func (m *CloudProviders) AssertKubernetesAction(t testing.TB, verb string, resource string) bool {
+	t.Helper()
+	if len(m.KubernetesActions(verb, resource)) > 0 {
+		return true
+	}
+	var performed []string
+	for _, action := range m.Kubernetes.Actions() {
+		performed = append(performed, fmt.Sprintf("  %s %s", action.GetVerb(), action.GetResource().Resource))
+	}
+	if len(performed) == 0 {
+		performed = append(performed, "  (no action)")
+	}
+	return assert.Fail(t, fmt.Sprintf("expected a Kubernetes action %s %s, got:\n%s", verb, resource, strings.Join(performed, "\n")))
+}
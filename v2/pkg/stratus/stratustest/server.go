@@ -0,0 +1,316 @@
+package stratustest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// originalHostHeader holds the host a request was sent to, before being redirected to the fake server
+	originalHostHeader = "X-Stratus-Original-Host"
+	// operationHeader holds the name of the operation of AWS requests
+	operationHeader = "X-Stratus-Operation"
+)
+
+// awsSigningScope extracts the service from the credential scope of SigV4 signatures, e.g.
+// "Credential=AKIA.../20240101/us-east-1/cloudtrail/aws4_request"
+var awsSigningScope = regexp.MustCompile(`Credential=[^/]+/[^/]+/[^/]+/([^/]+)/aws4_request`)
+
+// Call is a request received by the fake cloud APIs
+type Call struct {
+	// Service is the signing name of AWS services, e.g. "cloudtrail", and the host of other APIs,
+	// e.g. "compute.googleapis.com", "management.azure.com" or "graph.microsoft.com"
+	Service string
+	// Operation is the name of the operation of AWS requests, e.g. "StopLogging", and empty otherwise
+	Operation string
+	Method    string
+	Path      string
+	Query     url.Values
+	Header    http.Header
+	Body      []byte
+}
+
+// Action identifies a call in assertions and stubs: "<service>:<operation>" for AWS calls, e.g.
+// "cloudtrail:StopLogging", and "<method> <host><path>" otherwise, e.g.
+// "DELETE management.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Authorization/locks/lock"
+func (m Call) Action() string {
+	if m.Operation != "" {
+		return m.Service + ":" + m.Operation
+	}
+	return m.Method + " " + m.Service + m.Path
+}
+
+// Param returns a parameter of the call, looked up in its form-encoded body, its query string, and then
+// in its JSON body, where nested fields are separated by dots, e.g. "properties.displayName"
+func (m Call) Param(name string) (string, bool) {
+	if form, err := url.ParseQuery(string(m.Body)); err == nil && form.Has(name) &&
+		strings.HasPrefix(m.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return form.Get(name), true
+	}
+	if m.Query.Has(name) {
+		return m.Query.Get(name), true
+	}
+	var value any
+	if err := json.Unmarshal(m.Body, &value); err != nil {
+		return "", false
+	}
+	for _, key := range strings.Split(name, ".") {
+		object, isObject := value.(map[string]any)
+		if !isObject {
+			return "", false
+		}
+		if value, isObject = object[key]; !isObject {
+			return "", false
+		}
+	}
+	if text, isString := value.(string); isString {
+		return text, true
+	}
+	raw, _ := json.Marshal(value)
+	return string(raw), true
+}
+
+func (m Call) isAWSJSON() bool {
+	return m.Header.Get("X-Amz-Target") != "" || strings.Contains(m.Header.Get("Content-Type"), "json")
+}
+
+// CallMatcher restricts the calls an assertion considers
+type CallMatcher func(call Call) bool
+
+// WithParam matches calls having a parameter with the given value, see Call.Param
+func WithParam(name string, value string) CallMatcher {
+	return func(call Call) bool {
+		actual, found := call.Param(name)
+		return found && actual == value
+	}
+}
+
+// Response is the response of the fake cloud APIs to a call
+type Response struct {
+	StatusCode int
+	Header     map[string]string
+	Body       string
+}
+
+// JSONResponse returns a successful response with value as JSON body
+func JSONResponse(value any) Response {
+	body, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return Response{StatusCode: http.StatusOK, Header: map[string]string{"Content-Type": "application/json"}, Body: string(body)}
+}
+
+// Handler computes the response to a call
+type Handler func(call Call) Response
+
+type stub struct {
+	pattern string
+	handler Handler
+}
+
+// Server is an in-process fake of the HTTP APIs of AWS, GCP, Azure and Microsoft Graph, which records the
+// calls it receives. Unless stubbed, it answers calls with an empty successful response.
+type Server struct {
+	server *httptest.Server
+	mutex  sync.Mutex
+	calls  []Call
+	stubs  []stub
+}
+
+func newServer() *Server {
+	s := &Server{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL is the address of the fake server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the fake server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Transport redirects requests to the fake server, whatever API they are sent to
+func (s *Server) Transport() http.RoundTripper {
+	target, _ := url.Parse(s.server.URL)
+	return &redirectTransport{target: target, base: s.server.Client().Transport}
+}
+
+// Handle answers the calls whose action matches pattern with handler. Patterns are matched with
+// path.Match, e.g. "ec2:Describe*". Later stubs take precedence over earlier ones.
+func (s *Server) Handle(pattern string, handler Handler) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stubs = append(s.stubs, stub{pattern: pattern, handler: handler})
+}
+
+// Respond answers the calls whose action matches pattern with response
+func (s *Server) Respond(pattern string, response Response) {
+	s.Handle(pattern, func(Call) Response { return response })
+}
+
+// RespondWithAWSError answers the AWS calls whose action matches pattern with an error, e.g.
+// RespondWithAWSError("iam:GetUser", 404, "NoSuchEntity", "user not found")
+func (s *Server) RespondWithAWSError(pattern string, statusCode int, code string, message string) {
+	s.Handle(pattern, func(call Call) Response {
+		switch {
+		case call.isAWSJSON():
+			return Response{StatusCode: statusCode, Header: map[string]string{"Content-Type": "application/x-amz-json-1.1", "X-Amzn-ErrorType": code},
+				Body: fmt.Sprintf(`{"__type": %q, "message": %q}`, code, message)}
+		case call.Service == "ec2":
+			return Response{StatusCode: statusCode, Header: map[string]string{"Content-Type": "text/xml"},
+				Body: fmt.Sprintf(`<Response><Errors><Error><Code>%s</Code><Message>%s</Message></Error></Errors></Response>`, code, message)}
+		default:
+			return Response{StatusCode: statusCode, Header: map[string]string{"Content-Type": "text/xml"},
+				Body: fmt.Sprintf(`<ErrorResponse><Error><Code>%s</Code><Message>%s</Message></Error></ErrorResponse>`, code, message)}
+		}
+	})
+}
+
+// Calls returns the calls received so far, in order
+func (s *Server) Calls() []Call {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Call{}, s.calls...)
+}
+
+// CallsTo returns the calls whose action matches pattern, and all of the matchers
+func (s *Server) CallsTo(pattern string, matchers ...CallMatcher) []Call {
+	var calls []Call
+	for _, call := range s.Calls() {
+		if matched, _ := path.Match(pattern, call.Action()); !matched {
+			continue
+		}
+		matchesAll := true
+		for _, matcher := range matchers {
+			matchesAll = matchesAll && matcher(call)
+		}
+		if matchesAll {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// AssertCalled asserts that a call matching pattern and all of the matchers was received, e.g.
+// AssertCalled(t, "cloudtrail:StopLogging", WithParam("Name", "my-trail"))
+func (s *Server) AssertCalled(t testing.TB, pattern string, matchers ...CallMatcher) bool {
+	t.Helper()
+	if len(s.CallsTo(pattern, matchers...)) > 0 {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("expected a call to %s, got:\n%s", pattern, s.describeCalls()))
+}
+
+// AssertNotCalled asserts that no call matching pattern and all of the matchers was received
+func (s *Server) AssertNotCalled(t testing.TB, pattern string, matchers ...CallMatcher) bool {
+	t.Helper()
+	if len(s.CallsTo(pattern, matchers...)) == 0 {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("expected no call to %s, got:\n%s", pattern, s.describeCalls()))
+}
+
+func (s *Server) describeCalls() string {
+	calls := s.Calls()
+	if len(calls) == 0 {
+		return "  (no call)"
+	}
+	lines := make([]string, 0, len(calls))
+	for _, call := range calls {
+		lines = append(lines, "  "+call.Action())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	call := Call{
+		Service:   r.Header.Get(originalHostHeader),
+		Operation: r.Header.Get(operationHeader),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Query:     r.URL.Query(),
+		Header:    r.Header.Clone(),
+		Body:      body,
+	}
+	if match := awsSigningScope.FindStringSubmatch(r.Header.Get("Authorization")); match != nil {
+		call.Service = match[1]
+	}
+
+	s.mutex.Lock()
+	s.calls = append(s.calls, call)
+	handler := defaultHandler
+	for i := len(s.stubs) - 1; i >= 0; i-- {
+		if matched, _ := path.Match(s.stubs[i].pattern, call.Action()); matched {
+			handler = s.stubs[i].handler
+			break
+		}
+	}
+	s.mutex.Unlock()
+
+	response := handler(call)
+	for name, value := range response.Header {
+		w.Header().Set(name, value)
+	}
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write([]byte(response.Body))
+}
+
+// defaultHandler answers with an empty successful response, which SDKs decode as an empty output
+func defaultHandler(call Call) Response {
+	switch {
+	case call.Operation == "":
+		return JSONResponse(map[string]any{})
+	case call.isAWSJSON():
+		return Response{StatusCode: http.StatusOK, Header: map[string]string{"Content-Type": "application/x-amz-json-1.1"}, Body: "{}"}
+	default:
+		return Response{StatusCode: http.StatusOK}
+	}
+}
+
+// redirectTransport sends requests to the fake server, remembering the host they were sent to
+type redirectTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *redirectTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	redirected := request.Clone(request.Context())
+	if request.Body != nil {
+		// Cloning shares the body, which the caller may close or rewind on retries
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		_ = request.Body.Close()
+		redirected.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	redirected.Header.Set(originalHostHeader, request.URL.Host)
+	redirected.URL.Scheme = t.target.Scheme
+	redirected.URL.Host = t.target.Host
+	redirected.Host = t.target.Host
+	return t.base.RoundTrip(redirected)
+}
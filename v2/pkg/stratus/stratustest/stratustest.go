@@ -0,0 +1,242 @@
+// Package stratustest provides fake cloud providers to unit-test attack techniques without cloud accounts.
+//
+// The AWS, GCP, Azure and Microsoft Graph clients of the fake providers send their requests to an in-process
+// HTTP server recording them, and answering them with stubbed responses. The Kubernetes clients are
+// client-go fake clientsets. For instance:
+//
+//	providers := stratustest.New(t)
+//	err := technique.GetDetonate()(ctx, map[string]string{"cloudtrail_trail_name": "my-trail"}, providers)
+//	require.NoError(t, err)
+//	providers.Server.AssertCalled(t, "cloudtrail:StopLogging", stratustest.WithParam("Name", "my-trail"))
+package stratustest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// AccountID is the AWS account ID of the fake AWS provider
+	AccountID = "123456789012"
+	// DefaultAWSRegion is the region of the fake AWS provider, unless overridden with WithAWSRegion
+	DefaultAWSRegion = "us-east-1"
+	// DefaultGCPProjectID is the project of the fake GCP provider, unless overridden with WithGCPProjectID
+	DefaultGCPProjectID = "stratus-red-team"
+	// DefaultAzureSubscriptionID is the subscription of the fake Azure provider, unless overridden with
+	// WithAzureSubscriptionID
+	DefaultAzureSubscriptionID = "00000000-0000-0000-0000-000000000000"
+)
+
+// CloudProviders are fake cloud providers, implementing stratus.CloudProviders
+type CloudProviders struct {
+	// Server is the fake AWS, GCP, Azure and Microsoft Graph APIs, to stub responses and assert on calls
+	Server *Server
+	// Kubernetes is the fake clientset of the Kubernetes and EKS providers
+	Kubernetes *fake.Clientset
+
+	correlationID       uuid.UUID
+	awsRegion           string
+	gcpProjectID        string
+	azureSubscriptionID string
+	eksClusterName      string
+	kubernetesObjects   []runtime.Object
+
+	mutex           sync.Mutex
+	awsProvider     *providers.AWSProvider
+	gcpProvider     *providers.GCPProvider
+	azureProvider   *providers.AzureProvider
+	entraIdProvider *providers.EntraIdProvider
+	k8sProvider     *providers.K8sProvider
+	eksProvider     *providers.EKSProvider
+}
+
+var _ stratus.CloudProviders = &CloudProviders{}
+
+// Option configures fake cloud providers
+type Option func(*CloudProviders)
+
+// WithAWSRegion sets the region of the fake AWS provider
+func WithAWSRegion(region string) Option {
+	return func(m *CloudProviders) { m.awsRegion = region }
+}
+
+// WithGCPProjectID sets the project of the fake GCP provider
+func WithGCPProjectID(projectID string) Option {
+	return func(m *CloudProviders) { m.gcpProjectID = projectID }
+}
+
+// WithAzureSubscriptionID sets the subscription of the fake Azure provider
+func WithAzureSubscriptionID(subscriptionID string) Option {
+	return func(m *CloudProviders) { m.azureSubscriptionID = subscriptionID }
+}
+
+// WithEKSClusterName sets the name of the EKS cluster the fake Kubernetes provider is authenticated against
+func WithEKSClusterName(clusterName string) Option {
+	return func(m *CloudProviders) { m.eksClusterName = clusterName }
+}
+
+// WithKubernetesObjects pre-populates the fake Kubernetes clientset with objects
+func WithKubernetesObjects(objects ...runtime.Object) Option {
+	return func(m *CloudProviders) { m.kubernetesObjects = append(m.kubernetesObjects, objects...) }
+}
+
+// New returns fake cloud providers, whose server is shut down at the end of the test
+func New(t testing.TB, opts ...Option) *CloudProviders {
+	m := &CloudProviders{
+		Server:              newServer(),
+		correlationID:       uuid.New(),
+		awsRegion:           DefaultAWSRegion,
+		gcpProjectID:        DefaultGCPProjectID,
+		azureSubscriptionID: DefaultAzureSubscriptionID,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.Kubernetes = fake.NewSimpleClientset(m.kubernetesObjects...)
+	m.Server.Respond("sts:GetCallerIdentity", Response{
+		StatusCode: http.StatusOK,
+		Header:     map[string]string{"Content-Type": "text/xml"},
+		Body: `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><GetCallerIdentityResult>` +
+			`<Arn>arn:aws:iam::` + AccountID + `:user/stratus-red-team</Arn><UserId>AIDASTRATUSREDTEAM</UserId>` +
+			`<Account>` + AccountID + `</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`,
+	})
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// HTTPClient returns an HTTP client sending its requests to the fake server
+func (m *CloudProviders) HTTPClient() *http.Client {
+	return &http.Client{Transport: m.Server.Transport()}
+}
+
+// AWSConfig returns the configuration of the fake AWS provider, with static credentials and no retries
+func (m *CloudProviders) AWSConfig() aws.Config {
+	return aws.Config{
+		Region:      m.awsRegion,
+		Credentials: credentials.NewStaticCredentialsProvider("AKIASTRATUSREDTEAM", "secret", ""),
+		HTTPClient:  m.HTTPClient(),
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+		APIOptions:  []func(*middleware.Stack) error{addOperationHeader},
+	}
+}
+
+func (m *CloudProviders) AWS() *providers.AWSProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.awsProvider == nil {
+		m.awsProvider = providers.NewAWSProvider(m.correlationID, providers.WithAWSConfig(m.AWSConfig()))
+	}
+	return m.awsProvider
+}
+
+func (m *CloudProviders) GCP() *providers.GCPProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.gcpProvider == nil {
+		m.gcpProvider = providers.NewGCPProvider(m.correlationID,
+			providers.WithGCPProjectID(m.gcpProjectID),
+			providers.WithGCPHTTPClient(m.HTTPClient()),
+		)
+	}
+	return m.gcpProvider
+}
+
+func (m *CloudProviders) Azure() *providers.AzureProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.azureProvider == nil {
+		m.azureProvider = providers.NewAzureProvider(m.correlationID,
+			providers.WithAzureSubscriptionID(m.azureSubscriptionID),
+			providers.WithAzureCredentials(fakeCredential{}),
+			providers.WithAzureTransport(m.HTTPClient()),
+		)
+	}
+	return m.azureProvider
+}
+
+func (m *CloudProviders) EntraId() *providers.EntraIdProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.entraIdProvider == nil {
+		m.entraIdProvider = providers.NewEntraIdProvider(m.correlationID,
+			providers.WithEntraIdCredentials(fakeCredential{}),
+			providers.WithEntraIdTransport(m.Server.Transport()),
+		)
+	}
+	return m.entraIdProvider
+}
+
+func (m *CloudProviders) K8s() *providers.K8sProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.k8s()
+}
+
+func (m *CloudProviders) k8s() *providers.K8sProvider {
+	if m.k8sProvider == nil {
+		restConfig := &rest.Config{Host: m.Server.URL()}
+		if m.eksClusterName != "" {
+			// Like the kubeconfig files generated by "aws eks update-kubeconfig"
+			restConfig.ExecProvider = &clientcmdapi.ExecConfig{
+				Command: "aws",
+				Args:    []string{"eks", "get-token", "--cluster-name", m.eksClusterName},
+			}
+		}
+		m.k8sProvider = providers.NewK8sProvider(m.correlationID,
+			providers.WithK8sRestConfig(restConfig),
+			providers.WithK8sClient(m.Kubernetes),
+		)
+	}
+	return m.k8sProvider
+}
+
+func (m *CloudProviders) EKS() *providers.EKSProvider {
+	awsProvider := m.AWS()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.eksProvider == nil {
+		m.eksProvider = providers.NewEKSProvider(m.correlationID,
+			providers.WithEKSAWSProvider(awsProvider),
+			providers.WithEKSK8sProvider(m.k8s()),
+		)
+	}
+	return m.eksProvider
+}
+
+// addOperationHeader passes the name of the operation of AWS requests to the fake server, since it cannot
+// be inferred from requests of all AWS protocols
+func addOperationHeader(stack *middleware.Stack) error {
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("StratusTestOperationHeader", func(
+		ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+	) (middleware.BuildOutput, middleware.Metadata, error) {
+		if request, ok := in.Request.(*smithyhttp.Request); ok {
+			request.Header.Set(operationHeader, awsmiddleware.GetOperationName(ctx))
+		}
+		return next.HandleBuild(ctx, in)
+	}), middleware.After)
+}
+
+// fakeCredential is an Azure credential handing out static tokens
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "stratus-red-team", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
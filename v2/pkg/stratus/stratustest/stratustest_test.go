@@ -0,0 +1,196 @@
+package stratustest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/cassette"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gcpiam "google.golang.org/api/iam/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAWSCallsAreRecorded(t *testing.T) {
+	providers := New(t, WithAWSRegion("eu-west-3"))
+	ctx := context.Background()
+
+	_, err := cloudtrail.NewFromConfig(providers.AWS().GetConnection()).StopLogging(ctx, &cloudtrail.StopLoggingInput{Name: aws.String("my-trail")})
+	require.NoError(t, err)
+	_, err = iam.NewFromConfig(providers.AWS().GetConnection()).CreateUser(ctx, &iam.CreateUserInput{UserName: aws.String("alice")})
+	require.NoError(t, err)
+
+	providers.Server.AssertCalled(t, "cloudtrail:StopLogging", WithParam("Name", "my-trail"))
+	providers.Server.AssertCalled(t, "iam:CreateUser", WithParam("UserName", "alice"))
+	providers.Server.AssertNotCalled(t, "iam:CreateUser", WithParam("UserName", "bob"))
+	providers.Server.AssertNotCalled(t, "cloudtrail:DeleteTrail")
+	assert.Len(t, providers.Server.CallsTo("*:*"), 2)
+	assert.Equal(t, "cloudtrail:StopLogging", providers.Server.Calls()[0].Action())
+	assert.Equal(t, "eu-west-3", providers.AWS().GetConnection().Region)
+}
+
+func TestAWSAccountID(t *testing.T) {
+	providers := New(t)
+
+	accountID, err := providers.AWS().GetAccountID(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, AccountID, accountID)
+}
+
+func TestAWSStubs(t *testing.T) {
+	providers := New(t)
+	ctx := context.Background()
+	providers.Server.RespondWithAWSError("ec2:*", http.StatusBadRequest, "UnauthorizedOperation", "not allowed")
+	providers.Server.RespondWithAWSError("iam:GetUser", http.StatusNotFound, "NoSuchEntity", "no such user")
+	providers.Server.Respond("cloudtrail:DescribeTrails", JSONResponse(map[string]any{
+		"trailList": []map[string]any{{"Name": "my-trail"}},
+	}))
+
+	_, err := ec2.NewFromConfig(providers.AWS().GetConnection()).DescribeInstances(ctx, &ec2.DescribeInstancesInput{})
+	assert.ErrorContains(t, err, "UnauthorizedOperation")
+	_, err = iam.NewFromConfig(providers.AWS().GetConnection()).GetUser(ctx, &iam.GetUserInput{UserName: aws.String("alice")})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+	trails, err := cloudtrail.NewFromConfig(providers.AWS().GetConnection()).DescribeTrails(ctx, &cloudtrail.DescribeTrailsInput{})
+	require.NoError(t, err)
+	require.Len(t, trails.TrailList, 1)
+	assert.Equal(t, "my-trail", *trails.TrailList[0].Name)
+}
+
+func TestGCPCallsAreRecorded(t *testing.T) {
+	providers := New(t, WithGCPProjectID("my-project"))
+	service, err := gcpiam.NewService(context.Background(), providers.GCP().Options())
+	require.NoError(t, err)
+
+	_, err = service.Projects.ServiceAccounts.Create("projects/"+providers.GCP().GetProjectId(), &gcpiam.CreateServiceAccountRequest{
+		AccountId: "stratus",
+	}).Do()
+
+	require.NoError(t, err)
+	providers.Server.AssertCalled(t, "POST iam.googleapis.com/v1/projects/my-project/serviceAccounts", WithParam("accountId", "stratus"))
+}
+
+func TestAzureCallsAreRecorded(t *testing.T) {
+	providers := New(t)
+	azure := providers.Azure()
+	client, err := armresources.NewResourceGroupsClient(azure.SubscriptionID, azure.GetCredentials(), azure.ClientOptions)
+	require.NoError(t, err)
+
+	_, err = client.CreateOrUpdate(context.Background(), "my-group", armresources.ResourceGroup{Location: to.Ptr("westeurope")}, nil)
+
+	require.NoError(t, err)
+	providers.Server.AssertCalled(t, "PUT management.azure.com/subscriptions/"+DefaultAzureSubscriptionID+"/resourcegroups/my-group",
+		WithParam("location", "westeurope"))
+}
+
+func TestEntraIdCallsAreRecorded(t *testing.T) {
+	providers := New(t)
+	providers.Server.Respond("GET graph.microsoft.com/v1.0/users/*", JSONResponse(map[string]any{"displayName": "Alice"}))
+
+	user, err := providers.EntraId().GetGraphClient().Users().ByUserId("alice").Get(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", *user.GetDisplayName())
+	providers.Server.AssertCalled(t, "GET graph.microsoft.com/v1.0/users/alice")
+}
+
+func TestKubernetesClientset(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "stratus"}}
+	providers := New(t, WithKubernetesObjects(namespace), WithEKSClusterName("my-cluster"))
+	ctx := context.Background()
+
+	_, err := providers.K8s().GetClient().CoreV1().Namespaces().Get(ctx, "stratus", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = providers.EKS().GetK8sClient().CoreV1().Pods("stratus").Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	providers.AssertKubernetesAction(t, "create", "pods")
+	assert.Len(t, providers.KubernetesActions("get", "namespaces"), 1)
+	assert.Equal(t, "my-cluster", providers.EKS().GetEKSClusterName())
+	assert.Same(t, providers.AWS(), providers.EKS().GetAWSProvider())
+}
+
+func TestAssertionsReportCalls(t *testing.T) {
+	providers := New(t)
+	_, err := cloudtrail.NewFromConfig(providers.AWS().GetConnection()).StopLogging(context.Background(), &cloudtrail.StopLoggingInput{Name: aws.String("my-trail")})
+	require.NoError(t, err)
+
+	recorder := &failureRecorder{}
+	assert.False(t, providers.Server.AssertCalled(recorder, "cloudtrail:StopLogging", WithParam("Name", "other-trail")))
+	assert.False(t, providers.Server.AssertNotCalled(recorder, "cloudtrail:Stop*"))
+	assert.False(t, providers.AssertKubernetesAction(recorder, "delete", "pods"))
+	require.Len(t, recorder.failures, 3)
+	assert.Contains(t, recorder.failures[0], "expected a call to cloudtrail:StopLogging")
+	assert.Contains(t, recorder.failures[2], "(no action)")
+}
+
+// failureRecorder records the failures of assertions, instead of failing the test
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (m *failureRecorder) Helper() {}
+
+func (m *failureRecorder) Name() string { return "failureRecorder" }
+
+func (m *failureRecorder) Errorf(format string, args ...any) {
+	m.failures = append(m.failures, fmt.Sprintf(format, args...))
+}
+
+func TestReplayCassettes(t *testing.T) {
+	technique := &stratus.AttackTechnique{
+		ID: "test.stop-trail",
+		DetonateWithContext: func(ctx context.Context, params map[string]string, providers stratus.CloudProviders) error {
+			client := cloudtrail.NewFromConfig(providers.AWS().GetConnection())
+			_, err := client.StopLogging(ctx, &cloudtrail.StopLoggingInput{Name: aws.String(params["trail_name"])})
+			return err
+		},
+	}
+	recordCassette := func(directory string, target string) {
+		recorded := cassette.Cassette{
+			TechniqueID: technique.ID,
+			Operation:   cassette.OperationDetonate,
+			Environment: cassette.Environment{AWSRegion: "eu-west-1"},
+			Outputs:     map[string]string{"trail_name": "my-trail"},
+			Interactions: []cassette.Interaction{{
+				Request: cassette.Request{
+					Method:  http.MethodPost,
+					URL:     "https://cloudtrail.eu-west-1.amazonaws.com/",
+					Headers: map[string]string{"Content-Type": "application/x-amz-json-1.1", "X-Amz-Target": target},
+				},
+				Response: cassette.Response{StatusCode: http.StatusOK, Body: "{}"},
+			}},
+		}
+		require.NoError(t, recorded.Save(cassette.Path(directory, technique.ID, cassette.OperationDetonate)))
+	}
+
+	t.Run("passes when the technique makes the recorded calls", func(t *testing.T) {
+		directory := t.TempDir()
+		recordCassette(directory, "CloudTrail_20131101.StopLogging")
+		assert.True(t, ReplayCassettes(t, technique, directory))
+	})
+
+	t.Run("fails when the calls of the technique changed", func(t *testing.T) {
+		directory := t.TempDir()
+		recordCassette(directory, "CloudTrail_20131101.DeleteTrail")
+		recorder := &failureRecorder{}
+		assert.True(t, ReplayCassettes(recorder, technique, directory))
+		require.NotEmpty(t, recorder.failures)
+		assert.Contains(t, recorder.failures[0], "expected POST cloudtrail.eu-west-1.amazonaws.com/ CloudTrail_20131101.DeleteTrail")
+	})
+
+	t.Run("reports missing cassettes", func(t *testing.T) {
+		assert.False(t, ReplayCassettes(t, technique, t.TempDir()))
+	})
+}
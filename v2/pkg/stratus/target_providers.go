@@ -0,0 +1,91 @@
+package stratus
+
+import (
+	"sync"
+
+	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/google/uuid"
+)
+
+// targetCloudProviders are cloud providers pointed at a target, built from the base ones. Platforms other
+// than the one of the target keep the base providers.
+type targetCloudProviders struct {
+	correlationID uuid.UUID
+	target        config.Target
+	base          CloudProviders
+
+	mutex       sync.Mutex
+	awsProvider *providers.AWSProvider
+	eksProvider *providers.EKSProvider
+}
+
+// NewTargetCloudProviders returns cloud providers calling the APIs of the AWS region, GCP project or Azure
+// subscription of target, using the base providers to assume the AWS role of the target.
+func NewTargetCloudProviders(correlationID uuid.UUID, target config.Target, base CloudProviders) CloudProviders {
+	return &targetCloudProviders{correlationID: correlationID, target: target, base: base}
+}
+
+func (m *targetCloudProviders) AWS() *providers.AWSProvider {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.aws()
+}
+
+func (m *targetCloudProviders) aws() *providers.AWSProvider {
+	if m.target.AWS == nil {
+		return m.base.AWS()
+	}
+	if m.awsProvider == nil {
+		provider := m.base.AWS()
+		if m.target.AWS.Region != "" {
+			provider = provider.InRegion(m.target.AWS.Region)
+		}
+		if m.target.AWS.RoleARN != "" {
+			sessionName := "stratus-red-team-" + CorrelationShortID(m.correlationID)
+			provider = provider.AssumeRoles([]string{m.target.AWS.RoleARN}, m.target.AWS.ExternalID, sessionName)
+		}
+		m.awsProvider = provider
+	}
+	return m.awsProvider
+}
+
+func (m *targetCloudProviders) GCP() *providers.GCPProvider {
+	if m.target.GCP == nil {
+		return m.base.GCP()
+	}
+	return m.base.GCP().InProject(m.target.GCP.ProjectID)
+}
+
+func (m *targetCloudProviders) Azure() *providers.AzureProvider {
+	if m.target.Azure == nil {
+		return m.base.Azure()
+	}
+	return m.base.Azure().InSubscription(m.target.Azure.SubscriptionID)
+}
+
+// EntraId returns the base provider, since tenants are not targets
+func (m *targetCloudProviders) EntraId() *providers.EntraIdProvider {
+	return m.base.EntraId()
+}
+
+// K8s returns the base provider, since Kubernetes contexts are not targets
+func (m *targetCloudProviders) K8s() *providers.K8sProvider {
+	return m.base.K8s()
+}
+
+// EKS returns a provider calling AWS in the target, and the cluster of the base provider
+func (m *targetCloudProviders) EKS() *providers.EKSProvider {
+	if m.target.AWS == nil {
+		return m.base.EKS()
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.eksProvider == nil {
+		m.eksProvider = providers.NewEKSProvider(m.correlationID,
+			providers.WithEKSAWSProvider(m.aws()),
+			providers.WithEKSK8sProvider(m.base.K8s()),
+		)
+	}
+	return m.eksProvider
+}
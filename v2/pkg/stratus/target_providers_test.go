@@ -0,0 +1,68 @@
+package stratus_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/stratustest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetCloudProviders(t *testing.T) {
+	t.Run("assumes the role of an AWS target in its region", func(t *testing.T) {
+		base := stratustest.New(t)
+		base.Server.Respond("sts:AssumeRole", stratustest.Response{
+			StatusCode: http.StatusOK,
+			Header:     map[string]string{"Content-Type": "text/xml"},
+			Body: `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult><Credentials>` +
+				`<AccessKeyId>ASIATARGET</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+				`<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`,
+		})
+		target := stratus.NewTargetCloudProviders(uuid.New(), config.Target{
+			Name: "production",
+			AWS:  &config.AWSTarget{RoleARN: "arn:aws:iam::123456789012:role/stratus", ExternalID: "external", Region: "eu-west-3"},
+		}, base)
+
+		assert.Equal(t, "eu-west-3", target.AWS().GetConnection().Region)
+		_, err := sts.NewFromConfig(target.AWS().GetConnection()).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+		require.NoError(t, err)
+
+		base.Server.AssertCalled(t, "sts:AssumeRole",
+			stratustest.WithParam("RoleArn", "arn:aws:iam::123456789012:role/stratus"),
+			stratustest.WithParam("ExternalId", "external"),
+		)
+		calls := base.Server.CallsTo("sts:GetCallerIdentity")
+		require.Len(t, calls, 1)
+		assert.Contains(t, calls[0].Header.Get("Authorization"), "Credential=ASIATARGET/")
+		assert.Contains(t, calls[0].Header.Get("Authorization"), "/eu-west-3/sts/")
+		assert.Same(t, target.AWS(), target.AWS(), "the role is assumed once")
+		assert.Equal(t, "eu-west-3", target.EKS().GetAWSProvider().GetConnection().Region)
+	})
+
+	t.Run("points at the project of a GCP target", func(t *testing.T) {
+		base := stratustest.New(t)
+		target := stratus.NewTargetCloudProviders(uuid.New(), config.Target{Name: "sandbox", GCP: &config.GCPTarget{ProjectID: "sandbox"}}, base)
+
+		assert.Equal(t, "sandbox", target.GCP().GetProjectId())
+		assert.Equal(t, stratustest.DefaultGCPProjectID, base.GCP().GetProjectId(), "the base provider is unchanged")
+		assert.Same(t, base.AWS(), target.AWS())
+		assert.Same(t, base.Azure(), target.Azure())
+	})
+
+	t.Run("points at the subscription of an Azure target", func(t *testing.T) {
+		base := stratustest.New(t)
+		subscriptionID := "45e0ad3f-ff94-499a-a2f0-bbb884e9c4a3"
+		target := stratus.NewTargetCloudProviders(uuid.New(), config.Target{Name: "azure", Azure: &config.AzureTarget{SubscriptionID: subscriptionID}}, base)
+
+		assert.Equal(t, subscriptionID, target.Azure().SubscriptionID)
+		assert.NotEqual(t, subscriptionID, base.Azure().SubscriptionID, "the base provider is unchanged")
+		assert.Same(t, base.GCP(), target.GCP())
+		assert.Same(t, base.EntraId(), target.EntraId())
+	})
+}
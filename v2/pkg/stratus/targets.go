@@ -0,0 +1,102 @@
+package stratus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/datadog/stratus-red-team/v2/internal/providers"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+)
+
+// TargetKind is the kind of cloud resource that scopes where a technique runs, e.g. an AWS account.
+type TargetKind string
+
+const (
+	TargetKindAWSAccount        TargetKind = "AWS account"
+	TargetKindGCPProject        TargetKind = "GCP project"
+	TargetKindAzureSubscription TargetKind = "Azure subscription"
+	TargetKindEntraIDTenant     TargetKind = "Entra ID tenant"
+	TargetKindKubernetesContext TargetKind = "Kubernetes context"
+)
+
+// Target is an account, project, subscription, tenant or Kubernetes context a technique runs in.
+type Target struct {
+	Kind TargetKind
+	ID   string
+}
+
+func (m Target) String() string {
+	return fmt.Sprintf("%s %q", m.Kind, m.ID)
+}
+
+// ResolveTargets returns the targets that the current identity would run the techniques of a platform in.
+func ResolveTargets(ctx context.Context, platform Platform, providerFactory CloudProviders) ([]Target, error) {
+	switch platform {
+	case AWS:
+		accountID, err := providerFactory.AWS().GetAccountID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve the current AWS account: %w", err)
+		}
+		return []Target{{Kind: TargetKindAWSAccount, ID: accountID}}, nil
+	case GCP:
+		return []Target{{Kind: TargetKindGCPProject, ID: providerFactory.GCP().GetProjectId()}}, nil
+	case Azure:
+		return []Target{{Kind: TargetKindAzureSubscription, ID: providerFactory.Azure().SubscriptionID}}, nil
+	case EntraID:
+		tenantID, err := providerFactory.EntraId().GetTenantId()
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve the current Entra ID tenant: %w", err)
+		}
+		return []Target{{Kind: TargetKindEntraIDTenant, ID: tenantID}}, nil
+	case Kubernetes:
+		kubeContext, err := resolveKubernetesContext()
+		if err != nil {
+			return nil, err
+		}
+		return []Target{kubeContext}, nil
+	case EKS:
+		accountID, err := providerFactory.EKS().GetAWSProvider().GetAccountID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve the current AWS account: %w", err)
+		}
+		kubeContext, err := resolveKubernetesContext()
+		if err != nil {
+			return nil, err
+		}
+		return []Target{{Kind: TargetKindAWSAccount, ID: accountID}, kubeContext}, nil
+	default:
+		return nil, errors.New("unhandled platform " + string(platform))
+	}
+}
+
+func resolveKubernetesContext() (Target, error) {
+	kubeContext, err := providers.GetKubeCurrentContext()
+	if err != nil {
+		return Target{}, fmt.Errorf("unable to resolve the current Kubernetes context: %w", err)
+	}
+	return Target{Kind: TargetKindKubernetesContext, ID: kubeContext}, nil
+}
+
+// CheckAllowedTargets returns an error naming the targets that are not in the allowlist of the
+// configuration file. A nil allowlist allows any target.
+func CheckAllowedTargets(targets []Target, allowed *config.AllowedTargets) error {
+	if allowed == nil {
+		return nil
+	}
+	allowedIDs := map[TargetKind][]string{
+		TargetKindAWSAccount:        allowed.AWSAccounts,
+		TargetKindGCPProject:        allowed.GCPProjects,
+		TargetKindAzureSubscription: allowed.AzureSubscriptions,
+		TargetKindEntraIDTenant:     allowed.EntraIDTenants,
+		TargetKindKubernetesContext: allowed.KubernetesContexts,
+	}
+	var errs []error
+	for _, target := range targets {
+		if !slices.Contains(allowedIDs[target.Kind], target.ID) {
+			errs = append(errs, fmt.Errorf("%s is not in allowed_targets of the configuration file", target))
+		}
+	}
+	return errors.Join(errs...)
+}
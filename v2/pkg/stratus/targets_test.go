@@ -0,0 +1,36 @@
+package stratus
+
+import (
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAllowedTargets(t *testing.T) {
+	allowed := &config.AllowedTargets{
+		AWSAccounts:        []string{"123456789012"},
+		KubernetesContexts: []string{"kind-stratus"},
+	}
+	awsAccount := Target{Kind: TargetKindAWSAccount, ID: "123456789012"}
+	otherAWSAccount := Target{Kind: TargetKindAWSAccount, ID: "210987654321"}
+	kubeContext := Target{Kind: TargetKindKubernetesContext, ID: "kind-stratus"}
+	gcpProject := Target{Kind: TargetKindGCPProject, ID: "my-project"}
+
+	assert.NoError(t, CheckAllowedTargets([]Target{awsAccount}, allowed))
+	assert.NoError(t, CheckAllowedTargets([]Target{awsAccount, kubeContext}, allowed))
+	assert.ErrorContains(t, CheckAllowedTargets([]Target{otherAWSAccount}, allowed), `AWS account "210987654321" is not in allowed_targets`)
+	assert.ErrorContains(t, CheckAllowedTargets([]Target{otherAWSAccount, kubeContext}, allowed), "210987654321")
+
+	// Platforms without any allowed target are refused
+	assert.ErrorContains(t, CheckAllowedTargets([]Target{gcpProject}, allowed), `GCP project "my-project"`)
+
+	// Without allowlist, anything goes
+	assert.NoError(t, CheckAllowedTargets([]Target{gcpProject, otherAWSAccount}, nil))
+}
+
+func TestIsDestructive(t *testing.T) {
+	assert.False(t, AttackTechnique{}.IsDestructive())
+	assert.False(t, AttackTechnique{Impact: ImpactLow}.IsDestructive())
+	assert.True(t, AttackTechnique{Impact: ImpactDestructive}.IsDestructive())
+}
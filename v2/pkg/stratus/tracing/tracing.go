@@ -0,0 +1,79 @@
+// Package tracing holds the OpenTelemetry conventions of Stratus Red Team: the runner records a span for
+// each lifecycle operation, with child spans for Terraform and for the cloud API calls made by the technique.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans of Stratus Red Team
+const TracerName = "github.com/datadog/stratus-red-team/v2"
+
+// EnvVarTracesExporter selects where 'stratus' commands export their traces, following the OpenTelemetry
+// convention: "otlp" (configured with the standard OTEL_EXPORTER_OTLP_* variables), "console" or "none".
+const EnvVarTracesExporter = "OTEL_TRACES_EXPORTER"
+
+const (
+	AttributeTechniqueID   = attribute.Key("stratus.technique.id")
+	AttributeCorrelationID = attribute.Key("stratus.correlation_id")
+	AttributePlatform      = attribute.Key("stratus.platform")
+)
+
+// Tracer returns the tracer to use under ctx: the one of the span it carries, if any. Instrumented code
+// that is not handed a tracer provider, like the Terraform manager or the cloud providers, uses it so
+// that its spans are children of the operation that called it, and are not recorded outside of one.
+func Tracer(ctx context.Context) trace.Tracer {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName)
+}
+
+// EndSpan records err, if any, on span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// NewTracerProviderFromEnv builds the tracer provider selected by OTEL_TRACES_EXPORTER, or returns nil if
+// traces are not exported. Spans are exported as soon as they end rather than in batches, so that none is
+// lost when a command exits early.
+func NewTracerProviderFromEnv(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := os.Getenv(EnvVarTracesExporter); name {
+	case "", "none":
+		return nil, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "console":
+		// Not on stdout, which holds the output of the command
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported value %q for %s, expected otlp, console or none", name, EnvVarTracesExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up the %s traces exporter: %w", os.Getenv(EnvVarTracesExporter), err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over the default service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "stratus-red-team")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build the traces resource: %w", err)
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter), sdktrace.WithResource(res)), nil
+}
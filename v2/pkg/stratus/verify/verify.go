@@ -0,0 +1,205 @@
+package verify
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/useragent"
+)
+
+// maxLogLineSize bounds the size of a single log line, as CloudTrail digests can be large
+const maxLogLineSize = 16 * 1024 * 1024
+
+// LogSchema locates, in the audit logs of a platform, the fields that expected events are matched on.
+// Fields are dot-separated paths in the event.
+type LogSchema struct {
+	// Empty when the logs have no notion of event source
+	EventSourceField string
+	EventNameField   string
+	UserAgentField   string
+}
+
+var (
+	CloudTrailSchema = LogSchema{
+		EventSourceField: "eventSource",
+		EventNameField:   "eventName",
+		UserAgentField:   "userAgent",
+	}
+	GCPAuditLogsSchema = LogSchema{
+		EventSourceField: "protoPayload.serviceName",
+		EventNameField:   "protoPayload.methodName",
+		UserAgentField:   "protoPayload.requestMetadata.callerSuppliedUserAgent",
+	}
+	KubernetesAuditLogsSchema = LogSchema{
+		EventNameField: "verb",
+		UserAgentField: "userAgent",
+	}
+)
+
+// SchemaForPlatform returns the schema of the audit logs in which the techniques of a platform are visible
+func SchemaForPlatform(platform stratus.Platform) (LogSchema, error) {
+	switch platform {
+	case stratus.AWS, stratus.EKS:
+		return CloudTrailSchema, nil
+	case stratus.GCP:
+		return GCPAuditLogsSchema, nil
+	case stratus.Kubernetes:
+		return KubernetesAuditLogsSchema, nil
+	default:
+		return LogSchema{}, fmt.Errorf("verifying logs is not supported for the %s platform", platform)
+	}
+}
+
+// CanVerify returns an error explaining why the logs of a technique cannot be verified, if they cannot:
+// its platform has no supported audit logs, or it does not declare the events it is expected to produce.
+func CanVerify(technique *stratus.AttackTechnique) error {
+	if _, err := SchemaForPlatform(technique.Platform); err != nil {
+		return err
+	}
+	if len(technique.ExpectedEvents) == 0 {
+		return fmt.Errorf("%s does not declare the events its detonation is expected to produce, so its logs cannot be verified", technique.ID)
+	}
+	return nil
+}
+
+// EventResult is the outcome of looking for an expected event in the logs
+type EventResult struct {
+	Expected    stratus.ExpectedEvent
+	Occurrences int
+}
+
+func (r *EventResult) Found() bool {
+	return r.Occurrences > 0
+}
+
+// Report is the outcome of verifying the logs of a technique
+type Report struct {
+	TechniqueID   string
+	CorrelationID string
+	// Number of events read from the logs
+	ScannedEvents int
+	// Number of events emitted by Stratus Red Team, for the correlation ID if set
+	StratusEvents int
+	Events        []EventResult
+}
+
+// Missing returns the expected events that were not found in the logs
+func (r *Report) Missing() []stratus.ExpectedEvent {
+	var missing []stratus.ExpectedEvent
+	for i := range r.Events {
+		if !r.Events[i].Found() {
+			missing = append(missing, r.Events[i].Expected)
+		}
+	}
+	return missing
+}
+
+// Verify looks for the expected events of a technique in newline-delimited JSON logs. Only events
+// whose user agent is the one of Stratus Red Team are considered, further restricted to a single
+// execution when correlationID is set. A line holding a CloudTrail digest ({"Records": [...]}) is
+// read as all the events it contains.
+func Verify(logs io.Reader, technique *stratus.AttackTechnique, correlationID string) (*Report, error) {
+	if err := CanVerify(technique); err != nil {
+		return nil, err
+	}
+	schema, _ := SchemaForPlatform(technique.Platform)
+
+	userAgent := useragent.StratusUserAgentPrefix + "_" + correlationID
+	report := &Report{TechniqueID: technique.ID, CorrelationID: correlationID}
+	for _, expected := range technique.ExpectedEvents {
+		report.Events = append(report.Events, EventResult{Expected: expected})
+	}
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(nil, maxLogLineSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		events, err := parseEvents([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse line %d of the logs: %w", lineNumber, err)
+		}
+		for _, event := range events {
+			report.ScannedEvents++
+			if !strings.Contains(lookup(event, schema.UserAgentField), userAgent) {
+				continue
+			}
+			report.StratusEvents++
+			for i := range report.Events {
+				if matches(event, schema, report.Events[i].Expected) {
+					report.Events[i].Occurrences++
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the logs: %w", err)
+	}
+	return report, nil
+}
+
+func parseEvents(line []byte) ([]map[string]any, error) {
+	var event map[string]any
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil, err
+	}
+	records, isDigest := event["Records"].([]any)
+	if !isDigest {
+		return []map[string]any{event}, nil
+	}
+	var events []map[string]any
+	for _, record := range records {
+		recordEvent, ok := record.(map[string]any)
+		if !ok {
+			return nil, errors.New("CloudTrail records must be JSON objects")
+		}
+		events = append(events, recordEvent)
+	}
+	return events, nil
+}
+
+func matches(event map[string]any, schema LogSchema, expected stratus.ExpectedEvent) bool {
+	if expected.EventSource != "" && schema.EventSourceField != "" && lookup(event, schema.EventSourceField) != expected.EventSource {
+		return false
+	}
+	if expected.EventName != "" && lookup(event, schema.EventNameField) != expected.EventName {
+		return false
+	}
+	for path, value := range expected.Fields {
+		if lookup(event, path) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value at a dot-separated path of an event, formatted as a string,
+// or "" if there is none
+func lookup(event map[string]any, path string) string {
+	var current any = event
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+		current = object[key]
+	}
+	switch value := current.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case map[string]any, []any:
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	default:
+		return fmt.Sprint(value)
+	}
+}
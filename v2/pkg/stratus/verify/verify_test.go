@@ -0,0 +1,120 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const correlationID = "c97089f1-1ae3-4ecc-b006-f5e8fd0f2571"
+
+var stopTrail = &stratus.AttackTechnique{
+	ID:       "aws.defense-evasion.cloudtrail-stop",
+	Platform: stratus.AWS,
+	ExpectedEvents: []stratus.ExpectedEvent{
+		{EventSource: "cloudtrail.amazonaws.com", EventName: "StopLogging"},
+		{EventSource: "cloudtrail.amazonaws.com", EventName: "DeleteTrail"},
+	},
+}
+
+func TestVerifyCloudTrailLogs(t *testing.T) {
+	logs := strings.Join([]string{
+		`{"eventSource": "cloudtrail.amazonaws.com", "eventName": "StopLogging", "userAgent": "stratus-red-team_` + correlationID + `"}`,
+		``,
+		`{"eventSource": "cloudtrail.amazonaws.com", "eventName": "DeleteTrail", "userAgent": "aws-cli/2.15.0"}`,
+		`{"eventSource": "cloudtrail.amazonaws.com", "eventName": "StopLogging", "userAgent": "stratus-red-team_00000000-0000-0000-0000-000000000000"}`,
+	}, "\n")
+
+	report, err := Verify(strings.NewReader(logs), stopTrail, correlationID)
+	require.Nil(t, err)
+	assert.Equal(t, 3, report.ScannedEvents)
+	assert.Equal(t, 1, report.StratusEvents)
+	assert.Equal(t, 1, report.Events[0].Occurrences)
+	assert.Equal(t, []stratus.ExpectedEvent{stopTrail.ExpectedEvents[1]}, report.Missing(), "events from other user agents should be ignored")
+
+	report, err = Verify(strings.NewReader(logs), stopTrail, "")
+	require.Nil(t, err)
+	assert.Equal(t, 2, report.StratusEvents, "without a correlation ID, any Stratus Red Team execution should count")
+	assert.Equal(t, 2, report.Events[0].Occurrences)
+}
+
+func TestVerifyCloudTrailDigest(t *testing.T) {
+	logs := `{"Records": [` +
+		`{"eventSource": "cloudtrail.amazonaws.com", "eventName": "StopLogging", "userAgent": "stratus-red-team_` + correlationID + `"},` +
+		`{"eventSource": "cloudtrail.amazonaws.com", "eventName": "DeleteTrail", "userAgent": "stratus-red-team_` + correlationID + `"}` +
+		`]}`
+
+	report, err := Verify(strings.NewReader(logs), stopTrail, correlationID)
+	require.Nil(t, err)
+	assert.Equal(t, 2, report.ScannedEvents)
+	assert.Empty(t, report.Missing())
+}
+
+func TestVerifyKubernetesAuditLogsWithFieldMatchers(t *testing.T) {
+	technique := &stratus.AttackTechnique{
+		ID:       "k8s.privilege-escalation.privileged-pod",
+		Platform: stratus.Kubernetes,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventName: "create", Fields: map[string]string{"objectRef.resource": "pods", "responseStatus.code": "201"}},
+		},
+	}
+	userAgent := `"userAgent": "stratus-red-team_` + correlationID + `"`
+	logs := strings.Join([]string{
+		`{"verb": "create", "objectRef": {"resource": "secrets"}, "responseStatus": {"code": 201}, ` + userAgent + `}`,
+		`{"verb": "create", "objectRef": {"resource": "pods"}, "responseStatus": {"code": 403}, ` + userAgent + `}`,
+	}, "\n")
+
+	report, err := Verify(strings.NewReader(logs), technique, correlationID)
+	require.Nil(t, err)
+	assert.Len(t, report.Missing(), 1)
+
+	logs += "\n" + `{"verb": "create", "objectRef": {"resource": "pods"}, "responseStatus": {"code": 201}, ` + userAgent + `}`
+	report, err = Verify(strings.NewReader(logs), technique, correlationID)
+	require.Nil(t, err)
+	assert.Empty(t, report.Missing())
+}
+
+func TestVerifyGCPAuditLogs(t *testing.T) {
+	technique := &stratus.AttackTechnique{
+		ID:       "gcp.credential-access.secretmanager-retrieve-secrets",
+		Platform: stratus.GCP,
+		ExpectedEvents: []stratus.ExpectedEvent{
+			{EventSource: "secretmanager.googleapis.com", EventName: "google.cloud.secretmanager.v1.SecretManagerService.AccessSecretVersion"},
+		},
+	}
+	logs := `{"protoPayload": {"serviceName": "secretmanager.googleapis.com", ` +
+		`"methodName": "google.cloud.secretmanager.v1.SecretManagerService.AccessSecretVersion", ` +
+		`"requestMetadata": {"callerSuppliedUserAgent": "stratus-red-team_` + correlationID + ` grpc-go/1.70.0,gzip(gfe)"}}}`
+
+	report, err := Verify(strings.NewReader(logs), technique, correlationID)
+	require.Nil(t, err)
+	assert.Empty(t, report.Missing())
+}
+
+func TestVerifyErrors(t *testing.T) {
+	_, err := Verify(strings.NewReader(`{}`+"\n"+`not json`), stopTrail, "")
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = Verify(strings.NewReader(""), &stratus.AttackTechnique{ID: "aws.test.technique", Platform: stratus.AWS}, "")
+	assert.ErrorContains(t, err, "does not declare the events")
+
+	_, err = Verify(strings.NewReader(""), &stratus.AttackTechnique{
+		ID:             "azure.test.technique",
+		Platform:       stratus.Azure,
+		ExpectedEvents: []stratus.ExpectedEvent{{EventName: "foo"}},
+	}, "")
+	assert.ErrorContains(t, err, "not supported")
+}
+
+func TestCanVerify(t *testing.T) {
+	assert.NoError(t, CanVerify(stopTrail))
+	assert.ErrorContains(t, CanVerify(&stratus.AttackTechnique{ID: "aws.test.technique", Platform: stratus.AWS}), "does not declare the events")
+	assert.ErrorContains(t, CanVerify(&stratus.AttackTechnique{
+		ID:             "entra-id.test.technique",
+		Platform:       stratus.EntraID,
+		ExpectedEvents: []stratus.ExpectedEvent{{EventName: "foo"}},
+	}), "not supported")
+}
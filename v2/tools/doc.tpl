@@ -5,13 +5,14 @@ title: {{.Technique.FriendlyName}}
 # {{.Technique.FriendlyName}}
 
 {{ if .Technique.IsSlow }} <span class="smallcaps w3-badge w3-orange w3-round w3-text-sand" title="This attack technique might be slow to warm up or detonate">slow</span> {{ end }}
-{{ if .Technique.IsIdempotent }} <span class="smallcaps w3-badge w3-blue w3-round w3-text-white" title="This attack technique can be detonated multiple times">idempotent</span> {{ end }}
+{{ if .Technique.IsIdempotent }} <span class="smallcaps w3-badge w3-blue w3-round w3-text-white" title="This attack technique can be detonated multiple times">idempotent</span> {{ end }}{{ if .Technique.IsDestructive }} <span class="smallcaps w3-badge w3-red w3-round w3-text-white" title="Detonating this attack technique requires --i-understand, as it may not be reverted">destructive</span> {{ end }}
 
 Platform: {{FormatPlatformName .Technique.Platform}}
 
 ## Mappings
 
-- MITRE ATT&CK{{JoinTactics .Technique.MitreAttackTactics "\n    - " "\n  - "}}
+- MITRE ATT&CK{{JoinTactics .Technique.MitreAttackTactics "\n    - " "\n  - "}}{{range MitreAttackTechniques .Technique}}
+    - [{{.FullName}}]({{.URL}}) ({{.ID}}){{end}}
 
 {{range .Technique.FrameworkMappings}}
 - {{.Framework}}:
@@ -28,7 +29,16 @@ Platform: {{FormatPlatformName .Technique.Platform}}
 
 ```bash title="Detonate with Stratus Red Team"
 stratus detonate {{.Technique.ID}}
-```{{ if .Technique.Detection }}
+```{{ if .Technique.Parameters }}
+
+## Parameters
+
+Set these under `techniques.{{.Technique.ID}}.parameters` in the [configuration file](../../../user-guide/getting-started/#technique-parameters), or with `--param key=value`.
+
+| Name | Type | Default | Description |
+|------|------|---------|-------------|
+{{range .Technique.Parameters}}| `{{.Name}}` | {{.Type}} | `{{.Default}}` | {{.Description}} |
+{{end}}{{ end }}{{ if .Technique.Detection }}
 ## Detection
 
 {{ .Technique.Detection }}
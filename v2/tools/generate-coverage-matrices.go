@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/datadog/stratus-red-team/v2/pkg/stratus"
+	"github.com/datadog/stratus-red-team/v2/pkg/stratus/mitreattack"
 )
 
 // Define MITRE ATT&CK tactic order from MITRE website
@@ -27,7 +29,7 @@ var mitreTacticOrder = []string{
 }
 
 // GenerateCoverageMatrices generates a single static .md file containing MITRE ATT&CK coverage tables split by platform
-func GenerateCoverageMatrices(index map[stratus.Platform]map[string][]*stratus.AttackTechnique, docsDirectory string) error {
+func GenerateCoverageMatrices(index map[stratus.Platform]map[string][]*stratus.AttackTechnique, techniques []*stratus.AttackTechnique, docsDirectory string) error {
 	outputFilePath := filepath.Join(docsDirectory, "attack-techniques", "mitre-attack-coverage-matrices.md")
 
 	if err := os.MkdirAll(filepath.Dir(outputFilePath), 0755); err != nil {
@@ -71,7 +73,7 @@ func GenerateCoverageMatrices(index map[stratus.Platform]map[string][]*stratus.A
 
 # MITRE ATT&CK Coverage by Platform
 
-This provides coverage matrices of MITRE ATT&CK tactics and techniques currently covered by Stratus Red Team for different cloud platforms.
+This provides coverage matrices of MITRE ATT&CK tactics and techniques currently covered by Stratus Red Team for different cloud platforms, followed by the Stratus Red Team techniques covering each MITRE ATT&CK technique.
 `
 
 	// Loop through each platform and generate tables
@@ -155,6 +157,8 @@ This provides coverage matrices of MITRE ATT&CK tactics and techniques currently
 		htmlContent += "</tbody>\n</table>\n</div>\n" // Close scrollable div
 	}
 
+	htmlContent += generateTechniqueCoverageTable(techniques)
+
 	// Write to Markdown file
 	if _, err := file.WriteString(htmlContent); err != nil {
 		return fmt.Errorf("failed to write to file: %w", err)
@@ -163,3 +167,36 @@ This provides coverage matrices of MITRE ATT&CK tactics and techniques currently
 	fmt.Printf("Generated MITRE ATT&CK coverage markdown file: %s\n", outputFilePath)
 	return nil
 }
+
+// generateTechniqueCoverageTable generates a table listing, for each MITRE ATT&CK technique and sub-technique, the
+// Stratus Red Team techniques that map to it
+func generateTechniqueCoverageTable(techniques []*stratus.AttackTechnique) string {
+	// MITRE ATT&CK technique ID => list of stratus techniques
+	coverage := make(map[string][]*stratus.AttackTechnique)
+	for _, technique := range techniques {
+		for _, id := range technique.MitreAttackTechniques {
+			coverage[id] = append(coverage[id], technique)
+		}
+	}
+
+	htmlContent := "<h2>Coverage by MITRE ATT&CK Technique</h2>\n"
+	htmlContent += `<div class="table-container">`
+	htmlContent += "<table>\n<thead><tr><th>MITRE ATT&CK Technique</th><th>Stratus Red Team Techniques</th></tr></thead>\n<tbody>\n"
+	for _, mitreTechnique := range mitreattack.GetAllMitreAttackTechniques() {
+		covered := coverage[mitreTechnique.ID]
+		if len(covered) == 0 {
+			continue
+		}
+		links := make([]string, 0, len(covered))
+		for _, technique := range covered {
+			platform, _ := technique.Platform.FormatName()
+			links = append(links, fmt.Sprintf("<a href=\"../%s/%s\">%s</a>", platform, technique.ID, technique.FriendlyName))
+		}
+		htmlContent += fmt.Sprintf(
+			"<tr><td style=\"text-align: left\"><a href=\"%s\">%s</a> (%s)</td><td style=\"text-align: left\">%s</td></tr>\n",
+			mitreTechnique.URL(), mitreTechnique.FullName(), mitreTechnique.ID, strings.Join(links, "<br/>"),
+		)
+	}
+	htmlContent += "</tbody>\n</table>\n</div>\n"
+	return htmlContent
+}
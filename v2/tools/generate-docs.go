@@ -28,7 +28,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := GenerateCoverageMatrices(index, docsDirectory); err != nil {
+	if err := GenerateCoverageMatrices(index, techniques, docsDirectory); err != nil {
 		fmt.Fprintln(os.Stderr, "Could not generate MITRE ATT&CK coverage file")
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
@@ -44,6 +44,10 @@ func GenerateTechDocs(docsDirectory string, techniques []*stratus.AttackTechniqu
 			}
 			return prefix + strings.Join(result, sep)
 		},
+		"MitreAttackTechniques": func(technique *stratus.AttackTechnique) []mitreattack.Technique {
+			techniques, _ := technique.GetMitreAttackTechniques()
+			return techniques
+		},
 		"FormatPlatformName": FormatPlatformName,
 	}
 